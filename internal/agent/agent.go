@@ -2,6 +2,7 @@ package agent
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -11,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"cli/internal/ui"
 )
 
 const (
@@ -31,19 +34,36 @@ var (
 )
 
 type userConfig struct {
-	Ollama ollamaConfig `json:"ollama"`
-	OpenAI openAIConfig `json:"openai"`
+	Ollama       ollamaConfig                 `json:"ollama"`
+	OpenAI       openAIConfig                 `json:"openai"`
+	ModelAliases map[string]map[string]string `json:"model_aliases"`
+	Repair       repairConfig                 `json:"repair"`
+	Fallback     []string                     `json:"fallback"`
+	Prices       map[string]ModelPrice        `json:"prices"`
+	Prompts      promptsConfig                `json:"prompts"`
+	Approver     ApproverConfig               `json:"approver"`
+}
+
+type repairConfig struct {
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	LocalFirst bool   `json:"local_first"`
 }
 
 type ollamaConfig struct {
-	BaseURL string `json:"base_url"`
-	Model   string `json:"model"`
+	BaseURL    string           `json:"base_url"`
+	Model      string           `json:"model"`
+	Generation generationConfig `json:"generation"`
 }
 
 type openAIConfig struct {
 	APIKey  string `json:"api_key"`
 	BaseURL string `json:"base_url"`
 	Model   string `json:"model"`
+	// FunctionCalling offers planner actions as tools (default true); turn
+	// it off for OpenAI-compatible servers without tool support.
+	FunctionCalling *bool            `json:"function_calling"`
+	Generation      generationConfig `json:"generation"`
 }
 
 type AskOptions struct {
@@ -51,15 +71,33 @@ type AskOptions struct {
 	Model        string
 	BaseURL      string
 	Temperature  *float64
+	TopP         *float64
 	MaxTokens    int
+	Stop         []string
 	JSONMode     bool
 	SystemPrompt string
+	// OnChunk, when set, switches the request to streaming and receives
+	// the response text as it arrives.
+	OnChunk func(string)
+	// Context cancels the request, including retries; nil means no
+	// cancellation.
+	Context context.Context
+	// Explain asks the planner for the alternatives it considered.
+	Explain bool
+	// DecisionTools offers the planner actions as OpenAI function-calling
+	// tools; a tool call comes back as decision JSON. Ollama ignores it.
+	DecisionTools bool
+
+	// decision marks planner calls, which use the "decision" generation
+	// settings of the provider.
+	decision bool
 }
 
 type AskResult struct {
 	Text     string
 	Provider string
 	Model    string
+	Usage    Usage
 }
 
 type SessionProvider struct {
@@ -78,10 +116,19 @@ type DecisionResult struct {
 	Args                []string
 	Reason              string
 	FunctionDescription string
+	Alternatives        []DecisionAlternative
 	Provider            string
 	Model               string
 }
 
+// DecisionAlternative is an action the planner considered but did not pick,
+// returned when AskOptions.Explain is set.
+type DecisionAlternative struct {
+	Action string
+	Target string
+	Reason string
+}
+
 func AskWithOptions(prompt string, opts AskOptions) (AskResult, error) {
 	text := strings.TrimSpace(prompt)
 	if text == "" {
@@ -101,29 +148,22 @@ func AskWithOptions(prompt string, opts AskOptions) (AskResult, error) {
 	switch provider {
 	case "ollama":
 		applyOllamaOverrides(&cfg, opts)
-		answer, model, err := askOllama(text, cfg.Ollama, opts)
+		answer, model, usage, err := askOllamaLogged(text, cfg.Ollama, opts)
 		if err != nil {
 			return AskResult{}, err
 		}
-		return AskResult{Text: answer, Provider: "ollama", Model: model}, nil
+		return AskResult{Text: answer, Provider: "ollama", Model: model, Usage: usage}, nil
 	case "openai":
 		applyOpenAIOverrides(&cfg, opts)
-		answer, model, err := askOpenAI(text, cfg.OpenAI, opts)
+		answer, model, usage, err := askOpenAILogged(text, cfg.OpenAI, opts)
 		if err != nil {
 			return AskResult{}, err
 		}
-		return AskResult{Text: answer, Provider: "openai", Model: model}, nil
+		return AskResult{Text: answer, Provider: "openai", Model: model, Usage: usage}, nil
 	case "auto":
 		applyOllamaOverrides(&cfg, opts)
-		if answer, model, err := askOllama(text, cfg.Ollama, opts); err == nil {
-			return AskResult{Text: answer, Provider: "ollama", Model: model}, nil
-		}
 		applyOpenAIOverrides(&cfg, opts)
-		answer, model, err := askOpenAI(text, cfg.OpenAI, opts)
-		if err != nil {
-			return AskResult{}, fmt.Errorf("ollama unavailable and openai fallback failed: %w", err)
-		}
-		return AskResult{Text: answer, Provider: "openai", Model: model}, nil
+		return askFallback(text, cfg, opts)
 	default:
 		return AskResult{}, fmt.Errorf("invalid provider %q (use auto|ollama|openai)", opts.Provider)
 	}
@@ -164,13 +204,7 @@ func ResolveSessionProvider(opts AskOptions) (SessionProvider, error) {
 		}
 		return newSessionProvider("openai", openAIModel, openAIBase), nil
 	case "auto":
-		if err := pingOllama(ollamaBase); err == nil {
-			return newSessionProvider("ollama", ollamaModel, ollamaBase), nil
-		}
-		if strings.TrimSpace(openAIKey) == "" {
-			return SessionProvider{}, fmt.Errorf("ollama unavailable and OpenAI API key is missing\n  Hint: run 'dm doctor' for diagnostics")
-		}
-		return newSessionProvider("openai", openAIModel, openAIBase), nil
+		return resolveFallbackProvider(cfg)
 	default:
 		return SessionProvider{}, fmt.Errorf("invalid provider %q (use auto|ollama|openai)", opts.Provider)
 	}
@@ -197,6 +231,12 @@ func newSessionProvider(provider, model, baseURL string) SessionProvider {
 const decisionTemperature = 0.2
 const decisionMaxTokens = 1024
 
+// DecisionSystemPrompt returns the planner system prompt for the given
+// catalogs, as sent by DecideWithPlugins.
+func DecisionSystemPrompt(pluginCatalog, toolCatalog string) string {
+	return buildDecisionSystemPrompt(pluginCatalog, toolCatalog)
+}
+
 func buildDecisionSystemPrompt(pluginCatalog, toolCatalog string) string {
 	if strings.TrimSpace(pluginCatalog) == "" {
 		pluginCatalog = "(none)"
@@ -233,28 +273,46 @@ func buildDecisionSystemPrompt(pluginCatalog, toolCatalog string) string {
 		"- If a required parameter cannot be inferred from the user request at all, return action=answer and ask the user.",
 		"- If a previous step failed with 'missing mandatory parameters', the NEXT attempt MUST include those parameters.",
 		"",
-		"Decision process (follow in order):",
-		"1. Identify the user's INTENT: what do they want to accomplish?",
-		"2. Find the best matching toolkit group [Name] in the catalog for that domain.",
-		"3. Pick the specific function whose name and synopsis best match the intent.",
-		"4. Check required params (*): can ALL of them be inferred from the user request? If not, action=answer and ask.",
-		"5. Map user values to the correct parameter names. Use defaults when the user did not specify optional params.",
-		"6. If no plugin or tool matches, consider action=answer for knowledge questions or action=create_function for new automation.",
-		"7. Put your reasoning in the \"reason\" field.",
-		"",
-		"General rules:",
-		"- If answering the request requires live data you do not have (git changes, file contents, system state, etc.), run the appropriate tool FIRST; your output will appear in the action history so the next step can use it.",
-		"- When asked to write a commit message: output ONLY one subject line in English, imperative mood, <=72 chars, no trailing period; summarize the main change and motivation (component + intent), avoid vague wording and avoid file names unless essential. Example: 'Add retry logic to HTTP client for transient failures'.",
-		"- action must be answer, run_plugin, run_tool, or create_function.",
-		"- Do not invent plugin or tool names; use only the catalog above.",
-		"- If the user request requires an operation that no existing plugin or tool can handle, return action=create_function.",
-		"- Only use create_function for tasks that genuinely need a new automation capability, not for general knowledge questions.",
-		"- If a plugin requires confirmation or is destructive, mention it in the answer.",
-		"- Tool arguments are already listed in the catalog after 'tool_args:'. Use those exact keys.",
+	}
+	cfg, _ := cachedUserConfig()
+	custom := loadPlannerPrompts(cfg)
+	if custom.Guidance != "" {
+		parts = append(parts, custom.Guidance)
+	} else {
+		parts = append(parts, defaultPlannerGuidance...)
+	}
+	if len(custom.Rules) > 0 {
+		parts = append(parts, "", "House rules (set by the user; follow them unless they conflict with the JSON format above):")
+		for _, r := range custom.Rules {
+			parts = append(parts, "- "+r)
+		}
 	}
 	return strings.Join(parts, "\n")
 }
 
+// defaultPlannerGuidance is the part of the planner prompt that
+// prompts.planner_guidance replaces; catalogs and the JSON contract stay.
+var defaultPlannerGuidance = []string{
+	"Decision process (follow in order):",
+	"1. Identify the user's INTENT: what do they want to accomplish?",
+	"2. Find the best matching toolkit group [Name] in the catalog for that domain.",
+	"3. Pick the specific function whose name and synopsis best match the intent.",
+	"4. Check required params (*): can ALL of them be inferred from the user request? If not, action=answer and ask.",
+	"5. Map user values to the correct parameter names. Use defaults when the user did not specify optional params.",
+	"6. If no plugin or tool matches, consider action=answer for knowledge questions or action=create_function for new automation.",
+	"7. Put your reasoning in the \"reason\" field.",
+	"",
+	"General rules:",
+	"- If answering the request requires live data you do not have (git changes, file contents, system state, etc.), run the appropriate tool FIRST; your output will appear in the action history so the next step can use it.",
+	"- When asked to write a commit message: output ONLY one subject line in English, imperative mood, <=72 chars, no trailing period; summarize the main change and motivation (component + intent), avoid vague wording and avoid file names unless essential. Example: 'Add retry logic to HTTP client for transient failures'.",
+	"- action must be answer, run_plugin, run_tool, or create_function.",
+	"- Do not invent plugin or tool names; use only the catalog above.",
+	"- If the user request requires an operation that no existing plugin or tool can handle, return action=create_function.",
+	"- Only use create_function for tasks that genuinely need a new automation capability, not for general knowledge questions.",
+	"- If a plugin requires confirmation or is destructive, mention it in the answer.",
+	"- Tool arguments are already listed in the catalog after 'tool_args:'. Use those exact keys.",
+}
+
 func buildDecisionUserPrompt(userPrompt, envContext string) string {
 	parts := []string{}
 	if strings.TrimSpace(envContext) != "" {
@@ -264,16 +322,24 @@ func buildDecisionUserPrompt(userPrompt, envContext string) string {
 	return strings.Join(parts, "\n")
 }
 
+const decisionExplainRules = `Explain mode:
+- Also include "alternatives": a list of up to 3 other actions you considered, best first, e.g. "alternatives":[{"action":"run_plugin","target":"name","reason":"why it was not chosen"}].
+- target is the plugin or tool name; leave it empty for answer or create_function.
+- Keep each reason under 20 words.`
+
 func decisionOpts(base AskOptions, systemPrompt string) AskOptions {
 	temp := decisionTemperature
 	return AskOptions{
-		Provider:     base.Provider,
-		Model:        base.Model,
-		BaseURL:      base.BaseURL,
-		Temperature:  &temp,
-		MaxTokens:    decisionMaxTokens,
-		JSONMode:     true,
-		SystemPrompt: systemPrompt,
+		Provider:      base.Provider,
+		Model:         base.Model,
+		BaseURL:       base.BaseURL,
+		Temperature:   &temp,
+		MaxTokens:     decisionMaxTokens,
+		JSONMode:      true,
+		SystemPrompt:  systemPrompt,
+		Context:       base.Context,
+		DecisionTools: true,
+		decision:      true,
 	}
 }
 
@@ -284,18 +350,31 @@ func DecideWithPlugins(userPrompt string, pluginCatalog string, toolCatalog stri
 	}
 
 	systemPrompt := buildDecisionSystemPrompt(pluginCatalog, toolCatalog)
+	if opts.Explain {
+		systemPrompt += "\n" + decisionExplainRules
+	}
 	userMsg := buildDecisionUserPrompt(p, envContext)
 	dOpts := decisionOpts(opts, systemPrompt)
+	if opts.OnChunk != nil {
+		dOpts.OnChunk = newAnswerStreamer(opts.OnChunk).feed
+	}
 
+	trace := newDecisionTrace()
+	defer trace.dump()
 	raw, err := AskWithOptions(userMsg, dOpts)
 	if err != nil {
+		trace.Error = err.Error()
 		return DecisionResult{}, err
 	}
 	parsed, err := parseDecisionJSON(raw.Text)
 	if err != nil {
+		trace.ParseError = err.Error()
 		slog.Warn("JSON parse failed, attempting repair", "error", err)
 		slog.Debug("raw LLM output for repair", "text", truncateLog(raw.Text, 300))
-		repaired, repErr := askDecisionJSONRepair(raw.Text, dOpts)
+		rOpts := repairOpts(dOpts)
+		rOpts.OnChunk = nil
+		repaired, repErr := askDecisionJSONRepair(raw.Text, rOpts)
+		trace.Repair = "failed"
 		if repErr == nil {
 			if parsed2, p2Err := parseDecisionJSON(repaired.Text); p2Err == nil {
 				slog.Warn("JSON repair succeeded", "action", parsed2.Action)
@@ -304,10 +383,15 @@ func DecideWithPlugins(userPrompt string, pluginCatalog string, toolCatalog stri
 				if parsed2.Action != "run_plugin" && parsed2.Action != "run_tool" && parsed2.Action != "create_function" {
 					parsed2.Action = "answer"
 				}
+				trace.Repair = "ok"
+				trace.setDecision(parsed2)
 				return parsed2, nil
 			}
+		} else {
+			trace.Repair = "failed: " + repErr.Error()
 		}
 		slog.Warn("JSON repair failed, falling back to raw answer")
+		trace.Action = "answer"
 		return DecisionResult{
 			Action:   "answer",
 			Answer:   raw.Text,
@@ -320,9 +404,44 @@ func DecideWithPlugins(userPrompt string, pluginCatalog string, toolCatalog stri
 	if parsed.Action != "run_plugin" && parsed.Action != "run_tool" && parsed.Action != "create_function" {
 		parsed.Action = "answer"
 	}
+	trace.setDecision(parsed)
 	return parsed, nil
 }
 
+func repairOpts(base AskOptions) AskOptions {
+	cfg, _ := cachedUserConfig()
+	return repairOptsFromConfig(base, cfg.Repair, func() (string, bool) {
+		ollamaBase, _ := resolvedOllama(cfg)
+		return ollamaBase, pingOllama(ollamaBase) == nil
+	})
+}
+
+func repairOptsFromConfig(base AskOptions, rc repairConfig, localOllama func() (string, bool)) AskOptions {
+	opts := base
+	provider := strings.ToLower(strings.TrimSpace(rc.Provider))
+	model := strings.TrimSpace(rc.Model)
+	if rc.LocalFirst && strings.ToLower(strings.TrimSpace(base.Provider)) != "ollama" {
+		if ollamaBase, ok := localOllama(); ok {
+			opts.Provider = "ollama"
+			opts.BaseURL = ollamaBase
+			opts.Model = ""
+			if provider == "" || provider == "ollama" {
+				opts.Model = model
+			}
+			return opts
+		}
+	}
+	if provider != "" && provider != strings.ToLower(strings.TrimSpace(base.Provider)) {
+		opts.Provider = provider
+		opts.BaseURL = ""
+		opts.Model = ""
+	}
+	if model != "" {
+		opts.Model = model
+	}
+	return opts
+}
+
 func askDecisionJSONRepair(rawText string, opts AskOptions) (AskResult, error) {
 	repairPrompt := strings.Join([]string{
 		"Convert the following text to valid JSON only.",
@@ -399,12 +518,29 @@ func parseDecisionJSON(text string) (DecisionResult, error) {
 		Args                []string       `json:"args"`
 		Reason              string         `json:"reason"`
 		FunctionDescription string         `json:"function_description"`
+		Alternatives        []struct {
+			Action string `json:"action"`
+			Target string `json:"target"`
+			Reason string `json:"reason"`
+		} `json:"alternatives"`
 	}
 	if err := json.Unmarshal([]byte(payload), &obj); err != nil {
 		return DecisionResult{}, err
 	}
 	pluginArgs := sanitizeAnyMap(obj.PluginArgs)
 	toolArgs := sanitizeAnyMap(obj.ToolArgs)
+	var alternatives []DecisionAlternative
+	for _, a := range obj.Alternatives {
+		alt := DecisionAlternative{
+			Action: strings.ToLower(strings.TrimSpace(a.Action)),
+			Target: strings.TrimSpace(a.Target),
+			Reason: strings.TrimSpace(a.Reason),
+		}
+		if alt.Action == "" && alt.Target == "" {
+			continue
+		}
+		alternatives = append(alternatives, alt)
+	}
 	return DecisionResult{
 		Action:              strings.ToLower(strings.TrimSpace(obj.Action)),
 		Answer:              strings.TrimSpace(obj.Answer),
@@ -415,6 +551,7 @@ func parseDecisionJSON(text string) (DecisionResult, error) {
 		Args:                obj.Args,
 		Reason:              strings.TrimSpace(obj.Reason),
 		FunctionDescription: strings.TrimSpace(obj.FunctionDescription),
+		Alternatives:        alternatives,
 	}, nil
 }
 
@@ -422,7 +559,7 @@ func truncateLog(s string, max int) string {
 	if len(s) <= max {
 		return s
 	}
-	return s[:max] + "..."
+	return ui.TruncateBytes(s, max) + "..."
 }
 
 func sanitizeAnyMap(m map[string]any) map[string]string {
@@ -463,8 +600,8 @@ func sanitizeAnyMap(m map[string]any) map[string]string {
 }
 
 func applyOllamaOverrides(cfg *userConfig, opts AskOptions) {
-	if strings.TrimSpace(opts.Model) != "" {
-		cfg.Ollama.Model = strings.TrimSpace(opts.Model)
+	if model := resolveModelOverride(*cfg, "ollama", opts.Model); model != "" {
+		cfg.Ollama.Model = model
 	}
 	if strings.TrimSpace(opts.BaseURL) != "" {
 		cfg.Ollama.BaseURL = strings.TrimSpace(opts.BaseURL)
@@ -472,14 +609,33 @@ func applyOllamaOverrides(cfg *userConfig, opts AskOptions) {
 }
 
 func applyOpenAIOverrides(cfg *userConfig, opts AskOptions) {
-	if strings.TrimSpace(opts.Model) != "" {
-		cfg.OpenAI.Model = strings.TrimSpace(opts.Model)
+	if model := resolveModelOverride(*cfg, "openai", opts.Model); model != "" {
+		cfg.OpenAI.Model = model
 	}
 	if strings.TrimSpace(opts.BaseURL) != "" {
 		cfg.OpenAI.BaseURL = strings.TrimSpace(opts.BaseURL)
 	}
 }
 
+// resolveModelOverride maps a --model value through model_aliases.
+// An alias without an entry for the provider (or "default") keeps the configured model.
+func resolveModelOverride(cfg userConfig, provider, model string) string {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return ""
+	}
+	for name, targets := range cfg.ModelAliases {
+		if !strings.EqualFold(strings.TrimSpace(name), model) {
+			continue
+		}
+		if m := strings.TrimSpace(targets[provider]); m != "" {
+			return m
+		}
+		return strings.TrimSpace(targets["default"])
+	}
+	return model
+}
+
 func loadUserConfig() (userConfig, error) {
 	for _, path := range configPaths() {
 		data, err := os.ReadFile(path)
@@ -505,6 +661,11 @@ func cachedUserConfig() (userConfig, error) {
 	return configCached, configErr
 }
 
+// ConfigPath returns the path of the agent config file in use.
+func ConfigPath() string {
+	return configPath()
+}
+
 func configPath() string {
 	paths := configPaths()
 	if len(paths) == 0 {
@@ -535,19 +696,33 @@ func configPathNearExecutable() string {
 	return filepath.Join(filepath.Dir(exe), "dm.agent.json")
 }
 
-func doWithRetry(buildReq func() (*http.Request, error)) (*http.Response, error) {
+func (o AskOptions) context() context.Context {
+	if o.Context == nil {
+		return context.Background()
+	}
+	return o.Context
+}
+
+func doWithRetry(ctx context.Context, buildReq func(context.Context) (*http.Request, error)) (*http.Response, error) {
 	var lastErr error
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
 			delay := retryDelay * time.Duration(1<<(attempt-1))
-			time.Sleep(delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
 		}
-		req, err := buildReq()
+		req, err := buildReq(ctx)
 		if err != nil {
 			return nil, err
 		}
 		res, err := sharedHTTPClient.Do(req)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			lastErr = err
 			continue
 		}
@@ -561,7 +736,23 @@ func doWithRetry(buildReq func() (*http.Request, error)) (*http.Response, error)
 	return nil, lastErr
 }
 
-func askOllama(prompt string, cfg ollamaConfig, opts AskOptions) (string, string, error) {
+func askOllamaLogged(prompt string, cfg ollamaConfig, opts AskOptions) (string, string, Usage, error) {
+	start := time.Now()
+	answer, model, usage, err := askOllama(prompt, cfg, opts)
+	dumpLLMExchange("ollama", model, opts, prompt, answer, err, time.Since(start), usage)
+	recordUsage("ollama", model, usage)
+	return answer, model, usage, err
+}
+
+func askOpenAILogged(prompt string, cfg openAIConfig, opts AskOptions) (string, string, Usage, error) {
+	start := time.Now()
+	answer, model, usage, err := askOpenAI(prompt, cfg, opts)
+	dumpLLMExchange("openai", model, opts, prompt, answer, err, time.Since(start), usage)
+	recordUsage("openai", model, usage)
+	return answer, model, usage, err
+}
+
+func askOllama(prompt string, cfg ollamaConfig, opts AskOptions) (string, string, Usage, error) {
 	baseURL, model := normalizedOllamaValues(cfg)
 	slog.Debug("LLM request", "provider", "ollama", "model", model, "prompt_chars", len(prompt))
 
@@ -576,27 +767,34 @@ func askOllama(prompt string, cfg ollamaConfig, opts AskOptions) (string, string
 	reqBody := map[string]any{
 		"model":    model,
 		"messages": messages,
-		"stream":   false,
+		"stream":   opts.OnChunk != nil,
 	}
 	if opts.JSONMode {
 		reqBody["format"] = "json"
 	}
+	opts = cfg.Generation.apply(opts)
 	ollamaOpts := map[string]any{}
 	if opts.Temperature != nil {
 		ollamaOpts["temperature"] = *opts.Temperature
 	}
+	if opts.TopP != nil {
+		ollamaOpts["top_p"] = *opts.TopP
+	}
 	if opts.MaxTokens > 0 {
 		ollamaOpts["num_predict"] = opts.MaxTokens
 	}
+	if len(opts.Stop) > 0 {
+		ollamaOpts["stop"] = opts.Stop
+	}
 	if len(ollamaOpts) > 0 {
 		reqBody["options"] = ollamaOpts
 	}
 	raw, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", model, err
+		return "", model, Usage{}, err
 	}
-	res, err := doWithRetry(func() (*http.Request, error) {
-		req, err := http.NewRequest(http.MethodPost, baseURL+"/api/chat", bytes.NewReader(raw))
+	res, err := doWithRetry(opts.context(), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/api/chat", bytes.NewReader(raw))
 		if err != nil {
 			return nil, err
 		}
@@ -604,31 +802,45 @@ func askOllama(prompt string, cfg ollamaConfig, opts AskOptions) (string, string
 		return req, nil
 	})
 	if err != nil {
-		return "", model, err
+		return "", model, Usage{}, err
 	}
 	defer res.Body.Close()
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return "", model, fmt.Errorf("ollama status: %s", res.Status)
+	if res.StatusCode == http.StatusNotFound {
+		return "", model, Usage{}, fmt.Errorf("ollama model %q not found (run 'ollama pull %s' or use --auto-pull)", model, model)
 	}
-	var parsed struct {
-		Message struct {
-			Content string `json:"content"`
-		} `json:"message"`
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", model, Usage{}, fmt.Errorf("ollama status: %s", res.Status)
 	}
-	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
-		return "", model, err
+	var content string
+	var usage Usage
+	if opts.OnChunk != nil {
+		if content, usage, err = readOllamaStream(res.Body, opts.OnChunk); err != nil {
+			return "", model, Usage{}, err
+		}
+	} else {
+		var parsed struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			ollamaUsage
+		}
+		if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+			return "", model, Usage{}, err
+		}
+		content = parsed.Message.Content
+		usage = parsed.usage()
 	}
-	answer := strings.TrimSpace(parsed.Message.Content)
+	answer := strings.TrimSpace(content)
 	if answer == "" {
-		return "", model, fmt.Errorf("empty ollama response")
+		return "", model, usage, fmt.Errorf("empty ollama response")
 	}
-	return answer, model, nil
+	return answer, model, usage, nil
 }
 
-func askOpenAI(prompt string, cfg openAIConfig, opts AskOptions) (string, string, error) {
+func askOpenAI(prompt string, cfg openAIConfig, opts AskOptions) (string, string, Usage, error) {
 	baseURL, model, apiKey := normalizedOpenAIValues(cfg)
 	if apiKey == "" {
-		return "", "", fmt.Errorf("missing OpenAI API key (set in %s or OPENAI_API_KEY)", configPath())
+		return "", "", Usage{}, fmt.Errorf("missing OpenAI API key (set in %s or OPENAI_API_KEY)", configPath())
 	}
 	slog.Debug("LLM request", "provider", "openai", "model", model, "prompt_chars", len(prompt))
 
@@ -636,6 +848,10 @@ func askOpenAI(prompt string, cfg openAIConfig, opts AskOptions) (string, string
 	if strings.TrimSpace(opts.SystemPrompt) != "" {
 		systemMsg = opts.SystemPrompt
 	}
+	useTools := opts.DecisionTools && cfg.functionCalling()
+	if useTools {
+		systemMsg += "\n\n" + decisionToolsNote
+	}
 
 	reqBody := map[string]any{
 		"model": model,
@@ -644,21 +860,36 @@ func askOpenAI(prompt string, cfg openAIConfig, opts AskOptions) (string, string
 			{"role": "user", "content": prompt},
 		},
 	}
+	opts = cfg.Generation.apply(opts)
 	if opts.Temperature != nil {
 		reqBody["temperature"] = *opts.Temperature
 	}
+	if opts.TopP != nil {
+		reqBody["top_p"] = *opts.TopP
+	}
 	if opts.MaxTokens > 0 {
 		reqBody["max_tokens"] = opts.MaxTokens
 	}
+	if len(opts.Stop) > 0 {
+		reqBody["stop"] = opts.Stop
+	}
 	if opts.JSONMode {
 		reqBody["response_format"] = map[string]string{"type": "json_object"}
 	}
+	if useTools {
+		reqBody["tools"] = decisionTools()
+		reqBody["tool_choice"] = "auto"
+	}
+	if opts.OnChunk != nil {
+		reqBody["stream"] = true
+		reqBody["stream_options"] = map[string]bool{"include_usage": true}
+	}
 	raw, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", model, err
+		return "", model, Usage{}, err
 	}
-	res, err := doWithRetry(func() (*http.Request, error) {
-		req, err := http.NewRequest(http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(raw))
+	res, err := doWithRetry(opts.context(), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat/completions", bytes.NewReader(raw))
 		if err != nil {
 			return nil, err
 		}
@@ -667,31 +898,52 @@ func askOpenAI(prompt string, cfg openAIConfig, opts AskOptions) (string, string
 		return req, nil
 	})
 	if err != nil {
-		return "", model, err
+		return "", model, Usage{}, err
 	}
 	defer res.Body.Close()
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return "", model, fmt.Errorf("openai status: %s", res.Status)
+		return "", model, Usage{}, fmt.Errorf("openai status: %s", res.Status)
+	}
+	if opts.OnChunk != nil {
+		content, calls, usage, err := readOpenAIStream(res.Body, opts.OnChunk)
+		if err != nil {
+			return "", model, Usage{}, err
+		}
+		answer := strings.TrimSpace(content)
+		if len(calls) > 0 {
+			answer = toolCallDecisionJSON(calls[0])
+		}
+		if answer == "" {
+			return "", model, usage, fmt.Errorf("empty openai content")
+		}
+		return answer, model, usage, nil
 	}
 
 	var parsed struct {
 		Choices []struct {
 			Message struct {
-				Content string `json:"content"`
+				Content   string           `json:"content"`
+				ToolCalls []openAIToolCall `json:"tool_calls"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage *openAIUsage `json:"usage"`
 	}
 	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
-		return "", model, err
+		return "", model, Usage{}, err
 	}
+	usage := parsed.Usage.usage()
 	if len(parsed.Choices) == 0 {
-		return "", model, fmt.Errorf("empty openai response")
+		return "", model, usage, fmt.Errorf("empty openai response")
+	}
+	msg := parsed.Choices[0].Message
+	answer := strings.TrimSpace(msg.Content)
+	if len(msg.ToolCalls) > 0 {
+		answer = toolCallDecisionJSON(msg.ToolCalls[0])
 	}
-	answer := strings.TrimSpace(parsed.Choices[0].Message.Content)
 	if answer == "" {
-		return "", model, fmt.Errorf("empty openai content")
+		return "", model, usage, fmt.Errorf("empty openai content")
 	}
-	return answer, model, nil
+	return answer, model, usage, nil
 }
 
 func resolvedOllama(cfg userConfig) (string, string) {
@@ -1,12 +1,17 @@
 package agent
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestParseDecisionJSON_Answer(t *testing.T) {
@@ -23,6 +28,20 @@ func TestParseDecisionJSON_Answer(t *testing.T) {
 	}
 }
 
+func TestParseDecisionJSON_Alternatives(t *testing.T) {
+	raw := `{"action":"run_tool","tool":"grep","alternatives":[{"action":"Run_Plugin","target":"g_search","reason":"needs a repo"},{"action":"","target":""}]}`
+	d, err := parseDecisionJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Alternatives) != 1 {
+		t.Fatalf("expected 1 alternative, got %+v", d.Alternatives)
+	}
+	if a := d.Alternatives[0]; a.Action != "run_plugin" || a.Target != "g_search" || a.Reason != "needs a repo" {
+		t.Fatalf("unexpected alternative %+v", a)
+	}
+}
+
 func TestParseDecisionJSON_RunPlugin(t *testing.T) {
 	raw := `{"action":"run_plugin","plugin":"restart_backend","args":["-Force"],"reason":"user asked"}`
 	d, err := parseDecisionJSON(raw)
@@ -375,8 +394,8 @@ func TestDoWithRetry_SuccessOnFirst(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	res, err := doWithRetry(func() (*http.Request, error) {
-		return http.NewRequest(http.MethodGet, srv.URL, nil)
+	res, err := doWithRetry(context.Background(), func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -404,8 +423,8 @@ func TestDoWithRetry_RetriesOn500(t *testing.T) {
 	origDelay := retryDelay
 	defer func() { retryDelay = origDelay }()
 
-	res, err := doWithRetry(func() (*http.Request, error) {
-		return http.NewRequest(http.MethodGet, srv.URL, nil)
+	res, err := doWithRetry(context.Background(), func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -424,8 +443,8 @@ func TestDoWithRetry_NoRetryOn4xx(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	res, err := doWithRetry(func() (*http.Request, error) {
-		return http.NewRequest(http.MethodGet, srv.URL, nil)
+	res, err := doWithRetry(context.Background(), func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -453,8 +472,8 @@ func TestDoWithRetry_RetriesOn429(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	res, err := doWithRetry(func() (*http.Request, error) {
-		return http.NewRequest(http.MethodGet, srv.URL, nil)
+	res, err := doWithRetry(context.Background(), func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -477,8 +496,8 @@ func TestDoWithRetry_ExhaustsRetries(t *testing.T) {
 	}))
 	defer srv.Close()
 
-	_, err := doWithRetry(func() (*http.Request, error) {
-		return http.NewRequest(http.MethodGet, srv.URL, nil)
+	_, err := doWithRetry(context.Background(), func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
 	})
 	if err == nil {
 		t.Fatal("expected error after exhausting retries")
@@ -487,3 +506,226 @@ func TestDoWithRetry_ExhaustsRetries(t *testing.T) {
 		t.Fatalf("expected 3 calls (1 initial + 2 retries), got %d", calls)
 	}
 }
+
+func TestDoWithRetry_StopsWhenCanceled(t *testing.T) {
+	origDelay := retryDelay
+	retryDelay = time.Hour
+	defer func() { retryDelay = origDelay }()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(500)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for atomic.LoadInt32(&calls) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+	_, err := doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("expected no retry after cancel, got %d calls", calls)
+	}
+}
+
+func TestBuildSummarizePrompt_CapsInput(t *testing.T) {
+	long := strings.Repeat("x", summarizeMaxInputChars+50)
+	p := buildSummarizePrompt(long, "list big files")
+	if !strings.Contains(p, "list big files") {
+		t.Fatal("expected user request in prompt")
+	}
+	if !strings.Contains(p, "(50 more characters omitted)") {
+		t.Fatalf("expected omitted marker, got tail %q", p[len(p)-60:])
+	}
+	if strings.Count(p, "x") > summarizeMaxInputChars+5 {
+		t.Fatal("expected output to be capped")
+	}
+}
+
+func TestResolveModelOverride_Aliases(t *testing.T) {
+	cfg := userConfig{ModelAliases: map[string]map[string]string{
+		"fast":  {"openai": "gpt-4o-mini", "ollama": "qwen2.5"},
+		"smart": {"default": "gpt-4.1"},
+		"local": {"ollama": "llama3"},
+	}}
+	cases := []struct {
+		provider, model, want string
+	}{
+		{"openai", "fast", "gpt-4o-mini"},
+		{"ollama", "FAST", "qwen2.5"},
+		{"ollama", "smart", "gpt-4.1"},
+		{"openai", "local", ""},
+		{"openai", "gpt-4o", "gpt-4o"},
+		{"openai", " ", ""},
+	}
+	for _, tc := range cases {
+		if got := resolveModelOverride(cfg, tc.provider, tc.model); got != tc.want {
+			t.Fatalf("resolveModelOverride(%q, %q) = %q, want %q", tc.provider, tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestApplyOpenAIOverrides_AliasKeepsConfiguredModel(t *testing.T) {
+	cfg := userConfig{
+		OpenAI:       openAIConfig{Model: "gpt-4o"},
+		ModelAliases: map[string]map[string]string{"local": {"ollama": "llama3"}},
+	}
+	applyOpenAIOverrides(&cfg, AskOptions{Model: "local"})
+	if cfg.OpenAI.Model != "gpt-4o" {
+		t.Fatalf("expected configured model to be kept, got %q", cfg.OpenAI.Model)
+	}
+}
+
+func TestOllamaModelInstalled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"models":[{"name":"qwen2.5:latest"},{"name":"llama3:8b"},{"name":"coder","model":"qwen2.5-coder:7b"}]}`))
+	}))
+	defer srv.Close()
+
+	for model, want := range map[string]bool{"qwen2.5": true, "llama3:8b": true, "llama3": false, "mistral": false, "coder": true, "qwen2.5-coder:7b": true} {
+		got, err := OllamaModelInstalled(srv.URL, model)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("OllamaModelInstalled(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestPullOllamaModel_ReportsProgressAndErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/pull" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("{\"status\":\"pulling manifest\"}\n{\"status\":\"downloading\",\"total\":100,\"completed\":50}\n{\"status\":\"success\"}\n"))
+	}))
+	defer srv.Close()
+
+	var events []PullProgress
+	if err := PullOllamaModel(srv.URL, "qwen2.5", func(p PullProgress) { events = append(events, p) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[1].Completed != 50 || events[2].Status != "success" {
+		t.Fatalf("unexpected progress events: %+v", events)
+	}
+
+	errSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{\"error\":\"model not found\"}\n"))
+	}))
+	defer errSrv.Close()
+	if err := PullOllamaModel(errSrv.URL, "nope", nil); err == nil {
+		t.Fatal("expected pull error")
+	}
+}
+
+func TestRepairOptsFromConfig(t *testing.T) {
+	base := AskOptions{Provider: "openai", Model: "gpt-4.1", BaseURL: "https://api.openai.com/v1", JSONMode: true}
+	noLocal := func() (string, bool) { return "", false }
+	withLocal := func() (string, bool) { return "http://127.0.0.1:11434", true }
+
+	got := repairOptsFromConfig(base, repairConfig{}, noLocal)
+	if got.Provider != "openai" || got.Model != "gpt-4.1" {
+		t.Fatalf("empty config should keep base opts, got %+v", got)
+	}
+
+	got = repairOptsFromConfig(base, repairConfig{Model: "gpt-4o-mini"}, noLocal)
+	if got.Provider != "openai" || got.Model != "gpt-4o-mini" || got.BaseURL != base.BaseURL {
+		t.Fatalf("expected cheaper model on same provider, got %+v", got)
+	}
+
+	got = repairOptsFromConfig(base, repairConfig{Provider: "ollama", Model: "qwen2.5"}, noLocal)
+	if got.Provider != "ollama" || got.Model != "qwen2.5" || got.BaseURL != "" {
+		t.Fatalf("expected provider switch with cleared base url, got %+v", got)
+	}
+
+	got = repairOptsFromConfig(base, repairConfig{LocalFirst: true}, withLocal)
+	if got.Provider != "ollama" || got.Model != "" || got.BaseURL != "http://127.0.0.1:11434" {
+		t.Fatalf("expected local-first repair, got %+v", got)
+	}
+	if !got.JSONMode {
+		t.Fatal("expected JSON mode to be preserved")
+	}
+
+	got = repairOptsFromConfig(base, repairConfig{LocalFirst: true, Model: "gpt-4o-mini", Provider: "openai"}, noLocal)
+	if got.Provider != "openai" || got.Model != "gpt-4o-mini" {
+		t.Fatalf("expected fallback to configured repair model, got %+v", got)
+	}
+}
+
+func TestDumpLLMExchange_WritesRedactedLog(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DM_LLM_DEBUG", "1")
+	t.Setenv("DM_LLM_DEBUG_DIR", dir)
+	llmDebugPath = ""
+	defer func() { llmDebugPath = "" }()
+
+	dumpLLMExchange("openai", "gpt-4o-mini", AskOptions{SystemPrompt: "sys"}, "token=abc12345 hello", "world", nil, 1500*time.Millisecond, Usage{PromptTokens: 7, CompletionTokens: 3})
+	trace := newDecisionTrace()
+	trace.ParseError = "unexpected end of JSON input"
+	trace.Repair = "ok"
+	trace.setDecision(DecisionResult{Action: "run_tool", Tool: "read"})
+	trace.dump()
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one debug log, got %v (err=%v)", entries, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, want := range []string{"--- prompt ---", "world", "latency=1.5s tokens=7/3", "decision latency=", "action: run_tool read", "parse error: unexpected end", "repair: ok"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in log content: %q", want, text)
+		}
+	}
+	if strings.Contains(text, "abc12345") {
+		t.Fatal("expected secret to be redacted")
+	}
+}
+
+func TestWriteLLMDebugPrunesOldLogs(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DM_LLM_DEBUG_DIR", dir)
+	llmDebugPath = ""
+	defer func() { llmDebugPath = "" }()
+	for i := 0; i < llmDebugKeep+5; i++ {
+		name := fmt.Sprintf("llm-20250101-%06d-1.log", i)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("old"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	writeLLMDebug("new exchange\n")
+
+	logs, _ := filepath.Glob(filepath.Join(dir, "llm-*.log"))
+	if len(logs) != llmDebugKeep {
+		t.Fatalf("expected %d logs after pruning, got %d", llmDebugKeep, len(logs))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "llm-20250101-000005-1.log")); err == nil {
+		t.Fatal("expected the oldest logs to be pruned")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "llm-20250101-000024-1.log")); err != nil {
+		t.Fatal("expected the newest old log to be kept")
+	}
+	if _, err := os.Stat(llmDebugPath); err != nil {
+		t.Fatalf("expected the new log to exist: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatal("pruning must only touch debug logs")
+	}
+}
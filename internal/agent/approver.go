@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"strings"
+	"time"
+)
+
+const defaultApproverTimeout = 5 * time.Minute
+
+// ApproverConfig points confirmations of agent actions at an external
+// approver instead of the terminal: a command that reads the request on
+// stdin, or a URL the request is POSTed to. TokenSecret names a secret
+// in the dm secret store to use instead of a plaintext Token.
+type ApproverConfig struct {
+	Command        string `json:"command"`
+	URL            string `json:"url"`
+	Token          string `json:"token"`
+	TokenSecret    string `json:"token_secret"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// Enabled reports whether an approver command or URL is configured.
+func (c ApproverConfig) Enabled() bool {
+	return strings.TrimSpace(c.Command) != "" || strings.TrimSpace(c.URL) != ""
+}
+
+// Timeout is how long to wait for a decision (default 5 minutes).
+func (c ApproverConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return defaultApproverTimeout
+}
+
+// Approver returns the "approver" section of the agent config.
+func Approver() ApproverConfig {
+	cfg, _ := cachedUserConfig()
+	return cfg.Approver
+}
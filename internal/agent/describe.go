@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type DescribeRequest struct {
+	Name   string
+	Script bool
+	Source string
+}
+
+type DescribeParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type DescribeResult struct {
+	Synopsis    string          `json:"synopsis"`
+	Description string          `json:"description"`
+	Parameters  []DescribeParam `json:"parameters"`
+	Examples    []string        `json:"examples"`
+}
+
+// DescribeFunction asks the model to document an undocumented plugin so
+// it gets a useful entry in the agent catalog.
+func DescribeFunction(req DescribeRequest, opts AskOptions) (DescribeResult, error) {
+	kind := "PowerShell function " + req.Name
+	if req.Script {
+		kind = "PowerShell script " + req.Name
+	}
+	prompt := strings.Join([]string{
+		"Write comment-based help for the " + kind + " below.",
+		"It is shown to an automation planner that picks plugins by their synopsis, so be specific about what it does and what it acts on.",
+		"",
+		"Rules:",
+		"- synopsis: one line, at most 100 characters, imperative mood, no trailing period.",
+		"- description: one or two sentences; mention side effects (writes, deletes, network).",
+		"- parameters: one entry per parameter in the param() block, using the exact parameter names, in order.",
+		"- examples: one or two realistic invocations using the function name.",
+		"- Do not invent parameters that are not in the code.",
+		"",
+		"Return ONLY valid JSON with this schema:",
+		`{"synopsis":"...","description":"...","parameters":[{"name":"Path","description":"..."}],"examples":["name -Path C:\\temp"]}`,
+		"",
+		"Code:",
+		req.Source,
+	}, "\n")
+
+	dOpts := opts
+	dOpts.JSONMode = true
+	dOpts.SystemPrompt = "You document PowerShell code precisely and concisely."
+	raw, err := AskWithOptions(prompt, dOpts)
+	if err != nil {
+		return DescribeResult{}, fmt.Errorf("describe LLM call failed: %w", err)
+	}
+	result, err := parseDescribeJSON(raw.Text)
+	if err != nil {
+		return DescribeResult{}, fmt.Errorf("failed to parse describe response: %w", err)
+	}
+	return result, nil
+}
+
+func parseDescribeJSON(text string) (DescribeResult, error) {
+	m := findFirstJSONObject(strings.TrimSpace(text))
+	if m == "" {
+		return DescribeResult{}, fmt.Errorf("no json object found in describe response")
+	}
+	var result DescribeResult
+	if err := json.Unmarshal([]byte(m), &result); err != nil {
+		return DescribeResult{}, err
+	}
+	result.Synopsis = strings.TrimSpace(result.Synopsis)
+	if result.Synopsis == "" {
+		return DescribeResult{}, fmt.Errorf("describe response has no synopsis")
+	}
+	return result, nil
+}
+
+// HelpBlock renders the result as a PowerShell comment-based help block.
+func (r DescribeResult) HelpBlock() string {
+	lines := []string{"<#", ".SYNOPSIS", r.Synopsis}
+	if d := strings.TrimSpace(r.Description); d != "" {
+		lines = append(lines, ".DESCRIPTION", d)
+	}
+	for _, p := range r.Parameters {
+		name := strings.TrimPrefix(strings.TrimSpace(p.Name), "$")
+		if name == "" {
+			continue
+		}
+		lines = append(lines, ".PARAMETER "+name, strings.TrimSpace(p.Description))
+	}
+	for _, e := range r.Examples {
+		if e = strings.TrimSpace(e); e != "" {
+			lines = append(lines, ".EXAMPLE", e)
+		}
+	}
+	lines = append(lines, "#>")
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,17 @@
+package agent
+
+import "testing"
+
+func TestParseDescribeJSONAndHelpBlock(t *testing.T) {
+	r, err := parseDescribeJSON(`{"synopsis":" Show git status ","description":"Read-only.","parameters":[{"name":"$Path","description":"Repository folder"}],"examples":["g_status -Path C:\\repo",""]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<#\n.SYNOPSIS\nShow git status\n.DESCRIPTION\nRead-only.\n.PARAMETER Path\nRepository folder\n.EXAMPLE\ng_status -Path C:\\repo\n#>"
+	if got := r.HelpBlock(); got != want {
+		t.Fatalf("HelpBlock() =\n%s\nwant\n%s", got, want)
+	}
+	if _, err := parseDescribeJSON(`{"synopsis":""}`); err == nil {
+		t.Fatal("expected an error for an empty synopsis")
+	}
+}
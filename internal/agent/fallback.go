@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+var defaultFallbackChain = []string{"ollama", "openai"}
+
+// fallbackChain returns the providers tried in order for --provider auto,
+// from the "fallback" list in dm.agent.json (default ollama, then openai).
+func fallbackChain(cfg userConfig) ([]string, error) {
+	if len(cfg.Fallback) == 0 {
+		return defaultFallbackChain, nil
+	}
+	var chain []string
+	seen := map[string]bool{}
+	for _, raw := range cfg.Fallback {
+		name := strings.ToLower(strings.TrimSpace(raw))
+		switch name {
+		case "":
+			continue
+		case "ollama", "openai":
+		default:
+			return nil, fmt.Errorf("unknown provider %q in fallback chain (supported: ollama, openai)", raw)
+		}
+		if !seen[name] {
+			seen[name] = true
+			chain = append(chain, name)
+		}
+	}
+	if len(chain) == 0 {
+		return defaultFallbackChain, nil
+	}
+	return chain, nil
+}
+
+// FallbackChain reports the configured chain used by --provider auto.
+func FallbackChain() ([]string, error) {
+	cfg, _ := cachedUserConfig()
+	return fallbackChain(cfg)
+}
+
+// checkProvider is the health check for one provider in the chain: a
+// reachable server for ollama, an API key for openai.
+func checkProvider(name string, cfg userConfig) (SessionProvider, error) {
+	switch name {
+	case "ollama":
+		base, model := resolvedOllama(cfg)
+		if err := pingOllama(base); err != nil {
+			return SessionProvider{}, err
+		}
+		return newSessionProvider("ollama", model, base), nil
+	case "openai":
+		base, model, key := resolvedOpenAI(cfg)
+		if strings.TrimSpace(key) == "" {
+			return SessionProvider{}, fmt.Errorf("API key is missing")
+		}
+		return newSessionProvider("openai", model, base), nil
+	}
+	return SessionProvider{}, fmt.Errorf("unsupported provider %q", name)
+}
+
+func resolveFallbackProvider(cfg userConfig) (SessionProvider, error) {
+	chain, err := fallbackChain(cfg)
+	if err != nil {
+		return SessionProvider{}, err
+	}
+	var failures []string
+	for _, name := range chain {
+		session, err := checkProvider(name, cfg)
+		if err == nil {
+			return session, nil
+		}
+		failures = append(failures, name+": "+err.Error())
+	}
+	return SessionProvider{}, fmt.Errorf("no provider in the fallback chain is available (%s)\n  Hint: run 'dm doctor' for diagnostics", strings.Join(failures, "; "))
+}
+
+func askFallback(text string, cfg userConfig, opts AskOptions) (AskResult, error) {
+	chain, err := fallbackChain(cfg)
+	if err != nil {
+		return AskResult{}, err
+	}
+	var failures []string
+	for _, name := range chain {
+		var answer, model string
+		var usage Usage
+		var err error
+		switch name {
+		case "ollama":
+			answer, model, usage, err = askOllamaLogged(text, cfg.Ollama, opts)
+		case "openai":
+			answer, model, usage, err = askOpenAILogged(text, cfg.OpenAI, opts)
+		}
+		if err == nil {
+			return AskResult{Text: answer, Provider: name, Model: model, Usage: usage}, nil
+		}
+		if ctxErr := opts.context().Err(); ctxErr != nil {
+			return AskResult{}, ctxErr
+		}
+		failures = append(failures, name+": "+err.Error())
+	}
+	return AskResult{}, fmt.Errorf("all providers in the fallback chain failed (%s)", strings.Join(failures, "; "))
+}
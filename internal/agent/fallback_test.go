@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFallbackChain(t *testing.T) {
+	chain, err := fallbackChain(userConfig{})
+	if err != nil || strings.Join(chain, ",") != "ollama,openai" {
+		t.Fatalf("default chain = %v, %v", chain, err)
+	}
+	chain, err = fallbackChain(userConfig{Fallback: []string{" OpenAI", "ollama", "openai", ""}})
+	if err != nil || strings.Join(chain, ",") != "openai,ollama" {
+		t.Fatalf("configured chain = %v, %v", chain, err)
+	}
+	if _, err := fallbackChain(userConfig{Fallback: []string{"ollama", "claude"}}); err == nil || !strings.Contains(err.Error(), "claude") {
+		t.Fatalf("expected unknown provider error, got %v", err)
+	}
+}
+
+func TestResolveFallbackProviderSkipsUnhealthy(t *testing.T) {
+	cfg := userConfig{
+		Fallback: []string{"openai", "ollama"},
+		Ollama:   ollamaConfig{BaseURL: "http://127.0.0.1:1", Model: "llama3"},
+		OpenAI:   openAIConfig{APIKey: "sk-test", Model: "gpt-4o-mini"},
+	}
+	s, err := resolveFallbackProvider(cfg)
+	if err != nil || s.Provider != "openai" {
+		t.Fatalf("expected openai first, got %+v, %v", s, err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"models":[]}`))
+	}))
+	defer srv.Close()
+	cfg.OpenAI.APIKey = ""
+	cfg.Ollama.BaseURL = srv.URL
+	s, err = resolveFallbackProvider(cfg)
+	if err != nil || s.Provider != "ollama" || s.Model != "llama3" {
+		t.Fatalf("expected fallback to ollama, got %+v, %v", s, err)
+	}
+
+	cfg.Ollama.BaseURL = "http://127.0.0.1:1"
+	_, err = resolveFallbackProvider(cfg)
+	if err == nil || !strings.Contains(err.Error(), "openai: API key is missing") || !strings.Contains(err.Error(), "ollama:") {
+		t.Fatalf("expected combined failure, got %v", err)
+	}
+}
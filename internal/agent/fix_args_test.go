@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseArgFix(t *testing.T) {
+	fix, err := parseArgFix("Sure:\n{\"plugin_args\":{\"Path\":\"C:\\\\Data\",\"Force\":true},\"reason\":\"path had a typo\"}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fix.PluginArgs["Path"] != `C:\Data` || fix.PluginArgs["Force"] != "true" || fix.Reason != "path had a typo" {
+		t.Fatalf("unexpected fix: %+v", fix)
+	}
+
+	fix, err = parseArgFix(`{"plugin_args":{},"reason":"the script itself is broken"}`)
+	if err != nil || len(fix.PluginArgs) != 0 {
+		t.Fatalf("expected no args, got %+v (err %v)", fix, err)
+	}
+	if _, err := parseArgFix("no idea"); err == nil {
+		t.Fatal("expected error without a JSON object")
+	}
+}
+
+func TestBuildArgFixPromptKeepsErrorTail(t *testing.T) {
+	out := strings.Repeat("é", argFixMaxOutputChars) + "\nRequired path 'D:\\x' does not exist."
+	p := buildArgFixPrompt(ArgFixRequest{
+		UserRequest: "back up my data",
+		Plugin:      "backup_run",
+		Parameters:  []string{"Path", "Target"},
+		Args:        map[string]string{"Target": "E:", "Path": "D:\\x"},
+		ErrorKind:   "not-found",
+		ErrorOutput: out,
+	})
+	for _, want := range []string{"Function: backup_run", "Declared parameters: Path, Target", `-Path "D:\\x" -Target "E:"`, "Failure kind: not-found", "does not exist."} {
+		if !strings.Contains(p, want) {
+			t.Fatalf("expected %q in prompt:\n%s", want, p)
+		}
+	}
+	if !strings.Contains(p, "\n...é") {
+		t.Fatal("expected the output to be cut on a rune boundary")
+	}
+}
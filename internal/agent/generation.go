@@ -0,0 +1,52 @@
+package agent
+
+// generationParams are sampling settings sent to a provider; unset fields
+// leave the provider's own defaults.
+type generationParams struct {
+	Temperature *float64 `json:"temperature"`
+	TopP        *float64 `json:"top_p"`
+	MaxTokens   int      `json:"max_tokens"`
+	Stop        []string `json:"stop"`
+}
+
+// generationConfig is the "generation" section of a provider in
+// dm.agent.json. The top-level settings fill in whatever a call does not
+// set itself; "decision" replaces the planner defaults (temperature 0.2,
+// 1024 tokens) for decision calls.
+type generationConfig struct {
+	generationParams
+	Decision generationParams `json:"decision"`
+}
+
+func (g generationConfig) apply(opts AskOptions) AskOptions {
+	if opts.decision {
+		p := g.Decision
+		if p.Temperature != nil {
+			opts.Temperature = p.Temperature
+		}
+		if p.TopP != nil {
+			opts.TopP = p.TopP
+		}
+		if p.MaxTokens > 0 {
+			opts.MaxTokens = p.MaxTokens
+		}
+		if len(p.Stop) > 0 {
+			opts.Stop = p.Stop
+		}
+		return opts
+	}
+	p := g.generationParams
+	if opts.Temperature == nil {
+		opts.Temperature = p.Temperature
+	}
+	if opts.TopP == nil {
+		opts.TopP = p.TopP
+	}
+	if opts.MaxTokens <= 0 {
+		opts.MaxTokens = p.MaxTokens
+	}
+	if len(opts.Stop) == 0 {
+		opts.Stop = p.Stop
+	}
+	return opts
+}
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenerationConfigApply(t *testing.T) {
+	var cfg generationConfig
+	if err := json.Unmarshal([]byte(`{"temperature":0.9,"top_p":0.8,"max_tokens":2000,"stop":["END"],"decision":{"temperature":0.05,"max_tokens":512}}`), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	answer := cfg.apply(AskOptions{})
+	if *answer.Temperature != 0.9 || *answer.TopP != 0.8 || answer.MaxTokens != 2000 || fmt.Sprint(answer.Stop) != "[END]" {
+		t.Fatalf("unexpected answer settings: %+v", answer)
+	}
+
+	own := 0.1
+	tuned := cfg.apply(AskOptions{Temperature: &own, MaxTokens: 400})
+	if *tuned.Temperature != 0.1 || tuned.MaxTokens != 400 || *tuned.TopP != 0.8 {
+		t.Fatalf("expected a call's own settings to win, got %+v", tuned)
+	}
+
+	d := cfg.apply(decisionOpts(AskOptions{}, "system"))
+	if *d.Temperature != 0.05 || d.MaxTokens != 512 || d.TopP != nil || len(d.Stop) != 0 {
+		t.Fatalf("expected decision settings only, got %+v", d)
+	}
+	d = generationConfig{}.apply(decisionOpts(AskOptions{}, "system"))
+	if *d.Temperature != decisionTemperature || d.MaxTokens != decisionMaxTokens {
+		t.Fatalf("expected planner defaults without config, got %+v", d)
+	}
+}
+
+func TestAskProvidersSendGenerationSettings(t *testing.T) {
+	var req map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = nil
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path == "/api/chat" {
+			_, _ = w.Write([]byte(`{"message":{"content":"hi"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	topP := 0.5
+	gen := generationConfig{generationParams: generationParams{TopP: &topP, Stop: []string{"###"}}}
+
+	if _, _, _, err := askOpenAI("hi", openAIConfig{APIKey: "k", BaseURL: srv.URL, Model: "m", Generation: gen}, AskOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if req["top_p"] != 0.5 || fmt.Sprint(req["stop"]) != "[###]" {
+		t.Fatalf("unexpected openai request: %v", req)
+	}
+
+	if _, _, _, err := askOllama("hi", ollamaConfig{BaseURL: srv.URL, Model: "m", Generation: gen}, AskOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	opts, _ := req["options"].(map[string]any)
+	if opts["top_p"] != 0.5 || fmt.Sprint(opts["stop"]) != "[###]" {
+		t.Fatalf("unexpected ollama options: %v", req["options"])
+	}
+}
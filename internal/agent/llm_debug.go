@@ -0,0 +1,155 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cli/internal/platform"
+	"cli/internal/redact"
+)
+
+// llmDebugKeep is how many per-process debug logs are kept; older ones are
+// pruned when a new log is opened.
+const llmDebugKeep = 20
+
+var (
+	llmDebugMu     sync.Mutex
+	llmDebugForced bool
+	llmDebugPath   string
+	llmDebugNow    = time.Now
+)
+
+// EnableLLMDebug turns on prompt/response dumps for this process (same as DM_LLM_DEBUG=1).
+func EnableLLMDebug() {
+	llmDebugMu.Lock()
+	llmDebugForced = true
+	llmDebugMu.Unlock()
+}
+
+func llmDebugActive() bool {
+	llmDebugMu.Lock()
+	forced := llmDebugForced
+	llmDebugMu.Unlock()
+	if forced {
+		return true
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("DM_LLM_DEBUG"))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+func LLMDebugDir() string {
+	if d := strings.TrimSpace(os.Getenv("DM_LLM_DEBUG_DIR")); d != "" {
+		return d
+	}
+	return platform.StatePath("llm-debug")
+}
+
+// writeLLMDebug appends text, redacted, to this process's debug log,
+// creating it on first use.
+func writeLLMDebug(text string) {
+	llmDebugMu.Lock()
+	defer llmDebugMu.Unlock()
+	if llmDebugPath == "" {
+		dir := LLMDebugDir()
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: cannot create LLM debug dir:", err)
+			return
+		}
+		pruneLLMDebugLogs(dir, llmDebugKeep-1)
+		llmDebugPath = filepath.Join(dir, "llm-"+llmDebugNow().Format("20060102-150405")+fmt.Sprintf("-%d.log", os.Getpid()))
+		fmt.Fprintln(os.Stderr, "LLM debug log:", llmDebugPath)
+	}
+	f, err := os.OpenFile(llmDebugPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: cannot write LLM debug log:", err)
+		return
+	}
+	defer f.Close()
+	_, _ = f.WriteString(redact.String(text))
+}
+
+// pruneLLMDebugLogs deletes all but the keep newest llm-*.log files in dir.
+// The names start with a timestamp, so they sort oldest first.
+func pruneLLMDebugLogs(dir string, keep int) {
+	logs, err := filepath.Glob(filepath.Join(dir, "llm-*.log"))
+	if err != nil || len(logs) <= keep {
+		return
+	}
+	sort.Strings(logs)
+	for _, p := range logs[:len(logs)-keep] {
+		_ = os.Remove(p)
+	}
+}
+
+func dumpLLMExchange(provider, model string, opts AskOptions, prompt, response string, callErr error, latency time.Duration, usage Usage) {
+	if !llmDebugActive() {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s %s/%s json=%t max_tokens=%d latency=%s tokens=%d/%d ===\n",
+		llmDebugNow().Format(time.RFC3339Nano), provider, model, opts.JSONMode, opts.MaxTokens,
+		latency.Round(time.Millisecond), usage.PromptTokens, usage.CompletionTokens)
+	if strings.TrimSpace(opts.SystemPrompt) != "" {
+		b.WriteString("--- system ---\n" + opts.SystemPrompt + "\n")
+	}
+	b.WriteString("--- prompt ---\n" + prompt + "\n")
+	b.WriteString("--- response ---\n" + response + "\n")
+	if callErr != nil {
+		b.WriteString("--- error ---\n" + callErr.Error() + "\n")
+	}
+	b.WriteString("\n")
+	writeLLMDebug(b.String())
+}
+
+// decisionTrace records how a planner call turned out: the parsed action,
+// a JSON parse failure and its repair, and the time it all took. It is
+// written after the exchanges the call made.
+type decisionTrace struct {
+	Action     string
+	Target     string
+	ParseError string
+	Repair     string
+	Error      string
+	start      time.Time
+}
+
+func newDecisionTrace() *decisionTrace {
+	return &decisionTrace{start: time.Now()}
+}
+
+func (t *decisionTrace) setDecision(d DecisionResult) {
+	t.Action = d.Action
+	switch d.Action {
+	case "run_plugin":
+		t.Target = d.Plugin
+	case "run_tool":
+		t.Target = d.Tool
+	}
+}
+
+func (t *decisionTrace) dump() {
+	if !llmDebugActive() {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== %s decision latency=%s ===\n", llmDebugNow().Format(time.RFC3339Nano), time.Since(t.start).Round(time.Millisecond))
+	if t.Error != "" {
+		b.WriteString("error: " + t.Error + "\n")
+	} else {
+		fmt.Fprintf(&b, "action: %s %s\n", t.Action, t.Target)
+	}
+	if t.ParseError != "" {
+		b.WriteString("parse error: " + t.ParseError + "\n")
+		b.WriteString("repair: " + t.Repair + "\n")
+	}
+	b.WriteString("\n")
+	writeLLMDebug(b.String())
+}
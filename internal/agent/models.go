@@ -0,0 +1,108 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProviderModels is the model list of one provider as reported by its API,
+// with the model dm would use for it.
+type ProviderModels struct {
+	Provider string   `json:"provider"`
+	BaseURL  string   `json:"base_url"`
+	Selected string   `json:"selected"`
+	Models   []string `json:"models"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// HasSelected reports whether the selected model is in the list; Ollama
+// names without a tag match ":latest".
+func (p ProviderModels) HasSelected() bool {
+	for _, m := range p.Models {
+		if p.IsSelected(m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p ProviderModels) IsSelected(model string) bool {
+	if p.Provider == "ollama" {
+		return normalizeOllamaModelName(model) == normalizeOllamaModelName(p.Selected)
+	}
+	return model == p.Selected
+}
+
+// ListModels queries the available models of each provider ("ollama",
+// "openai"); an empty list means both. opts.Model and opts.BaseURL are
+// applied as they would be for dm ask.
+func ListModels(providers []string, opts AskOptions) []ProviderModels {
+	cfg, _ := cachedUserConfig()
+	applyOllamaOverrides(&cfg, opts)
+	applyOpenAIOverrides(&cfg, opts)
+	if len(providers) == 0 {
+		providers = []string{"ollama", "openai"}
+	}
+	out := make([]ProviderModels, 0, len(providers))
+	for _, name := range providers {
+		var p ProviderModels
+		var err error
+		switch name {
+		case "ollama":
+			base, model := resolvedOllama(cfg)
+			p = ProviderModels{Provider: name, BaseURL: base, Selected: model}
+			p.Models, err = ollamaModelNames(base)
+		case "openai":
+			base, model, key := resolvedOpenAI(cfg)
+			p = ProviderModels{Provider: name, BaseURL: base, Selected: model}
+			p.Models, err = openAIModelNames(base, key)
+		default:
+			p = ProviderModels{Provider: name}
+			err = fmt.Errorf("invalid provider %q (use ollama|openai)", name)
+		}
+		if err != nil {
+			p.Error = err.Error()
+		}
+		sort.Strings(p.Models)
+		out = append(out, p)
+	}
+	return out
+}
+
+func openAIModelNames(baseURL, apiKey string) ([]string, error) {
+	if strings.TrimSpace(apiKey) == "" {
+		return nil, fmt.Errorf("missing OpenAI API key (set in %s or OPENAI_API_KEY)", configPath())
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(baseURL, "/")+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	res, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("openai status: %s", res.Status)
+	}
+	var parsed struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(parsed.Data))
+	for _, m := range parsed.Data {
+		if id := strings.TrimSpace(m.ID); id != "" {
+			names = append(names, id)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestOllamaModelNames(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"models":[{"name":"qwen2.5:latest"},{"model":"llama3:8b"}]}`))
+	}))
+	defer srv.Close()
+
+	got, err := ollamaModelNames(srv.URL)
+	if err != nil || !reflect.DeepEqual(got, []string{"qwen2.5:latest", "llama3:8b"}) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	p := ProviderModels{Provider: "ollama", Selected: "qwen2.5", Models: got}
+	if !p.HasSelected() || !p.IsSelected("qwen2.5:latest") {
+		t.Fatal("untagged ollama model should match :latest")
+	}
+}
+
+func TestOpenAIModelNames(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" || r.Header.Get("Authorization") != "Bearer k" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"gpt-4o-mini"},{"id":"gpt-4.1"}]}`))
+	}))
+	defer srv.Close()
+
+	got, err := openAIModelNames(srv.URL, "k")
+	if err != nil || !reflect.DeepEqual(got, []string{"gpt-4o-mini", "gpt-4.1"}) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	if _, err := openAIModelNames(srv.URL, "wrong"); err == nil {
+		t.Fatal("expected an error for a rejected key")
+	}
+	if _, err := openAIModelNames(srv.URL, ""); err == nil {
+		t.Fatal("expected an error without a key")
+	}
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type PullProgress struct {
+	Status    string
+	Total     int64
+	Completed int64
+}
+
+func OllamaModelInstalled(baseURL, model string) (bool, error) {
+	tags, err := ollamaTags(baseURL)
+	if err != nil {
+		return false, err
+	}
+	want := normalizeOllamaModelName(model)
+	for _, m := range tags {
+		if normalizeOllamaModelName(m.Name) == want || normalizeOllamaModelName(m.Model) == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ollamaTag is one entry of /api/tags; Ollama may fill name, model or both.
+type ollamaTag struct {
+	Name  string `json:"name"`
+	Model string `json:"model"`
+}
+
+func ollamaTags(baseURL string) ([]ollamaTag, error) {
+	u := strings.TrimRight(strings.TrimSpace(baseURL), "/") + "/api/tags"
+	client := &http.Client{Timeout: 5 * time.Second}
+	res, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("ollama status: %s", res.Status)
+	}
+	var parsed struct {
+		Models []ollamaTag `json:"models"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&parsed); err != nil {
+		return nil, err
+	}
+	return parsed.Models, nil
+}
+
+// ollamaModelNames lists the models installed on an Ollama server, one
+// display name per model.
+func ollamaModelNames(baseURL string) ([]string, error) {
+	tags, err := ollamaTags(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tags))
+	for _, m := range tags {
+		if n := strings.TrimSpace(m.Name); n != "" {
+			names = append(names, n)
+		} else if n := strings.TrimSpace(m.Model); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names, nil
+}
+
+func normalizeOllamaModelName(name string) string {
+	n := strings.ToLower(strings.TrimSpace(name))
+	if n != "" && !strings.Contains(n, ":") {
+		n += ":latest"
+	}
+	return n
+}
+
+func PullOllamaModel(baseURL, model string, progress func(PullProgress)) error {
+	raw, err := json.Marshal(map[string]any{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	u := strings.TrimRight(strings.TrimSpace(baseURL), "/") + "/api/pull"
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Pulls can take minutes; no client timeout.
+	res, err := (&http.Client{}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("ollama pull status: %s", res.Status)
+	}
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var ev struct {
+			Status    string `json:"status"`
+			Error     string `json:"error"`
+			Total     int64  `json:"total"`
+			Completed int64  `json:"completed"`
+		}
+		if err := json.Unmarshal(line, &ev); err != nil {
+			continue
+		}
+		if ev.Error != "" {
+			return fmt.Errorf("ollama pull failed: %s", ev.Error)
+		}
+		if progress != nil {
+			progress(PullProgress{Status: ev.Status, Total: ev.Total, Completed: ev.Completed})
+		}
+	}
+	return scanner.Err()
+}
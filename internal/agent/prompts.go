@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	plannerGuidanceFile = "planner_guidance.md"
+	plannerRulesFile    = "planner_rules.md"
+)
+
+// promptsConfig customizes the planner prompt from dm.agent.json. Files in
+// the prompts/ directory next to the config do the same and take
+// precedence for the guidance; rules from both are combined.
+type promptsConfig struct {
+	PlannerGuidance string   `json:"planner_guidance"`
+	PlannerRules    []string `json:"planner_rules"`
+}
+
+type plannerPrompts struct {
+	Guidance string
+	Rules    []string
+	Sources  []string
+}
+
+func promptsDir() string {
+	return filepath.Join(filepath.Dir(configPath()), "prompts")
+}
+
+func loadPlannerPrompts(cfg userConfig) plannerPrompts {
+	var p plannerPrompts
+	if g := strings.TrimSpace(cfg.Prompts.PlannerGuidance); g != "" {
+		p.Guidance = g
+		p.Sources = append(p.Sources, configPath()+" (prompts.planner_guidance)")
+	}
+	for _, r := range cfg.Prompts.PlannerRules {
+		if r = strings.TrimSpace(r); r != "" {
+			p.Rules = append(p.Rules, r)
+		}
+	}
+	if len(p.Rules) > 0 {
+		p.Sources = append(p.Sources, configPath()+" (prompts.planner_rules)")
+	}
+
+	dir := promptsDir()
+	if data, err := os.ReadFile(filepath.Join(dir, plannerGuidanceFile)); err == nil {
+		if g := strings.TrimSpace(string(data)); g != "" {
+			p.Guidance = g
+			p.Sources = append(p.Sources, filepath.Join(dir, plannerGuidanceFile))
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, plannerRulesFile)); err == nil {
+		rules := parsePromptRules(string(data))
+		if len(rules) > 0 {
+			p.Rules = append(p.Rules, rules...)
+			p.Sources = append(p.Sources, filepath.Join(dir, plannerRulesFile))
+		}
+	}
+	return p
+}
+
+// parsePromptRules reads one rule per line, ignoring blank lines, markdown
+// headings and list markers.
+func parsePromptRules(text string) []string {
+	var rules []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, marker := range []string{"- ", "* "} {
+			line = strings.TrimSpace(strings.TrimPrefix(line, marker))
+		}
+		if line != "" {
+			rules = append(rules, line)
+		}
+	}
+	return rules
+}
+
+// PlannerPromptSources lists where the planner prompt customization comes
+// from; it is empty when the built-in prompt is used unchanged.
+func PlannerPromptSources() []string {
+	cfg, _ := cachedUserConfig()
+	return loadPlannerPrompts(cfg).Sources
+}
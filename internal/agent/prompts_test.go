@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePromptRules(t *testing.T) {
+	got := parsePromptRules("# House rules\r\n\r\n- Answer in Italian\r\n* Keep answers short\r\nNever run git push\r\n")
+	want := []string{"Answer in Italian", "Keep answers short", "Never run git push"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected rules: %#v", got)
+	}
+}
+
+func TestLoadPlannerPromptsCombinesConfigAndFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DM_AGENT_CONFIG", filepath.Join(dir, "agent.json"))
+	if p := loadPlannerPrompts(userConfig{}); p.Guidance != "" || len(p.Rules) != 0 || len(p.Sources) != 0 {
+		t.Fatalf("expected no customization, got %+v", p)
+	}
+
+	cfg := userConfig{Prompts: promptsConfig{PlannerGuidance: "config guidance", PlannerRules: []string{"Answer in Italian", " "}}}
+	p := loadPlannerPrompts(cfg)
+	if p.Guidance != "config guidance" || !reflect.DeepEqual(p.Rules, []string{"Answer in Italian"}) || len(p.Sources) != 2 {
+		t.Fatalf("unexpected config prompts: %+v", p)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "prompts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "prompts", plannerGuidanceFile), []byte("file guidance\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "prompts", plannerRulesFile), []byte("- Never delete files\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p = loadPlannerPrompts(cfg)
+	if p.Guidance != "file guidance" {
+		t.Fatalf("file guidance should override config, got %q", p.Guidance)
+	}
+	if !reflect.DeepEqual(p.Rules, []string{"Answer in Italian", "Never delete files"}) {
+		t.Fatalf("rules not combined: %#v", p.Rules)
+	}
+	if len(p.Sources) != 4 {
+		t.Fatalf("unexpected sources: %#v", p.Sources)
+	}
+}
@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const streamMaxLine = 1024 * 1024
+
+// readOllamaStream reads the NDJSON body of a streaming /api/chat call,
+// passing each content delta to onChunk and returning the full text and
+// the token counts of the final chunk.
+func readOllamaStream(body io.Reader, onChunk func(string)) (string, Usage, error) {
+	var full strings.Builder
+	var usage Usage
+	sc := bufio.NewScanner(body)
+	sc.Buffer(make([]byte, 0, 64*1024), streamMaxLine)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var part struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			Done bool `json:"done"`
+			ollamaUsage
+		}
+		if err := json.Unmarshal([]byte(line), &part); err != nil {
+			return full.String(), usage, err
+		}
+		if part.Message.Content != "" {
+			full.WriteString(part.Message.Content)
+			onChunk(part.Message.Content)
+		}
+		if part.Done {
+			usage = part.usage()
+			break
+		}
+	}
+	return full.String(), usage, sc.Err()
+}
+
+// readOpenAIStream reads the server-sent events of a streaming
+// /chat/completions call, passing each content delta to onChunk and
+// collecting any tool calls. Usage is only reported when the request set
+// stream_options.include_usage.
+func readOpenAIStream(body io.Reader, onChunk func(string)) (string, []openAIToolCall, Usage, error) {
+	var full strings.Builder
+	var calls []openAIToolCall
+	var usage Usage
+	sc := bufio.NewScanner(body)
+	sc.Buffer(make([]byte, 0, 64*1024), streamMaxLine)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var part struct {
+			Choices []struct {
+				Delta struct {
+					Content   string                `json:"content"`
+					ToolCalls []openAIToolCallDelta `json:"tool_calls"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *openAIUsage `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &part); err != nil {
+			return full.String(), calls, usage, err
+		}
+		if part.Usage != nil {
+			usage = part.Usage.usage()
+		}
+		for _, c := range part.Choices {
+			if c.Delta.Content != "" {
+				full.WriteString(c.Delta.Content)
+				onChunk(c.Delta.Content)
+			}
+			for _, d := range c.Delta.ToolCalls {
+				calls = appendToolCallDelta(calls, d)
+			}
+		}
+	}
+	return full.String(), calls, usage, sc.Err()
+}
+
+var (
+	streamActionAnswerRe = regexp.MustCompile(`"action"\s*:\s*"answer"`)
+	streamAnswerKeyRe    = regexp.MustCompile(`"answer"\s*:\s*"`)
+)
+
+// answerStreamer pulls the "answer" string out of a decision JSON object
+// while it is still being generated. Text is only emitted when the action
+// is known to be "answer", so plugin and tool decisions stay silent.
+type answerStreamer struct {
+	emit    func(string)
+	buf     strings.Builder
+	state   int
+	pending string
+}
+
+const (
+	streamSearching = iota
+	streamInAnswer
+	streamDone
+)
+
+func newAnswerStreamer(emit func(string)) *answerStreamer {
+	return &answerStreamer{emit: emit}
+}
+
+func (s *answerStreamer) feed(chunk string) {
+	switch s.state {
+	case streamSearching:
+		s.buf.WriteString(chunk)
+		text := s.buf.String()
+		loc := streamAnswerKeyRe.FindStringIndex(text)
+		if loc == nil {
+			return
+		}
+		if !streamActionAnswerRe.MatchString(text[:loc[0]]) {
+			s.state = streamDone
+			return
+		}
+		s.state = streamInAnswer
+		s.decode(text[loc[1]:])
+	case streamInAnswer:
+		s.decode(chunk)
+	}
+}
+
+// decode unescapes JSON string content up to the closing quote. Escapes
+// split across chunks are kept in pending until they are complete.
+func (s *answerStreamer) decode(chunk string) {
+	text := s.pending + chunk
+	s.pending = ""
+	var out strings.Builder
+	i := 0
+loop:
+	for i < len(text) {
+		c := text[i]
+		switch c {
+		case '"':
+			s.state = streamDone
+			break loop
+		case '\\':
+			if i+1 >= len(text) {
+				s.pending = text[i:]
+				break loop
+			}
+			switch e := text[i+1]; e {
+			case 'n':
+				out.WriteByte('\n')
+			case 't':
+				out.WriteByte('\t')
+			case 'r':
+				out.WriteByte('\r')
+			case 'u':
+				if i+6 > len(text) {
+					s.pending = text[i:]
+					break loop
+				}
+				v, err := strconv.ParseUint(text[i+2:i+6], 16, 32)
+				if err == nil {
+					out.WriteRune(rune(v))
+				}
+				i += 6
+				continue
+			default:
+				out.WriteByte(e)
+			}
+			i += 2
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	if out.Len() > 0 {
+		s.emit(out.String())
+	}
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnswerStreamerEmitsAnswerAcrossChunks(t *testing.T) {
+	var got strings.Builder
+	s := newAnswerStreamer(func(c string) { got.WriteString(c) })
+	for _, c := range []string{`{"act`, `ion": "answer", "ans`, `wer": "Line one\`, `nLine \"two\" \u00`, `e9`, `", "reason": "x"}`} {
+		s.feed(c)
+	}
+	if want := "Line one\nLine \"two\" é"; got.String() != want {
+		t.Fatalf("got %q, want %q", got.String(), want)
+	}
+}
+
+func TestAnswerStreamerIgnoresOtherActions(t *testing.T) {
+	var got strings.Builder
+	s := newAnswerStreamer(func(c string) { got.WriteString(c) })
+	s.feed(`{"action":"run_tool","tool":"grep","answer":"searching"}`)
+	if got.Len() != 0 {
+		t.Fatalf("expected no output for run_tool, got %q", got.String())
+	}
+}
+
+func TestReadOllamaStream(t *testing.T) {
+	body := `{"message":{"content":"Hel"},"done":false}
+{"message":{"content":"lo"},"done":false}
+{"message":{"content":""},"done":true,"prompt_eval_count":12,"eval_count":3}
+`
+	var chunks []string
+	full, usage, err := readOllamaStream(strings.NewReader(body), func(c string) { chunks = append(chunks, c) })
+	if err != nil || full != "Hello" || len(chunks) != 2 {
+		t.Fatalf("got %q, %v, chunks %q", full, err, chunks)
+	}
+	if usage != (Usage{PromptTokens: 12, CompletionTokens: 3}) {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestReadOpenAIStream(t *testing.T) {
+	body := `data: {"choices":[{"delta":{"role":"assistant"}}]}
+
+data: {"choices":[{"delta":{"content":"Hi"}}]}
+
+data: {"choices":[{"delta":{"content":" there"}}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":20,"completion_tokens":2}}
+
+data: [DONE]
+`
+	var chunks []string
+	full, calls, usage, err := readOpenAIStream(strings.NewReader(body), func(c string) { chunks = append(chunks, c) })
+	if err != nil || full != "Hi there" || len(chunks) != 2 || len(calls) != 0 {
+		t.Fatalf("got %q, %v, chunks %q", full, err, chunks)
+	}
+	if usage != (Usage{PromptTokens: 20, CompletionTokens: 2}) {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+)
+
+// decisionToolsNote is added to the planner system prompt when the
+// decision actions are offered as OpenAI tools.
+const decisionToolsNote = `Function calling is enabled: call run_plugin, run_tool or create_function instead of writing their JSON. Use the answer JSON only to answer directly.`
+
+// openAIToolCall is a function call returned by /chat/completions.
+type openAIToolCall struct {
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+func stringProp(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+// decisionTools describes the planner actions other than answer as OpenAI
+// function-calling tools, mirroring the JSON schemas of the system prompt.
+func decisionTools() []map[string]any {
+	alternatives := map[string]any{
+		"type":        "array",
+		"description": "other actions considered, only when asked to explain",
+		"items": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"action": stringProp("answer, run_plugin, run_tool or create_function"),
+				"target": stringProp("plugin or tool name"),
+				"reason": stringProp("why it was not chosen"),
+			},
+		},
+	}
+	argsObject := func(description string) map[string]any {
+		return map[string]any{
+			"type":                 "object",
+			"description":          description,
+			"additionalProperties": map[string]any{"type": "string"},
+		}
+	}
+	tool := func(name, description string, props map[string]any, required ...string) map[string]any {
+		props["reason"] = stringProp("why this action was chosen")
+		props["alternatives"] = alternatives
+		return map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        name,
+				"description": description,
+				"parameters": map[string]any{
+					"type":       "object",
+					"properties": props,
+					"required":   required,
+				},
+			},
+		}
+	}
+	return []map[string]any{
+		tool("run_plugin", "Run a plugin (PowerShell function) from the catalog.", map[string]any{
+			"plugin":      stringProp("plugin name from the catalog"),
+			"plugin_args": argsObject("named parameters without the leading dash; switches are \"true\""),
+			"answer":      stringProp("optional text for the user"),
+		}, "plugin"),
+		tool("run_tool", "Run a built-in tool from the catalog.", map[string]any{
+			"tool":      stringProp("tool name from the catalog"),
+			"tool_args": argsObject("tool arguments, using the keys listed after tool_args:"),
+			"answer":    stringProp("optional text for the user"),
+		}, "tool"),
+		tool("create_function", "Propose a new function when no plugin or tool can handle the request.", map[string]any{
+			"function_description": stringProp("what the function should do, its inputs and outputs"),
+		}, "function_description"),
+	}
+}
+
+// toolCallDecisionJSON turns a planner tool call into the decision JSON
+// that parseDecisionJSON reads. Arguments that are not valid JSON are
+// returned as they are so the usual repair step can deal with them.
+func toolCallDecisionJSON(call openAIToolCall) string {
+	name := strings.TrimSpace(call.Function.Name)
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(call.Function.Arguments), &obj); err != nil || obj == nil {
+		slog.Debug("unreadable tool call arguments", "tool", name, "err", err)
+		return name + " " + call.Function.Arguments
+	}
+	obj["action"] = name
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return name + " " + call.Function.Arguments
+	}
+	return string(data)
+}
+
+// openAIToolCallDelta is one fragment of a streamed tool call.
+type openAIToolCallDelta struct {
+	Index    int `json:"index"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// appendToolCallDelta merges a streamed fragment into calls by index.
+func appendToolCallDelta(calls []openAIToolCall, d openAIToolCallDelta) []openAIToolCall {
+	if d.Index < 0 {
+		return calls
+	}
+	for len(calls) <= d.Index {
+		calls = append(calls, openAIToolCall{})
+	}
+	calls[d.Index].Function.Name += d.Function.Name
+	calls[d.Index].Function.Arguments += d.Function.Arguments
+	return calls
+}
+
+func (c openAIConfig) functionCalling() bool {
+	return c.FunctionCalling == nil || *c.FunctionCalling
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestToolCallDecisionJSON(t *testing.T) {
+	var call openAIToolCall
+	call.Function.Name = "run_plugin"
+	call.Function.Arguments = `{"plugin":"g_status","plugin_args":{"Path":"."},"reason":"show status"}`
+	d, err := parseDecisionJSON(toolCallDecisionJSON(call))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Action != "run_plugin" || d.Plugin != "g_status" || d.PluginArgs["Path"] != "." || d.Reason != "show status" {
+		t.Fatalf("unexpected decision: %+v", d)
+	}
+
+	call.Function.Arguments = `{"plugin":`
+	if _, err := parseDecisionJSON(toolCallDecisionJSON(call)); err == nil {
+		t.Fatal("expected broken arguments to need repair")
+	}
+}
+
+func TestReadOpenAIStreamToolCall(t *testing.T) {
+	body := `data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"name":"run_tool","arguments":""}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"tool\":"}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"grep\"}"}}]}}]}
+
+data: [DONE]
+`
+	full, calls, _, err := readOpenAIStream(strings.NewReader(body), func(string) {})
+	if err != nil || full != "" || len(calls) != 1 {
+		t.Fatalf("got %q, %v, calls %+v", full, err, calls)
+	}
+	if calls[0].Function.Name != "run_tool" || calls[0].Function.Arguments != `{"tool":"grep"}` {
+		t.Fatalf("unexpected call: %+v", calls[0])
+	}
+}
+
+func TestAskOpenAIDecisionTools(t *testing.T) {
+	var req map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":null,"tool_calls":[{"type":"function","function":{"name":"create_function","arguments":"{\"function_description\":\"zip a folder\"}"}}]}}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := openAIConfig{APIKey: "k", BaseURL: srv.URL, Model: "m"}
+	answer, _, _, err := askOpenAI("zip it", cfg, AskOptions{DecisionTools: true, JSONMode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tools, _ := req["tools"].([]any); len(tools) != 3 {
+		t.Fatalf("expected 3 tools in request, got %v", req["tools"])
+	}
+	d, err := parseDecisionJSON(answer)
+	if err != nil || d.Action != "create_function" || d.FunctionDescription != "zip a folder" {
+		t.Fatalf("unexpected decision %+v (%v) from %q", d, err, answer)
+	}
+
+	off := false
+	cfg.FunctionCalling = &off
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = nil
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"{\"action\":\"answer\",\"answer\":\"hi\"}"}}]}`))
+	})
+	if _, _, _, err := askOpenAI("hi", cfg, AskOptions{DecisionTools: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := req["tools"]; ok {
+		t.Fatal("tools should not be sent when function_calling is off")
+	}
+}
@@ -17,6 +17,10 @@ type BuilderRequest struct {
 	FunctionDescription string
 	ExistingToolkits    []ToolkitSummary
 	UserRequest         string
+	// FunctionName and Params pin the name and parameters, as listed in a
+	// toolkit spec file; the builder chooses them when empty.
+	FunctionName string
+	Params       []string
 }
 
 type BuilderResult struct {
@@ -106,7 +110,7 @@ func BuildFunction(req BuilderRequest, opts AskOptions) (BuilderResult, error) {
 		"",
 		"FUNCTION NEEDED:",
 		req.FunctionDescription,
-		"",
+		builderPinnedSignature(req),
 		"INSTRUCTIONS:",
 		"1. Decide if this function fits in an existing toolkit or needs a new one.",
 		"2. If it fits an existing toolkit, set target_file to that toolkit's file path and is_new_toolkit=false.",
@@ -148,6 +152,17 @@ func BuildFunction(req BuilderRequest, opts AskOptions) (BuilderResult, error) {
 	return result, nil
 }
 
+func builderPinnedSignature(req BuilderRequest) string {
+	var lines []string
+	if name := strings.TrimSpace(req.FunctionName); name != "" {
+		lines = append(lines, "FUNCTION NAME (use exactly): "+name)
+	}
+	if len(req.Params) > 0 {
+		lines = append(lines, "PARAMETERS (use exactly these names, in this order): "+strings.Join(req.Params, ", "))
+	}
+	return strings.Join(append(lines, ""), "\n")
+}
+
 func parseBuilderJSON(text string) (BuilderResult, error) {
 	trimmed := strings.TrimSpace(text)
 	if trimmed == "" {
@@ -0,0 +1,113 @@
+package agent
+
+import (
+	"strings"
+	"sync"
+)
+
+// Usage is the token count reported by a provider for one or more calls.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+func (u Usage) Add(o Usage) Usage {
+	return Usage{PromptTokens: u.PromptTokens + o.PromptTokens, CompletionTokens: u.CompletionTokens + o.CompletionTokens}
+}
+
+func (u Usage) Sub(o Usage) Usage {
+	return Usage{PromptTokens: u.PromptTokens - o.PromptTokens, CompletionTokens: u.CompletionTokens - o.CompletionTokens}
+}
+
+func (u Usage) IsZero() bool {
+	return u.PromptTokens == 0 && u.CompletionTokens == 0
+}
+
+// ModelPrice is the USD price per million tokens of a model; dm.agent.json
+// can override or extend the defaults under "prices".
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+var defaultModelPrices = map[string]ModelPrice{
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"gpt-4o":       {Input: 2.50, Output: 10},
+	"gpt-4.1":      {Input: 2, Output: 8},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+}
+
+type ollamaUsage struct {
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
+}
+
+func (u ollamaUsage) usage() Usage {
+	return Usage{PromptTokens: u.PromptEvalCount, CompletionTokens: u.EvalCount}
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+func (u *openAIUsage) usage() Usage {
+	if u == nil {
+		return Usage{}
+	}
+	return Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens}
+}
+
+var (
+	usageMu      sync.Mutex
+	usageByModel = map[string]Usage{}
+)
+
+func recordUsage(provider, model string, u Usage) {
+	if u.IsZero() {
+		return
+	}
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	key := provider + "/" + model
+	usageByModel[key] = usageByModel[key].Add(u)
+}
+
+// UsageByModel returns the tokens used so far in this process, keyed by
+// "provider/model".
+func UsageByModel() map[string]Usage {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	out := make(map[string]Usage, len(usageByModel))
+	for k, v := range usageByModel {
+		out[k] = v
+	}
+	return out
+}
+
+// EstimateCost returns the USD cost of u for a "provider/model" key.
+// Local ollama models are free; ok is false for models without a price.
+func EstimateCost(key string, u Usage) (float64, bool) {
+	provider, model, _ := strings.Cut(key, "/")
+	if provider == "ollama" {
+		return 0, true
+	}
+	cfg, _ := cachedUserConfig()
+	price, ok := modelPrice(cfg, model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(u.PromptTokens)*price.Input + float64(u.CompletionTokens)*price.Output) / 1e6, true
+}
+
+func modelPrice(cfg userConfig, model string) (ModelPrice, bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	for name, p := range cfg.Prices {
+		if strings.EqualFold(strings.TrimSpace(name), model) {
+			return p, true
+		}
+	}
+	p, ok := defaultModelPrices[model]
+	return p, ok
+}
@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRecordUsageAccumulates(t *testing.T) {
+	recordUsage("openai", "usage-test-model", Usage{PromptTokens: 10, CompletionTokens: 2})
+	recordUsage("openai", "usage-test-model", Usage{PromptTokens: 5, CompletionTokens: 1})
+	recordUsage("openai", "usage-test-model", Usage{})
+	got := UsageByModel()["openai/usage-test-model"]
+	if got != (Usage{PromptTokens: 15, CompletionTokens: 3}) {
+		t.Fatalf("unexpected usage: %+v", got)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	cost, ok := EstimateCost("openai/gpt-4o-mini", Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000})
+	if !ok || math.Abs(cost-0.75) > 1e-9 {
+		t.Fatalf("unexpected cost: %v %v", cost, ok)
+	}
+	if cost, ok := EstimateCost("ollama/llama3", Usage{PromptTokens: 500}); !ok || cost != 0 {
+		t.Fatalf("ollama should be free, got %v %v", cost, ok)
+	}
+	if _, ok := EstimateCost("openai/unknown-model", Usage{PromptTokens: 1}); ok {
+		t.Fatal("unknown model should have no price")
+	}
+}
+
+func TestModelPriceOverride(t *testing.T) {
+	cfg := userConfig{Prices: map[string]ModelPrice{"GPT-4o": {Input: 1, Output: 2}, "my-model": {Input: 3, Output: 4}}}
+	if p, ok := modelPrice(cfg, "gpt-4o"); !ok || p.Input != 1 || p.Output != 2 {
+		t.Fatalf("override not applied: %+v %v", p, ok)
+	}
+	if p, ok := modelPrice(cfg, "my-model"); !ok || p.Input != 3 {
+		t.Fatalf("custom model not priced: %+v %v", p, ok)
+	}
+	if p, ok := modelPrice(cfg, "gpt-4.1-nano"); !ok || p.Input != 0.10 {
+		t.Fatalf("default price missing: %+v %v", p, ok)
+	}
+}
@@ -0,0 +1,90 @@
+// Package aliastarget finds the folder or workflow file a dm alias points
+// at and checks that it still exists; `dm alias ls`, `dm jump` and
+// `dm doctor` share it.
+package aliastarget
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatTimeout bounds how long a check waits for a slow target, e.g. a
+// disconnected network drive.
+const StatTimeout = 750 * time.Millisecond
+
+var (
+	cdPattern     = regexp.MustCompile(`(?i)^(?:cd|chdir|sl|set-location|push-location|pushd)\s+(?:-(?:literal)?path\s+)?(.+)$`)
+	replayPattern = regexp.MustCompile(`--replay '((?:[^']|'')+)'`)
+)
+
+// IsWorkflow reports whether command replays a saved ask workflow.
+func IsWorkflow(command string) bool {
+	return replayPattern.MatchString(strings.TrimSpace(command))
+}
+
+// IsFolder reports whether command changes to a folder.
+func IsFolder(command string) bool {
+	return cdPattern.MatchString(strings.TrimSpace(command))
+}
+
+// Path returns the directory or workflow file an alias points at, or ""
+// when the command has no checkable target.
+func Path(command string) string {
+	command = strings.TrimSpace(command)
+	if m := replayPattern.FindStringSubmatch(command); m != nil {
+		return strings.ReplaceAll(m[1], "''", "'")
+	}
+	if m := cdPattern.FindStringSubmatch(command); m != nil {
+		p := strings.TrimSpace(m[1])
+		if len(p) >= 2 && (p[0] == '"' || p[0] == '\'') && p[len(p)-1] == p[0] {
+			p = p[1 : len(p)-1]
+		}
+		if p == "" || p == "-" || strings.ContainsAny(p, "$%;|") {
+			return ""
+		}
+		return p
+	}
+	return ""
+}
+
+// Check stats every alias target in parallel and returns a status
+// ("missing" or "unreachable") for the broken ones.
+func Check(aliases map[string]string, timeout time.Duration, stat func(string) (os.FileInfo, error)) map[string]string {
+	out := map[string]string{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, command := range aliases {
+		target := Path(command)
+		if target == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(name, target string) {
+			defer wg.Done()
+			done := make(chan error, 1)
+			go func() {
+				_, err := stat(target)
+				done <- err
+			}()
+			status := ""
+			select {
+			case err := <-done:
+				if err != nil {
+					status = "missing"
+				}
+			case <-time.After(timeout):
+				status = "unreachable"
+			}
+			if status != "" {
+				mu.Lock()
+				out[name] = status
+				mu.Unlock()
+			}
+		}(name, target)
+	}
+	wg.Wait()
+	return out
+}
@@ -0,0 +1,43 @@
+package aliastarget
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPath(t *testing.T) {
+	cases := map[string]string{
+		`cd C:\Users\Demtro\Downloads`:               `C:\Users\Demtro\Downloads`,
+		`Set-Location -Path "D:\Work Items"`:         `D:\Work Items`,
+		`& 'dm.exe' ask --replay 'C:\wf\it''s.json'`: `C:\wf\it's.json`,
+		`Get-ChildItem -Force`:                       "",
+		`cd $env:USERPROFILE`:                        "",
+	}
+	for in, want := range cases {
+		if got := Path(in); got != want {
+			t.Fatalf("Path(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	aliases := map[string]string{
+		"ok":      "cd " + dir,
+		"gone":    "cd " + filepath.Join(dir, "missing"),
+		"slow":    `cd \\nas\share`,
+		"command": "Get-ChildItem",
+	}
+	stat := func(p string) (os.FileInfo, error) {
+		if p == `\\nas\share` {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return os.Stat(p)
+	}
+	got := Check(aliases, 50*time.Millisecond, stat)
+	if len(got) != 2 || got["gone"] != "missing" || got["slow"] != "unreachable" {
+		t.Fatalf("unexpected statuses: %v", got)
+	}
+}
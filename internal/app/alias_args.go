@@ -0,0 +1,76 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Alias commands may reference extra arguments with {{1}}..{{9}} or {{*}};
+// without placeholders the arguments are appended.
+var aliasArgPlaceholder = regexp.MustCompile(`\{\{\s*([1-9]|\*)\s*\}\}`)
+
+var plainPowerShellArg = regexp.MustCompile(`^[A-Za-z0-9_\-./:\\=,+@]+$`)
+
+// powerShellArg quotes a value unless it is a bare word, so that switches
+// such as -Recurse keep working as parameters.
+func powerShellArg(s string) string {
+	if plainPowerShellArg.MatchString(s) {
+		return s
+	}
+	return "'" + escapePowerShellSingleQuoted(s) + "'"
+}
+
+// expandAliasCommand builds the PowerShell command for `dm alias run`.
+func expandAliasCommand(name, command string, extra []string) (string, error) {
+	quoted := make([]string, len(extra))
+	for i, a := range extra {
+		quoted[i] = powerShellArg(a)
+	}
+	if aliasArgPlaceholder.MatchString(command) {
+		var missing []string
+		out := aliasArgPlaceholder.ReplaceAllStringFunc(command, func(m string) string {
+			key := aliasArgPlaceholder.FindStringSubmatch(m)[1]
+			if key == "*" {
+				return strings.Join(quoted, " ")
+			}
+			n, _ := strconv.Atoi(key)
+			if n > len(quoted) {
+				missing = append(missing, m)
+				return m
+			}
+			return quoted[n-1]
+		})
+		if len(missing) > 0 {
+			return "", fmt.Errorf("alias %s expects argument %s", name, strings.Join(missing, ", "))
+		}
+		return out, nil
+	}
+	if len(quoted) == 0 {
+		return command, nil
+	}
+	if strings.Contains(strings.ToLower(command), "$args") {
+		return "& { " + command + " } " + strings.Join(quoted, " "), nil
+	}
+	return command + " " + strings.Join(quoted, " "), nil
+}
+
+// aliasProfileScript is the body of the $PROFILE function for an alias:
+// placeholders map to $args and plain commands forward @args.
+func aliasProfileScript(command string) string {
+	if aliasArgPlaceholder.MatchString(command) {
+		return aliasArgPlaceholder.ReplaceAllStringFunc(command, func(m string) string {
+			key := aliasArgPlaceholder.FindStringSubmatch(m)[1]
+			if key == "*" {
+				return "@args"
+			}
+			n, _ := strconv.Atoi(key)
+			return fmt.Sprintf("$args[%d]", n-1)
+		})
+	}
+	if strings.Contains(strings.ToLower(command), "$args") {
+		return command
+	}
+	return command + " @args"
+}
@@ -0,0 +1,39 @@
+package app
+
+import "testing"
+
+func TestExpandAliasCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		extra   []string
+		want    string
+	}{
+		{"Get-ChildItem -Force", nil, "Get-ChildItem -Force"},
+		{"Get-ChildItem -Force", []string{"-Recurse", "My Docs"}, "Get-ChildItem -Force -Recurse 'My Docs'"},
+		{"git log -n {{1}} -- {{2}}", []string{"5", "main.go"}, "git log -n 5 -- main.go"},
+		{"Write-Host {{*}}", []string{"it's", "ok"}, "Write-Host 'it''s' ok"},
+		{"Write-Host $args[0]", []string{"hi"}, "& { Write-Host $args[0] } hi"},
+	}
+	for _, tt := range tests {
+		got, err := expandAliasCommand("x", tt.command, tt.extra)
+		if err != nil || got != tt.want {
+			t.Fatalf("expandAliasCommand(%q, %v) = %q, %v; want %q", tt.command, tt.extra, got, err, tt.want)
+		}
+	}
+	if _, err := expandAliasCommand("x", "ping {{1}} {{2}}", []string{"host"}); err == nil {
+		t.Fatal("expected error for missing positional argument")
+	}
+}
+
+func TestAliasProfileScript(t *testing.T) {
+	tests := map[string]string{
+		"Get-ChildItem -Force":      "Get-ChildItem -Force @args",
+		"git log -n {{1}} -- {{*}}": "git log -n $args[0] -- @args",
+		"Write-Host $args":          "Write-Host $args",
+	}
+	for in, want := range tests {
+		if got := aliasProfileScript(in); got != want {
+			t.Fatalf("aliasProfileScript(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cli/internal/aliastarget"
+	"cli/internal/ui"
+)
+
+// aliasListFold is how many aliases a category shows before folding the
+// rest into an "and N more" line; --all lists everything.
+const aliasListFold = 10
+
+// aliasCategory groups an alias by what it runs: folder jumps, workflow
+// replays, or the program its command starts with.
+func aliasCategory(command string) string {
+	command = strings.TrimSpace(command)
+	switch {
+	case aliastarget.IsWorkflow(command):
+		return "workflows"
+	case aliastarget.IsFolder(command):
+		return "folders"
+	}
+	command = strings.TrimSpace(strings.TrimPrefix(command, "&"))
+	prog := ""
+	if command != "" && (command[0] == '"' || command[0] == '\'') {
+		if end := strings.IndexByte(command[1:], command[0]); end >= 0 {
+			prog = command[1 : end+1]
+		}
+	} else if fields := strings.Fields(command); len(fields) > 0 {
+		prog = fields[0]
+	}
+	prog = strings.ToLower(filepath.Base(strings.ReplaceAll(prog, `\`, "/")))
+	prog = strings.TrimSuffix(prog, filepath.Ext(prog))
+	if prog == "" || prog == "." {
+		return "other"
+	}
+	return prog
+}
+
+// printAliasList prints aliases grouped by category with per-category
+// counts. Broken aliases are listed first so folding never hides them.
+func printAliasList(aliases map[string]string, broken map[string]string, all bool) {
+	groups := map[string][]string{}
+	for _, name := range sortedAliasNames(aliases) {
+		c := aliasCategory(aliases[name])
+		groups[c] = append(groups[c], name)
+	}
+	categories := make([]string, 0, len(groups))
+	for c := range groups {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+
+	for i, c := range categories {
+		names := groups[c]
+		sort.SliceStable(names, func(a, b int) bool {
+			return broken[names[a]] != "" && broken[names[b]] == ""
+		})
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s %s\n", ui.Accent(c), ui.Muted(fmt.Sprintf("(%d)", len(names))))
+		shown := names
+		if !all && len(names) > aliasListFold {
+			shown = names[:aliasListFold]
+		}
+		for _, name := range shown {
+			line := fmt.Sprintf("  %s -> %s", name, aliases[name])
+			if status := broken[name]; status != "" {
+				line += " " + ui.Error("["+status+"]")
+			}
+			fmt.Println(line)
+		}
+		if hidden := len(names) - len(shown); hidden > 0 {
+			fmt.Println(ui.Muted(fmt.Sprintf("  and %d more… (use --all)", hidden)))
+		}
+	}
+	fmt.Println()
+	fmt.Println(ui.Muted(fmt.Sprintf("%d aliases in %d categories", len(aliases), len(categories))))
+}
@@ -0,0 +1,21 @@
+package app
+
+import "testing"
+
+func TestAliasCategory(t *testing.T) {
+	cases := map[string]string{
+		`cd "C:\Users\me\Downloads"`:                       "folders",
+		`Set-Location -Path D:\work`:                       "folders",
+		`dm ask --replay 'C:\state\workflows\deploy.json'`: "workflows",
+		`git log -n {{1}}`:                                 "git",
+		`& "C:\Program Files\Docker\docker.exe" ps`:        "docker",
+		`Get-ChildItem -Force`:                             "get-childitem",
+		`  `:                                               "other",
+		`C:\tools\backup.ps1 -Target nas`:                  "backup",
+	}
+	for command, want := range cases {
+		if got := aliasCategory(command); got != want {
+			t.Fatalf("aliasCategory(%q) = %q, want %q", command, got, want)
+		}
+	}
+}
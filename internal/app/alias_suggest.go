@@ -0,0 +1,70 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"cli/internal/history"
+)
+
+// rankAliasMatches returns aliases whose name starts with (first) or contains
+// partial, each group ordered by how often and how recently it was run.
+func rankAliasMatches(aliases map[string]string, partial string, runs map[string]history.Record, at time.Time) []string {
+	p := strings.ToLower(strings.TrimSpace(partial))
+	type candidate struct {
+		name  string
+		group int
+		score float64
+	}
+	var items []candidate
+	for name := range aliases {
+		group := 0
+		switch {
+		case strings.HasPrefix(name, p):
+		case strings.Contains(name, p):
+			group = 1
+		default:
+			continue
+		}
+		items = append(items, candidate{name: name, group: group, score: history.Score(runs[history.Key(history.KindAlias, name)], at)})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].group != items[j].group {
+			return items[i].group < items[j].group
+		}
+		if items[i].score != items[j].score {
+			return items[i].score > items[j].score
+		}
+		return items[i].name < items[j].name
+	})
+	out := make([]string, len(items))
+	for i, c := range items {
+		out[i] = c.name
+	}
+	return out
+}
+
+// resolveAliasName returns input when it is an alias, or the only alias
+// it is a prefix of. Otherwise ok is false and candidates lists the
+// aliases matching input, best frecency first.
+func resolveAliasName(aliases map[string]string, input string) (name string, candidates []string, ok bool) {
+	name = strings.ToLower(strings.TrimSpace(input))
+	if _, exact := aliases[name]; exact {
+		return name, nil, true
+	}
+	if name == "" {
+		return "", nil, false
+	}
+	matches := rankAliasMatches(aliases, name, history.Load(), time.Now())
+	var prefixed []string
+	for _, m := range matches {
+		if strings.HasPrefix(m, name) {
+			prefixed = append(prefixed, m)
+		}
+	}
+	if len(prefixed) == 1 {
+		return prefixed[0], nil, true
+	}
+	return "", matches, false
+}
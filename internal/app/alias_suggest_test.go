@@ -0,0 +1,49 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"cli/internal/history"
+)
+
+func TestRankAliasMatchesPrefersPrefixThenFrecency(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	aliases := map[string]string{
+		"docs":     "cd D:\\docs",
+		"download": "cd D:\\dl",
+		"mydocs":   "cd D:\\my",
+		"logs":     "cd D:\\logs",
+	}
+	runs := map[string]history.Record{
+		history.Key(history.KindAlias, "download"): {Count: 8, LastRun: at.Add(-time.Hour)},
+		history.Key(history.KindAlias, "docs"):     {Count: 2, LastRun: at.Add(-time.Hour)},
+		history.Key(history.KindAlias, "mydocs"):   {Count: 40, LastRun: at},
+	}
+	got := rankAliasMatches(aliases, "do", runs, at)
+	want := []string{"download", "docs", "mydocs"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestResolveAliasNameExactAndPartial(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	aliases := map[string]string{"docs": "cd D:\\docs", "dl": "cd D:\\dl", "mydocs": "cd D:\\my"}
+	if got, _, ok := resolveAliasName(aliases, "DL"); !ok || got != "dl" {
+		t.Fatalf("expected exact match dl, got %q (%v)", got, ok)
+	}
+	if got, _, ok := resolveAliasName(aliases, "doc"); !ok || got != "docs" {
+		t.Fatalf("expected unique prefix match docs, got %q (%v)", got, ok)
+	}
+	if _, candidates, ok := resolveAliasName(aliases, "d"); ok || len(candidates) != 3 {
+		t.Fatalf("expected ambiguous prefix to list candidates, got %v (%v)", candidates, ok)
+	}
+	if _, candidates, ok := resolveAliasName(aliases, "ydoc"); ok || len(candidates) != 1 || candidates[0] != "mydocs" {
+		t.Fatalf("expected a contains match not to run, got %v (%v)", candidates, ok)
+	}
+	if _, candidates, ok := resolveAliasName(aliases, "zzz"); ok || len(candidates) != 0 {
+		t.Fatal("expected no match")
+	}
+}
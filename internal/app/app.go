@@ -5,7 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"cli/internal/config"
+	"cli/internal/history"
 	"cli/internal/plugins"
 )
 
@@ -13,7 +16,12 @@ func runPluginOrSuggest(baseDir string, args []string) int {
 	if len(args) == 0 {
 		return 0
 	}
-	if err := plugins.Run(baseDir, args[0], args[1:]); err != nil {
+	started := time.Now()
+	err := plugins.Run(baseDir, args[0], args[1:])
+	if !plugins.IsNotFound(err) {
+		history.AddTimed(history.KindPlugin, args[0], exitCodeOf(err), time.Since(started))
+	}
+	if err != nil {
 		if plugins.IsNotFound(err) {
 			fmt.Fprintln(os.Stderr, "Error:", err)
 			if suggestion := suggestTopLevelName(baseDir, args[0]); suggestion != "" {
@@ -63,6 +71,13 @@ func loadRuntime() (runtimeContext, error) {
 	if err != nil {
 		return runtimeContext{}, fmt.Errorf("cannot determine executable directory: %w", err)
 	}
+	startupTrace.mark("exe dir")
+	if err := config.Load(baseDir); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+	}
+	startupTrace.mark("config load")
+	applySecrets()
+	startupTrace.mark("secrets")
 	return runtimeContext{BaseDir: baseDir}, nil
 }
 
@@ -78,33 +93,7 @@ func runPlugin(baseDir string, args []string) int {
 	case "menu":
 		return runPluginMenu(baseDir)
 	case "list":
-		includeFunctions := false
-		for _, arg := range args[1:] {
-			if arg == "--functions" || arg == "-f" {
-				includeFunctions = true
-			}
-		}
-		items, err := plugins.ListEntries(baseDir, includeFunctions)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error:", err)
-			return 1
-		}
-		if len(items) == 0 {
-			fmt.Println("No plugins found.")
-			return 0
-		}
-		for _, item := range items {
-			if includeFunctions {
-				if item.Kind == "function" {
-					fmt.Println(item.Name)
-				}
-				continue
-			}
-			if item.Kind == "script" {
-				fmt.Println(item.Name)
-			}
-		}
-		return 0
+		return runPluginList(baseDir, args[1:])
 	case "info":
 		if len(args) < 2 {
 			fmt.Println("Usage: dm plugins info <name>")
@@ -142,27 +131,38 @@ func runPlugin(baseDir string, args []string) int {
 		}
 		return 0
 	case "run":
+		sandbox := len(args) > 1 && args[1] == "--sandbox"
+		if sandbox {
+			args = append(args[:1:1], args[2:]...)
+		}
 		if len(args) < 2 {
-			fmt.Println("Usage: dm plugins run <name> [args...]")
+			fmt.Println("Usage: dm plugins run [--sandbox] <name> [args...]")
 			return 0
 		}
-		if err := plugins.Run(baseDir, args[1], args[2:]); err != nil {
+		run := plugins.Run
+		if sandbox {
+			run = plugins.RunSandboxed
+		}
+		started := time.Now()
+		err := run(baseDir, args[1], args[2:])
+		history.AddTimed(history.KindPlugin, args[1], exitCodeOf(err), time.Since(started))
+		if err != nil {
 			fmt.Fprintln(os.Stderr, "Error:", err)
 			return 1
 		}
 		return 0
 	default:
-		if suggestion := suggestClosest(args[0], []string{"list", "info", "run", "menu"}, 3); suggestion != "" {
+		if suggestion := suggestClosest(args[0], []string{"list", "info", "run", "menu", "lint", "describe", "source"}, 3); suggestion != "" {
 			fmt.Printf("Did you mean: dm plugins %s\n", suggestion)
 		}
-		fmt.Println("Usage: dm plugins <list|info|run|menu> ...")
+		fmt.Println("Usage: dm plugins <list|info|run|menu|lint|describe|source> ...")
 		return 0
 	}
 }
 
 func suggestTopLevelName(baseDir string, input string) string {
 	candidates := []string{
-		"ps_profile", "cp", "open", "doctor", "plugins", "tools", "ask", "completion", "help",
+		"ps_profile", "profile", "cp", "cache", "open", "doctor", "plugins", "tools", "ask", "completion", "help",
 	}
 	if items, err := plugins.ListEntries(baseDir, true); err == nil {
 		for _, it := range items {
@@ -99,6 +99,29 @@ func TestParsePluginMenuChoice(t *testing.T) {
 	}
 }
 
+func TestParsePluginMenuSelection(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+		ok   bool
+	}{
+		{"2", []int{1}, true},
+		{"1,3", []int{0, 2}, true},
+		{"a c", []int{0, 2}, true},
+		{"2-4", []int{1, 2, 3}, true},
+		{"b-d, 1", []int{1, 2, 3, 0}, true},
+		{"4-2", nil, false},
+		{"1,9", nil, false},
+		{"", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePluginMenuSelection(tt.in, 5)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("parsePluginMenuSelection(%q) => (%v,%v), want (%v,%v)", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
 func TestSplitMenuArgs(t *testing.T) {
 	got, err := splitMenuArgs(`-Message "hello world" -Confirm`)
 	if err != nil {
@@ -125,3 +148,14 @@ func TestSuggestClosestNoMatch(t *testing.T) {
 		t.Fatalf("expected empty suggestion, got %q", got)
 	}
 }
+
+func TestParseRecentMenuChoice(t *testing.T) {
+	if idx, ok := parseRecentMenuChoice("R2", 3); !ok || idx != 1 {
+		t.Fatalf("expected r2 -> 1, got (%d,%v)", idx, ok)
+	}
+	for _, in := range []string{"r", "r0", "r4", "2", "rx"} {
+		if _, ok := parseRecentMenuChoice(in, 3); ok {
+			t.Fatalf("expected %q to be rejected", in)
+		}
+	}
+}
@@ -3,10 +3,14 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+
+	"cli/internal/platform"
 )
 
 const (
@@ -53,7 +57,14 @@ func loadAskAliases(baseDir string) (map[string]string, error) {
 	}
 	var aliases map[string]string
 	if err := json.Unmarshal(raw, &aliases); err != nil {
-		return nil, err
+		// A truncated file from an interrupted write must not block every
+		// command; keep the bytes aside and start from an empty set.
+		moved, qerr := platform.QuarantineFile(path)
+		if qerr != nil {
+			return nil, fmt.Errorf("invalid %s: %w", path, err)
+		}
+		slog.Warn("corrupt alias file moved aside", "path", moved, "err", err)
+		return map[string]string{}, nil
 	}
 	if aliases == nil {
 		return map[string]string{}, nil
@@ -83,7 +94,7 @@ func saveAskAliases(baseDir string, aliases map[string]string) error {
 		return err
 	}
 	data = append(data, '\n')
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := platform.WriteFileAtomic(path, data, 0644); err != nil {
 		return err
 	}
 	if err := syncAskAliasesToProfile(aliases); err != nil {
@@ -130,7 +141,7 @@ func syncAskAliasesBlockToProfilePath(profilePath, block string) error {
 		return err
 	}
 	updated := upsertAskAliasesProfileBlock(existing, block)
-	return os.WriteFile(profilePath, []byte(updated), 0644)
+	return platform.WriteFileAtomic(profilePath, []byte(updated), 0644)
 }
 
 func resolveAllUserPowerShellProfilePaths() []string {
@@ -149,8 +160,9 @@ func resolveAllUserPowerShellProfilePaths() []string {
 	}
 	home, _ := os.UserHomeDir()
 	if strings.TrimSpace(home) != "" {
-		add(filepath.Join(home, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"))
-		add(filepath.Join(home, "Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1"))
+		for _, p := range powerShellProfileCandidates(home, runtime.GOOS) {
+			add(p)
+		}
 	}
 	add(askAliasProfilePathResolver())
 	return paths
@@ -168,7 +180,7 @@ func renderAskAliasesProfileBlock(aliases map[string]string) string {
 		b.WriteString("    '")
 		b.WriteString(escapePowerShellSingleQuoted(k))
 		b.WriteString("' = '")
-		b.WriteString(escapePowerShellSingleQuoted(v))
+		b.WriteString(escapePowerShellSingleQuoted(aliasProfileScript(v)))
 		b.WriteString("'\n")
 	}
 	b.WriteString("}\n")
@@ -179,6 +191,7 @@ func renderAskAliasesProfileBlock(aliases map[string]string) string {
 	b.WriteString("    Set-Item -Path ('Function:' + $fn) -Value ([ScriptBlock]::Create($cmd))\n")
 	b.WriteString("    Set-Alias -Name $name -Value $fn -Scope Global -Force\n")
 	b.WriteString("}\n")
+	b.WriteString("function dmj { $target = dm jump @args; if ($LASTEXITCODE -eq 0 -and $target) { Set-Location -LiteralPath $target } }\n")
 	b.WriteString(dmAliasProfileEnd + "\n")
 	return b.String()
 }
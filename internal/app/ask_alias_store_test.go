@@ -53,27 +53,23 @@ func TestLoadSaveAskAliases(t *testing.T) {
 	if !strings.Contains(profileText, dmAliasProfileBegin) || !strings.Contains(profileText, dmAliasProfileEnd) {
 		t.Fatalf("expected dm alias markers in profile, got: %q", profileText)
 	}
-	if !strings.Contains(profileText, "'ll' = 'Get-ChildItem -Force'") {
+	if !strings.Contains(profileText, "'ll' = 'Get-ChildItem -Force @args'") {
 		t.Fatalf("expected alias payload in profile, got: %q", profileText)
 	}
 }
 
 func TestResolveAllUserPowerShellProfilePathsIncludesBoth(t *testing.T) {
-	prevResolver := askAliasProfilePathResolver
-	askAliasProfilePathResolver = func() string { return "" }
-	defer func() { askAliasProfilePathResolver = prevResolver }()
-
-	paths := resolveAllUserPowerShellProfilePaths()
+	paths := powerShellProfileCandidates(t.TempDir(), "windows")
 	if len(paths) < 2 {
 		t.Fatalf("expected at least two profile paths, got %v", paths)
 	}
 	hasPowerShell := false
 	hasWindowsPowerShell := false
 	for _, p := range paths {
-		if strings.Contains(strings.ToLower(p), strings.ToLower(`\documents\powershell\microsoft.powershell_profile.ps1`)) {
+		if strings.Contains(strings.ToLower(filepath.ToSlash(p)), `/documents/powershell/microsoft.powershell_profile.ps1`) {
 			hasPowerShell = true
 		}
-		if strings.Contains(strings.ToLower(p), strings.ToLower(`\documents\windowspowershell\microsoft.powershell_profile.ps1`)) {
+		if strings.Contains(strings.ToLower(filepath.ToSlash(p)), `/documents/windowspowershell/microsoft.powershell_profile.ps1`) {
 			hasWindowsPowerShell = true
 		}
 	}
@@ -93,6 +89,28 @@ func TestLoadAskAliasesMissingFile(t *testing.T) {
 	}
 }
 
+func TestLoadAskAliasesQuarantinesCorruptFile(t *testing.T) {
+	baseDir := t.TempDir()
+	path := askAliasFilePath(baseDir)
+	if err := os.WriteFile(path, []byte(`{"ll": "Get-Chi`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadAskAliases(baseDir)
+	if err != nil {
+		t.Fatalf("expected corrupt file to be healed, got %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected empty aliases, got %v", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected corrupt file moved aside, got %v", err)
+	}
+	matches, _ := filepath.Glob(path + ".corrupt-*")
+	if len(matches) != 1 {
+		t.Fatalf("expected one quarantined copy, got %v", matches)
+	}
+}
+
 func TestSortedAliasNames(t *testing.T) {
 	keys := sortedAliasNames(map[string]string{
 		"z": "a",
@@ -141,7 +159,7 @@ func TestUpsertAskAliasesProfileBlock(t *testing.T) {
 	if strings.Count(out, dmAliasProfileBegin) != 1 {
 		t.Fatalf("expected one begin marker, got %q", out)
 	}
-	if !strings.Contains(out, "'cli' = 'Get-Location'") {
+	if !strings.Contains(out, "'cli' = 'Get-Location @args'") {
 		t.Fatalf("expected updated alias in profile block, got %q", out)
 	}
 }
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"cli/internal/agent"
+	"cli/internal/redact"
+	"cli/internal/secrets"
+	"cli/internal/ui"
+)
+
+// askApprovalRequest is what the external approver receives for an action
+// that needs confirmation.
+type askApprovalRequest struct {
+	Kind       string    `json:"kind"`
+	Prompt     string    `json:"prompt,omitempty"`
+	Step       int       `json:"step,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	Target     string    `json:"target,omitempty"`
+	Args       string    `json:"args,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Risk       string    `json:"risk,omitempty"`
+	RiskReason string    `json:"risk_reason,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Cwd        string    `json:"cwd"`
+	Host       string    `json:"host"`
+	Time       time.Time `json:"time"`
+}
+
+type askApprovalResponse struct {
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+func newAskApprovalRequest(kind string) askApprovalRequest {
+	host, _ := os.Hostname()
+	return askApprovalRequest{Kind: kind, Cwd: askCurrentDir(), Host: host, Time: time.Now()}
+}
+
+// askApprove asks the configured approver about req and prints its
+// answer. Anything but an explicit allow, including errors and timeouts,
+// denies the action.
+func askApprove(cfg agent.ApproverConfig, req askApprovalRequest) bool {
+	subject := req.Action + " " + req.Target
+	if req.Kind == "broad_path" {
+		subject = req.Path
+	}
+	subject = strings.TrimSpace(subject)
+	fmt.Println(ui.Muted("Waiting for approver: " + subject))
+	allow, reason, err := requestApproval(cfg, req)
+	if err != nil {
+		fmt.Println(ui.Error("Error:"), "approver failed:", err)
+		return false
+	}
+	msg := "Approved"
+	if !allow {
+		msg = "Denied"
+	}
+	if reason != "" {
+		msg += ": " + reason
+	}
+	if allow {
+		fmt.Println(ui.Muted(msg))
+	} else {
+		fmt.Println(ui.Warn(msg))
+	}
+	return allow
+}
+
+// askApprovePath asks the approver before a tool works on a very broad path.
+func askApprovePath(path string) bool {
+	req := newAskApprovalRequest("broad_path")
+	req.Path = path
+	return askApprove(agent.Approver(), req)
+}
+
+// requestApproval sends req to the approver with the prompt, arguments and
+// path redacted, as in the LLM debug log.
+func requestApproval(cfg agent.ApproverConfig, req askApprovalRequest) (bool, string, error) {
+	req.Prompt = redact.String(req.Prompt)
+	req.Args = redact.String(req.Args)
+	req.Path = redact.String(req.Path)
+	body, err := json.Marshal(req)
+	if err != nil {
+		return false, "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout())
+	defer cancel()
+	var out []byte
+	if strings.TrimSpace(cfg.Command) != "" {
+		out, err = runApproverCommand(ctx, cfg.Command, body)
+	} else {
+		out, err = postApproval(ctx, cfg, body)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return false, "", fmt.Errorf("no decision within %s", cfg.Timeout())
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return parseApprovalResponse(out)
+}
+
+func runApproverCommand(ctx context.Context, command string, body []byte) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stderr = os.Stderr
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok && len(bytes.TrimSpace(out)) == 0 {
+			return []byte("deny"), nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func postApproval(ctx context.Context, cfg agent.ApproverConfig, body []byte) ([]byte, error) {
+	token, err := approverToken(cfg)
+	if err != nil {
+		return nil, err
+	}
+	target := strings.TrimSpace(cfg.URL)
+	if token != "" && !approverURLSecure(target) {
+		return nil, fmt.Errorf("approver url %q must use https when a token is set", target)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	out, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusForbidden {
+		return []byte("deny"), nil
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("approver returned %s", res.Status)
+	}
+	return out, nil
+}
+
+// approverToken returns the bearer token, read from the secret store when
+// token_secret is set.
+func approverToken(cfg agent.ApproverConfig) (string, error) {
+	name := strings.TrimSpace(cfg.TokenSecret)
+	if name == "" {
+		return strings.TrimSpace(cfg.Token), nil
+	}
+	v, ok, err := secrets.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("approver token: %w", err)
+	}
+	if !ok {
+		return "", fmt.Errorf("approver token: no secret named %s (set it with 'dm secret set %s')", name, name)
+	}
+	return strings.TrimSpace(v), nil
+}
+
+// approverURLSecure reports whether a token may be sent to raw: https, or
+// plain http to a loopback address that never leaves the machine.
+func approverURLSecure(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		if strings.EqualFold(host, "localhost") {
+			return true
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	}
+	return false
+}
+
+// parseApprovalResponse reads {"decision":"allow|deny","reason":"..."}
+// or a plain allow/deny (yes/no) word.
+func parseApprovalResponse(out []byte) (bool, string, error) {
+	text := strings.TrimSpace(string(out))
+	var res askApprovalResponse
+	if strings.HasPrefix(text, "{") {
+		if err := json.Unmarshal([]byte(text), &res); err != nil {
+			return false, "", fmt.Errorf("unreadable approver response: %w", err)
+		}
+	} else if fields := strings.Fields(text); len(fields) > 0 {
+		res.Decision = fields[0]
+		res.Reason = strings.TrimSpace(strings.TrimPrefix(text, fields[0]))
+	}
+	switch strings.ToLower(strings.TrimSpace(res.Decision)) {
+	case "allow", "approve", "approved", "yes", "y":
+		return true, res.Reason, nil
+	case "deny", "denied", "reject", "no", "n":
+		return false, res.Reason, nil
+	}
+	return false, "", fmt.Errorf("approver gave no allow/deny decision")
+}
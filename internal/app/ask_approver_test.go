@@ -0,0 +1,130 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"cli/internal/agent"
+	"cli/internal/secrets"
+)
+
+func TestParseApprovalResponse(t *testing.T) {
+	cases := []struct {
+		in     string
+		allow  bool
+		reason string
+		err    bool
+	}{
+		{in: `{"decision":"allow"}`, allow: true},
+		{in: `{"decision":"deny","reason":"not now"}`, reason: "not now"},
+		{in: "yes\n", allow: true},
+		{in: "deny outside office hours", reason: "outside office hours"},
+		{in: "", err: true},
+		{in: "maybe", err: true},
+		{in: `{"decision":`, err: true},
+	}
+	for _, c := range cases {
+		allow, reason, err := parseApprovalResponse([]byte(c.in))
+		if (err != nil) != c.err || allow != c.allow || reason != c.reason {
+			t.Fatalf("%q: got allow=%v reason=%q err=%v", c.in, allow, reason, err)
+		}
+	}
+}
+
+func TestRequestApprovalWebhook(t *testing.T) {
+	var got askApprovalRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		if got.Risk == "high" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"decision":"allow","reason":"ok from phone"}`))
+	}))
+	defer srv.Close()
+
+	cfg := agent.ApproverConfig{URL: srv.URL, Token: "s3cret"}
+	req := newAskApprovalRequest("action")
+	req.Action, req.Target, req.Risk = "run_tool", "rename", "medium"
+	allow, reason, err := requestApproval(cfg, req)
+	if err != nil || !allow || reason != "ok from phone" {
+		t.Fatalf("expected approval, got allow=%v reason=%q err=%v", allow, reason, err)
+	}
+	if got.Target != "rename" || got.Kind != "action" || got.Cwd == "" {
+		t.Fatalf("unexpected request body: %+v", got)
+	}
+
+	req.Risk = "high"
+	if allow, _, err := requestApproval(cfg, req); err != nil || allow {
+		t.Fatalf("expected 403 to deny, got allow=%v err=%v", allow, err)
+	}
+	cfg.Token = "wrong"
+	if allow, _, err := requestApproval(cfg, req); err == nil || allow {
+		t.Fatalf("expected an error for 401, got allow=%v err=%v", allow, err)
+	}
+}
+
+func TestRequestApprovalRedactsAndSecuresToken(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	var got askApprovalRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer from-store" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte("allow"))
+	}))
+	defer srv.Close()
+
+	if err := secrets.Set("approver_token", "from-store", nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg := agent.ApproverConfig{URL: srv.URL, TokenSecret: "approver_token"}
+	req := newAskApprovalRequest("action")
+	req.Prompt = "deploy with password=hunter22"
+	req.Args = "-Token sk-abcdefghijklmnopqrstuvwx"
+	if allow, _, err := requestApproval(cfg, req); err != nil || !allow {
+		t.Fatalf("expected approval, got allow=%v err=%v", allow, err)
+	}
+	if strings.Contains(got.Prompt, "hunter22") || strings.Contains(got.Args, "sk-abc") {
+		t.Fatalf("request was not redacted: %+v", got)
+	}
+
+	cfg = agent.ApproverConfig{URL: "http://approve.example.com/dm", Token: "s3cret"}
+	if allow, _, err := requestApproval(cfg, req); err == nil || allow {
+		t.Fatalf("expected a plain http url with a token to be refused, got allow=%v err=%v", allow, err)
+	}
+	cfg = agent.ApproverConfig{URL: srv.URL, TokenSecret: "missing"}
+	if allow, _, err := requestApproval(cfg, req); err == nil || allow {
+		t.Fatalf("expected a missing token secret to fail, got allow=%v err=%v", allow, err)
+	}
+}
+
+func TestRequestApprovalCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	cfg := agent.ApproverConfig{Command: `grep -q '"target":"backup"' && echo allow`}
+	req := newAskApprovalRequest("action")
+	req.Target = "backup"
+	if allow, _, err := requestApproval(cfg, req); err != nil || !allow {
+		t.Fatalf("expected approval, got allow=%v err=%v", allow, err)
+	}
+	req.Target = "wipe"
+	if allow, _, err := requestApproval(cfg, req); err != nil || allow {
+		t.Fatalf("expected a failing command to deny, got allow=%v err=%v", allow, err)
+	}
+	cfg = agent.ApproverConfig{Command: "sleep 5", TimeoutSeconds: 1}
+	if allow, _, err := requestApproval(cfg, req); err == nil || allow {
+		t.Fatalf("expected a timeout error, got allow=%v err=%v", allow, err)
+	}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cli/internal/agent"
+	"cli/internal/cache"
+	"cli/internal/platform"
+)
+
+const (
+	askDecisionCacheLimit = 200
+	askDecisionCacheTTL   = 24 * time.Hour
+)
+
+// askDecisionCache holds planner decisions for this process; with --cache
+// it is backed by askDecisionCachePath so later invocations reuse them.
+var askDecisionCache = cache.New[string, askCachedDecision]("ask-decisions", askDecisionCacheLimit)
+
+var askDiskCache = &askDecisionDiskCache{}
+
+type askCachedDecision struct {
+	Key      string               `json:"key"`
+	Stored   time.Time            `json:"stored"`
+	Decision agent.DecisionResult `json:"decision"`
+}
+
+// askDecisionDiskCache is the on-disk copy of the cache. It is loaded once
+// per process and rewritten after every store.
+type askDecisionDiskCache struct {
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]askCachedDecision
+}
+
+func askDecisionCachePath() string {
+	return platform.StatePath("cache", "ask-decisions.json")
+}
+
+// askDecisionKey identifies a planner call by everything that goes into it,
+// including the system prompt so custom planner prompts are respected.
+func askDecisionKey(opts agent.AskOptions, decisionPrompt, catalog, toolsCatalog, envContext string) string {
+	temp := ""
+	if opts.Temperature != nil {
+		temp = fmt.Sprint(*opts.Temperature)
+	}
+	h := sha256.New()
+	for _, part := range []string{
+		strings.ToLower(strings.TrimSpace(opts.Provider)), strings.TrimSpace(opts.Model), strings.TrimSpace(opts.BaseURL),
+		temp, fmt.Sprint(opts.MaxTokens), fmt.Sprint(opts.Explain), opts.SystemPrompt,
+		decisionPrompt, agent.DecisionSystemPrompt(catalog, toolsCatalog), envContext,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// lookupAskDecision returns a cached decision younger than ttl, checking
+// memory first and then the disk cache.
+func lookupAskDecision(key string, ttl time.Duration, now time.Time) (agent.DecisionResult, bool) {
+	fresh := func(e askCachedDecision) bool { return now.Sub(e.Stored) < ttl }
+	if e, ok := askDecisionCache.GetIf(key, fresh); ok {
+		return e.Decision, true
+	}
+	e, ok := askDiskCache.get(key)
+	if !ok || !fresh(e) {
+		return agent.DecisionResult{}, false
+	}
+	askDecisionCache.Set(key, e)
+	return e.Decision, true
+}
+
+func storeAskDecision(key string, decision agent.DecisionResult, ttl time.Duration, now time.Time) {
+	e := askCachedDecision{Key: key, Stored: now, Decision: decision}
+	askDecisionCache.Set(key, e)
+	if err := askDiskCache.put(e, ttl, now); err != nil {
+		slog.Debug("ask cache write failed", "err", err)
+	}
+}
+
+func (c *askDecisionDiskCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = map[string]askCachedDecision{}
+	data, err := os.ReadFile(askDecisionCachePath())
+	if err != nil {
+		return
+	}
+	var list []askCachedDecision
+	if err := json.Unmarshal(data, &list); err != nil {
+		slog.Debug("ignoring unreadable ask cache", "err", err)
+		return
+	}
+	for _, e := range list {
+		c.entries[e.Key] = e
+	}
+}
+
+func (c *askDecisionDiskCache) get(key string) (askCachedDecision, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	e, ok := c.entries[key]
+	return e, ok
+}
+
+// put adds e, drops entries older than ttl and keeps only the newest
+// askDecisionCacheLimit before rewriting the file. Another dm process may
+// have saved entries since this one loaded the file, so it is re-read
+// under a lock first.
+func (c *askDecisionDiskCache) put(e askCachedDecision, ttl time.Duration, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	path := askDecisionCachePath()
+	release, err := platform.LockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	// Every put saves its entry, so the file already holds this process's
+	// earlier ones; reading it again picks up the other processes'.
+	c.loaded = false
+	c.load()
+	c.entries[e.Key] = e
+	list := make([]askCachedDecision, 0, len(c.entries))
+	for _, v := range c.entries {
+		if now.Sub(v.Stored) < ttl {
+			list = append(list, v)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Stored.After(list[j].Stored) })
+	if len(list) > askDecisionCacheLimit {
+		list = list[:askDecisionCacheLimit]
+	}
+	c.entries = make(map[string]askCachedDecision, len(list))
+	for _, v := range list {
+		c.entries[v.Key] = v
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return platform.WriteFileAtomic(path, data, 0600)
+}
+
+func (c *askDecisionDiskCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.loaded = false
+	c.entries = nil
+}
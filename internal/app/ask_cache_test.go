@@ -0,0 +1,123 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"cli/internal/agent"
+)
+
+func resetAskDecisionCaches(t *testing.T) {
+	t.Helper()
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	askDecisionCache.Reset()
+	askDiskCache.reset()
+	t.Cleanup(func() {
+		askDecisionCache.Reset()
+		askDiskCache.reset()
+	})
+}
+
+func TestAskDecisionCachePersistsAcrossProcesses(t *testing.T) {
+	resetAskDecisionCaches(t)
+	now := time.Now()
+	key := askDecisionKey(agent.AskOptions{Provider: "openai"}, "list files", "catalog", "tools", "- Working directory: /tmp")
+	storeAskDecision(key, agent.DecisionResult{Action: "run_tool", Tool: "list"}, time.Hour, now)
+
+	// A new process starts with empty caches and reads the file.
+	askDecisionCache.Reset()
+	askDiskCache.reset()
+	got, ok := lookupAskDecision(key, time.Hour, now.Add(time.Minute))
+	if !ok || got.Action != "run_tool" || got.Tool != "list" {
+		t.Fatalf("expected cached decision from disk, got %+v %v", got, ok)
+	}
+	if _, ok := lookupAskDecision(key, time.Hour, now.Add(2*time.Hour)); ok {
+		t.Fatal("expired decision should not be returned")
+	}
+}
+
+func TestAskDecisionKeyDependsOnInputs(t *testing.T) {
+	opts := agent.AskOptions{Provider: "openai", Model: "gpt-4o-mini"}
+	base := askDecisionKey(opts, "p", "c", "t", "e")
+	if base != askDecisionKey(opts, "p", "c", "t", "e") {
+		t.Fatal("key should be stable")
+	}
+	other := opts
+	other.Model = "gpt-4.1"
+	for name, k := range map[string]string{
+		"prompt":  askDecisionKey(opts, "q", "c", "t", "e"),
+		"catalog": askDecisionKey(opts, "p", "c2", "t", "e"),
+		"env":     askDecisionKey(opts, "p", "c", "t", "e2"),
+		"model":   askDecisionKey(other, "p", "c", "t", "e"),
+	} {
+		if k == base {
+			t.Fatalf("key should change with %s", name)
+		}
+	}
+}
+
+func TestAskDecisionDiskCacheEvicts(t *testing.T) {
+	resetAskDecisionCaches(t)
+	now := time.Now()
+	stale := askCachedDecision{Key: "stale", Stored: now.Add(-2 * time.Hour), Decision: agent.DecisionResult{Action: "answer"}}
+	if err := askDiskCache.put(stale, time.Hour, now.Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < askDecisionCacheLimit+5; i++ {
+		e := askCachedDecision{Key: fmt.Sprint(i), Stored: now.Add(time.Duration(i) * time.Second), Decision: agent.DecisionResult{Action: "answer"}}
+		if err := askDiskCache.put(e, time.Hour, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(askDecisionCachePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []askCachedDecision
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != askDecisionCacheLimit {
+		t.Fatalf("expected %d entries, got %d", askDecisionCacheLimit, len(list))
+	}
+	for _, e := range list {
+		if e.Key == "stale" || e.Key == "0" {
+			t.Fatalf("entry %q should have been evicted", e.Key)
+		}
+	}
+}
+
+func TestAskDecisionDiskCacheMergesConcurrentWriters(t *testing.T) {
+	resetAskDecisionCaches(t)
+	now := time.Now()
+	// Each cache stands for a dm process that loaded the file before the
+	// others saved.
+	procs := make([]*askDecisionDiskCache, 8)
+	for i := range procs {
+		procs[i] = &askDecisionDiskCache{}
+		procs[i].get("warm-up")
+	}
+	var wg sync.WaitGroup
+	for i, c := range procs {
+		wg.Add(1)
+		go func(i int, c *askDecisionDiskCache) {
+			defer wg.Done()
+			e := askCachedDecision{Key: fmt.Sprintf("k%d", i), Stored: now, Decision: agent.DecisionResult{Action: "answer"}}
+			if err := c.put(e, time.Hour, now); err != nil {
+				t.Error(err)
+			}
+		}(i, c)
+	}
+	wg.Wait()
+
+	var fresh askDecisionDiskCache
+	for i := range procs {
+		if _, ok := fresh.get(fmt.Sprintf("k%d", i)); !ok {
+			t.Fatalf("entry k%d was lost", i)
+		}
+	}
+}
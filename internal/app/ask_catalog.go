@@ -12,9 +12,11 @@ import (
 	"cli/tools"
 )
 
+// catalogTokenBudget is the default --catalog-budget: the plugin and tool
+// catalogs are trimmed to the entries most relevant to the prompt above it.
 const catalogTokenBudget = 6000
 
-func buildPluginCatalogScoped(baseDir, scope string) string {
+func buildPluginCatalogScoped(baseDir, scope string, filter askActionFilter) string {
 	items, err := plugins.ListEntries(baseDir, true)
 	if err != nil || len(items) == 0 {
 		return "(none)"
@@ -37,6 +39,14 @@ func buildPluginCatalogScoped(baseDir, scope string) string {
 		if scopeLower != "" && !scopeMatches(item.Name, label, scopeLower) {
 			continue
 		}
+		if !filter.allowsPlugin(item.Name) {
+			continue
+		}
+		if filter.readOnly {
+			if risk, _ := assessPluginRisk(item.Name, item.Path); risk != "low" {
+				continue
+			}
+		}
 
 		info, _ := plugins.GetInfo(baseDir, item.Name)
 
@@ -77,13 +87,7 @@ func buildPluginCatalogScoped(baseDir, scope string) string {
 	}
 	catalog := strings.Join(out, "\n")
 
-	tokens := estimateTokens(catalog)
-	slog.Debug("plugin catalog built", "tokens", tokens, "functions", countCatalogFunctions(out), "scope", scope)
-	if tokens > catalogTokenBudget {
-		slog.Warn("plugin catalog exceeds token budget",
-			"tokens", tokens, "budget", catalogTokenBudget,
-			"hint", "use --scope to reduce catalog size")
-	}
+	slog.Debug("plugin catalog built", "tokens", estimateTokens(catalog), "functions", countCatalogFunctions(out), "scope", scope)
 
 	return catalog
 }
@@ -163,8 +167,8 @@ func formatParamDetailsForCatalog(details []plugins.ParamDetail) string {
 	return strings.Join(parts, ", ")
 }
 
-func buildToolsCatalog() string {
-	return tools.BuildAgentCatalog()
+func buildToolsCatalog(filter askActionFilter) string {
+	return tools.BuildAgentCatalogFiltered(filter.catalogTool)
 }
 
 func isKnownTool(name string) bool {
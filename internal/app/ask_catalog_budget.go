@@ -0,0 +1,156 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var catalogStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"this": true, "that": true, "what": true, "which": true, "how": true, "are": true,
+	"can": true, "you": true, "please": true, "all": true, "some": true, "any": true,
+	"use": true, "get": true, "show": true, "list": true, "want": true, "need": true,
+}
+
+// catalogTerms splits text into lowercase words for relevance matching,
+// dropping short words and common filler.
+func catalogTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var out []string
+	for _, w := range words {
+		if len(w) >= 3 && !catalogStopWords[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// termsMatch compares words on their first five letters so that simple
+// plurals and verb forms ("containers", "container") still match.
+func termsMatch(a, b string) bool {
+	if len(a) >= 5 && len(b) >= 5 {
+		return a[:5] == b[:5]
+	}
+	return a == b
+}
+
+func catalogEntryScore(promptTerms []string, entry string) int {
+	entryTerms := catalogTerms(entry)
+	score := 0
+	for _, p := range promptTerms {
+		for _, e := range entryTerms {
+			if termsMatch(p, e) {
+				score++
+				break
+			}
+		}
+	}
+	return score
+}
+
+type budgetEntry struct {
+	tool   bool
+	index  int
+	line   string
+	group  string
+	score  int
+	tokens int
+}
+
+// budgetCatalogs trims the plugin and tool catalogs to fit budget tokens,
+// keeping the entries most relevant to prompt. The catalogs are returned
+// unchanged when they fit or budget is 0, along with the number of dropped
+// entries.
+func budgetCatalogs(prompt, pluginCatalog, toolsCatalog string, budget int) (string, string, int) {
+	if budget <= 0 || estimateTokens(pluginCatalog)+estimateTokens(toolsCatalog) <= budget {
+		return pluginCatalog, toolsCatalog, 0
+	}
+	terms := catalogTerms(prompt)
+
+	var entries []*budgetEntry
+	pluginLines := strings.Split(pluginCatalog, "\n")
+	group := ""
+	for i, l := range pluginLines {
+		if strings.HasPrefix(l, "[") {
+			group = strings.Trim(l, "[]")
+			continue
+		}
+		if strings.HasPrefix(l, "- ") {
+			entries = append(entries, &budgetEntry{index: i, line: l, group: group})
+		}
+	}
+	toolLines := strings.Split(toolsCatalog, "\n")
+	for i, l := range toolLines {
+		if strings.HasPrefix(l, "- ") {
+			entries = append(entries, &budgetEntry{tool: true, index: i, line: l})
+		}
+	}
+	for _, e := range entries {
+		e.score = catalogEntryScore(terms, e.group+" "+e.line)
+		e.tokens = estimateTokens(e.line + "\n")
+	}
+
+	ranked := append([]*budgetEntry{}, entries...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].tool && !ranked[j].tool
+	})
+	keepPlugin := map[int]bool{}
+	keepTool := map[int]bool{}
+	keptGroups := map[string]bool{}
+	used, dropped := 0, 0
+	for _, e := range ranked {
+		cost := e.tokens
+		if !e.tool && !keptGroups[e.group] {
+			cost += estimateTokens("\n[" + e.group + "]\n")
+		}
+		if used+cost > budget {
+			dropped++
+			continue
+		}
+		used += cost
+		if e.tool {
+			keepTool[e.index] = true
+		} else {
+			keepPlugin[e.index] = true
+			keptGroups[e.group] = true
+		}
+	}
+	if dropped == 0 {
+		return pluginCatalog, toolsCatalog, 0
+	}
+
+	var plugins []string
+	header := ""
+	for i, l := range pluginLines {
+		switch {
+		case strings.HasPrefix(l, "["):
+			header = l
+		case strings.HasPrefix(l, "- "):
+			if !keepPlugin[i] {
+				continue
+			}
+			if header != "" {
+				plugins = append(plugins, "", header)
+				header = ""
+			}
+			plugins = append(plugins, l)
+		}
+	}
+	plugins = append(plugins, "", fmt.Sprintf("(%d less relevant entries omitted to fit the context budget)", dropped))
+	var tools []string
+	for i, l := range toolLines {
+		if keepTool[i] {
+			tools = append(tools, l)
+		}
+	}
+	slog.Debug("catalog trimmed to token budget", "budget", budget, "kept", len(entries)-dropped, "dropped", dropped)
+	return strings.Join(plugins, "\n"), strings.Join(tools, "\n"), dropped
+}
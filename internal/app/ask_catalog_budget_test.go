@@ -0,0 +1,57 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBudgetCatalogsKeepsRelevantEntries(t *testing.T) {
+	plugins := strings.Join([]string{
+		"",
+		"[Docker]",
+		"- docker_ps: List running containers",
+		"- docker_restart(Name*): Restart a container",
+		"",
+		"[Git]",
+		"- g_status: Show working tree status",
+		"- g_log(Count=10): Show recent commits",
+		"",
+		"[Network]",
+		"- net_ping(Host*): Ping a host",
+	}, "\n")
+	tools := "- search: Find files by name | tool_args: pattern\n- system: Show system info (no args needed)"
+
+	if p, tl, dropped := budgetCatalogs("restart web", plugins, tools, 0); p != plugins || tl != tools || dropped != 0 {
+		t.Fatal("budget 0 should leave the catalogs unchanged")
+	}
+	if _, _, dropped := budgetCatalogs("restart web", plugins, tools, 10000); dropped != 0 {
+		t.Fatalf("catalogs within budget should not be trimmed, dropped %d", dropped)
+	}
+
+	p, tl, dropped := budgetCatalogs("restart the web containers", plugins, tools, 40)
+	if dropped == 0 {
+		t.Fatal("expected entries to be dropped")
+	}
+	if !strings.Contains(p, "docker_restart") || !strings.Contains(p, "docker_ps") || !strings.Contains(p, "[Docker]") {
+		t.Fatalf("relevant docker entries missing:\n%s", p)
+	}
+	if strings.Contains(p, "[Network]") && !strings.Contains(p, "net_ping") {
+		t.Fatalf("empty group header kept:\n%s", p)
+	}
+	if !strings.Contains(p, "omitted") {
+		t.Fatalf("expected an omission note:\n%s", p)
+	}
+	if estimateTokens(p)+estimateTokens(tl) > 40+estimateTokens("\n(99 less relevant entries omitted to fit the context budget)") {
+		t.Fatalf("catalog over budget: %d tokens", estimateTokens(p)+estimateTokens(tl))
+	}
+}
+
+func TestCatalogTerms(t *testing.T) {
+	got := catalogTerms("Show me the Docker-containers, please!")
+	if strings.Join(got, ",") != "docker,containers" {
+		t.Fatalf("unexpected terms: %q", got)
+	}
+	if !termsMatch("containers", "container") || termsMatch("git", "gitlab") {
+		t.Fatal("unexpected term matching")
+	}
+}
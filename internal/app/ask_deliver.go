@@ -0,0 +1,138 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli/internal/platform"
+	"cli/internal/ui"
+)
+
+// askCaptureWriter records the answer and step log of one ask turn while
+// passing everything through to the real writer, for --out and --copy.
+type askCaptureWriter struct {
+	askOutputWriter
+	answer string
+	steps  []askJSONStep
+}
+
+func (w *askCaptureWriter) keep(answer string) {
+	if strings.TrimSpace(answer) != "" {
+		w.answer = answer
+	}
+}
+
+func (w *askCaptureWriter) Answer(answer string) {
+	w.keep(answer)
+	w.askOutputWriter.Answer(answer)
+}
+
+func (w *askCaptureWriter) PartialAnswer(answer string) {
+	w.keep(answer)
+	w.askOutputWriter.PartialAnswer(answer)
+}
+
+func (w *askCaptureWriter) ErrorWithAnswer(msg, answer string) {
+	w.keep(answer)
+	w.askOutputWriter.ErrorWithAnswer(msg, answer)
+}
+
+func (w *askCaptureWriter) LoopDetected(answer string) {
+	w.keep(answer)
+	w.askOutputWriter.LoopDetected(answer)
+}
+
+func (w *askCaptureWriter) AddStep(step askJSONStep) {
+	w.steps = append(w.steps, step)
+	w.askOutputWriter.AddStep(step)
+}
+
+// content is the answer, followed by a markdown step log when withSteps
+// is set and any step ran.
+func (w *askCaptureWriter) content(withSteps bool) string {
+	text := strings.TrimSpace(w.answer)
+	if !withSteps || len(w.steps) == 0 {
+		return text
+	}
+	var b strings.Builder
+	if text != "" {
+		b.WriteString(text)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("## Steps\n\n")
+	for _, s := range w.steps {
+		line := fmt.Sprintf("%d. %s %s", s.Step, s.Action, s.Target)
+		if s.Args != "" {
+			line += " `" + s.Args + "`"
+		}
+		fmt.Fprintf(&b, "%s (%s)\n", line, s.Status)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// deliverAskAnswer hands the captured answer to the session transcript and
+// writes it to --out and/or the clipboard. Notes go to stderr so --json
+// output stays parseable.
+func deliverAskAnswer(p askSessionParams, w *askCaptureWriter) {
+	if p.answerSink != nil {
+		*p.answerSink = strings.TrimSpace(w.answer)
+	}
+	text := w.content(p.outSteps)
+	if text == "" {
+		return
+	}
+	if p.answerOut != "" {
+		fileText := text
+		if !p.outSteps && !isMarkdownPath(p.answerOut) {
+			if code, ok := singleCodeBlock(text); ok {
+				fileText = code
+			}
+		}
+		if err := os.WriteFile(p.answerOut, []byte(fileText+"\n"), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, "Error: saving answer:", err)
+		} else {
+			fmt.Fprintln(os.Stderr, ui.Muted("Answer saved to "+p.answerOut))
+		}
+	}
+	if p.copyAnswer {
+		if err := platform.CopyToClipboard(text); err != nil {
+			fmt.Fprintln(os.Stderr, "Error: copying answer:", err)
+		} else {
+			fmt.Fprintln(os.Stderr, ui.Muted("Answer copied to clipboard"))
+		}
+	}
+}
+
+func isMarkdownPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".txt", "":
+		return true
+	}
+	return false
+}
+
+// singleCodeBlock returns the body of the only fenced code block in text,
+// so `--out script.ps1` gets a runnable script rather than markdown.
+func singleCodeBlock(text string) (string, bool) {
+	var body []string
+	blocks := 0
+	inBlock := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if !inBlock {
+				blocks++
+			}
+			inBlock = !inBlock
+			continue
+		}
+		if inBlock && blocks == 1 {
+			body = append(body, line)
+		}
+	}
+	if blocks != 1 || inBlock {
+		return "", false
+	}
+	return strings.Join(body, "\n"), true
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAskCaptureWriterContent(t *testing.T) {
+	w := &askCaptureWriter{askOutputWriter: &askTTYWriter{plain: true}}
+	w.AddStep(askJSONStep{Step: 1, Action: "run_tool", Target: "grep", Args: "pattern=todo", Status: "ok"})
+	w.PartialAnswer("draft")
+	w.Answer("```powershell\nGet-ChildItem\n```")
+	w.LoopDetected("")
+
+	if got := w.content(false); got != "```powershell\nGet-ChildItem\n```" {
+		t.Fatalf("unexpected answer %q", got)
+	}
+	got := w.content(true)
+	if !strings.HasSuffix(got, "## Steps\n\n1. run_tool grep `pattern=todo` (ok)") {
+		t.Fatalf("unexpected step log %q", got)
+	}
+}
+
+func TestDeliverAskAnswerWritesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answer.md")
+	w := &askCaptureWriter{askOutputWriter: &askTTYWriter{plain: true}}
+	deliverAskAnswer(askSessionParams{answerOut: path}, w)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected no file without an answer")
+	}
+	w.Answer("hello")
+	deliverAskAnswer(askSessionParams{answerOut: path}, w)
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "hello\n" {
+		t.Fatalf("unexpected file %q, %v", data, err)
+	}
+}
+
+func TestDeliverAskAnswerExtractsScript(t *testing.T) {
+	dir := t.TempDir()
+	w := &askCaptureWriter{askOutputWriter: &askTTYWriter{plain: true}}
+	w.Answer("Here it is:\n```powershell\nGet-ChildItem\nRemove-Item x\n```\nRun it as admin.")
+
+	script := filepath.Join(dir, "cleanup.ps1")
+	deliverAskAnswer(askSessionParams{answerOut: script}, w)
+	if data, _ := os.ReadFile(script); string(data) != "Get-ChildItem\nRemove-Item x\n" {
+		t.Fatalf("expected only the code block, got %q", data)
+	}
+	notes := filepath.Join(dir, "answer.md")
+	deliverAskAnswer(askSessionParams{answerOut: notes}, w)
+	if data, _ := os.ReadFile(notes); !strings.HasPrefix(string(data), "Here it is:") {
+		t.Fatalf("expected the full answer in markdown files, got %q", data)
+	}
+}
+
+func TestSingleCodeBlock(t *testing.T) {
+	if _, ok := singleCodeBlock("```\na\n```\n```\nb\n```"); ok {
+		t.Fatal("two blocks should not be extracted")
+	}
+	if _, ok := singleCodeBlock("```\nunterminated"); ok {
+		t.Fatal("unterminated block should not be extracted")
+	}
+	if got, ok := singleCodeBlock("text\n```go\nfmt.Println()\n```"); !ok || got != "fmt.Println()" {
+		t.Fatalf("got %q, %v", got, ok)
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	envAskProvider   = "DM_ASK_PROVIDER"
+	envAskModel      = "DM_ASK_MODEL"
+	envAskRiskPolicy = "DM_ASK_RISK_POLICY"
+	envAskJSON       = "DM_ASK_JSON"
+)
+
+type askEnvTargets struct {
+	provider   *string
+	model      *string
+	riskPolicy *string
+	jsonOut    *bool
+}
+
+// applyAskEnvDefaults fills ask settings from DM_ASK_* variables.
+// Flags set explicitly on the command line always win.
+func applyAskEnvDefaults(changed func(name string) bool, t askEnvTargets) error {
+	setString := func(flag, env string, dst *string) {
+		if dst == nil || changed(flag) {
+			return
+		}
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			*dst = v
+		}
+	}
+	setString("provider", envAskProvider, t.provider)
+	setString("model", envAskModel, t.model)
+	setString("risk-policy", envAskRiskPolicy, t.riskPolicy)
+
+	if t.jsonOut != nil && !changed("json") {
+		if raw := strings.TrimSpace(os.Getenv(envAskJSON)); raw != "" {
+			v, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q (use true|false)", envAskJSON, raw)
+			}
+			*t.jsonOut = v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"cli/internal/plugins"
+	"cli/tools"
+)
+
+const askFilterNone = "none"
+
+// askActionFilter restricts which tools and plugins a single ask invocation
+// may see and run. A nil list means no restriction; an empty non-nil list
+// (from "none") disables that kind of action entirely. readOnly further
+// limits both to actions rated low risk.
+type askActionFilter struct {
+	tools    []string
+	plugins  []string
+	readOnly bool
+}
+
+func parseAskFilter(toolsRaw, pluginsRaw string) (askActionFilter, error) {
+	var f askActionFilter
+	toolNames, err := splitAskFilterList(toolsRaw)
+	if err != nil {
+		return f, fmt.Errorf("--tools: %w", err)
+	}
+	for i, name := range toolNames {
+		canonical := tools.CanonicalToolName(name)
+		if canonical == "" {
+			return f, fmt.Errorf("--tools: unknown tool %q", name)
+		}
+		toolNames[i] = canonical
+	}
+	f.tools = toolNames
+
+	patterns, err := splitAskFilterList(pluginsRaw)
+	if err != nil {
+		return f, fmt.Errorf("--plugins: %w", err)
+	}
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return f, fmt.Errorf("--plugins: invalid pattern %q", p)
+		}
+	}
+	f.plugins = patterns
+	return f, nil
+}
+
+func splitAskFilterList(raw string) ([]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if strings.EqualFold(raw, askFilterNone) {
+		return []string{}, nil
+	}
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		if part == askFilterNone {
+			return nil, fmt.Errorf("%q cannot be combined with other names", askFilterNone)
+		}
+		out = append(out, part)
+	}
+	return out, nil
+}
+
+func (f askActionFilter) active() bool {
+	return f.tools != nil || f.plugins != nil || f.readOnly
+}
+
+func (f askActionFilter) allowsTool(name string) bool {
+	if f.tools == nil {
+		return true
+	}
+	canonical := tools.CanonicalToolName(name)
+	for _, t := range f.tools {
+		if t == canonical {
+			return true
+		}
+	}
+	return false
+}
+
+// catalogTool reports whether a tool belongs in the planner catalog. In
+// read-only mode only tools whose default risk is low are listed; their
+// arguments are still checked per call by readOnlyRefusal.
+func (f askActionFilter) catalogTool(name string) bool {
+	if !f.allowsTool(name) {
+		return false
+	}
+	if f.readOnly {
+		risk, _ := tools.ToolRisk(name, nil)
+		return risk == "low"
+	}
+	return true
+}
+
+// allowsPlugin matches the plugin name against the --plugins patterns
+// (see plugins.MatchName).
+func (f askActionFilter) allowsPlugin(name string) bool {
+	if f.plugins == nil {
+		return true
+	}
+	for _, p := range f.plugins {
+		if plugins.MatchName(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f askActionFilter) String() string {
+	var parts []string
+	if f.tools != nil {
+		parts = append(parts, "tools="+askFilterListString(f.tools))
+	}
+	if f.plugins != nil {
+		parts = append(parts, "plugins="+askFilterListString(f.plugins))
+	}
+	if f.readOnly {
+		parts = append(parts, "read-only")
+	}
+	return strings.Join(parts, " ")
+}
+
+func askFilterListString(list []string) string {
+	if len(list) == 0 {
+		return askFilterNone
+	}
+	return strings.Join(list, ",")
+}
+
+func outOfFilterMessage(kind, name string, f askActionFilter) string {
+	return fmt.Sprintf("%s %s is outside this session's scope (%s); pick an allowed %s or answer directly", kind, name, f.String(), kind)
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli/internal/agent"
+)
+
+func TestParseAskFilter(t *testing.T) {
+	f, err := parseAskFilter("grep, C", "git_*,Docker")
+	if err != nil {
+		t.Fatalf("parseAskFilter: %v", err)
+	}
+	if !f.active() {
+		t.Fatal("expected filter to be active")
+	}
+	if strings.Join(f.tools, ",") != "grep,clean" {
+		t.Fatalf("unexpected tools %v", f.tools)
+	}
+	if !f.allowsTool("rg") || f.allowsTool("read") {
+		t.Fatal("tool aliases should resolve before matching")
+	}
+	for name, want := range map[string]bool{
+		"git_status":   true,
+		"GIT_log":      true,
+		"docker":       true,
+		"docker_ps":    true,
+		"dockerize":    false,
+		"stibs_deploy": false,
+	} {
+		if got := f.allowsPlugin(name); got != want {
+			t.Fatalf("allowsPlugin(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParseAskFilterEmptyAndNone(t *testing.T) {
+	f, err := parseAskFilter("", "")
+	if err != nil || f.active() {
+		t.Fatalf("empty flags should not filter: %+v, %v", f, err)
+	}
+	if !f.allowsTool("read") || !f.allowsPlugin("anything") {
+		t.Fatal("inactive filter should allow everything")
+	}
+
+	f, err = parseAskFilter("none", "")
+	if err != nil {
+		t.Fatalf("parseAskFilter: %v", err)
+	}
+	if f.allowsTool("grep") || !f.allowsPlugin("git_status") {
+		t.Fatal("none should disable tools only")
+	}
+	if f.String() != "tools=none" {
+		t.Fatalf("unexpected String() %q", f.String())
+	}
+}
+
+func TestParseAskFilterErrors(t *testing.T) {
+	cases := []struct{ tools, plugins, want string }{
+		{"nosuchtool", "", "unknown tool"},
+		{"grep,none", "", "cannot be combined"},
+		{"", "git_[", "invalid pattern"},
+	}
+	for _, c := range cases {
+		_, err := parseAskFilter(c.tools, c.plugins)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Fatalf("parseAskFilter(%q, %q) error = %v, want %q", c.tools, c.plugins, err, c.want)
+		}
+	}
+}
+
+func TestBuildToolsCatalogFiltered(t *testing.T) {
+	f, err := parseAskFilter("grep", "")
+	if err != nil {
+		t.Fatalf("parseAskFilter: %v", err)
+	}
+	catalog := buildToolsCatalog(f)
+	if !strings.HasPrefix(catalog, "- grep:") || strings.Contains(catalog, "- read:") {
+		t.Fatalf("unexpected filtered catalog:\n%s", catalog)
+	}
+	if buildToolsCatalog(askActionFilter{}) == catalog {
+		t.Fatal("unfiltered catalog should list more tools")
+	}
+}
+
+func TestReadOnlyCatalogAndRefusal(t *testing.T) {
+	f := askActionFilter{readOnly: true}
+	if f.catalogTool("rename") || !f.catalogTool("clean") || !f.catalogTool("grep") {
+		t.Fatal("read-only catalog should keep only low-risk tools")
+	}
+	if msg := readOnlyRefusal(agent.DecisionResult{Action: "run_tool", Tool: "clean"}); msg != "" {
+		t.Fatalf("clean preview should be allowed, got %q", msg)
+	}
+	msg := readOnlyRefusal(agent.DecisionResult{Action: "run_tool", Tool: "clean", ToolArgs: map[string]string{"apply": "true"}})
+	if !strings.Contains(msg, "high risk") {
+		t.Fatalf("expected clean apply to be refused, got %q", msg)
+	}
+	if readOnlyRefusal(agent.DecisionResult{Action: "create_function"}) == "" {
+		t.Fatal("expected create_function to be refused")
+	}
+	if readOnlyRefusal(agent.DecisionResult{Action: "answer"}) != "" {
+		t.Fatal("answers should always be allowed")
+	}
+}
+
+func TestAssessPluginRiskUsesSafetyHeader(t *testing.T) {
+	dir := t.TempDir()
+	readOnly := filepath.Join(dir, "Git_Toolkit.ps1")
+	if err := os.WriteFile(readOnly, []byte("# Safety: Read-only\nfunction g_status {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if risk, _ := assessPluginRisk("g_status", readOnly); risk != "low" {
+		t.Fatalf("expected low risk from read-only header, got %q", risk)
+	}
+	if risk, _ := assessPluginRisk("g_delete_branch", readOnly); risk != "high" {
+		t.Fatalf("destructive names should stay high, got %q", risk)
+	}
+	if risk, _ := assessPluginRisk("g_status", ""); risk != "medium" {
+		t.Fatalf("expected medium without a header, got %q", risk)
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/internal/plugins"
+	"cli/internal/redact"
+	"cli/internal/ui"
+)
+
+// proposeArgFix asks the model for corrected arguments after a failed
+// run_plugin step and lets the user accept a single retry with them.
+func proposeArgFix(ctx askStepContext, decision agent.DecisionResult, info plugins.Info, run plugins.RunResult) (agent.DecisionResult, bool) {
+	if !ctx.retryFix || ctx.jsonOut || !ui.StdinIsTerminal() {
+		return decision, false
+	}
+	kind := plugins.ErrorKindOf(run.Err)
+	if kind == plugins.ErrorSyntax || kind == plugins.ErrorTimeout {
+		return decision, false
+	}
+	fix, err := runLLMCall("Looking for corrected arguments...", llmLabel(ctx.opts), true, func(callCtx context.Context) (agent.ArgFix, error) {
+		opts := ctx.opts
+		opts.Context = callCtx
+		return agent.SuggestArgFix(agent.ArgFixRequest{
+			UserRequest: ctx.prompt,
+			Plugin:      decision.Plugin,
+			Parameters:  info.Parameters,
+			Args:        decision.PluginArgs,
+			ErrorKind:   string(kind),
+			ErrorOutput: redact.String(run.Err.Error() + "\n" + run.Output),
+		}, opts)
+	})
+	if err != nil {
+		slog.Debug("argument fix failed", "err", err)
+		return decision, false
+	}
+	var dropped []string
+	fix.PluginArgs, dropped = declaredPluginArgs(info, fix.PluginArgs)
+	if len(dropped) > 0 {
+		fmt.Println("  " + ui.Muted("Ignored undeclared parameters: "+strings.Join(dropped, ", ")))
+	}
+	if len(fix.PluginArgs) == 0 || formatPluginArgs(fix.PluginArgs) == formatPluginArgs(decision.PluginArgs) {
+		fmt.Println("  " + ui.Muted("No argument fix suggested."))
+		return decision, false
+	}
+	fmt.Println("  " + ui.Warn("Suggested fix:") + " " + formatPluginArgs(fix.PluginArgs))
+	if fix.Reason != "" {
+		fmt.Println("  " + ui.Muted(fix.Reason))
+	}
+	fmt.Print(ui.Prompt("Retry with these arguments? [y/N] "))
+	confirm := strings.ToLower(strings.TrimSpace(readLine(bufio.NewReader(os.Stdin))))
+	if confirm != "y" && confirm != "yes" {
+		return decision, false
+	}
+	fixed := decision
+	fixed.PluginArgs = fix.PluginArgs
+	fixed.Args = nil
+	fixed.Reason = strings.TrimSpace("retry with corrected arguments. " + fix.Reason)
+	return fixed, true
+}
+
+// declaredPluginArgs keeps the args that name a parameter the plugin
+// declares, spelled as declared, and returns the other keys sorted.
+func declaredPluginArgs(info plugins.Info, args map[string]string) (map[string]string, []string) {
+	kept := map[string]string{}
+	var dropped []string
+	for k, v := range args {
+		name := ""
+		for _, p := range info.ParamDetails {
+			if strings.EqualFold(strings.TrimPrefix(k, "-"), p.Name) {
+				name = p.Name
+				break
+			}
+		}
+		if name == "" {
+			dropped = append(dropped, k)
+			continue
+		}
+		kept[name] = v
+	}
+	sort.Strings(dropped)
+	return kept, dropped
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"cli/internal/plugins"
+)
+
+func TestDeclaredPluginArgsDropsUnknownKeys(t *testing.T) {
+	info := plugins.Info{ParamDetails: []plugins.ParamDetail{{Name: "Path"}, {Name: "Force", Switch: true}}}
+	kept, dropped := declaredPluginArgs(info, map[string]string{
+		"path": `C:\Data`, "-Force": "true", "Command": "Remove-Item C:\\", "Verbose": "true",
+	})
+	if want := map[string]string{"Path": `C:\Data`, "Force": "true"}; !reflect.DeepEqual(kept, want) {
+		t.Fatalf("expected %v, got %v", want, kept)
+	}
+	if want := []string{"Command", "Verbose"}; !reflect.DeepEqual(dropped, want) {
+		t.Fatalf("expected dropped %v, got %v", want, dropped)
+	}
+
+	kept, dropped = declaredPluginArgs(plugins.Info{}, map[string]string{"Path": "x"})
+	if len(kept) != 0 || len(dropped) != 1 {
+		t.Fatalf("a plugin without declared parameters takes no args, got %v %v", kept, dropped)
+	}
+}
@@ -1,18 +1,40 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
 
 	"cli/internal/agent"
 	"cli/internal/plugins"
+	"cli/internal/redact"
 	"cli/internal/ui"
 )
 
+// askPluginLockPaths returns the plugin arguments naming existing files or
+// folders, the paths a plugin run is locked on.
+func askPluginLockPaths(args []string) []string {
+	var out []string
+	for _, a := range args {
+		a = strings.Trim(strings.TrimSpace(a), `"'`)
+		if a == "" || strings.HasPrefix(a, "-") {
+			continue
+		}
+		if !filepath.IsAbs(a) {
+			a = filepath.Join(askCurrentDir(), a)
+		}
+		if _, err := os.Stat(a); err == nil {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
 func pluginArgsToPS(pluginArgs map[string]string) []string {
 	if len(pluginArgs) == 0 {
 		return nil
@@ -102,7 +124,7 @@ func plannedActionSummary(decision agent.DecisionResult) string {
 	case "create_function":
 		desc := strings.TrimSpace(decision.FunctionDescription)
 		if len(desc) > askDescMaxLen {
-			desc = desc[:askDescMaxLen] + "..."
+			desc = ui.TruncateBytes(desc, askDescMaxLen) + "..."
 		}
 		return "create function: " + desc
 	default:
@@ -175,18 +197,39 @@ func trimToTokenBudget(prompt, sessionBlock, previousBlock string, budget int) (
 }
 
 func truncateForHistory(s string, maxLen int) string {
-	s = strings.TrimSpace(s)
+	s = redact.String(strings.TrimSpace(s))
 	if len(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen] + "\n... (truncated)"
+	return ui.TruncateBytes(s, maxLen) + "\n... (truncated)"
 }
 
-func printAgentActionError(err error) {
+// summarizeForHistory redacts secrets before the output is stored or sent
+// to the model for summarization.
+func summarizeForHistory(ctx askStepContext, output string) string {
+	trimmed := redact.String(strings.TrimSpace(output))
+	if len(trimmed) <= askHistoryMaxLen || ctx.offline {
+		return truncateForHistory(trimmed, askHistoryMaxLen)
+	}
+	res, err := runLLMCall("Summarizing output...", "", !ctx.jsonOut, func(callCtx context.Context) (agent.AskResult, error) {
+		opts := ctx.opts
+		opts.Context = callCtx
+		return agent.SummarizeOutput(trimmed, ctx.prompt, opts)
+	})
+	if err != nil || strings.TrimSpace(res.Text) == "" {
+		slog.Debug("output summarization failed, truncating", "err", err)
+		return truncateForHistory(trimmed, askHistoryMaxLen)
+	}
+	summary := fmt.Sprintf("(summary of %d chars of output)\n%s", len(trimmed), strings.TrimSpace(res.Text))
+	return truncateForHistory(summary, askHistoryMaxLen)
+}
+
+func printAgentActionError(name string, err error) {
 	raw := plugins.ErrorOutput(err)
 	combined := strings.TrimSpace(err.Error() + "\n" + raw)
+	kind := plugins.ErrorKindOf(err)
 
-	slog.Debug("plugin error trace", "raw", combined)
+	slog.Debug("plugin error trace", "kind", kind, "raw", redact.String(combined))
 
 	friendly := extractFriendlyError(combined)
 	if friendly != "" {
@@ -198,7 +241,35 @@ func printAgentActionError(err error) {
 	if m := missingPathErr.FindStringSubmatch(combined); len(m) == 2 {
 		fmt.Println("  " + ui.Warn("Missing path: "+m[1]))
 		fmt.Println("  " + ui.Muted("Check plugin config, then retry."))
+		return
+	}
+	if hint := runErrorHint(kind, name); hint != "" {
+		fmt.Println("  " + ui.Muted(hint))
+	}
+}
+
+// runErrorHint suggests the next step for a failed plugin run.
+func runErrorHint(kind plugins.ErrorKind, name string) string {
+	switch kind {
+	case plugins.ErrorSyntax:
+		return "The script does not parse; fix it with: dm open plugin " + name
+	case plugins.ErrorNotFound:
+		return "A command, function or path it needs is missing; check the plugin's prerequisites."
+	case plugins.ErrorThrow:
+		return "The plugin threw an error; check its arguments with: dm plugins info " + name
+	case plugins.ErrorTimeout:
+		return "The plugin did not finish in time; run it directly with: dm plugins run " + name
+	}
+	return ""
+}
+
+// historyErrorPrefix tags failed steps in ask history so the model can tell
+// a broken script from a bad argument.
+func historyErrorPrefix(err error) string {
+	if kind := plugins.ErrorKindOf(err); kind != "" {
+		return "error (" + string(kind) + "): "
 	}
+	return "error: "
 }
 
 const fileContextMaxBytes = 32 * 1024
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"strings"
+
+	"cli/internal/ui"
+)
+
+const (
+	askHeredoc    = `"""`
+	askPasteStart = "\033[200~"
+	askPasteEnd   = "\033[201~"
+)
+
+// readAskInput reads one prompt from the interactive ask loop through next,
+// which prints its prompt argument and returns one line. A line starting
+// with """ continues until a line ending with """, and a bracketed paste
+// that reaches us unparsed continues until the paste ends, so pasted logs
+// arrive as one prompt instead of one prompt per line.
+func readAskInput(next func(prompt string) (string, error), prompt, continuation string) (string, error) {
+	line, err := next(prompt)
+	if strings.Contains(line, askPasteStart) {
+		return readAskPaste(next, line, err)
+	}
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, askHeredoc) {
+		return trimmed, err
+	}
+	body := strings.TrimPrefix(trimmed, askHeredoc)
+	if strings.HasSuffix(body, askHeredoc) {
+		return strings.TrimSpace(strings.TrimSuffix(body, askHeredoc)), err
+	}
+	lines := []string{}
+	if strings.TrimSpace(body) != "" {
+		lines = append(lines, body)
+	}
+	for err == nil {
+		line, err = next(ui.Muted(continuation))
+		text := strings.TrimRight(line, "\r\n")
+		if strings.HasSuffix(strings.TrimSpace(text), askHeredoc) {
+			text = strings.TrimSuffix(strings.TrimSpace(text), askHeredoc)
+			if text != "" {
+				lines = append(lines, text)
+			}
+			return strings.TrimSpace(strings.Join(lines, "\n")), nil
+		}
+		if err == nil || text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), err
+}
+
+func readAskPaste(next func(prompt string) (string, error), first string, err error) (string, error) {
+	var b strings.Builder
+	b.WriteString(strings.Replace(first, askPasteStart, "", 1))
+	for !strings.Contains(b.String(), askPasteEnd) && err == nil {
+		var line string
+		line, err = next("")
+		b.WriteString("\n" + line)
+	}
+	text := strings.Replace(b.String(), askPasteEnd, "", 1)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.TrimSpace(text), err
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func plainLines(r *bufio.Reader) func(string) (string, error) {
+	return func(string) (string, error) {
+		line, err := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err
+	}
+}
+
+func TestReadAskInputSingleLine(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("  list pdf files \nnext\n"))
+	got, err := readAskInput(plainLines(r), "", "")
+	if err != nil || got != "list pdf files" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+}
+
+func TestReadAskInputHeredoc(t *testing.T) {
+	in := "\"\"\"explain this error\npanic: boom\n  at main.go:3\n\"\"\"\nnext\n"
+	r := bufio.NewReader(strings.NewReader(in))
+	got, err := readAskInput(plainLines(r), "", "")
+	if err != nil {
+		t.Fatalf("readAskInput: %v", err)
+	}
+	want := "explain this error\npanic: boom\n  at main.go:3"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if rest, _ := readAskInput(plainLines(r), "", ""); rest != "next" {
+		t.Fatalf("expected the following line to stay unread, got %q", rest)
+	}
+}
+
+func TestReadAskInputHeredocSameLine(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\"\"\" hello \"\"\"\n"))
+	if got, _ := readAskInput(plainLines(r), "", ""); got != "hello" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestReadAskInputHeredocEOF(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\"\"\"\nline one\nline two"))
+	got, err := readAskInput(plainLines(r), "", "")
+	if err != io.EOF || got != "line one\nline two" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+}
+
+func TestReadAskInputBracketedPaste(t *testing.T) {
+	in := "why does this fail? " + askPasteStart + "ERROR 1\r\nERROR 2\r\n" + askPasteEnd + "\nnext\n"
+	r := bufio.NewReader(strings.NewReader(in))
+	got, err := readAskInput(plainLines(r), "", "")
+	if err != nil {
+		t.Fatalf("readAskInput: %v", err)
+	}
+	if got != "why does this fail? ERROR 1\nERROR 2" {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"cli/internal/agent"
+	"cli/internal/ui"
+)
+
+var errLLMCanceled = errors.New("canceled")
+
+// runLLMCall runs call behind a spinner showing message, the elapsed time
+// and label (usually provider/model). When show is false (JSON output) it just runs
+// call. While the spinner is up, Ctrl-C cancels ctx, which call passes on
+// as AskOptions.Context so the request is aborted, and returns
+// errLLMCanceled.
+func runLLMCall[T any](message, label string, show bool, call func(ctx context.Context) (T, error)) (T, error) {
+	return runLLMStream(message, label, show, nil, func(ctx context.Context, _ func(string)) (T, error) {
+		return call(ctx)
+	})
+}
+
+// askStreamSink is where streamed answer text goes: the terminal and
+// porcelain chunk records. Plain --json output does not stream at all.
+func askStreamSink(p askSessionParams, out askOutputWriter) func(string) {
+	if p.jsonOut && p.porcelain == nil {
+		return nil
+	}
+	return out.StreamChunk
+}
+
+// runLLMStream is runLLMCall for streaming calls: chunks passed to the
+// callback are forwarded to sink, and the spinner is cleared before the
+// first one is written. Chunks that arrive after Ctrl-C are dropped.
+func runLLMStream[T any](message, label string, show bool, sink func(string), call func(ctx context.Context, onChunk func(string)) (T, error)) (T, error) {
+	if !show {
+		return call(context.Background(), sink)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	interrupted := make(chan struct{}, 1)
+	var mu sync.Mutex
+	canceled := false
+	release := onInterrupt(func() {
+		mu.Lock()
+		canceled = true
+		mu.Unlock()
+		cancel()
+		select {
+		case interrupted <- struct{}{}:
+		default:
+		}
+	})
+	defer release()
+
+	spinner := ui.NewSpinner(message)
+	spinner.SetDetail(label)
+	spinner.Start()
+	defer spinner.Stop()
+
+	var onChunk func(string)
+	if sink != nil {
+		onChunk = func(chunk string) {
+			mu.Lock()
+			defer mu.Unlock()
+			if canceled {
+				return
+			}
+			spinner.Stop()
+			sink(chunk)
+		}
+	}
+	go func() {
+		v, err := call(ctx, onChunk)
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-interrupted:
+		var zero T
+		return zero, errLLMCanceled
+	}
+}
+
+func llmLabel(opts agent.AskOptions) string {
+	provider := strings.TrimSpace(opts.Provider)
+	model := strings.TrimSpace(opts.Model)
+	switch {
+	case provider != "" && model != "":
+		return provider + "/" + model
+	case model != "":
+		return model
+	default:
+		return provider
+	}
+}
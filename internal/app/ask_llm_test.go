@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cli/internal/agent"
+)
+
+func TestRunLLMCallReturnsResult(t *testing.T) {
+	got, err := runLLMCall("Thinking...", "openai/gpt", true, func(context.Context) (string, error) {
+		return "ok", nil
+	})
+	if err != nil || got != "ok" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	if currentInterruptHook() != nil {
+		t.Fatal("interrupt hook should be released after the call")
+	}
+}
+
+func TestRunLLMCallCanceledByInterrupt(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	go func() {
+		for currentInterruptHook() == nil {
+			time.Sleep(time.Millisecond)
+		}
+		currentInterruptHook()()
+	}()
+	aborted := make(chan struct{})
+	_, err := runLLMCall("Thinking...", "", true, func(ctx context.Context) (string, error) {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			close(aborted)
+		}
+		return "late", nil
+	})
+	if !errors.Is(err, errLLMCanceled) {
+		t.Fatalf("expected errLLMCanceled, got %v", err)
+	}
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("expected the call's context to be canceled")
+	}
+}
+
+func TestRunLLMStreamForwardsChunks(t *testing.T) {
+	var got []string
+	res, err := runLLMStream("Thinking...", "", true, func(c string) { got = append(got, c) }, func(_ context.Context, onChunk func(string)) (string, error) {
+		onChunk("Hel")
+		onChunk("lo")
+		return "Hello", nil
+	})
+	if err != nil || res != "Hello" {
+		t.Fatalf("got %q, %v", res, err)
+	}
+	if len(got) != 2 || got[0]+got[1] != "Hello" {
+		t.Fatalf("unexpected chunks %q", got)
+	}
+}
+
+func TestRunLLMStreamWithoutSink(t *testing.T) {
+	_, err := runLLMStream("Thinking...", "", false, nil, func(_ context.Context, onChunk func(string)) (string, error) {
+		if onChunk != nil {
+			t.Fatal("expected no chunk callback without a sink")
+		}
+		return "", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLLMLabel(t *testing.T) {
+	cases := map[string]agent.AskOptions{
+		"ollama/llama3": {Provider: "ollama", Model: "llama3"},
+		"openai":        {Provider: "openai"},
+		"gpt-4o":        {Model: "gpt-4o"},
+	}
+	for want, opts := range cases {
+		if got := llmLabel(opts); got != want {
+			t.Fatalf("llmLabel(%+v) = %q, want %q", opts, got, want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/internal/plugins"
+)
+
+var (
+	offlineQuoted = regexp.MustCompile(`["']([^"']+)["']`)
+	offlineDotExt = regexp.MustCompile(`^[a-z0-9]{1,5}$`)
+
+	offlineExtensions = map[string]bool{
+		"pdf": true, "doc": true, "docx": true, "xls": true, "xlsx": true, "ppt": true, "pptx": true,
+		"csv": true, "txt": true, "md": true, "log": true, "json": true, "yaml": true, "yml": true, "xml": true,
+		"jpg": true, "jpeg": true, "png": true, "gif": true, "svg": true, "mp3": true, "mp4": true, "mkv": true,
+		"zip": true, "rar": true, "7z": true, "iso": true, "exe": true, "msi": true,
+		"ps1": true, "py": true, "js": true,
+	}
+
+	offlineFolders = map[string]string{
+		"downloads": "Downloads", "download": "Downloads", "scaricati": "Downloads",
+		"desktop": "Desktop", "scrivania": "Desktop",
+		"documents": "Documents", "documenti": "Documents",
+		"pictures": "Pictures", "immagini": "Pictures", "foto": "Pictures",
+		"music": "Music", "musica": "Music",
+		"videos": "Videos", "video": "Videos",
+		"home": "",
+	}
+
+	offlineRunVerbs    = []string{"run", "start", "execute", "esegui", "lancia", "avvia"}
+	offlineSystemWords = []string{"system", "sistema", "cpu", "ram", "memory", "memoria", "disk", "disks", "disco", "dischi"}
+	offlineRecentWords = []string{"recent", "recenti", "latest", "newest", "ultimi", "modified", "modificati"}
+	offlineGrepWords   = []string{"containing", "contain", "contains", "contiene", "contengono", "grep"}
+	offlineReadWords   = []string{"read", "leggi", "cat", "open", "apri"}
+	offlineSearchWords = []string{"search", "find", "locate", "where", "cerca", "trova"}
+	offlineNameWords   = []string{"named", "called", "chiamato", "chiamati", "nome"}
+)
+
+// isProviderUnreachable reports whether err means no LLM provider could be
+// contacted at all, as opposed to a provider that answered with an error.
+func isProviderUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, hint := range []string{
+		"connection refused", "no such host", "dial tcp", "network is unreachable",
+		"i/o timeout", "client.timeout", "no provider in the fallback chain is available",
+	} {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+type offlinePrompt struct {
+	words  []string
+	quoted string
+	ext    string
+	base   string
+	file   string
+}
+
+func parseOfflinePrompt(prompt string) offlinePrompt {
+	var p offlinePrompt
+	if m := offlineQuoted.FindStringSubmatch(prompt); m != nil {
+		p.quoted = strings.TrimSpace(m[1])
+	}
+	home, _ := os.UserHomeDir()
+	for _, raw := range strings.Fields(offlineQuoted.ReplaceAllString(prompt, " ")) {
+		tok := strings.Trim(raw, ",;:!?()")
+		lower := strings.ToLower(tok)
+		if lower == "" {
+			continue
+		}
+		p.words = append(p.words, lower)
+		if ext := strings.TrimPrefix(lower, "."); p.ext == "" && (offlineExtensions[ext] || (ext != lower && offlineDotExt.MatchString(ext))) {
+			p.ext = ext
+		} else if ext := strings.TrimSuffix(lower, "s"); p.ext == "" && len(ext) >= 3 && offlineExtensions[ext] {
+			p.ext = ext
+		}
+		if folder, ok := offlineFolders[lower]; ok && p.base == "" && home != "" {
+			p.base = filepath.Join(home, folder)
+			continue
+		}
+		if strings.HasPrefix(tok, "~") && home != "" {
+			tok = filepath.Join(home, strings.TrimPrefix(strings.TrimPrefix(tok, "~"), "/"))
+		}
+		info, err := os.Stat(tok)
+		switch {
+		case err != nil:
+		case info.IsDir() && p.base == "":
+			p.base = tok
+		case !info.IsDir() && p.file == "":
+			p.file = tok
+		}
+	}
+	return p
+}
+
+func (p offlinePrompt) has(words []string) bool {
+	for _, w := range p.words {
+		for _, k := range words {
+			if w == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// after returns the word following the first of keys in the prompt.
+func (p offlinePrompt) after(keys []string) string {
+	for i, w := range p.words {
+		for _, k := range keys {
+			if w == k && i+1 < len(p.words) {
+				return p.words[i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// offlinePlugin picks the plugin named exactly after a run verb
+// ("run backup_home"). A word that is only part of a name is not enough:
+// "how do I start docker?" must not pick docker_prune.
+func offlinePlugin(p offlinePrompt, pluginNames []string, filter askActionFilter) string {
+	word := p.after(offlineRunVerbs)
+	if word == "" {
+		return ""
+	}
+	for _, name := range pluginNames {
+		if filter.allowsPlugin(name) && strings.ToLower(name) == word {
+			return name
+		}
+	}
+	return ""
+}
+
+// offlineDecision maps simple requests to a tool or plugin with keyword
+// rules (English and Italian), for when no provider can be reached. Only
+// read-only tools are chosen; a plugin is always confirmed, defaulting to no.
+func offlineDecision(prompt string, pluginNames []string, filter askActionFilter) (agent.DecisionResult, bool) {
+	p := parseOfflinePrompt(prompt)
+	decision := agent.DecisionResult{Action: "run_tool", ToolArgs: map[string]string{}, Provider: "offline"}
+	setArg := func(key, value string) {
+		if value != "" {
+			decision.ToolArgs[key] = value
+		}
+	}
+
+	if name := offlinePlugin(p, pluginNames, filter); name != "" {
+		return agent.DecisionResult{
+			Action: "run_plugin", Plugin: name, Provider: "offline",
+			Reason: "offline rule: run verb followed by a plugin name",
+		}, true
+	}
+	switch {
+	case p.has(offlineSystemWords) && filter.allowsTool("system"):
+		decision.Tool = "system"
+		decision.Reason = "offline rule: system keyword"
+	case p.has(offlineRecentWords) && filter.allowsTool("recent"):
+		decision.Tool = "recent"
+		decision.Reason = "offline rule: recent-files keyword"
+		setArg("base", p.base)
+		setArg("ext", p.ext)
+	case p.has(offlineGrepWords) && filter.allowsTool("grep"):
+		pattern := p.quoted
+		if pattern == "" {
+			pattern = p.after(offlineGrepWords)
+		}
+		if pattern == "" {
+			return agent.DecisionResult{}, false
+		}
+		decision.Tool = "grep"
+		decision.Reason = "offline rule: content-search keyword"
+		setArg("pattern", pattern)
+		setArg("base", p.base)
+		setArg("ext", p.ext)
+	case p.has(offlineReadWords) && p.file != "" && filter.allowsTool("read"):
+		decision.Tool = "read"
+		decision.Reason = "offline rule: read keyword with an existing file"
+		setArg("path", p.file)
+	case (p.has(offlineSearchWords) || p.ext != "") && filter.allowsTool("search"):
+		decision.Tool = "search"
+		decision.Reason = "offline rule: search keyword"
+		setArg("base", p.base)
+		setArg("ext", p.ext)
+		name := p.quoted
+		if name == "" {
+			name = p.after(offlineNameWords)
+		}
+		setArg("name", name)
+	default:
+		return agent.DecisionResult{}, false
+	}
+	return decision, true
+}
+
+// askOfflineDecision runs the offline rules against the plugins in baseDir.
+func askOfflineDecision(baseDir, prompt string, filter askActionFilter) (agent.DecisionResult, error) {
+	var names []string
+	if entries, err := plugins.ListEntries(baseDir, true); err == nil {
+		for _, e := range entries {
+			names = append(names, e.Name)
+		}
+	}
+	if d, ok := offlineDecision(prompt, names, filter); ok {
+		return d, nil
+	}
+	return agent.DecisionResult{}, fmt.Errorf("offline mode: no keyword rule matches this request (try e.g. \"search pdf in downloads\" or \"run <plugin>\")")
+}
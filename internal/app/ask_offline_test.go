@@ -0,0 +1,96 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOfflineDecision(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	notes := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(notes, []byte("hi"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plugins := []string{"backup_home", "git_status", "git_log", "docker_prune"}
+
+	cases := []struct {
+		prompt string
+		action string
+		target string
+		args   map[string]string
+	}{
+		{"search pdf in downloads", "run_tool", "search", map[string]string{"base": filepath.Join(home, "Downloads"), "ext": "pdf"}},
+		{"cerca i file .xlsx nei documenti", "run_tool", "search", map[string]string{"base": filepath.Join(home, "Documents"), "ext": "xlsx"}},
+		{"find files named report", "run_tool", "search", map[string]string{"name": "report"}},
+		{"run backup_home", "run_plugin", "backup_home", nil},
+		{"esegui git_log", "run_plugin", "git_log", nil},
+		{"file recenti in downloads", "run_tool", "recent", map[string]string{"base": filepath.Join(home, "Downloads")}},
+		{"find files containing 'TODO' in desktop", "run_tool", "grep", map[string]string{"pattern": "TODO", "base": filepath.Join(home, "Desktop")}},
+		{"read " + notes, "run_tool", "read", map[string]string{"path": notes}},
+		{"how much disk is free", "run_tool", "system", nil},
+	}
+	for _, c := range cases {
+		d, ok := offlineDecision(c.prompt, plugins, askActionFilter{})
+		if !ok {
+			t.Fatalf("%q: expected a match", c.prompt)
+		}
+		target := d.Tool + d.Plugin
+		if d.Action != c.action || target != c.target || d.Provider != "offline" {
+			t.Fatalf("%q: got %s %s (provider %q)", c.prompt, d.Action, target, d.Provider)
+		}
+		if fmt.Sprint(d.ToolArgs) != fmt.Sprint(c.args) && !(len(d.ToolArgs) == 0 && len(c.args) == 0) {
+			t.Fatalf("%q: expected args %v, got %v", c.prompt, c.args, d.ToolArgs)
+		}
+	}
+
+	for _, prompt := range []string{"how are you", "run git", "run backup", "how do I start docker?", "explain this error"} {
+		if d, ok := offlineDecision(prompt, plugins, askActionFilter{}); ok {
+			t.Fatalf("%q: expected no match, got %+v", prompt, d)
+		}
+	}
+	filter, err := parseAskFilter("none", "none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := offlineDecision("run backup_home and search pdf", plugins, filter); ok {
+		t.Fatalf("expected the filter to block every rule, got %+v", d)
+	}
+}
+
+func TestIsProviderUnreachable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&url.Error{Op: "Post", URL: "http://127.0.0.1:11434", Err: errors.New("refused")}, true},
+		{errors.New("ollama: dial tcp 127.0.0.1:11434: connect: connection refused"), true},
+		{errors.New("no provider in the fallback chain is available (ollama: ...)"), true},
+		{errors.New("openai error: 401 invalid api key"), false},
+	}
+	for _, c := range cases {
+		if got := isProviderUnreachable(c.err); got != c.want {
+			t.Fatalf("%v: expected %v, got %v", c.err, c.want, got)
+		}
+	}
+}
+
+func TestConfirmAgentActionGuessDefaultsToNo(t *testing.T) {
+	if confirmAgentAction(bufio.NewReader(strings.NewReader("\n")), "medium", true) {
+		t.Fatal("an offline guess must not run on Enter")
+	}
+	if !confirmAgentAction(bufio.NewReader(strings.NewReader("y\n")), "medium", true) {
+		t.Fatal("expected y to confirm a guess")
+	}
+	if !confirmAgentAction(bufio.NewReader(strings.NewReader("\n")), "medium", false) {
+		t.Fatal("expected Enter to confirm a model decision")
+	}
+}
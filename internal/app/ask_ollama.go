@@ -0,0 +1,69 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/internal/ui"
+)
+
+func ensureAskOllamaModel(opts agent.AskOptions, autoPull, jsonOut bool) error {
+	provider := strings.ToLower(strings.TrimSpace(opts.Provider))
+	if provider != "ollama" && provider != "auto" {
+		return nil
+	}
+	session, err := agent.ResolveSessionProvider(opts)
+	if err != nil || session.Provider != "ollama" {
+		return nil
+	}
+	installed, err := agent.OllamaModelInstalled(session.Options.BaseURL, session.Model)
+	if err != nil || installed {
+		return nil
+	}
+	if !autoPull {
+		if provider == "auto" || jsonOut || !ui.StdinIsTerminal() {
+			return nil
+		}
+		fmt.Println(ui.Warn("Ollama model not found: " + session.Model))
+		fmt.Print(ui.Prompt("Pull it now? [y/N] "))
+		confirm := strings.ToLower(strings.TrimSpace(readLine(bufio.NewReader(os.Stdin))))
+		if confirm != "y" && confirm != "yes" {
+			return nil
+		}
+	}
+	fmt.Fprintln(os.Stderr, ui.Muted("Pulling "+session.Model+"..."))
+	err = agent.PullOllamaModel(session.Options.BaseURL, session.Model, func(p agent.PullProgress) {
+		fmt.Fprint(os.Stderr, "\r\033[K"+ui.Muted("  "+formatPullProgress(p)))
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return fmt.Errorf("cannot pull ollama model %s: %w", session.Model, err)
+	}
+	fmt.Fprintln(os.Stderr, ui.OK("Pulled "+session.Model))
+	return nil
+}
+
+func formatPullProgress(p agent.PullProgress) string {
+	status := strings.TrimSpace(p.Status)
+	if p.Total <= 0 {
+		return status
+	}
+	pct := p.Completed * 100 / p.Total
+	return fmt.Sprintf("%s %d%% (%s/%s)", status, pct, formatPullBytes(p.Completed), formatPullBytes(p.Total))
+}
+
+func formatPullBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"cli/internal/agent"
 	"cli/internal/ui"
 )
 
@@ -15,6 +16,9 @@ type askOutputWriter interface {
 	StepInfo(step, maxSteps int, summary, reason, risk, riskReason string)
 	Answer(answer string)
 	PartialAnswer(answer string)
+	StreamChunk(chunk string)
+	CachedDecision(step int)
+	Alternatives(step int, alts []agent.DecisionAlternative)
 	Error(msg string)
 	ErrorWithAnswer(msg, answer string)
 	Canceled(answer string)
@@ -25,6 +29,17 @@ type askOutputWriter interface {
 
 type askTTYWriter struct {
 	providerShown bool
+	plain         bool
+	streamed      strings.Builder
+}
+
+// render formats an answer for the terminal; with --plain the model's
+// markdown is printed untouched.
+func (w *askTTYWriter) render(answer string) string {
+	if w.plain {
+		return answer
+	}
+	return ui.RenderMarkdown(answer)
 }
 
 func (w *askTTYWriter) ProviderInfo(provider, model string) {
@@ -50,19 +65,79 @@ func (w *askTTYWriter) StepInfo(step, maxSteps int, summary, reason, risk, riskR
 	}
 }
 
+// Answer prints the final answer. When the same text was already
+// streamed it is replaced by the rendered answer, or with --plain (or
+// when it cannot be erased) left as it is.
 func (w *askTTYWriter) Answer(answer string) {
+	streamed := w.takeStreamed()
+	if streamed != "" && strings.TrimSpace(streamed) == strings.TrimSpace(answer) {
+		if !w.plain && ui.ClearPrinted(streamed) {
+			fmt.Println(w.render(answer))
+			return
+		}
+		fmt.Println()
+		return
+	}
+	if streamed != "" {
+		fmt.Println()
+	}
 	fmt.Println()
-	fmt.Println(ui.RenderMarkdown(answer))
+	fmt.Println(w.render(answer))
+}
+
+// StreamChunk prints answer text as the model generates it.
+func (w *askTTYWriter) StreamChunk(chunk string) {
+	if w.streamed.Len() == 0 {
+		fmt.Println()
+		chunk = strings.TrimLeft(chunk, " \t\r\n")
+		if chunk == "" {
+			return
+		}
+	}
+	w.streamed.WriteString(chunk)
+	fmt.Print(chunk)
+}
+
+func (w *askTTYWriter) CachedDecision(step int) {
+	fmt.Println(ui.Muted(fmt.Sprintf("Step %d: cached decision (no LLM call)", step)))
+}
+
+// Alternatives prints the actions the planner considered (--explain).
+func (w *askTTYWriter) Alternatives(_ int, alts []agent.DecisionAlternative) {
+	fmt.Println(ui.Muted("Considered:"))
+	for i, a := range alts {
+		fmt.Println(ui.Muted(fmt.Sprintf("  %d. %s", i+1, formatDecisionAlternative(a))))
+	}
+}
+
+func formatDecisionAlternative(a agent.DecisionAlternative) string {
+	line := a.Action
+	if a.Target != "" {
+		line += " " + a.Target
+	}
+	if a.Reason != "" {
+		line += " - " + a.Reason
+	}
+	return line
+}
+
+func (w *askTTYWriter) takeStreamed() string {
+	s := w.streamed.String()
+	w.streamed.Reset()
+	return s
 }
 
 func (w *askTTYWriter) PartialAnswer(answer string) {
 	if strings.TrimSpace(answer) != "" {
 		fmt.Println()
-		fmt.Println(ui.Muted(ui.RenderMarkdown(answer)))
+		fmt.Println(ui.Muted(w.render(answer)))
 	}
 }
 
 func (w *askTTYWriter) Error(msg string) {
+	if w.takeStreamed() != "" {
+		fmt.Println()
+	}
 	fmt.Println()
 	fmt.Println(ui.Error("Error: " + msg))
 }
@@ -71,15 +146,18 @@ func (w *askTTYWriter) ErrorWithAnswer(msg, answer string) {
 	fmt.Println()
 	fmt.Println(ui.Error("Error: " + msg))
 	if strings.TrimSpace(answer) != "" {
-		fmt.Println(ui.RenderMarkdown(answer))
+		fmt.Println(w.render(answer))
 	}
 }
 
 func (w *askTTYWriter) Canceled(answer string) {
+	if w.takeStreamed() != "" {
+		fmt.Println()
+	}
 	fmt.Println()
 	fmt.Println(ui.Warn("Canceled."))
 	if strings.TrimSpace(answer) != "" {
-		fmt.Println(ui.RenderMarkdown(answer))
+		fmt.Println(w.render(answer))
 	}
 }
 
@@ -92,7 +170,7 @@ func (w *askTTYWriter) LoopDetected(answer string) {
 	fmt.Println()
 	fmt.Println(ui.Warn("Stopped to avoid repeated action."))
 	if strings.TrimSpace(answer) != "" {
-		fmt.Println(ui.RenderMarkdown(answer))
+		fmt.Println(w.render(answer))
 	}
 }
 
@@ -105,7 +183,7 @@ func humanizeSummary(summary string) string {
 		name := parts[0]
 		label := "Running " + ui.Accent(name)
 		if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
-			label += " " + ui.Muted(parts[1])
+			label += " " + ui.Muted(ui.Truncate(parts[1], ui.WidthLeft(11+ui.StringWidth(name))))
 		}
 		return label
 	}
@@ -115,24 +193,26 @@ func humanizeSummary(summary string) string {
 		name := parts[0]
 		label := "Running tool " + ui.Accent(name)
 		if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
-			label += " " + ui.Muted(parts[1])
+			label += " " + ui.Muted(ui.Truncate(parts[1], ui.WidthLeft(16+ui.StringWidth(name))))
 		}
 		return label
 	}
 	if strings.HasPrefix(summary, "create function:") {
 		desc := strings.TrimPrefix(summary, "create function: ")
-		return "Creating new function: " + ui.Accent(desc)
+		return "Creating new function: " + ui.Accent(ui.Truncate(desc, ui.WidthLeft(25)))
 	}
 	return summary
 }
 
 type askJSONWriter struct {
-	result askJSONOutput
+	result     askJSONOutput
+	usageStart map[string]agent.Usage
 }
 
 func newAskJSONWriter() *askJSONWriter {
 	return &askJSONWriter{
-		result: askJSONOutput{Action: "answer", Steps: []askJSONStep{}},
+		result:     askJSONOutput{Action: "answer", Steps: []askJSONStep{}},
+		usageStart: agent.UsageByModel(),
 	}
 }
 
@@ -155,6 +235,26 @@ func (w *askJSONWriter) PartialAnswer(answer string) {
 	}
 }
 
+// StreamChunk collects partial text so a canceled or failed run still
+// reports what the model produced; the JSON document is emitted once.
+// Plain --json runs do not stream, so this only sees chunks when another
+// writer forwards them.
+func (w *askJSONWriter) StreamChunk(chunk string) {
+	w.result.Answer += chunk
+}
+
+func (w *askJSONWriter) CachedDecision(step int) {
+	w.result.CachedSteps = append(w.result.CachedSteps, step)
+}
+
+func (w *askJSONWriter) Alternatives(step int, alts []agent.DecisionAlternative) {
+	for _, a := range alts {
+		w.result.Alternatives = append(w.result.Alternatives, askJSONAlternative{
+			Step: step, Action: a.Action, Target: a.Target, Reason: a.Reason,
+		})
+	}
+}
+
 func (w *askJSONWriter) Error(msg string) {
 	w.result.Action = "error"
 	w.result.Error = msg
@@ -199,6 +299,7 @@ func (w *askJSONWriter) AddStep(step askJSONStep) {
 }
 
 func (w *askJSONWriter) emit() {
+	w.result.Usage = usageReport(usageSince(w.usageStart))
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 	_ = enc.Encode(w.result)
@@ -0,0 +1,20 @@
+package app
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAskStreamSink(t *testing.T) {
+	if askStreamSink(askSessionParams{jsonOut: true}, newAskJSONWriter()) != nil {
+		t.Fatal("expected --json not to stream")
+	}
+	porcelain := newAskPorcelainWriter(io.Discard, strings.NewReader(""))
+	if askStreamSink(askSessionParams{jsonOut: true, porcelain: porcelain}, porcelain) == nil {
+		t.Fatal("expected porcelain to stream chunk records")
+	}
+	if askStreamSink(askSessionParams{}, &askTTYWriter{}) == nil {
+		t.Fatal("expected the terminal to stream")
+	}
+}
@@ -0,0 +1,320 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/internal/platform"
+	"cli/internal/plugins"
+	"cli/internal/ui"
+	"cli/tools"
+)
+
+const askPlanVersion = 1
+
+type askPlanStep struct {
+	Action     string            `json:"action"`
+	Plugin     string            `json:"plugin,omitempty"`
+	PluginArgs map[string]string `json:"plugin_args,omitempty"`
+	Args       []string          `json:"args,omitempty"`
+	Tool       string            `json:"tool,omitempty"`
+	ToolArgs   map[string]string `json:"tool_args,omitempty"`
+	Prompt     string            `json:"prompt,omitempty"`
+}
+
+type askPlan struct {
+	Version int           `json:"version"`
+	Steps   []askPlanStep `json:"steps"`
+}
+
+func planStepFromDecision(prompt string, d agent.DecisionResult) askPlanStep {
+	step := askPlanStep{Action: d.Action, Prompt: strings.TrimSpace(prompt)}
+	switch d.Action {
+	case "run_plugin":
+		step.Plugin = strings.TrimSpace(d.Plugin)
+		step.PluginArgs = d.PluginArgs
+		if len(d.PluginArgs) == 0 {
+			step.Args = d.Args
+		}
+	case "run_tool":
+		step.Tool = strings.TrimSpace(d.Tool)
+		step.ToolArgs = d.ToolArgs
+	}
+	return step
+}
+
+func (s askPlanStep) decision() agent.DecisionResult {
+	return agent.DecisionResult{
+		Action:     s.Action,
+		Plugin:     s.Plugin,
+		PluginArgs: s.PluginArgs,
+		Args:       s.Args,
+		Tool:       s.Tool,
+		ToolArgs:   s.ToolArgs,
+	}
+}
+
+func loadAskPlan(path string) (askPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return askPlan{}, err
+	}
+	var plan askPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return askPlan{}, fmt.Errorf("invalid plan file %s: %w", path, err)
+	}
+	for i, s := range plan.Steps {
+		switch s.Action {
+		case "run_plugin":
+			if strings.TrimSpace(s.Plugin) == "" {
+				return askPlan{}, fmt.Errorf("plan step %d: missing plugin name", i+1)
+			}
+		case "run_tool":
+			if strings.TrimSpace(s.Tool) == "" {
+				return askPlan{}, fmt.Errorf("plan step %d: missing tool name", i+1)
+			}
+		default:
+			return askPlan{}, fmt.Errorf("plan step %d: unsupported action %q", i+1, s.Action)
+		}
+	}
+	return plan, nil
+}
+
+func saveAskPlan(path string, plan askPlan) error {
+	plan.Version = askPlanVersion
+	if plan.Steps == nil {
+		plan.Steps = []askPlanStep{}
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return platform.WriteFileAtomic(path, append(data, '\n'), 0644)
+}
+
+func appendAskPlanSteps(path string, steps []askPlanStep) error {
+	plan, err := loadAskPlan(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	plan.Steps = append(plan.Steps, steps...)
+	return saveAskPlan(path, plan)
+}
+
+func runAskReplay(baseDir, path string, confirmTools bool, riskPolicy string, jsonOut bool, porcelain *askPorcelainWriter) int {
+	plan, err := loadAskPlan(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	var out askOutputWriter
+	if porcelain != nil {
+		out = porcelain
+	} else if jsonOut {
+		out = newAskJSONWriter()
+	} else {
+		out = &askTTYWriter{}
+	}
+	if len(plan.Steps) == 0 {
+		out.Answer("Plan has no steps to replay.")
+		return 0
+	}
+	askRiskBaseDir = baseDir
+	for i, s := range plan.Steps {
+		decision := s.decision()
+		risk, riskReason := assessDecisionRisk(decision)
+		out.StepInfo(i+1, len(plan.Steps), plannedActionSummary(decision), s.Prompt, risk, riskReason)
+		stepRecord := askJSONStep{
+			Step: i + 1, Action: s.Action, Target: s.Plugin + s.Tool,
+			Risk: risk, RiskReason: riskReason, Status: "pending",
+		}
+		if s.Action == "run_plugin" {
+			stepRecord.Args = formatPluginArgs(s.PluginArgs)
+			if stepRecord.Args == "" {
+				stepRecord.Args = strings.Join(s.Args, " ")
+			}
+		} else {
+			stepRecord.Args = formatToolArgs(s.ToolArgs)
+		}
+		if shouldConfirmAction(confirmTools, riskPolicy, risk) && !confirmAskStep(out, s.Prompt, stepRecord) {
+			stepRecord.Status = "canceled"
+			out.AddStep(stepRecord)
+			out.Canceled("")
+			return 0
+		}
+		if errMsg := runAskPlanStep(baseDir, s); errMsg != "" {
+			stepRecord.Status = "error"
+			out.AddStep(stepRecord)
+			out.Error(fmt.Sprintf("replay step %d failed: %s", i+1, errMsg))
+			return 1
+		}
+		stepRecord.Status = "ok"
+		out.AddStep(stepRecord)
+	}
+	out.Answer(fmt.Sprintf("Replayed %d step(s) from %s.", len(plan.Steps), path))
+	return 0
+}
+
+func runAskPlanStep(baseDir string, s askPlanStep) string {
+	switch s.Action {
+	case "run_plugin":
+		args := s.Args
+		if len(s.PluginArgs) > 0 {
+			args = pluginArgsToPS(s.PluginArgs)
+		}
+		res := plugins.RunWithOutputAgent(baseDir, s.Plugin, args)
+		if res.Err != nil {
+			return res.Err.Error()
+		}
+	case "run_tool":
+		if !isKnownTool(s.Tool) {
+			return "unknown tool: " + s.Tool
+		}
+		if err := tools.ValidateToolArgs(s.Tool, s.ToolArgs); err != nil {
+			return err.Error()
+		}
+		res := tools.RunByNameWithParamsCapture(baseDir, s.Tool, s.ToolArgs)
+		if res.Code != 0 {
+			return fmt.Sprintf("tool %s exited with code %d", s.Tool, res.Code)
+		}
+	}
+	return ""
+}
+
+func askWorkflowDir() string {
+	return platform.StatePath("workflows")
+}
+
+func legacyAskWorkflowDir(baseDir string) string {
+	return filepath.Join(baseDir, "workflows")
+}
+
+// migrateLegacyWorkflows moves plans saved next to the executable into the
+// state dir and repoints the aliases that replay them.
+func migrateLegacyWorkflows(baseDir string) error {
+	legacy := legacyAskWorkflowDir(baseDir)
+	entries, err := os.ReadDir(legacy)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	moved := map[string]string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".json") {
+			continue
+		}
+		src := filepath.Join(legacy, e.Name())
+		dst := filepath.Join(askWorkflowDir(), e.Name())
+		if fileExists(dst) {
+			continue
+		}
+		if err := platform.MoveFile(src, dst); err != nil {
+			return err
+		}
+		moved[src] = dst
+	}
+	_ = os.Remove(legacy)
+	if len(moved) == 0 {
+		return nil
+	}
+	aliases, err := loadAskAliases(baseDir)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for name, cmd := range aliases {
+		for src, dst := range moved {
+			old := "'" + escapePowerShellSingleQuoted(src) + "'"
+			if strings.Contains(cmd, old) {
+				aliases[name] = strings.ReplaceAll(cmd, old, "'"+escapePowerShellSingleQuoted(dst)+"'")
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return saveAskAliases(baseDir, aliases)
+}
+
+// errAskAliasExists is returned by saveAskWorkflow when the name is taken
+// by another alias and replace is false.
+var errAskAliasExists = errors.New("alias already exists")
+
+func saveAskWorkflow(baseDir, name string, steps []askPlanStep, replace bool) (string, string, error) {
+	aliasName, err := normalizeAskAliasName(name)
+	if err != nil {
+		return "", "", err
+	}
+	if err := migrateLegacyWorkflows(baseDir); err != nil {
+		slog.Debug("workflow migration failed", "err", err)
+	}
+	aliases, err := loadAskAliases(baseDir)
+	if err != nil {
+		return "", "", err
+	}
+	if existing, ok := aliases[aliasName]; ok && !replace {
+		return aliasName, "", fmt.Errorf("%w: %s -> %s", errAskAliasExists, aliasName, existing)
+	}
+	if err := os.MkdirAll(askWorkflowDir(), 0755); err != nil {
+		return "", "", err
+	}
+	planPath := filepath.Join(askWorkflowDir(), aliasName+".json")
+	if err := saveAskPlan(planPath, askPlan{Steps: steps}); err != nil {
+		return "", "", err
+	}
+	exe, err := os.Executable()
+	if err != nil || strings.TrimSpace(exe) == "" {
+		exe = "dm"
+	}
+	command := "& '" + escapePowerShellSingleQuoted(exe) + "' ask --replay '" + escapePowerShellSingleQuoted(planPath) + "'"
+	aliases[aliasName] = command
+	if err := saveAskAliases(baseDir, aliases); err != nil {
+		return "", "", err
+	}
+	return aliasName, planPath, nil
+}
+
+func offerSaveAskWorkflow(reader *bufio.Reader, baseDir string, steps []askPlanStep) bool {
+	if len(steps) == 0 {
+		return false
+	}
+	fmt.Printf("%s\n", ui.Muted(fmt.Sprintf("This session ran %d step(s).", len(steps))))
+	fmt.Print(ui.Prompt("Save as workflow? Name (empty to skip): "))
+	name := strings.TrimSpace(readLine(reader))
+	if name == "" {
+		return false
+	}
+	return saveAskWorkflowAndReport(reader, baseDir, name, steps)
+}
+
+// saveAskWorkflowAndReport saves the workflow, asking before it replaces
+// an existing alias of the same name.
+func saveAskWorkflowAndReport(reader *bufio.Reader, baseDir, name string, steps []askPlanStep) bool {
+	aliasName, planPath, err := saveAskWorkflow(baseDir, name, steps, false)
+	if errors.Is(err, errAskAliasExists) {
+		fmt.Println(ui.Warn(err.Error()))
+		fmt.Print(ui.Prompt("Replace it? [y/N] "))
+		if answer := strings.ToLower(strings.TrimSpace(readLine(reader))); answer != "y" && answer != "yes" {
+			fmt.Println(ui.Muted("Workflow not saved."))
+			return false
+		}
+		aliasName, planPath, err = saveAskWorkflow(baseDir, name, steps, true)
+	}
+	if err != nil {
+		fmt.Println(ui.Error("Error: " + err.Error()))
+		return false
+	}
+	fmt.Println(ui.OK("Saved workflow: " + planPath))
+	fmt.Println(ui.Muted("Run it with: dm alias run " + aliasName))
+	return true
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"cli/internal/ui"
+)
+
+// askPlannedResult is the history result of a step --plan did not run; the
+// planner is told to assume it worked so it goes on with the next step.
+const askPlannedResult = "planned: not executed (plan-only mode); assume it succeeds"
+
+const askPlanOnlyContext = "- Plan-only mode: actions are NOT executed. Propose every step the task needs, one per decision, then return action=answer with a short summary of the plan."
+
+// recordPlannedStep reports a step that --plan stops before running and
+// adds it to the turn history.
+func recordPlannedStep(ctx askStepContext, step askJSONStep) (bool, int) {
+	step.Status = "planned"
+	ctx.out.AddStep(step)
+	*ctx.history = append(*ctx.history, askActionRecord{
+		Step: step.Step, Action: step.Action, Target: step.Target, Args: step.Args,
+		Result: askPlannedResult,
+	})
+	return true, 0
+}
+
+// printAskPlanSteps lists the steps --plan proposed, with their risk.
+func printAskPlanSteps(steps []askJSONStep) {
+	fmt.Println()
+	var planned []askJSONStep
+	for _, s := range steps {
+		if s.Status == "planned" {
+			planned = append(planned, s)
+		}
+	}
+	if len(planned) == 0 {
+		fmt.Println(ui.Muted("No steps proposed."))
+		return
+	}
+	fmt.Println(ui.Accent("Proposed plan (not executed)"))
+	for i, s := range planned {
+		risk := strings.ToLower(s.Risk)
+		label := ui.OK(strings.ToUpper(risk))
+		switch risk {
+		case "medium":
+			label = ui.Warn(strings.ToUpper(risk))
+		case "high":
+			label = ui.Error(strings.ToUpper(risk))
+		}
+		line := fmt.Sprintf("%2d. [%s] %s %s", i+1, label, strings.TrimPrefix(s.Action, "run_"), s.Target)
+		if s.Args != "" {
+			line += " " + s.Args
+		}
+		fmt.Println(line)
+		if s.Reason != "" {
+			fmt.Println(ui.Muted("    " + s.Reason))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cli/internal/agent"
+)
+
+func TestRecordPlannedStep(t *testing.T) {
+	out := &askCaptureWriter{askOutputWriter: newAskJSONWriter()}
+	var history []askActionRecord
+	ctx := askStepContext{out: out, history: &history, planOnly: true}
+
+	cont, code := recordPlannedStep(ctx, askJSONStep{
+		Step: 1, Action: "run_tool", Target: "clean", Args: "base=C:/tmp", Risk: "high", Status: "pending",
+	})
+	if !cont || code != 0 {
+		t.Fatalf("expected the planner loop to continue, got %v %d", cont, code)
+	}
+	if len(out.steps) != 1 || out.steps[0].Status != "planned" || out.steps[0].Risk != "high" {
+		t.Fatalf("unexpected steps %+v", out.steps)
+	}
+	if len(history) != 1 || history[0].Target != "clean" || history[0].Result != askPlannedResult {
+		t.Fatalf("unexpected history %+v", history)
+	}
+}
+
+// planOnlyConfirmer fails the test if a plan-only step asks for confirmation.
+type planOnlyConfirmer struct {
+	askOutputWriter
+	t *testing.T
+}
+
+func (c planOnlyConfirmer) ConfirmStep(step askJSONStep) bool {
+	c.t.Fatalf("plan-only step %s %s asked for confirmation", step.Action, step.Target)
+	return true
+}
+
+func TestPlanOnlyNeverRunsOrPrompts(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	base := t.TempDir()
+	empty := filepath.Join(base, "work", "empty")
+	if err := os.MkdirAll(empty, 0755); err != nil {
+		t.Fatal(err)
+	}
+	marker := filepath.Join(base, "ran")
+	pluginsDir := filepath.Join(base, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ntouch '" + marker + "'\n"
+	if err := os.WriteFile(filepath.Join(pluginsDir, "delete_cache.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &askCaptureWriter{askOutputWriter: planOnlyConfirmer{newAskJSONWriter(), t}}
+	var history []askActionRecord
+	ctx := askStepContext{
+		baseDir: base, out: out, history: &history, planOnly: true,
+		confirmTools: true, riskPolicy: riskPolicyStrict, step: 1,
+	}
+
+	cont, code := handleRunTool(ctx, agent.DecisionResult{
+		Action: "run_tool", Tool: "clean",
+		ToolArgs: map[string]string{"base": filepath.Join(base, "work"), "apply": "true"},
+	})
+	if !cont || code != 0 {
+		t.Fatalf("clean: expected the planner loop to continue, got %v %d", cont, code)
+	}
+	if _, err := os.Stat(empty); err != nil {
+		t.Fatalf("plan-only clean removed %s: %v", empty, err)
+	}
+
+	ctx.step = 2
+	cont, code = handleRunPlugin(ctx, agent.DecisionResult{Action: "run_plugin", Plugin: "delete_cache"})
+	if !cont || code != 0 {
+		t.Fatalf("plugin: expected the planner loop to continue, got %v %d", cont, code)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("plan-only plugin step ran the plugin")
+	}
+
+	if len(out.steps) != 2 || out.steps[0].Status != "planned" || out.steps[1].Status != "planned" {
+		t.Fatalf("unexpected steps %+v", out.steps)
+	}
+	if out.steps[0].Risk != "high" || out.steps[1].Risk != "high" {
+		t.Fatalf("expected both steps to be rated high risk, got %+v", out.steps)
+	}
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli/internal/agent"
+)
+
+func TestAskPlanRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := saveAskPlan(path, askPlan{}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	steps := []askPlanStep{
+		planStepFromDecision("find pdfs", agent.DecisionResult{
+			Action: "run_tool", Tool: "search", ToolArgs: map[string]string{"ext": "pdf"},
+		}),
+		planStepFromDecision("status", agent.DecisionResult{
+			Action: "run_plugin", Plugin: "g_status", Args: []string{"-Short"},
+		}),
+	}
+	if err := appendAskPlanSteps(path, steps); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	plan, err := loadAskPlan(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if plan.Version != askPlanVersion || len(plan.Steps) != 2 {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	d := plan.Steps[0].decision()
+	if d.Tool != "search" || d.ToolArgs["ext"] != "pdf" {
+		t.Fatalf("unexpected tool decision: %+v", d)
+	}
+	if got := plan.Steps[1].decision(); got.Plugin != "g_status" || len(got.Args) != 1 {
+		t.Fatalf("unexpected plugin decision: %+v", got)
+	}
+}
+
+func TestLoadAskPlanRejectsUnknownAction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := os.WriteFile(path, []byte(`{"version":1,"steps":[{"action":"answer"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadAskPlan(path); err == nil {
+		t.Fatal("expected error for unsupported action")
+	}
+}
+
+func TestSaveAskWorkflowCreatesPlanAndAlias(t *testing.T) {
+	baseDir := t.TempDir()
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	prevPathsResolver := askAliasProfilePathsResolver
+	askAliasProfilePathsResolver = func() []string { return nil }
+	defer func() { askAliasProfilePathsResolver = prevPathsResolver }()
+
+	steps := []askPlanStep{{Action: "run_tool", Tool: "recent"}}
+	name, planPath, err := saveAskWorkflow(baseDir, "Daily-Check", steps, false)
+	if err != nil {
+		t.Fatalf("save workflow: %v", err)
+	}
+	if name != "daily-check" {
+		t.Fatalf("expected normalized name, got %q", name)
+	}
+	if _, err := loadAskPlan(planPath); err != nil {
+		t.Fatalf("expected readable plan, got %v", err)
+	}
+	aliases, err := loadAskAliases(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(aliases["daily-check"], "--replay") {
+		t.Fatalf("expected replay alias, got %q", aliases["daily-check"])
+	}
+
+	aliases["deploy"] = "git push"
+	if err := saveAskAliases(baseDir, aliases); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := saveAskWorkflow(baseDir, "deploy", steps, false); !errors.Is(err, errAskAliasExists) {
+		t.Fatalf("expected an existing alias to be refused, got %v", err)
+	}
+	if saveAskWorkflowAndReport(bufio.NewReader(strings.NewReader("\n")), baseDir, "deploy", steps) {
+		t.Fatal("an empty answer must not replace the alias")
+	}
+	if aliases, _ := loadAskAliases(baseDir); aliases["deploy"] != "git push" {
+		t.Fatalf("alias was overwritten: %q", aliases["deploy"])
+	}
+	if !saveAskWorkflowAndReport(bufio.NewReader(strings.NewReader("y\n")), baseDir, "deploy", steps) {
+		t.Fatal("expected the alias to be replaced after confirming")
+	}
+	if aliases, _ := loadAskAliases(baseDir); !strings.Contains(aliases["deploy"], "--replay") {
+		t.Fatalf("expected replay alias after replace, got %q", aliases["deploy"])
+	}
+}
+
+func TestMigrateLegacyWorkflowsMovesPlansAndAliases(t *testing.T) {
+	baseDir := t.TempDir()
+	stateDir := t.TempDir()
+	t.Setenv("DM_STATE_DIR", stateDir)
+	prevPathsResolver := askAliasProfilePathsResolver
+	askAliasProfilePathsResolver = func() []string { return nil }
+	defer func() { askAliasProfilePathsResolver = prevPathsResolver }()
+
+	legacy := filepath.Join(legacyAskWorkflowDir(baseDir), "daily.json")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveAskPlan(legacy, askPlan{Steps: []askPlanStep{{Action: "run_tool", Tool: "recent"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveAskAliases(baseDir, map[string]string{"daily": "& 'dm' ask --replay '" + legacy + "'"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateLegacyWorkflows(baseDir); err != nil {
+		t.Fatal(err)
+	}
+	moved := filepath.Join(stateDir, "workflows", "daily.json")
+	if _, err := loadAskPlan(moved); err != nil {
+		t.Fatalf("expected plan in state dir, got %v", err)
+	}
+	if fileExists(legacy) {
+		t.Fatal("expected legacy plan to be moved")
+	}
+	aliases, err := loadAskAliases(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(aliases["daily"], moved) {
+		t.Fatalf("expected alias to point at %s, got %q", moved, aliases["daily"])
+	}
+}
@@ -0,0 +1,246 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/tools"
+)
+
+// askPorcelainWriter prints ask progress as stable, line-prefixed records
+// for GUI and editor wrappers (--porcelain). Every record is one line:
+// a kind, a slash and a JSON object. Replies to confirm-request records
+// are read from in, one line each.
+type askPorcelainWriter struct {
+	w         io.Writer
+	in        *bufio.Reader
+	confirmID int
+}
+
+type askPorcelainConfirm struct {
+	ID         int    `json:"id"`
+	Kind       string `json:"kind"`
+	Step       int    `json:"step,omitempty"`
+	Action     string `json:"action,omitempty"`
+	Target     string `json:"target,omitempty"`
+	Args       string `json:"args,omitempty"`
+	Risk       string `json:"risk,omitempty"`
+	RiskReason string `json:"risk_reason,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Question   string `json:"question,omitempty"`
+	Default    string `json:"default"`
+}
+
+func newAskPorcelainWriter(w io.Writer, in io.Reader) *askPorcelainWriter {
+	return &askPorcelainWriter{w: w, in: bufio.NewReader(in)}
+}
+
+func (w *askPorcelainWriter) record(kind string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		data = []byte("{}")
+	}
+	fmt.Fprintf(w.w, "%s/%s\n", kind, data)
+}
+
+func (w *askPorcelainWriter) ProviderInfo(provider, model string) {
+	w.record("provider", map[string]string{"provider": provider, "model": model})
+}
+
+func (w *askPorcelainWriter) StepInfo(step, maxSteps int, summary, reason, risk, riskReason string) {
+	w.record("step", map[string]any{
+		"step": step, "max_steps": maxSteps, "summary": summary, "reason": strings.TrimSpace(reason),
+		"risk": strings.ToLower(risk), "risk_reason": riskReason,
+	})
+}
+
+func (w *askPorcelainWriter) Answer(answer string) {
+	w.record("answer", map[string]string{"text": answer})
+}
+
+func (w *askPorcelainWriter) PartialAnswer(answer string) {
+	if strings.TrimSpace(answer) != "" {
+		w.record("partial", map[string]string{"text": answer})
+	}
+}
+
+func (w *askPorcelainWriter) StreamChunk(chunk string) {
+	w.record("chunk", map[string]string{"text": chunk})
+}
+
+func (w *askPorcelainWriter) CachedDecision(step int) {
+	w.record("cached", map[string]int{"step": step})
+}
+
+func (w *askPorcelainWriter) Alternatives(step int, alts []agent.DecisionAlternative) {
+	for _, a := range alts {
+		w.record("alternative", askJSONAlternative{Step: step, Action: a.Action, Target: a.Target, Reason: a.Reason})
+	}
+}
+
+func (w *askPorcelainWriter) Error(msg string) {
+	w.record("error", map[string]string{"message": msg})
+}
+
+func (w *askPorcelainWriter) ErrorWithAnswer(msg, answer string) {
+	w.record("error", map[string]string{"message": msg, "answer": strings.TrimSpace(answer)})
+}
+
+func (w *askPorcelainWriter) Canceled(answer string) {
+	w.record("canceled", map[string]string{"answer": strings.TrimSpace(answer)})
+}
+
+func (w *askPorcelainWriter) MaxStepsReached(answer string) {
+	w.record("stopped", map[string]string{"reason": "max_steps", "answer": strings.TrimSpace(answer)})
+}
+
+func (w *askPorcelainWriter) LoopDetected(answer string) {
+	w.record("stopped", map[string]string{"reason": "loop", "answer": strings.TrimSpace(answer)})
+}
+
+// AddStep reports the outcome of a step as a result record.
+func (w *askPorcelainWriter) AddStep(step askJSONStep) {
+	w.record("result", step)
+}
+
+// Done ends the records of one prompt.
+func (w *askPorcelainWriter) Done(code int, usage *askUsageReport) {
+	w.record("done", map[string]any{"exit_code": code, "usage": usage})
+}
+
+// confirm sends a confirm-request and waits for a y/yes or n/no line. An
+// empty line takes the default; end of input declines.
+func (w *askPorcelainWriter) confirm(req askPorcelainConfirm) bool {
+	w.confirmID++
+	req.ID = w.confirmID
+	w.record("confirm-request", req)
+	line, err := w.in.ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	case "":
+		return req.Default == "yes"
+	}
+	return false
+}
+
+// ConfirmStep asks the wrapper to approve a planned agent action. Only
+// low-risk actions default to yes.
+func (w *askPorcelainWriter) ConfirmStep(step askJSONStep) bool {
+	def := "no"
+	if strings.ToLower(step.Risk) == "low" {
+		def = "yes"
+	}
+	return w.confirm(askPorcelainConfirm{
+		Kind: "action", Step: step.Step, Action: step.Action, Target: step.Target, Args: step.Args,
+		Risk: step.Risk, RiskReason: step.RiskReason, Default: def,
+	})
+}
+
+// ConfirmPath asks the wrapper before a tool works on a very broad path.
+func (w *askPorcelainWriter) ConfirmPath(path string) bool {
+	return w.confirm(askPorcelainConfirm{Kind: "broad_path", Path: path, Default: "no"})
+}
+
+// Ask refuses to prompt for a missing tool argument: stdin carries
+// replies to confirm-requests only, so the tool fails instead.
+func (w *askPorcelainWriter) Ask(label, def string) (string, bool) {
+	return "", false
+}
+
+// Confirm asks the wrapper a yes/no question raised by a running tool.
+func (w *askPorcelainWriter) Confirm(question string) bool {
+	return w.confirm(askPorcelainConfirm{Kind: "tool", Question: question, Default: "no"})
+}
+
+// ReadPrompt returns the next non-empty line of input as a prompt.
+func (w *askPorcelainWriter) ReadPrompt() (string, bool) {
+	for {
+		line, err := w.in.ReadString('\n')
+		if prompt := strings.TrimSpace(line); prompt != "" {
+			return prompt, true
+		}
+		if err != nil {
+			return "", false
+		}
+	}
+}
+
+// askStepConfirmer is implemented by writers that take confirmations
+// themselves instead of prompting on the terminal.
+type askStepConfirmer interface {
+	ConfirmStep(step askJSONStep) bool
+}
+
+// confirmAskStep asks before a planned action runs: the configured
+// approver decides when there is one, then the output writer when it
+// handles confirmations, otherwise the user on the terminal.
+func confirmAskStep(out askOutputWriter, prompt string, step askJSONStep) bool {
+	if cfg := agent.Approver(); cfg.Enabled() {
+		req := newAskApprovalRequest("action")
+		req.Prompt, req.Step, req.Action, req.Target, req.Args = prompt, step.Step, step.Action, step.Target, step.Args
+		req.Reason, req.Risk, req.RiskReason = step.Reason, step.Risk, step.RiskReason
+		return askApprove(cfg, req)
+	}
+	if c, ok := out.(*askCaptureWriter); ok {
+		out = c.askOutputWriter
+	}
+	if c, ok := out.(askStepConfirmer); ok {
+		return c.ConfirmStep(step)
+	}
+	return confirmAgentAction(bufio.NewReader(os.Stdin), step.Risk, step.Guessed)
+}
+
+// startAskPorcelain sends everything written to stdout to stderr for the
+// rest of the process, so plugin and tool output cannot be mistaken for
+// records, and returns the writer for the real stdout.
+func startAskPorcelain() *askPorcelainWriter {
+	w := newAskPorcelainWriter(os.Stdout, os.Stdin)
+	os.Stdout = os.Stderr
+	tools.SetPathConfirmer(w.ConfirmPath)
+	tools.SetPrompter(w)
+	return w
+}
+
+// runAskPorcelain answers prompt, or each line read from stdin when prompt
+// is empty, ending the records of every prompt with a done record.
+func runAskPorcelain(base askSessionParams, prompt string) int {
+	w := base.porcelain
+	var sessionHistory []askActionRecord
+	var previousPrompts []string
+	code := 0
+	turn := func(prompt string) {
+		p := base
+		p.prompt = prompt
+		p.previousPrompts = previousPrompts
+		p.sessionHistory = sessionHistory
+		start := agent.UsageByModel()
+		var turnHistory []askActionRecord
+		code, turnHistory = runAskOnceWithSession(p)
+		w.Done(code, usageReport(usageSince(start)))
+		sessionHistory = appendSessionHistory(sessionHistory, turnHistory)
+		previousPrompts = append(previousPrompts, prompt)
+		if len(previousPrompts) > askPreviousPromptsMax {
+			previousPrompts = previousPrompts[len(previousPrompts)-askPreviousPromptsMax:]
+		}
+	}
+	if strings.TrimSpace(prompt) != "" {
+		turn(prompt)
+		return code
+	}
+	for {
+		next, ok := w.ReadPrompt()
+		if !ok {
+			return 0
+		}
+		turn(next)
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAskPorcelainWriterRecords(t *testing.T) {
+	var buf bytes.Buffer
+	w := newAskPorcelainWriter(&buf, strings.NewReader(""))
+	w.StepInfo(1, 5, "tool search base=.", "find files", "LOW", "read only")
+	w.AddStep(askJSONStep{Step: 1, Action: "run_tool", Target: "search", Status: "ok"})
+	w.Answer("line one\nline two")
+	w.Done(0, nil)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{
+		`step/{"max_steps":5,"reason":"find files","risk":"low","risk_reason":"read only","step":1,"summary":"tool search base=."}`,
+		`result/{"step":1,"action":"run_tool","target":"search","status":"ok"}`,
+		`answer/{"text":"line one\nline two"}`,
+		`done/{"exit_code":0,"usage":null}`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d records, got %q", len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("record %d: expected %s, got %s", i, want[i], lines[i])
+		}
+	}
+}
+
+func TestAskPorcelainConfirmStep(t *testing.T) {
+	var buf bytes.Buffer
+	w := newAskPorcelainWriter(&buf, strings.NewReader("\n\nno\nyes\n"))
+	if w.ConfirmStep(askJSONStep{Step: 1, Action: "run_tool", Risk: "medium"}) {
+		t.Fatal("expected empty reply to refuse a medium-risk step")
+	}
+	if w.ConfirmStep(askJSONStep{Step: 2, Action: "run_plugin", Risk: "high"}) {
+		t.Fatal("expected empty reply to refuse a high-risk step")
+	}
+	if w.ConfirmStep(askJSONStep{Step: 3, Risk: "low"}) {
+		t.Fatal("expected 'no' to refuse")
+	}
+	if !w.ConfirmPath("/") {
+		t.Fatal("expected 'yes' to confirm")
+	}
+	if w.ConfirmStep(askJSONStep{Step: 4, Risk: "low"}) {
+		t.Fatal("expected end of input to refuse")
+	}
+	if !strings.Contains(buf.String(), `confirm-request/{"id":2,"kind":"action","step":2,"action":"run_plugin","risk":"high","default":"no"}`) {
+		t.Fatalf("unexpected confirm-request records:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `confirm-request/{"id":4,"kind":"broad_path","path":"/","default":"no"}`) {
+		t.Fatalf("expected a broad_path confirm-request, got:\n%s", buf.String())
+	}
+}
+
+func TestAskPorcelainToolPrompts(t *testing.T) {
+	var buf bytes.Buffer
+	w := newAskPorcelainWriter(&buf, strings.NewReader("y\n\n"))
+	if _, ok := w.Ask("Replace from", ""); ok {
+		t.Fatal("expected missing arguments not to be asked")
+	}
+	if !w.Confirm("Apply these renames?") {
+		t.Fatal("expected 'y' to confirm")
+	}
+	if w.Confirm("Apply these renames?") {
+		t.Fatal("expected empty reply to refuse")
+	}
+	if !strings.Contains(buf.String(), `confirm-request/{"id":1,"kind":"tool","question":"Apply these renames?","default":"no"}`) {
+		t.Fatalf("expected a tool confirm-request, got:\n%s", buf.String())
+	}
+}
+
+func TestConfirmAskStepUnwrapsCaptureWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := newAskPorcelainWriter(&buf, strings.NewReader("y\n"))
+	out := &askCaptureWriter{askOutputWriter: w}
+	if !confirmAskStep(out, "", askJSONStep{Step: 1, Risk: "high"}) {
+		t.Fatal("expected the porcelain reply to confirm")
+	}
+	if !strings.HasPrefix(buf.String(), "confirm-request/") {
+		t.Fatalf("expected a confirm-request record, got %q", buf.String())
+	}
+}
+
+func TestAskPorcelainReadPrompt(t *testing.T) {
+	w := newAskPorcelainWriter(&bytes.Buffer{}, strings.NewReader("\n  list files \n\nlast"))
+	for _, want := range []string{"list files", "last"} {
+		got, ok := w.ReadPrompt()
+		if !ok || got != want {
+			t.Fatalf("expected %q, got %q (ok %v)", want, got, ok)
+		}
+	}
+	if _, ok := w.ReadPrompt(); ok {
+		t.Fatal("expected end of input")
+	}
+}
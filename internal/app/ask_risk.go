@@ -48,12 +48,19 @@ func shouldConfirmAction(confirmTools bool, riskPolicy, risk string) bool {
 	}
 }
 
-func confirmAgentAction(reader *bufio.Reader, risk string) bool {
+// confirmAgentAction asks before a step runs. High-risk steps and guessed
+// ones (an offline keyword rule) default to no.
+func confirmAgentAction(reader *bufio.Reader, risk string, guessed bool) bool {
 	if strings.ToLower(risk) == "high" {
 		fmt.Print(ui.Error("!") + " " + ui.Prompt("Confirm? [y/N] "))
 		confirm := strings.ToLower(strings.TrimSpace(readLine(reader)))
 		return confirm == "y" || confirm == "yes"
 	}
+	if guessed {
+		fmt.Print(ui.Prompt("Proceed? [y/N] "))
+		confirm := strings.ToLower(strings.TrimSpace(readLine(reader)))
+		return confirm == "y" || confirm == "yes"
+	}
 	fmt.Print(ui.Prompt("Proceed? [Y/n] "))
 	confirm := strings.ToLower(strings.TrimSpace(readLine(reader)))
 	return !(confirm == "n" || confirm == "no")
@@ -64,18 +71,54 @@ func assessDecisionRisk(decision agent.DecisionResult) (string, string) {
 		return tools.ToolRisk(decision.Tool, decision.ToolArgs)
 	}
 	if decision.Action == "run_plugin" {
-		name := strings.ToLower(strings.TrimSpace(decision.Plugin))
-		if strings.Contains(name, "reset") || strings.Contains(name, "delete") || strings.Contains(name, "drop") || strings.Contains(name, "rm") {
-			return "high", "plugin may perform destructive operations"
-		}
+		path := ""
 		if info, err := plugins.GetInfo(askRiskBaseDir, decision.Plugin); err == nil {
-			safety := plugins.ParseToolkitSafety(info.Path)
-			if safety != "" {
-				risk := plugins.ToolkitRiskLevel(safety)
-				return risk, safety
-			}
+			path = info.Path
 		}
-		return "medium", "external plugin execution"
+		return assessPluginRisk(decision.Plugin, path)
 	}
 	return "low", "response only"
 }
+
+// assessPluginRisk rates a plugin from its name and the Safety header of
+// the toolkit at path; an empty path skips the header lookup.
+func assessPluginRisk(name, path string) (string, string) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if strings.Contains(lower, "reset") || strings.Contains(lower, "delete") || strings.Contains(lower, "drop") || strings.Contains(lower, "rm") {
+		return "high", "plugin may perform destructive operations"
+	}
+	if path != "" {
+		if safety := plugins.ParseToolkitSafety(path); safety != "" {
+			return plugins.ToolkitRiskLevel(safety), safety
+		}
+	}
+	return "medium", "external plugin execution"
+}
+
+// readOnlyRefusal explains why a decision is not allowed in a --read-only
+// session, or returns "" when it only reads.
+func readOnlyRefusal(decision agent.DecisionResult) string {
+	switch decision.Action {
+	case "run_tool", "run_plugin":
+		risk, reason := assessDecisionRisk(decision)
+		if risk == "low" {
+			return ""
+		}
+		return fmt.Sprintf("read-only session: %s is rated %s risk (%s); use a read-only action or answer directly", plannedActionSummary(decision), risk, reason)
+	case "create_function":
+		return "read-only session: creating functions is not allowed"
+	default:
+		return ""
+	}
+}
+
+func decisionTarget(decision agent.DecisionResult) string {
+	switch decision.Action {
+	case "run_plugin":
+		return strings.TrimSpace(decision.Plugin)
+	case "run_tool":
+		return strings.TrimSpace(decision.Tool)
+	default:
+		return ""
+	}
+}
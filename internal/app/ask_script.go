@@ -0,0 +1,186 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"cli/internal/plugins"
+	"cli/internal/ui"
+)
+
+// askScript is a runnable code block found in an answer.
+type askScript struct {
+	lang string
+	ext  string
+	code string
+}
+
+var scriptPluginNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+func scriptExtForLang(lang string) string {
+	switch strings.ToLower(strings.TrimSpace(lang)) {
+	case "powershell", "pwsh", "ps1", "ps":
+		return ".ps1"
+	case "bash", "sh", "shell", "zsh":
+		return ".sh"
+	case "cmd", "bat", "batch":
+		if runtime.GOOS == "windows" {
+			return ".cmd"
+		}
+	}
+	return ""
+}
+
+// detectAnswerScript reports whether answer is mostly a single shell or
+// PowerShell code block, i.e. the code is at least as long as the prose
+// around it.
+func detectAnswerScript(answer string) (askScript, bool) {
+	var code, prose []string
+	lang := ""
+	blocks := 0
+	inBlock := false
+	for _, line := range strings.Split(answer, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if !inBlock {
+				blocks++
+				lang = strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			}
+			inBlock = !inBlock
+			continue
+		}
+		switch {
+		case inBlock:
+			code = append(code, line)
+		case trimmed != "":
+			prose = append(prose, trimmed)
+		}
+	}
+	if blocks != 1 || inBlock {
+		return askScript{}, false
+	}
+	body := strings.TrimSpace(strings.Join(code, "\n"))
+	ext := scriptExtForLang(lang)
+	if body == "" || ext == "" || len(body) < len(strings.Join(prose, " ")) {
+		return askScript{}, false
+	}
+	return askScript{lang: lang, ext: ext, code: body + "\n"}, true
+}
+
+// offerAnswerScript lets the user run a script answer now or keep it as a
+// standalone plugin file. It returns a history record when something was
+// done.
+func offerAnswerScript(baseDir string, script askScript) (askActionRecord, bool) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Println()
+	fmt.Print(ui.Prompt("Script detected: [r]un now, [s]ave as plugin, Enter to skip: "))
+	switch strings.ToLower(readLine(reader)) {
+	case "r", "run":
+		fmt.Print(ui.Error("!") + " " + ui.Prompt("Run this "+script.lang+" script? [y/N] "))
+		confirm := strings.ToLower(readLine(reader))
+		if confirm != "y" && confirm != "yes" {
+			fmt.Println(ui.Warn("Canceled."))
+			return askActionRecord{}, false
+		}
+		return runAnswerScript(script), true
+	case "s", "save":
+		fmt.Print(ui.Prompt("Plugin name: "))
+		name := readLine(reader)
+		path, err := saveAnswerScript(filepath.Join(baseDir, "plugins"), name, script)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return askActionRecord{}, false
+		}
+		fmt.Println(ui.OK("Saved: " + path))
+		fmt.Println(ui.Muted("Run it with: dm " + name))
+		return askActionRecord{Action: "save_script", Target: name, Result: "ok; plugin saved to " + path}, true
+	}
+	return askActionRecord{}, false
+}
+
+func maybeOfferAnswerScript(p askSessionParams, step int, answer string, history *[]askActionRecord) {
+	if p.jsonOut || p.planOnly || p.filter.readOnly || !ui.StdinIsTerminal() {
+		return
+	}
+	script, ok := detectAnswerScript(answer)
+	if !ok {
+		return
+	}
+	if rec, done := offerAnswerScript(p.baseDir, script); done {
+		rec.Step = step
+		*history = append(*history, rec)
+	}
+}
+
+// askCatalogChanged reports whether a turn added plugins, so an interactive
+// session should rebuild its catalog.
+func askCatalogChanged(records []askActionRecord) bool {
+	for _, r := range records {
+		if (r.Action == "create_function" || r.Action == "save_script") && strings.HasPrefix(r.Result, "ok") {
+			return true
+		}
+	}
+	return false
+}
+
+func runAnswerScript(script askScript) askActionRecord {
+	rec := askActionRecord{Action: "run_script", Target: script.lang}
+	tmp, err := os.CreateTemp("", "dm-answer-*"+script.ext)
+	if err != nil {
+		rec.Result = "error: " + err.Error()
+		fmt.Println("Error:", err)
+		return rec
+	}
+	path := tmp.Name()
+	defer func() { _ = os.Remove(path) }()
+	_, err = tmp.WriteString(script.code)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(path, 0700)
+	}
+	if err != nil {
+		rec.Result = "error: " + err.Error()
+		fmt.Println("Error:", err)
+		return rec
+	}
+	fmt.Println()
+	res := plugins.RunScriptFile(path)
+	if res.Err != nil {
+		rec.Result = "error: " + res.Err.Error()
+		fmt.Println(ui.Error("Error: " + res.Err.Error()))
+		return rec
+	}
+	rec.Result = "ok; " + truncateForHistory(strings.TrimSpace(res.Output), askHistoryMaxLen)
+	return rec
+}
+
+// saveAnswerScript writes script as plugins/<name><ext>. Existing plugins
+// are never overwritten.
+func saveAnswerScript(pluginsDir, name string, script askScript) (string, error) {
+	name = strings.TrimSpace(name)
+	if !scriptPluginNameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid plugin name %q (use letters, digits, _ and -)", name)
+	}
+	if info, err := plugins.GetInfo(filepath.Dir(pluginsDir), name); err == nil {
+		return "", fmt.Errorf("plugin %q already exists (%s)", name, info.Path)
+	}
+	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(pluginsDir, name+script.ext)
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	if err := os.WriteFile(path, []byte(script.code), 0755); err != nil {
+		return "", err
+	}
+	plugins.ResetCaches()
+	return path, nil
+}
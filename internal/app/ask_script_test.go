@@ -0,0 +1,67 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectAnswerScript(t *testing.T) {
+	answer := "Run this:\n\n```powershell\nGet-ChildItem -Recurse *.log |\n  Remove-Item -WhatIf\n```\n"
+	s, ok := detectAnswerScript(answer)
+	if !ok {
+		t.Fatal("expected a script to be detected")
+	}
+	if s.ext != ".ps1" || !strings.HasPrefix(s.code, "Get-ChildItem") || !strings.HasSuffix(s.code, "-WhatIf\n") {
+		t.Fatalf("unexpected script %+v", s)
+	}
+}
+
+func TestDetectAnswerScriptRejects(t *testing.T) {
+	cases := map[string]string{
+		"no block":      "Just use git status.",
+		"two blocks":    "```sh\nls\n```\nthen\n```sh\npwd\n```",
+		"unknown lang":  "```go\nfmt.Println(1)\n```",
+		"mostly prose":  "This is a long explanation of what the command does and why you might want it.\n```sh\nls\n```",
+		"unterminated":  "```bash\nls -la",
+		"empty content": "```bash\n```",
+	}
+	for name, answer := range cases {
+		if _, ok := detectAnswerScript(answer); ok {
+			t.Fatalf("%s: expected no script", name)
+		}
+	}
+}
+
+func TestSaveAnswerScript(t *testing.T) {
+	base := t.TempDir()
+	dir := filepath.Join(base, "plugins")
+	s := askScript{lang: "bash", ext: ".sh", code: "echo hi\n"}
+	path, err := saveAnswerScript(dir, "say_hi", s)
+	if err != nil {
+		t.Fatalf("saveAnswerScript: %v", err)
+	}
+	if path != filepath.Join(dir, "say_hi.sh") {
+		t.Fatalf("unexpected path %q", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "echo hi\n" {
+		t.Fatalf("unexpected content %q, %v", data, err)
+	}
+	if _, err := saveAnswerScript(dir, "say_hi", s); err == nil {
+		t.Fatal("expected an existing plugin to be kept")
+	}
+	if _, err := saveAnswerScript(dir, "../evil", s); err == nil {
+		t.Fatal("expected an invalid name to be rejected")
+	}
+}
+
+func TestAskCatalogChanged(t *testing.T) {
+	if askCatalogChanged([]askActionRecord{{Action: "run_tool", Result: "ok"}}) {
+		t.Fatal("tool runs should not change the catalog")
+	}
+	if !askCatalogChanged([]askActionRecord{{Action: "save_script", Result: "ok; plugin saved"}}) {
+		t.Fatal("saved scripts should change the catalog")
+	}
+}
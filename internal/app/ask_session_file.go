@@ -0,0 +1,162 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"cli/internal/platform"
+	"cli/internal/redact"
+	"cli/internal/ui"
+)
+
+const (
+	askSavedTurnsMax   = 50
+	askRecapTurns      = 3
+	askRecapAnswerSize = 300
+)
+
+// askSavedSession is an interactive ask session saved with --save, so
+// --resume can continue it later with the same context.
+type askSavedSession struct {
+	Name            string           `json:"name"`
+	Saved           time.Time        `json:"saved"`
+	Dir             string           `json:"dir,omitempty"`
+	PreviousPrompts []string         `json:"previous_prompts"`
+	History         []askSavedRecord `json:"history"`
+	Turns           []askSavedTurn   `json:"turns"`
+}
+
+type askSavedRecord struct {
+	Action string `json:"action"`
+	Target string `json:"target,omitempty"`
+	Args   string `json:"args,omitempty"`
+	Result string `json:"result,omitempty"`
+}
+
+type askSavedTurn struct {
+	Prompt string    `json:"prompt"`
+	Answer string    `json:"answer,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+func askSessionDir() string {
+	return platform.StatePath("sessions")
+}
+
+func askSessionPath(name string) (string, error) {
+	n, err := normalizeAskAliasName(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid session name: %w", err)
+	}
+	return filepath.Join(askSessionDir(), n+".json"), nil
+}
+
+func listAskSessions() []string {
+	entries, _ := os.ReadDir(askSessionDir())
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func loadAskSession(name string) (askSavedSession, error) {
+	path, err := askSessionPath(name)
+	if err != nil {
+		return askSavedSession{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if names := listAskSessions(); len(names) > 0 {
+				return askSavedSession{}, fmt.Errorf("no saved ask session %q (saved: %s)", name, strings.Join(names, ", "))
+			}
+			return askSavedSession{}, fmt.Errorf("no saved ask session %q", name)
+		}
+		return askSavedSession{}, err
+	}
+	var s askSavedSession
+	if err := json.Unmarshal(data, &s); err != nil {
+		return askSavedSession{}, fmt.Errorf("invalid session file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func saveAskSession(s askSavedSession) error {
+	path, err := askSessionPath(s.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return platform.WriteFileAtomic(path, append(data, '\n'), 0600)
+}
+
+// record stores the state after one turn: the context the planner sees
+// next time and the prompt with its answer for the transcript. Everything
+// user-supplied is redacted before it reaches the file.
+func (s *askSavedSession) record(prompt, answer string, previousPrompts []string, history []askActionRecord, now time.Time) {
+	s.Saved = now
+	s.Dir = askCurrentDir()
+	s.PreviousPrompts = s.PreviousPrompts[:0]
+	for _, p := range previousPrompts {
+		s.PreviousPrompts = append(s.PreviousPrompts, redact.String(p))
+	}
+	s.History = s.History[:0]
+	for _, h := range history {
+		s.History = append(s.History, askSavedRecord{Action: h.Action, Target: h.Target, Args: redact.String(h.Args), Result: redact.String(h.Result)})
+	}
+	s.Turns = append(s.Turns, askSavedTurn{Prompt: redact.String(prompt), Answer: redact.String(strings.TrimSpace(answer)), Time: now})
+	if len(s.Turns) > askSavedTurnsMax {
+		s.Turns = s.Turns[len(s.Turns)-askSavedTurnsMax:]
+	}
+}
+
+func sameAskDir(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func (s askSavedSession) records() []askActionRecord {
+	out := make([]askActionRecord, 0, len(s.History))
+	for _, h := range s.History {
+		out = append(out, askActionRecord{Action: h.Action, Target: h.Target, Args: h.Args, Result: h.Result})
+	}
+	return out
+}
+
+// printAskSessionRecap shows the last turns of a resumed session, and the
+// directory it was saved in when that is not where dm runs now.
+func printAskSessionRecap(s askSavedSession) {
+	fmt.Println(ui.Muted(fmt.Sprintf("Resumed session %s (%d turns, saved %s)", s.Name, len(s.Turns), s.Saved.Local().Format("2006-01-02 15:04"))))
+	if s.Dir != "" && !sameAskDir(s.Dir, askCurrentDir()) {
+		fmt.Println(ui.Warn("Saved in " + s.Dir + "; still working in " + askCurrentDir() + " (type 'cd " + s.Dir + "' to switch)"))
+	}
+	turns := s.Turns
+	if len(turns) > askRecapTurns {
+		turns = turns[len(turns)-askRecapTurns:]
+	}
+	for _, t := range turns {
+		fmt.Printf("%s%s\n", ui.Muted("ask> "), t.Prompt)
+		if t.Answer != "" {
+			fmt.Println(ui.Muted(truncateForHistory(t.Answer, askRecapAnswerSize)))
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAskSessionSaveAndResume(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	now := time.Date(2026, 10, 15, 18, 4, 0, 0, time.UTC)
+
+	s := askSavedSession{Name: "Disk-Cleanup"}
+	history := []askActionRecord{{Action: "run_tool", Target: "search", Args: "ext=log", Result: "ok: 3 files"}}
+	s.record("find logs", "There are 3 log files.", []string{"find logs"}, history, now)
+	if err := saveAskSession(s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	got, err := loadAskSession("disk-cleanup")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got.PreviousPrompts) != 1 || got.PreviousPrompts[0] != "find logs" {
+		t.Fatalf("unexpected prompts %v", got.PreviousPrompts)
+	}
+	if recs := got.records(); len(recs) != 1 || recs[0].Target != "search" || recs[0].Result != "ok: 3 files" {
+		t.Fatalf("unexpected history %+v", recs)
+	}
+	if len(got.Turns) != 1 || got.Turns[0].Answer != "There are 3 log files." || !got.Saved.Equal(now) {
+		t.Fatalf("unexpected turns %+v", got.Turns)
+	}
+}
+
+func TestAskSessionRedactsPrompts(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	s := askSavedSession{Name: "secret-work"}
+	history := []askActionRecord{{Action: "run_plugin", Target: "login", Args: "-Password=hunter22"}}
+	s.record("login with password=hunter22", "done", []string{"use token=abc12345"}, history, time.Now())
+	if err := saveAskSession(s); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := askSessionPath("secret-work")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter22") || strings.Contains(string(data), "abc12345") {
+		t.Fatalf("session file keeps secrets:\n%s", data)
+	}
+	if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Fatalf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestAskSessionRecordKeepsLastTurns(t *testing.T) {
+	var s askSavedSession
+	for i := 0; i < askSavedTurnsMax+5; i++ {
+		s.record("p", "a", nil, nil, time.Now())
+	}
+	if len(s.Turns) != askSavedTurnsMax {
+		t.Fatalf("expected %d turns, got %d", askSavedTurnsMax, len(s.Turns))
+	}
+}
+
+func TestLoadAskSessionMissingListsSaved(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	if err := saveAskSession(askSavedSession{Name: "monday"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := loadAskSession("tuesday")
+	if err == nil || !strings.Contains(err.Error(), "saved: monday") {
+		t.Fatalf("expected missing session error listing saved ones, got %v", err)
+	}
+	if _, err := askSessionPath("../etc"); err == nil {
+		t.Fatal("expected invalid name error")
+	}
+}
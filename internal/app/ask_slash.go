@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/internal/ui"
+)
+
+// splitAskSlashCommand separates "/cmd arg..." into a lowercased command and
+// its argument. Input that does not start with "/" is returned whole so bare
+// words like "help" still match while "help me with..." does not.
+func splitAskSlashCommand(input string) (string, string) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "/") {
+		return strings.ToLower(input), ""
+	}
+	cmd, arg, _ := strings.Cut(input, " ")
+	return strings.ToLower(cmd), strings.TrimSpace(arg)
+}
+
+// switchAskProvider re-resolves the session with a new provider; model and
+// base URL overrides are dropped since they belonged to the old provider.
+func switchAskProvider(opts agent.AskOptions, provider string) (agent.SessionProvider, error) {
+	p := strings.ToLower(strings.TrimSpace(provider))
+	switch p {
+	case "openai", "ollama", "auto":
+	default:
+		return agent.SessionProvider{}, fmt.Errorf("invalid provider %q (use openai|ollama|auto)", provider)
+	}
+	opts.Provider = p
+	opts.Model = ""
+	opts.BaseURL = ""
+	return agent.ResolveSessionProvider(opts)
+}
+
+func switchAskModel(opts agent.AskOptions, model string) (agent.SessionProvider, error) {
+	opts.Model = strings.TrimSpace(model)
+	return agent.ResolveSessionProvider(opts)
+}
+
+func formatAskSessionHistory(records []askActionRecord) []string {
+	lines := make([]string, 0, len(records))
+	for i, r := range records {
+		line := fmt.Sprintf("%d. %s %s", i+1, r.Action, r.Target)
+		if strings.TrimSpace(r.Args) != "" {
+			line += " " + r.Args
+		}
+		result := strings.Join(strings.Fields(r.Result), " ")
+		if result != "" {
+			line += " -> " + truncateForHistory(result, 80)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func printAskSessionHistory(prompts []string, records []askActionRecord) {
+	fmt.Println()
+	fmt.Println(ui.Accent("Session history"))
+	if len(prompts) == 0 && len(records) == 0 {
+		fmt.Println(ui.Muted("(empty)"))
+		return
+	}
+	for i, p := range prompts {
+		fmt.Printf("%s %s\n", ui.Muted(fmt.Sprintf("prompt %d:", i+1)), p)
+	}
+	for _, line := range formatAskSessionHistory(records) {
+		fmt.Println(ui.Muted("- ") + line)
+	}
+}
+
+func printAskAvailableActions(pluginCatalog, toolsCatalog string, filter askActionFilter) {
+	fmt.Println()
+	fmt.Println(ui.Accent("Tools"))
+	if strings.TrimSpace(toolsCatalog) == "" {
+		fmt.Println(ui.Muted("(none)"))
+	}
+	for _, line := range strings.Split(toolsCatalog, "\n") {
+		if name, _, ok := strings.Cut(strings.TrimPrefix(line, "- "), ":"); ok {
+			fmt.Println("- " + name)
+		}
+	}
+	n := countCatalogFunctions(strings.Split(pluginCatalog, "\n"))
+	fmt.Printf("%s %d\n", ui.Muted("Plugin functions:"), n)
+	if filter.active() {
+		fmt.Printf("%s %s\n", ui.Muted("Allowed:"), filter.String())
+	}
+}
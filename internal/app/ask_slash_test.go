@@ -0,0 +1,45 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"cli/internal/agent"
+)
+
+func TestSplitAskSlashCommand(t *testing.T) {
+	cases := []struct{ in, cmd, arg string }{
+		{"/model  llama3.1:8b ", "/model", "llama3.1:8b"},
+		{"/RISK strict", "/risk", "strict"},
+		{"/history", "/history", ""},
+		{"Help", "help", ""},
+		{"help me find pdf files", "help me find pdf files", ""},
+	}
+	for _, c := range cases {
+		cmd, arg := splitAskSlashCommand(c.in)
+		if cmd != c.cmd || arg != c.arg {
+			t.Fatalf("splitAskSlashCommand(%q) = %q, %q; want %q, %q", c.in, cmd, arg, c.cmd, c.arg)
+		}
+	}
+}
+
+func TestSwitchAskProviderRejectsUnknown(t *testing.T) {
+	_, err := switchAskProvider(agent.AskOptions{Provider: "openai"}, "claude")
+	if err == nil || !strings.Contains(err.Error(), "invalid provider") {
+		t.Fatalf("expected invalid provider error, got %v", err)
+	}
+}
+
+func TestFormatAskSessionHistory(t *testing.T) {
+	got := formatAskSessionHistory([]askActionRecord{
+		{Action: "run_tool", Target: "grep", Args: "pattern=todo", Result: "ok\nmain.go:3"},
+		{Action: "run_plugin", Target: "g_status", Result: "ok"},
+	})
+	want := []string{
+		"1. run_tool grep pattern=todo -> ok main.go:3",
+		"2. run_plugin g_status -> ok",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected history lines %q", got)
+	}
+}
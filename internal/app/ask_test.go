@@ -327,3 +327,78 @@ func TestIsKnownTool(t *testing.T) {
 		}
 	}
 }
+
+func TestApplyAskEnvDefaults(t *testing.T) {
+	t.Setenv(envAskProvider, "ollama")
+	t.Setenv(envAskModel, "llama3")
+	t.Setenv(envAskRiskPolicy, "strict")
+	t.Setenv(envAskJSON, "true")
+
+	provider, model, risk, jsonOut := "openai", "", riskPolicyNormal, false
+	changed := func(name string) bool { return name == "model" }
+	model = "gpt-4o"
+	err := applyAskEnvDefaults(changed, askEnvTargets{provider: &provider, model: &model, riskPolicy: &risk, jsonOut: &jsonOut})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider != "ollama" || risk != "strict" || !jsonOut {
+		t.Fatalf("env defaults not applied: provider=%q risk=%q json=%v", provider, risk, jsonOut)
+	}
+	if model != "gpt-4o" {
+		t.Fatalf("explicit flag should win, got model=%q", model)
+	}
+}
+
+func TestApplyAskEnvDefaults_InvalidJSON(t *testing.T) {
+	t.Setenv(envAskJSON, "maybe")
+	jsonOut := false
+	err := applyAskEnvDefaults(func(string) bool { return false }, askEnvTargets{jsonOut: &jsonOut})
+	if err == nil {
+		t.Fatal("expected error for invalid DM_ASK_JSON")
+	}
+}
+
+func TestFormatPullProgress(t *testing.T) {
+	got := formatPullProgress(agent.PullProgress{Status: "downloading", Total: 2048, Completed: 1024})
+	if got != "downloading 50% (1.0 KB/2.0 KB)" {
+		t.Fatalf("unexpected progress line: %q", got)
+	}
+	if got := formatPullProgress(agent.PullProgress{Status: "verifying"}); got != "verifying" {
+		t.Fatalf("unexpected status line: %q", got)
+	}
+}
+
+func TestAskTTYWriterPlainKeepsMarkdown(t *testing.T) {
+	answer := "## Title\n- **item**"
+	if got := (&askTTYWriter{plain: true}).render(answer); got != answer {
+		t.Fatalf("expected raw markdown with plain, got %q", got)
+	}
+	if got := (&askTTYWriter{}).render(answer); strings.Contains(got, "**") || strings.Contains(got, "##") {
+		t.Fatalf("expected rendered markdown, got %q", got)
+	}
+}
+
+func TestAskJSONWriterCollectsAlternatives(t *testing.T) {
+	w := newAskJSONWriter()
+	w.Alternatives(2, []agent.DecisionAlternative{
+		{Action: "run_plugin", Target: "g_search", Reason: "needs a repo"},
+		{Action: "answer"},
+	})
+	if len(w.result.Alternatives) != 2 {
+		t.Fatalf("expected 2 alternatives, got %+v", w.result.Alternatives)
+	}
+	if a := w.result.Alternatives[0]; a.Step != 2 || a.Target != "g_search" {
+		t.Fatalf("unexpected alternative %+v", a)
+	}
+	if got := formatDecisionAlternative(agent.DecisionAlternative{Action: "run_tool", Target: "grep", Reason: "faster"}); got != "run_tool grep - faster" {
+		t.Fatalf("unexpected format %q", got)
+	}
+}
+
+func TestAskPluginLockPaths(t *testing.T) {
+	dir := t.TempDir()
+	got := askPluginLockPaths([]string{"-Path", dir, "-Force", "not-a-real-path-xyz", ""})
+	if len(got) != 1 || got[0] != dir {
+		t.Fatalf("expected only the existing folder, got %v", got)
+	}
+}
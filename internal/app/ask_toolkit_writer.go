@@ -2,6 +2,7 @@ package app
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,11 +11,18 @@ import (
 	"strings"
 
 	"cli/internal/agent"
+	"cli/internal/assets"
 	"cli/internal/plugins"
 )
 
 var functionsIndexRe = regexp.MustCompile(`(?m)^#\s+FUNCTIONS\s*$`)
 
+// errSyntaxNotValidated means pwsh is not installed, so generated code
+// could not be syntax-checked; it is not a pass.
+var errSyntaxNotValidated = errors.New("pwsh not found; syntax not validated")
+
+var lookPwsh = func() (string, error) { return exec.LookPath("pwsh") }
+
 func listToolkitSummaries(baseDir string) []agent.ToolkitSummary {
 	fnFiles, err := plugins.ListFunctionFiles(baseDir)
 	if err != nil {
@@ -126,67 +134,25 @@ func createNewToolkit(pluginsDir, toolkitName, prefix, functionCode string) (str
 		}
 	}
 
-	upperName := strings.ToUpper(strings.ReplaceAll(toolkitName, "_", " "))
-	header := fmt.Sprintf(`# =============================================================================
-# %s TOOLKIT – Auto-generated toolkit (standalone)
-# Safety: Review generated functions before use.
-# Entry point: %s_*
-#
-# FUNCTIONS
-#   %s
-# =============================================================================
-
-Set-StrictMode -Version Latest
-$ErrorActionPreference = "Stop"
-
-# -----------------------------------------------------------------------------
-# Internal helpers
-# -----------------------------------------------------------------------------
-
-<#
-.SYNOPSIS
-Ensure a command is available in PATH.
-.PARAMETER Name
-Command name to validate.
-.EXAMPLE
-_assert_command_available -Name docker
-#>
-function _assert_command_available {
-    param([Parameter(Mandatory = $true)][string]$Name)
-    if (-not (Get-Command -Name $Name -ErrorAction SilentlyContinue)) {
-        throw "Required command '$Name' was not found in PATH."
-    }
-}
-
-<#
-.SYNOPSIS
-Ensure a filesystem path exists.
-.PARAMETER Path
-Path to validate.
-.EXAMPLE
-_assert_path_exists -Path C:\Data
-#>
-function _assert_path_exists {
-    param([Parameter(Mandatory = $true)][string]$Path)
-    if (-not (Test-Path -LiteralPath $Path)) {
-        throw "Required path '$Path' does not exist."
-    }
-}
-
-# -----------------------------------------------------------------------------
-# Public functions
-# -----------------------------------------------------------------------------
-
-`, upperName, prefix, fnName)
+	header, err := assets.RenderToolkitHeader(assets.ToolkitHeader{
+		Title:    strings.ToUpper(strings.ReplaceAll(toolkitName, "_", " ")),
+		Prefix:   prefix,
+		Function: fnName,
+	})
+	if err != nil {
+		return "", err
+	}
 
 	fullContent := header + strings.TrimSpace(functionCode) + "\n"
 	return filePath, os.WriteFile(filePath, []byte(fullContent), 0644)
 }
 
+// validatePowerShellSyntax parses code with pwsh. It returns
+// errSyntaxNotValidated when pwsh is missing.
 func validatePowerShellSyntax(code string) error {
-	pwsh, err := exec.LookPath("pwsh")
+	pwsh, err := lookPwsh()
 	if err != nil {
-		return nil
+		return errSyntaxNotValidated
 	}
 	cmd := exec.Command(pwsh, "-NoProfile", "-Command", "[scriptblock]::Create($input)")
 	cmd.Stdin = bytes.NewReader([]byte(code))
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"cli/internal/agent"
+	"cli/internal/ui"
+)
+
+type askUsageReport struct {
+	PromptTokens     int      `json:"prompt_tokens"`
+	CompletionTokens int      `json:"completion_tokens"`
+	CostUSD          *float64 `json:"cost_usd,omitempty"`
+}
+
+type askModelUsage struct {
+	Model string
+	agent.Usage
+	Cost   float64
+	Priced bool
+}
+
+// usageSince returns the tokens used per "provider/model" since the
+// snapshot before was taken with agent.UsageByModel.
+func usageSince(before map[string]agent.Usage) []askModelUsage {
+	var rows []askModelUsage
+	for key, u := range agent.UsageByModel() {
+		d := u.Sub(before[key])
+		if d.IsZero() {
+			continue
+		}
+		cost, ok := agent.EstimateCost(key, d)
+		rows = append(rows, askModelUsage{Model: key, Usage: d, Cost: cost, Priced: ok})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Model < rows[j].Model })
+	return rows
+}
+
+// totalUsage sums rows; the cost is only known when every model is priced.
+func totalUsage(rows []askModelUsage) (agent.Usage, float64, bool) {
+	var total agent.Usage
+	cost, priced := 0.0, true
+	for _, r := range rows {
+		total = total.Add(r.Usage)
+		cost += r.Cost
+		priced = priced && r.Priced
+	}
+	return total, cost, priced
+}
+
+func usageReport(rows []askModelUsage) *askUsageReport {
+	if len(rows) == 0 {
+		return nil
+	}
+	total, cost, priced := totalUsage(rows)
+	report := &askUsageReport{PromptTokens: total.PromptTokens, CompletionTokens: total.CompletionTokens}
+	if priced {
+		report.CostUSD = &cost
+	}
+	return report
+}
+
+func formatUsage(u agent.Usage, cost float64, priced bool) string {
+	s := fmt.Sprintf("%d in / %d out", u.PromptTokens, u.CompletionTokens)
+	if priced {
+		s += fmt.Sprintf(" (~$%.4f)", cost)
+	}
+	return s
+}
+
+// printAskUsage prints the tokens of the last ask and, in interactive mode,
+// the running session total.
+func printAskUsage(turnStart, sessionStart map[string]agent.Usage) {
+	turn := usageSince(turnStart)
+	if len(turn) == 0 {
+		return
+	}
+	u, cost, priced := totalUsage(turn)
+	line := "Tokens: " + formatUsage(u, cost, priced)
+	if sessionStart != nil {
+		su, scost, spriced := totalUsage(usageSince(sessionStart))
+		line += " | session: " + formatUsage(su, scost, spriced)
+	}
+	fmt.Println(ui.Muted(line))
+}
+
+func printAskSessionUsage(sessionStart map[string]agent.Usage) {
+	rows := usageSince(sessionStart)
+	if len(rows) == 0 {
+		fmt.Printf("%s %s\n", ui.Muted("Tokens:"), "none")
+		return
+	}
+	for _, r := range rows {
+		fmt.Printf("%s %s\n", ui.Muted("Tokens "+r.Model+":"), formatUsage(r.Usage, r.Cost, r.Priced))
+	}
+	if len(rows) > 1 {
+		u, cost, priced := totalUsage(rows)
+		fmt.Printf("%s %s\n", ui.Muted("Tokens total:"), formatUsage(u, cost, priced))
+	}
+}
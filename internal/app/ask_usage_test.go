@@ -0,0 +1,35 @@
+package app
+
+import (
+	"testing"
+
+	"cli/internal/agent"
+)
+
+func TestUsageReport(t *testing.T) {
+	rows := []askModelUsage{
+		{Model: "openai/gpt-4o-mini", Usage: agent.Usage{PromptTokens: 100, CompletionTokens: 20}, Cost: 0.01, Priced: true},
+		{Model: "ollama/llama3", Usage: agent.Usage{PromptTokens: 50, CompletionTokens: 5}, Priced: true},
+	}
+	r := usageReport(rows)
+	if r == nil || r.PromptTokens != 150 || r.CompletionTokens != 25 || r.CostUSD == nil || *r.CostUSD != 0.01 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	rows = append(rows, askModelUsage{Model: "openai/custom", Usage: agent.Usage{PromptTokens: 1}})
+	if r := usageReport(rows); r.CostUSD != nil {
+		t.Fatalf("cost should be unknown with an unpriced model, got %v", *r.CostUSD)
+	}
+	if usageReport(nil) != nil {
+		t.Fatal("expected no report without usage")
+	}
+}
+
+func TestFormatUsage(t *testing.T) {
+	u := agent.Usage{PromptTokens: 1200, CompletionTokens: 34}
+	if got := formatUsage(u, 0.00123, true); got != "1200 in / 34 out (~$0.0012)" {
+		t.Fatalf("unexpected format: %q", got)
+	}
+	if got := formatUsage(u, 0, false); got != "1200 in / 34 out" {
+		t.Fatalf("unexpected format: %q", got)
+	}
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+type agentCatalogReport struct {
+	BaseDir         string `json:"base_dir"`
+	Scope           string `json:"scope,omitempty"`
+	Filter          string `json:"filter,omitempty"`
+	RiskPolicy      string `json:"risk_policy"`
+	EnvContext      string `json:"env_context"`
+	ToolsCatalog    string `json:"tools_catalog"`
+	PluginCatalog   string `json:"plugin_catalog"`
+	PluginFunctions int    `json:"plugin_functions"`
+	SystemPrompt    string `json:"system_prompt,omitempty"`
+
+	PromptSources []string `json:"prompt_sources,omitempty"`
+	Prompt        string   `json:"prompt,omitempty"`
+	Omitted       int      `json:"omitted,omitempty"`
+}
+
+// buildAgentCatalogReport collects what the planner is sent; with a prompt
+// the catalogs are trimmed to budget as dm ask would for that request.
+func buildAgentCatalogReport(baseDir, scope string, filter askActionFilter, riskPolicy string, withSystem bool, prompt string, budget int) agentCatalogReport {
+	r := agentCatalogReport{
+		BaseDir:       baseDir,
+		Scope:         strings.TrimSpace(scope),
+		RiskPolicy:    riskPolicy,
+		EnvContext:    askPlannerEnvContext(filter),
+		ToolsCatalog:  buildToolsCatalog(filter),
+		PluginCatalog: buildPluginCatalogScoped(baseDir, scope, filter),
+		PromptSources: agent.PlannerPromptSources(),
+	}
+	if filter.active() {
+		r.Filter = filter.String()
+	}
+	if r.Prompt = strings.TrimSpace(prompt); r.Prompt != "" {
+		r.PluginCatalog, r.ToolsCatalog, r.Omitted = budgetCatalogs(r.Prompt, r.PluginCatalog, r.ToolsCatalog, budget)
+	}
+	r.PluginFunctions = countCatalogFunctions(strings.Split(r.PluginCatalog, "\n"))
+	if withSystem {
+		r.SystemPrompt = agent.DecisionSystemPrompt(r.PluginCatalog, r.ToolsCatalog)
+	}
+	return r
+}
+
+func riskPolicyDescription(policy string) string {
+	switch policy {
+	case riskPolicyStrict:
+		return "every action is confirmed"
+	case riskPolicyOff:
+		return "actions are confirmed only with --confirm-tools"
+	default:
+		return "high-risk actions are always confirmed; others follow --confirm-tools"
+	}
+}
+
+func printAgentCatalogReport(r agentCatalogReport) {
+	section := func(title, body string) {
+		fmt.Println()
+		fmt.Println(ui.Accent(title))
+		if strings.TrimSpace(body) == "" {
+			fmt.Println(ui.Muted("(none)"))
+			return
+		}
+		width := ui.TerminalWidth()
+		for _, line := range strings.Split(body, "\n") {
+			fmt.Println(ui.Wrap(line, width, "    "))
+		}
+	}
+	fmt.Printf("%s %s\n", ui.Muted("Base dir:"), r.BaseDir)
+	if r.Scope != "" {
+		fmt.Printf("%s %s\n", ui.Muted("Scope:"), r.Scope)
+	}
+	if r.Filter != "" {
+		fmt.Printf("%s %s\n", ui.Muted("Allowed:"), r.Filter)
+	}
+	fmt.Printf("%s %s %s\n", ui.Muted("Risk policy:"), r.RiskPolicy, ui.Muted("("+riskPolicyDescription(r.RiskPolicy)+")"))
+	for _, src := range r.PromptSources {
+		fmt.Printf("%s %s\n", ui.Muted("Custom prompt:"), src)
+	}
+	if r.Prompt != "" {
+		fmt.Printf("%s %s %s\n", ui.Muted("Prompt:"), r.Prompt, ui.Muted(fmt.Sprintf("(%d entries omitted by the catalog budget)", r.Omitted)))
+	}
+	section("Environment context", r.EnvContext)
+	section("Tools catalog", r.ToolsCatalog)
+	section(fmt.Sprintf("Plugin catalog (%d functions)", r.PluginFunctions), r.PluginCatalog)
+	if r.SystemPrompt != "" {
+		section("System prompt", r.SystemPrompt)
+	}
+}
+
+func newAgentCommand() *cobra.Command {
+	agentCmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Inspect what the ask agent sees",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	var scope, toolsFlag, pluginsFlag, riskPolicyFlag, prompt string
+	var readOnly, withSystem, asJSON bool
+	var budget int
+	catalogCmd := &cobra.Command{
+		Use:     "catalog",
+		Short:   "Print the plugin and tool catalogs, env context and risk policy sent to the planner",
+		Example: "dm agent catalog\ndm agent catalog --scope git --read-only\ndm agent catalog --system\ndm agent catalog --prompt \"restart the web container\"",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyAskEnvDefaults(cmd.Flags().Changed, askEnvTargets{riskPolicy: &riskPolicyFlag}); err != nil {
+				return err
+			}
+			riskPolicy, err := normalizeRiskPolicy(riskPolicyFlag)
+			if err != nil {
+				return err
+			}
+			filter, err := parseAskFilter(toolsFlag, pluginsFlag)
+			if err != nil {
+				return err
+			}
+			filter.readOnly = readOnly
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			r := buildAgentCatalogReport(rt.BaseDir, scope, filter, riskPolicy, withSystem, prompt, budget)
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(r)
+			}
+			printAgentCatalogReport(r)
+			return nil
+		},
+	}
+	catalogCmd.Flags().StringVarP(&scope, "scope", "s", "", "limit plugin catalog to a toolkit prefix or domain, as with dm ask --scope")
+	catalogCmd.Flags().StringVar(&toolsFlag, "tools", "", "only list these tools (comma-separated, or \"none\")")
+	catalogCmd.Flags().StringVar(&pluginsFlag, "plugins", "", "only list plugins matching these prefixes or globs")
+	catalogCmd.Flags().BoolVar(&readOnly, "read-only", false, "show the catalog a --read-only session gets")
+	catalogCmd.Flags().StringVar(&riskPolicyFlag, "risk-policy", riskPolicyNormal, "risk policy: strict|normal|off")
+	catalogCmd.Flags().BoolVar(&withSystem, "system", false, "also print the full planner system prompt")
+	catalogCmd.Flags().StringVar(&prompt, "prompt", "", "trim the catalogs for this request, as dm ask does under --catalog-budget")
+	catalogCmd.Flags().IntVar(&budget, "catalog-budget", catalogTokenBudget, "token budget used with --prompt (0 = no limit)")
+	catalogCmd.Flags().BoolVar(&asJSON, "json", false, "render the catalog report as JSON")
+	agentCmd.AddCommand(catalogCmd)
+	agentCmd.AddCommand(newAgentModelsCommand())
+	return agentCmd
+}
+
+func newAgentModelsCommand() *cobra.Command {
+	var provider, model string
+	var asJSON bool
+	cmd := &cobra.Command{
+		Use:     "models",
+		Short:   "List the models available from the configured providers",
+		Example: "dm agent models\ndm agent models --provider ollama\ndm agent models --model fast",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyAskEnvDefaults(cmd.Flags().Changed, askEnvTargets{provider: &provider, model: &model}); err != nil {
+				return err
+			}
+			providers, err := agentModelsProviders(provider)
+			if err != nil {
+				return err
+			}
+			lists := agent.ListModels(providers, agent.AskOptions{Model: model})
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(lists); err != nil {
+					return err
+				}
+			} else {
+				printAgentModels(lists)
+			}
+			for _, l := range lists {
+				if l.Error == "" {
+					return nil
+				}
+			}
+			return exitCodeError{code: 1}
+		},
+	}
+	cmd.Flags().StringVar(&provider, "provider", "", "only query this provider: ollama|openai|auto (default: all)")
+	cmd.Flags().StringVar(&model, "model", "", "model or alias to highlight instead of the configured one")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "render the model lists as JSON")
+	return cmd
+}
+
+// agentModelsProviders maps --provider to the providers to query; auto
+// means the fallback chain.
+func agentModelsProviders(provider string) ([]string, error) {
+	switch p := strings.ToLower(strings.TrimSpace(provider)); p {
+	case "", "all":
+		return nil, nil
+	case "ollama", "openai":
+		return []string{p}, nil
+	case "auto":
+		return agent.FallbackChain()
+	default:
+		return nil, fmt.Errorf("invalid provider %q (use ollama|openai|auto)", provider)
+	}
+}
+
+func printAgentModels(lists []agent.ProviderModels) {
+	for i, l := range lists {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s %s\n", ui.Accent(l.Provider), ui.Muted(l.BaseURL))
+		if l.Error != "" {
+			fmt.Println(ui.Error("  Error: " + l.Error))
+			continue
+		}
+		if len(l.Models) == 0 {
+			fmt.Println(ui.Muted("  (no models)"))
+		}
+		for _, m := range l.Models {
+			if l.IsSelected(m) {
+				fmt.Printf("%s %s\n", ui.OK("* "+m), ui.Muted("(selected)"))
+			} else {
+				fmt.Println("  " + m)
+			}
+		}
+		if l.Selected != "" && !l.HasSelected() {
+			fmt.Println(ui.Warn("  selected model " + l.Selected + " is not available"))
+		}
+	}
+}
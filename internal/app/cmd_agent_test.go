@@ -0,0 +1,57 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildAgentCatalogReport(t *testing.T) {
+	base := t.TempDir()
+	pluginsDir := filepath.Join(base, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	toolkit := "# Safety: Read-only\nfunction g_status {\n  git status\n}\n\nfunction d_ps {\n  docker ps\n}\n"
+	if err := os.WriteFile(filepath.Join(pluginsDir, "Dev_Toolkit.ps1"), []byte(toolkit), 0644); err != nil {
+		t.Fatal(err)
+	}
+	filter, err := parseAskFilter("grep", "g_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter.readOnly = true
+
+	r := buildAgentCatalogReport(base, "", filter, riskPolicyStrict, true, "", 0)
+	if !strings.Contains(r.PluginCatalog, "g_status") || strings.Contains(r.PluginCatalog, "d_ps") {
+		t.Fatalf("plugin catalog should follow the filter:\n%s", r.PluginCatalog)
+	}
+	if !strings.HasPrefix(r.ToolsCatalog, "- grep:") {
+		t.Fatalf("unexpected tools catalog:\n%s", r.ToolsCatalog)
+	}
+	if !strings.Contains(r.EnvContext, "read-only") {
+		t.Fatalf("env context should mention read-only mode:\n%s", r.EnvContext)
+	}
+	if r.Filter == "" || r.RiskPolicy != riskPolicyStrict {
+		t.Fatalf("unexpected report header %+v", r)
+	}
+	if !strings.Contains(r.SystemPrompt, r.ToolsCatalog) {
+		t.Fatal("system prompt should embed the tools catalog")
+	}
+	if buildAgentCatalogReport(base, "", filter, riskPolicyNormal, false, "", 0).SystemPrompt != "" {
+		t.Fatal("system prompt should only be included on request")
+	}
+}
+
+func TestAgentModelsProviders(t *testing.T) {
+	if got, err := agentModelsProviders(""); err != nil || got != nil {
+		t.Fatalf("empty provider should query all, got %v %v", got, err)
+	}
+	if got, err := agentModelsProviders("Ollama"); err != nil || len(got) != 1 || got[0] != "ollama" {
+		t.Fatalf("unexpected %v %v", got, err)
+	}
+	if _, err := agentModelsProviders("claude"); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cli/internal/filesearch"
+	"cli/internal/platform"
+	"cli/internal/plugins"
+	"cli/tools"
+
+	"github.com/spf13/cobra"
+)
+
+var benchWorkloadOrder = []string{"search", "recent", "plugins", "agent"}
+
+type benchWorkload struct {
+	Name string
+	Run  func() (int, error)
+}
+
+type benchResult struct {
+	Name  string  `json:"name"`
+	Runs  int     `json:"runs"`
+	Items int     `json:"items"`
+	MinMS float64 `json:"min_ms"`
+	P50MS float64 `json:"p50_ms"`
+	P90MS float64 `json:"p90_ms"`
+	P99MS float64 `json:"p99_ms"`
+	MaxMS float64 `json:"max_ms"`
+	Err   string  `json:"error,omitempty"`
+}
+
+// benchWorkloads builds the selected workloads; an empty selection runs all.
+// Plugin and agent workloads start cold: caches are dropped before each run.
+func benchWorkloads(baseDir, path, name string, selected []string) ([]benchWorkload, error) {
+	all := map[string]benchWorkload{
+		"search": {Name: "search", Run: func() (int, error) {
+			res, err := filesearch.Find(filesearch.Options{BasePath: path, NamePart: name})
+			return len(res), err
+		}},
+		"recent": {Name: "recent", Run: func() (int, error) {
+			return tools.CountRecent(path)
+		}},
+		"plugins": {Name: "plugins", Run: func() (int, error) {
+			plugins.ResetCaches()
+			items, err := plugins.ListEntries(baseDir, true)
+			return len(items), err
+		}},
+		"agent": {Name: "agent", Run: func() (int, error) {
+			plugins.ResetCaches()
+			catalog := buildPluginCatalogScoped(baseDir, "", askActionFilter{}) + "\n" + buildToolsCatalog(askActionFilter{})
+			return strings.Count(catalog, "\n") + 1, nil
+		}},
+	}
+	if len(selected) == 0 {
+		selected = benchWorkloadOrder
+	}
+	out := make([]benchWorkload, 0, len(selected))
+	for _, s := range selected {
+		w, ok := all[strings.ToLower(strings.TrimSpace(s))]
+		if !ok {
+			return nil, fmt.Errorf("unknown workload %q (use %s)", s, strings.Join(benchWorkloadOrder, "|"))
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+func runBenchWorkload(w benchWorkload, runs int) benchResult {
+	r := benchResult{Name: w.Name}
+	var times []time.Duration
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		items, err := w.Run()
+		elapsed := time.Since(start)
+		if err != nil {
+			r.Err = err.Error()
+			break
+		}
+		r.Items = items
+		times = append(times, elapsed)
+	}
+	r.Runs = len(times)
+	if len(times) == 0 {
+		return r
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	r.MinMS = durationMS(times[0])
+	r.P50MS = durationMS(percentile(times, 50))
+	r.P90MS = durationMS(percentile(times, 90))
+	r.P99MS = durationMS(percentile(times, 99))
+	r.MaxMS = durationMS(times[len(times)-1])
+	return r
+}
+
+// percentile uses the nearest-rank method on sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+func durationMS(d time.Duration) float64 {
+	return math.Round(float64(d.Microseconds())/10) / 100
+}
+
+func printBenchResults(results []benchResult) {
+	fmt.Printf("%-8s %5s %8s %10s %10s %10s %10s %10s\n", "Workload", "Runs", "Items", "min", "p50", "p90", "p99", "max")
+	for _, r := range results {
+		if r.Err != "" && r.Runs == 0 {
+			fmt.Printf("%-8s %s\n", r.Name, "error: "+r.Err)
+			continue
+		}
+		fmt.Printf("%-8s %5d %8d %8.1fms %8.1fms %8.1fms %8.1fms %8.1fms\n", r.Name, r.Runs, r.Items, r.MinMS, r.P50MS, r.P90MS, r.P99MS, r.MaxMS)
+	}
+}
+
+func newBenchCommand() *cobra.Command {
+	var (
+		path   string
+		name   string
+		runs   int
+		asJSON bool
+	)
+	benchCmd := &cobra.Command{
+		Use:   "bench [search|recent|plugins|agent...]",
+		Short: "Time dm workloads on your machine and print percentiles",
+		Long: "Runs the search, recent, plugin listing and agent catalog workloads several times and prints " +
+			"min/p50/p90/p99/max timings. search and recent scan --path (default: current directory).",
+		Example: "dm bench\ndm bench search --path \\\\nas\\share --runs 10\ndm bench plugins agent --json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			if runs <= 0 {
+				return fmt.Errorf("--runs must be positive")
+			}
+			base := normalizeBenchPath(path)
+			workloads, err := benchWorkloads(rt.BaseDir, base, name, args)
+			if err != nil {
+				return err
+			}
+			results := make([]benchResult, 0, len(workloads))
+			for _, w := range workloads {
+				if !asJSON {
+					fmt.Fprintf(os.Stderr, "Running %s (%d runs)...\n", w.Name, runs)
+				}
+				results = append(results, runBenchWorkload(w, runs))
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(results)
+			}
+			fmt.Println("Path:", base)
+			printBenchResults(results)
+			return nil
+		},
+	}
+	benchCmd.Flags().StringVar(&path, "path", "", "directory for search and recent (default: current directory)")
+	benchCmd.Flags().StringVar(&name, "name", "", "name filter for the search workload")
+	benchCmd.Flags().IntVar(&runs, "runs", 5, "number of runs per workload")
+	benchCmd.Flags().BoolVar(&asJSON, "json", false, "render results as JSON")
+	return benchCmd
+}
+
+func normalizeBenchPath(path string) string {
+	path = strings.TrimSpace(path)
+	if path != "" {
+		return filepath.Clean(platform.NormalizeUNC(path))
+	}
+	if wd, err := os.Getwd(); err == nil {
+		return wd
+	}
+	return "."
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPercentileNearestRank(t *testing.T) {
+	var d []time.Duration
+	for i := 1; i <= 10; i++ {
+		d = append(d, time.Duration(i)*time.Millisecond)
+	}
+	if got := percentile(d, 50); got != 5*time.Millisecond {
+		t.Fatalf("p50 = %v", got)
+	}
+	if got := percentile(d, 90); got != 9*time.Millisecond {
+		t.Fatalf("p90 = %v", got)
+	}
+	if got := percentile(d, 99); got != 10*time.Millisecond {
+		t.Fatalf("p99 = %v", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Fatalf("expected 0 for empty input, got %v", got)
+	}
+}
+
+func TestRunBenchWorkloadStopsOnError(t *testing.T) {
+	calls := 0
+	r := runBenchWorkload(benchWorkload{Name: "x", Run: func() (int, error) {
+		calls++
+		if calls == 3 {
+			return 0, errors.New("boom")
+		}
+		return 7, nil
+	}}, 5)
+	if r.Runs != 2 || r.Items != 7 || r.Err != "boom" {
+		t.Fatalf("unexpected result %+v", r)
+	}
+	if r.MinMS > r.P50MS || r.P50MS > r.MaxMS {
+		t.Fatalf("percentiles out of order: %+v", r)
+	}
+}
+
+func TestBenchWorkloadsSelection(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "note.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	all, err := benchWorkloads(dir, dir, "", nil)
+	if err != nil || len(all) != len(benchWorkloadOrder) {
+		t.Fatalf("expected all workloads, got %d (err %v)", len(all), err)
+	}
+	ws, err := benchWorkloads(dir, dir, "note", []string{"Search"})
+	if err != nil || len(ws) != 1 {
+		t.Fatalf("unexpected selection %v (err %v)", ws, err)
+	}
+	if n, err := ws[0].Run(); err != nil || n != 1 {
+		t.Fatalf("expected one search hit, got %d (err %v)", n, err)
+	}
+	if _, err := benchWorkloads(dir, dir, "", []string{"nope"}); err == nil {
+		t.Fatal("expected unknown workload error")
+	}
+}
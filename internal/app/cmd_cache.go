@@ -0,0 +1,205 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cli/internal/agent"
+	"cli/internal/cache"
+	"cli/internal/filesearch"
+	"cli/internal/platform"
+	"cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+type diskCacheStatus struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Files    int       `json:"files"`
+	Bytes    int64     `json:"bytes"`
+	Newest   time.Time `json:"newest,omitempty"`
+	Oldest   time.Time `json:"oldest,omitempty"`
+	HasFiles bool      `json:"-"`
+}
+
+type memoryCacheStatus struct {
+	Name      string `json:"name"`
+	Entries   int    `json:"entries"`
+	Limit     int    `json:"limit"`
+	Hits      int64  `json:"hits"`
+	Misses    int64  `json:"misses"`
+	Evictions int64  `json:"evictions"`
+}
+
+type cacheStatusReport struct {
+	Disk     []diskCacheStatus   `json:"disk"`
+	InMemory []memoryCacheStatus `json:"in_memory"`
+}
+
+// diskCacheDirs lists the on-disk caches that `dm cache clear` may delete.
+func diskCacheDirs() map[string]string {
+	return map[string]string{
+		"cache":     platform.StatePath("cache"),
+		"llm-debug": agent.LLMDebugDir(),
+	}
+}
+
+var diskCacheOrder = []string{"cache", "llm-debug"}
+
+func scanDiskCache(name, dir string) diskCacheStatus {
+	st := diskCacheStatus{Name: name, Path: dir}
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		st.Files++
+		st.Bytes += info.Size()
+		mod := info.ModTime()
+		if !st.HasFiles || mod.After(st.Newest) {
+			st.Newest = mod
+		}
+		if !st.HasFiles || mod.Before(st.Oldest) {
+			st.Oldest = mod
+		}
+		st.HasFiles = true
+		return nil
+	})
+	return st
+}
+
+func collectCacheStatus() cacheStatusReport {
+	var r cacheStatusReport
+	dirs := diskCacheDirs()
+	for _, name := range diskCacheOrder {
+		r.Disk = append(r.Disk, scanDiskCache(name, dirs[name]))
+	}
+	for _, s := range cache.Stats() {
+		r.InMemory = append(r.InMemory, memoryCacheStatus(s))
+	}
+	return r
+}
+
+func printCacheStatus(r cacheStatusReport, now time.Time) {
+	ui.PrintSection("Disk caches")
+	for _, d := range r.Disk {
+		line := fmt.Sprintf("%-10s %4d files %9s", d.Name, d.Files, filesearch.FormatSize(d.Bytes))
+		if d.HasFiles {
+			line += ui.Muted(fmt.Sprintf("  newest %s ago, oldest %s ago", formatAge(now.Sub(d.Newest)), formatAge(now.Sub(d.Oldest))))
+		}
+		fmt.Println(line)
+		fmt.Println(ui.Muted("           " + d.Path))
+	}
+	ui.PrintSection("In-memory caches (this process)")
+	for _, m := range r.InMemory {
+		line := fmt.Sprintf("%-15s %4d/%d entries  %s", m.Name, m.Entries, m.Limit, formatHitRate(m.Hits, m.Misses))
+		if m.Evictions > 0 {
+			line += ui.Muted(fmt.Sprintf("  %d evicted", m.Evictions))
+		}
+		fmt.Println(line)
+	}
+}
+
+func formatHitRate(hits, misses int64) string {
+	total := hits + misses
+	if total == 0 {
+		return ui.Muted("no lookups")
+	}
+	return fmt.Sprintf("%d/%d hits (%.0f%%)", hits, total, float64(hits)*100/float64(total))
+}
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// clearCaches removes the selected disk caches and returns what was
+// cleared. The in-memory caches belong to a running dm process, so a
+// separate `dm cache clear` cannot reach them; they go when it exits.
+func clearCaches(kind string) ([]string, error) {
+	var cleared []string
+	dirs := diskCacheDirs()
+	switch kind {
+	case "all", "cache", "llm-debug":
+	case "memory", "plugins", "paging":
+		return nil, fmt.Errorf("the %s cache is in-memory and only lives inside a running dm process; it is dropped when that process exits", kind)
+	default:
+		return nil, fmt.Errorf("unknown cache type %q (use all|cache|llm-debug)", kind)
+	}
+	for _, name := range diskCacheOrder {
+		if kind != "all" && kind != name {
+			continue
+		}
+		if err := os.RemoveAll(dirs[name]); err != nil {
+			return cleared, err
+		}
+		if name == "cache" {
+			askDiskCache.reset()
+		}
+		cleared = append(cleared, name)
+	}
+	return cleared, nil
+}
+
+func newCacheCommand() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and clear dm caches",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	var statusJSON bool
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show cache sizes, ages and hit rates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := collectCacheStatus()
+			if statusJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(r)
+			}
+			printCacheStatus(r, time.Now())
+			return nil
+		},
+	}
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "render cache status as JSON")
+	cacheCmd.AddCommand(statusCmd)
+
+	var clearType string
+	clearCmd := &cobra.Command{
+		Use:     "clear",
+		Short:   "Delete cached data on disk",
+		Example: "dm cache clear\ndm cache clear --type llm-debug",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cleared, err := clearCaches(strings.ToLower(strings.TrimSpace(clearType)))
+			if len(cleared) > 0 {
+				fmt.Println("Cleared:", strings.Join(cleared, ", "))
+			}
+			return err
+		},
+	}
+	clearCmd.Flags().StringVar(&clearType, "type", "all", "disk cache to clear: all|cache|llm-debug")
+	cacheCmd.AddCommand(clearCmd)
+	return cacheCmd
+}
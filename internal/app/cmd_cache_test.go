@@ -0,0 +1,71 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanDiskCacheCountsFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.json"), []byte("12345"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "b.json"), []byte("123"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	st := scanDiskCache("cache", dir)
+	if st.Files != 2 || st.Bytes != 8 || !st.HasFiles {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+	missing := scanDiskCache("cache", filepath.Join(dir, "missing"))
+	if missing.Files != 0 || missing.HasFiles {
+		t.Fatalf("expected empty status for missing dir, got %+v", missing)
+	}
+}
+
+func TestClearCachesRemovesDiskCache(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	dir := diskCacheDirs()["cache"]
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "x"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cleared, err := clearCaches("cache")
+	if err != nil {
+		t.Fatalf("clearCaches: %v", err)
+	}
+	if len(cleared) != 1 || cleared[0] != "cache" {
+		t.Fatalf("unexpected cleared list: %v", cleared)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected cache dir removed, got %v", err)
+	}
+	if _, err := clearCaches("bogus"); err == nil {
+		t.Fatal("expected error for unknown cache type")
+	}
+	if _, err := clearCaches("paging"); err == nil || !strings.Contains(err.Error(), "in-memory") {
+		t.Fatalf("expected in-memory caches to be refused, got %v", err)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	cases := map[time.Duration]string{
+		30 * time.Second: "30s",
+		5 * time.Minute:  "5m",
+		3 * time.Hour:    "3h",
+		72 * time.Hour:   "3d",
+	}
+	for d, want := range cases {
+		if got := formatAge(d); got != want {
+			t.Fatalf("formatAge(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -2,11 +2,17 @@ package app
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"cli/internal/agent"
+	"cli/internal/aliastarget"
 	"cli/internal/doctor"
+	"cli/internal/history"
+	"cli/internal/platform"
 	"cli/internal/plugins"
+	"cli/internal/ui"
 	"cli/tools"
 
 	"github.com/spf13/cobra"
@@ -27,27 +33,41 @@ func addCobraSubcommands(root *cobra.Command) {
 	})
 	openCmd := &cobra.Command{
 		Use:   "open",
-		Short: "Open profile files in Notepad",
-		Args:  cobra.NoArgs,
+		Short: "Open dm files and folders",
+		Example: "dm open ps_profile\n" +
+			"dm open config\n" +
+			"dm open plugin word_export_pdf",
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
 		},
 	}
 	openCmd.AddCommand(&cobra.Command{
-		Use:   "ps_profile",
-		Short: "Open PowerShell $PROFILE in Notepad",
-		Args:  cobra.NoArgs,
+		Use:     "ps_profile",
+		Aliases: []string{"profile"},
+		Short:   "Open PowerShell $PROFILE in the editor",
+		Args:    cobra.NoArgs,
 		ValidArgs: []string{
 			"ps_profile",
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return openUserPowerShellProfileInNotepad()
+			return openUserPowerShellProfileInEditor()
 		},
 	})
+	addOpenTargetCommands(openCmd)
 	root.AddCommand(openCmd)
 	root.AddCommand(newPluginCommand())
 	root.AddCommand(newToolsCommand())
 	root.AddCommand(newAliasCommand())
+	root.AddCommand(newProfileCommand())
+	root.AddCommand(newCopyCommand())
+	root.AddCommand(newCacheCommand())
+	root.AddCommand(newSecretCommand())
+	root.AddCommand(newBenchCommand())
+	root.AddCommand(newStatsCommand())
+	root.AddCommand(newJumpCommand())
+	root.AddCommand(newAgentCommand())
+	root.AddCommand(newToolkitCommand())
 	var doctorJSON bool
 	doctorCmd := &cobra.Command{
 		Use:   "doctor",
@@ -85,6 +105,27 @@ func addCobraSubcommands(root *cobra.Command) {
 	var askFiles []string
 	var askScope string
 	var askAsPowerShell bool
+	var askRecord string
+	var askReplay string
+	var askPlanOnly bool
+	var askAutoPull bool
+	var askAllowProtected bool
+	var askRetryFix bool
+	var askTools string
+	var askPlugins string
+	var askReadOnly bool
+	var askPlain bool
+	var askOut string
+	var askCopy bool
+	var askOutSteps bool
+	var askExplain bool
+	var askCache bool
+	var askCacheTTL time.Duration
+	var askCatalogBudget int
+	var askPorcelain bool
+	var askOffline bool
+	var askSave string
+	var askResume string
 	askCmd := &cobra.Command{
 		Use:   "ask <prompt...>",
 		Short: "Ask AI (openai|ollama|auto)",
@@ -92,6 +133,14 @@ func addCobraSubcommands(root *cobra.Command) {
 			"With --provider auto, dm tries Ollama first and falls back to OpenAI.",
 		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyAskEnvDefaults(cmd.Flags().Changed, askEnvTargets{
+				provider: &askProvider, model: &askModel, riskPolicy: &askRiskPolicy, jsonOut: &askJSON,
+			}); err != nil {
+				return err
+			}
+			if askPorcelain {
+				askJSON = false
+			}
 			if askAsPowerShell {
 				if len(args) == 0 {
 					return fmt.Errorf("--as-powershell (-a) requires a command")
@@ -107,6 +156,17 @@ func addCobraSubcommands(root *cobra.Command) {
 				Provider: askProvider,
 				Model:    askModel,
 				BaseURL:  askBaseURL,
+				Explain:  askExplain,
+			}
+			if askCatalogBudget < 0 {
+				return fmt.Errorf("--catalog-budget must not be negative")
+			}
+			var cacheTTL time.Duration
+			if askCache {
+				if askCacheTTL <= 0 {
+					return fmt.Errorf("--cache-ttl must be positive")
+				}
+				cacheTTL = askCacheTTL
 			}
 			confirmTools := askConfirmTools
 			if askNoConfirmTools {
@@ -124,6 +184,39 @@ func addCobraSubcommands(root *cobra.Command) {
 			if err != nil {
 				return err
 			}
+			tools.SetAllowProtected(askAllowProtected)
+			filter, err := parseAskFilter(askTools, askPlugins)
+			if err != nil {
+				return err
+			}
+			filter.readOnly = askReadOnly
+			var porcelain *askPorcelainWriter
+			if askPorcelain {
+				porcelain = startAskPorcelain()
+			}
+			if agent.Approver().Enabled() {
+				tools.SetPathConfirmer(askApprovePath)
+			}
+			if askReplay != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("--replay does not take a prompt")
+				}
+				code := runAskReplay(rt.BaseDir, askReplay, confirmTools, riskPolicy, askJSON, porcelain)
+				if code != 0 {
+					return exitCodeError{code: code}
+				}
+				return nil
+			}
+			if askRecord != "" {
+				if err := saveAskPlan(askRecord, askPlan{}); err != nil {
+					return fmt.Errorf("cannot create plan file: %w", err)
+				}
+			}
+			if !askOffline {
+				if err := ensureAskOllamaModel(askOpts, askAutoPull, askJSON || askPorcelain); err != nil {
+					return err
+				}
+			}
 			var fileCtx string
 			if len(askFiles) > 0 {
 				fc, fcErr := buildFileContext(askFiles)
@@ -139,7 +232,9 @@ func addCobraSubcommands(root *cobra.Command) {
 				code, _ := runAskOnceWithSession(askSessionParams{
 					baseDir: rt.BaseDir, prompt: strings.Join(args, " "), opts: askOpts,
 					confirmTools: confirmTools, riskPolicy: riskPolicy, responseMode: responseMode, jsonOut: true,
-					fileContext: fileCtx, scope: askScope,
+					fileContext: fileCtx, scope: askScope, filter: filter, recordPath: askRecord,
+					answerOut: askOut, copyAnswer: askCopy, outSteps: askOutSteps, cacheTTL: cacheTTL,
+					catalogBudget: askCatalogBudget, offline: askOffline, planOnly: askPlanOnly,
 				})
 				if code != 0 {
 					return exitCodeError{code: code}
@@ -150,7 +245,44 @@ func addCobraSubcommands(root *cobra.Command) {
 			if len(args) > 0 {
 				initialPrompt = strings.Join(args, " ")
 			}
-			code := runAskInteractiveWithRisk(rt.BaseDir, askOpts, confirmTools, riskPolicy, responseMode, initialPrompt, fileCtx, askScope)
+			sessionName := askSave
+			if sessionName != "" {
+				if _, err := askSessionPath(sessionName); err != nil {
+					return err
+				}
+			}
+			var resumed *askSavedSession
+			if askResume != "" {
+				s, err := loadAskSession(askResume)
+				if err != nil {
+					return err
+				}
+				resumed = &s
+				if sessionName == "" {
+					sessionName = askResume
+				}
+			}
+			if porcelain != nil {
+				code := runAskPorcelain(askSessionParams{
+					baseDir: rt.BaseDir, opts: askOpts,
+					confirmTools: confirmTools, riskPolicy: riskPolicy, responseMode: responseMode, jsonOut: true,
+					porcelain: porcelain, fileContext: fileCtx, scope: askScope, filter: filter, recordPath: askRecord,
+					answerOut: askOut, copyAnswer: askCopy, outSteps: askOutSteps, cacheTTL: cacheTTL,
+					catalogBudget: askCatalogBudget, offline: askOffline, planOnly: askPlanOnly,
+				}, initialPrompt)
+				if code != 0 {
+					return exitCodeError{code: code}
+				}
+				return nil
+			}
+			code := runAskInteractiveWithRisk(askSessionParams{
+				baseDir: rt.BaseDir, opts: askOpts,
+				confirmTools: confirmTools, riskPolicy: riskPolicy, responseMode: responseMode,
+				fileContext: fileCtx, scope: askScope, filter: filter, recordPath: askRecord, retryFix: askRetryFix,
+				plain: askPlain, answerOut: askOut, copyAnswer: askCopy, outSteps: askOutSteps, cacheTTL: cacheTTL,
+				catalogBudget: askCatalogBudget, offline: askOffline, saveSession: sessionName, resumed: resumed,
+				planOnly: askPlanOnly,
+			}, initialPrompt)
 			if code != 0 {
 				return exitCodeError{code: code}
 			}
@@ -166,10 +298,47 @@ func addCobraSubcommands(root *cobra.Command) {
 	askCmd.Flags().StringVar(&askRiskPolicy, "risk-policy", riskPolicyNormal, "risk policy: strict|normal|off")
 	askCmd.Flags().StringVar(&askResponseMode, "response-mode", responseModeRawFirst, "response mode: raw-first|llm-first")
 	askCmd.Flags().BoolVar(&askJSON, "json", false, "print structured JSON output (non-interactive only)")
+	askCmd.Flags().BoolVar(&askOffline, "offline", false, "map simple requests to tools and plugins with keyword rules instead of calling a provider")
+	askCmd.Flags().BoolVar(&askPorcelain, "porcelain", false, "print progress as line-prefixed records for GUI wrappers and read confirmations and prompts from stdin")
 	askCmd.Flags().StringArrayVarP(&askFiles, "file", "f", nil, "attach file as context (repeatable)")
 	askCmd.Flags().StringVarP(&askScope, "scope", "s", "", "limit plugin catalog to a toolkit prefix or domain (e.g. stibs, m365, docker)")
+	askCmd.Flags().StringVar(&askTools, "tools", "", "only let the agent use these tools (comma-separated, or \"none\")")
+	askCmd.Flags().StringVar(&askPlugins, "plugins", "", "only let the agent use plugins matching these prefixes or globs (e.g. \"git_*,docker_*\", or \"none\")")
+	askCmd.Flags().BoolVar(&askReadOnly, "read-only", false, "only offer and run actions rated low risk; refuse writes, renames and deletes")
 	askCmd.Flags().BoolVarP(&askAsPowerShell, "as-powershell", "a", false, "run prompt as a direct PowerShell command (bypass AI)")
+	askCmd.Flags().StringVar(&askRecord, "record", "", "save approved agent steps to a plan file")
+	askCmd.Flags().StringVar(&askReplay, "replay", "", "re-execute steps from a plan file without calling the LLM")
+	askCmd.Flags().BoolVar(&askPlanOnly, "plan", false, "plan the steps but never run plugins, tools or new functions; print them with their risk")
+	askCmd.Flags().StringVar(&askSave, "save", "", "save the interactive session (prompts, results and answers) under this name after every turn")
+	askCmd.Flags().StringVar(&askResume, "resume", "", "continue a session saved with --save (and keep saving to it)")
+	askCmd.Flags().BoolVar(&askAutoPull, "auto-pull", false, "pull the Ollama model automatically when it is not installed")
+	askCmd.Flags().BoolVar(&askPlain, "plain", false, "print answers as raw text instead of rendering markdown")
+	askCmd.Flags().StringVar(&askOut, "out", "", "write the final answer to this file (e.g. answer.md)")
+	askCmd.Flags().BoolVar(&askCopy, "copy", false, "copy the final answer to the clipboard")
+	askCmd.Flags().BoolVar(&askOutSteps, "out-steps", false, "include the step log with --out/--copy")
+	askCmd.Flags().BoolVar(&askExplain, "explain", false, "show the alternatives the planner considered at each step")
+	askCmd.Flags().BoolVar(&askCache, "cache", false, "reuse planner decisions for identical requests, also across invocations (stored on disk)")
+	askCmd.Flags().DurationVar(&askCacheTTL, "cache-ttl", askDecisionCacheTTL, "how long --cache keeps a decision")
+	askCmd.Flags().IntVar(&askCatalogBudget, "catalog-budget", catalogTokenBudget, "approximate token limit for the plugin and tool catalogs; less relevant entries are left out (0 = no limit)")
+	askCmd.Flags().BoolVar(&askRetryFix, "retry-fix", false, "after a failed plugin run, offer one retry with arguments corrected by the model")
+	askCmd.Flags().BoolVar(&askAllowProtected, "allow-protected", false, "let tools modify protected paths (roots, home, system and dm.json \"protected\" dirs)")
 	askCmd.MarkFlagsMutuallyExclusive("as-powershell", "json")
+	askCmd.MarkFlagsMutuallyExclusive("porcelain", "json")
+	askCmd.MarkFlagsMutuallyExclusive("porcelain", "as-powershell")
+	askCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	askCmd.MarkFlagsMutuallyExclusive("plan", "replay")
+	askCmd.MarkFlagsMutuallyExclusive("plan", "as-powershell")
+	askCmd.MarkFlagsMutuallyExclusive("as-powershell", "replay")
+	askCmd.MarkFlagsMutuallyExclusive("read-only", "as-powershell")
+	askCmd.MarkFlagsMutuallyExclusive("read-only", "replay")
+	askCmd.MarkFlagsMutuallyExclusive("save", "json")
+	askCmd.MarkFlagsMutuallyExclusive("resume", "json")
+	askCmd.MarkFlagsMutuallyExclusive("save", "replay")
+	askCmd.MarkFlagsMutuallyExclusive("resume", "replay")
+	askCmd.MarkFlagsMutuallyExclusive("save", "as-powershell")
+	askCmd.MarkFlagsMutuallyExclusive("save", "porcelain")
+	askCmd.MarkFlagsMutuallyExclusive("resume", "porcelain")
+	askCmd.MarkFlagsMutuallyExclusive("resume", "as-powershell")
 	root.AddCommand(askCmd)
 }
 
@@ -184,9 +353,10 @@ func newAliasCommand() *cobra.Command {
 		},
 	}
 
-	aliasCmd.AddCommand(&cobra.Command{
+	var listAll bool
+	lsCmd := &cobra.Command{
 		Use:   "ls",
-		Short: "List aliases",
+		Short: "List aliases grouped by category",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			rt, err := loadRuntime()
@@ -201,12 +371,13 @@ func newAliasCommand() *cobra.Command {
 				fmt.Println("No aliases configured.")
 				return nil
 			}
-			for _, k := range sortedAliasNames(aliases) {
-				fmt.Printf("%s -> %s\n", k, aliases[k])
-			}
+			broken := aliastarget.Check(aliases, aliastarget.StatTimeout, os.Stat)
+			printAliasList(aliases, broken, listAll)
 			return nil
 		},
-	})
+	}
+	lsCmd.Flags().BoolVar(&listAll, "all", false, "list every alias instead of folding long categories")
+	aliasCmd.AddCommand(lsCmd)
 
 	aliasCmd.AddCommand(&cobra.Command{
 		Use:   "add <name> <command...>",
@@ -276,23 +447,27 @@ func newAliasCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			name, err := normalizeAskAliasName(args[0])
-			if err != nil {
-				return err
-			}
 			aliases, err := loadAskAliases(rt.BaseDir)
 			if err != nil {
 				return err
 			}
-			baseCommand, ok := aliases[name]
+			name, candidates, ok := resolveAliasName(aliases, args[0])
 			if !ok {
-				return fmt.Errorf("alias not found: %s", name)
+				if len(candidates) > 0 {
+					return fmt.Errorf("no alias named %s; did you mean: %s", args[0], strings.Join(candidates, ", "))
+				}
+				return fmt.Errorf("alias not found: %s", args[0])
 			}
-			fullCommand := baseCommand
-			if len(args) > 1 {
-				fullCommand += " " + strings.Join(args[1:], " ")
+			if name != strings.ToLower(strings.TrimSpace(args[0])) {
+				fmt.Println(ui.Muted("-> " + name))
+			}
+			fullCommand, err := expandAliasCommand(name, aliases[name], args[1:])
+			if err != nil {
+				return err
 			}
+			started := time.Now()
 			code := runAskPowerShellBuiltin(fullCommand)
+			history.AddTimed(history.KindAlias, name, code, time.Since(started))
 			if code != 0 {
 				return exitCodeError{code: code}
 			}
@@ -300,6 +475,37 @@ func newAliasCommand() *cobra.Command {
 		},
 	})
 
+	aliasCmd.AddCommand(&cobra.Command{
+		Use:   "suggest [partial]",
+		Short: "Rank aliases matching a partial name by frecency",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			aliases, err := loadAskAliases(rt.BaseDir)
+			if err != nil {
+				return err
+			}
+			partial := ""
+			if len(args) == 1 {
+				partial = args[0]
+			}
+			runs := history.Load()
+			matches := rankAliasMatches(aliases, partial, runs, time.Now())
+			if len(matches) == 0 {
+				fmt.Println("No matching aliases.")
+				return nil
+			}
+			for _, name := range matches {
+				rec := runs[history.Key(history.KindAlias, name)]
+				fmt.Printf("%s %s %s\n", ui.PadRight(name, 16), ui.Muted(fmt.Sprintf("(%d runs)", rec.Count)), aliases[name])
+			}
+			return nil
+		},
+	})
+
 	aliasCmd.AddCommand(&cobra.Command{
 		Use:   "sync",
 		Short: "Sync aliases to $PROFILE",
@@ -313,10 +519,15 @@ func newAliasCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			profilePaths := askAliasProfilePathsResolver()
+			release, err := platform.AcquirePathLock("alias sync", profilePaths...)
+			if err != nil {
+				return err
+			}
+			defer release()
 			if err := syncAskAliasesToProfile(aliases); err != nil {
 				return err
 			}
-			profilePaths := askAliasProfilePathsResolver()
 			if len(profilePaths) == 0 {
 				fmt.Println("Aliases synced. $PROFILE path is not available on this system.")
 				return nil
@@ -346,13 +557,18 @@ func newPluginCommand() *cobra.Command {
 	}
 
 	pluginCmd := &cobra.Command{
-		Use:   "plugins",
-		Short: "Manage plugins",
-		Long:  "List and execute scripts/functions from the plugins directory.",
+		Use:     "plugins",
+		Aliases: []string{"plugin"},
+		Short:   "Manage plugins",
+		Long:    "List and execute scripts/functions from the plugins directory.",
 		Example: "dm plugins list\n" +
 			"dm plugins list --functions\n" +
+			"dm plugins list --functions --sources\n" +
+			"dm plugins list --all --filter git --json\n" +
 			"dm plugins info restart_backend\n" +
 			"dm plugins menu\n" +
+			"dm plugins lint\n" +
+			"dm plugins describe my_function\n" +
 			"dm plugins run paint",
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -361,18 +577,41 @@ func newPluginCommand() *cobra.Command {
 	}
 
 	var listFunctions bool
+	var listAll bool
+	var listSources bool
+	var listJSON bool
+	var listFilter string
+	var listSort string
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List available plugins",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			out := []string{"list", "--sort", listSort}
 			if listFunctions {
-				return runPluginArgs("list", "--functions")
+				out = append(out, "--functions")
+			}
+			if listAll {
+				out = append(out, "--all")
+			}
+			if listSources {
+				out = append(out, "--sources")
+			}
+			if listJSON {
+				out = append(out, "--json")
 			}
-			return runPluginArgs("list")
+			if listFilter != "" {
+				out = append(out, "--filter", listFilter)
+			}
+			return runPluginArgs(out...)
 		},
 	}
 	listCmd.Flags().BoolVarP(&listFunctions, "functions", "f", false, "include discovered PowerShell functions")
+	listCmd.Flags().BoolVarP(&listAll, "all", "a", false, "list scripts and functions together")
+	listCmd.Flags().BoolVarP(&listSources, "sources", "s", false, "show the defining file and any shadowed duplicates")
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "render the list as JSON")
+	listCmd.Flags().StringVar(&listFilter, "filter", "", "only names containing this substring (case-insensitive)")
+	listCmd.Flags().StringVar(&listSort, "sort", "name", "sort by name|kind|path")
 	pluginCmd.AddCommand(listCmd)
 	pluginCmd.AddCommand(&cobra.Command{
 		Use:               "info <name>",
@@ -383,6 +622,75 @@ func newPluginCommand() *cobra.Command {
 			return runPluginArgs("info", args[0])
 		},
 	})
+	var sourceOpen bool
+	sourceCmd := &cobra.Command{
+		Use:               "source <name>",
+		Short:             "Print a function's code with line numbers, or open it in the editor at that line",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePluginEntryNames(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			if code := runPluginSource(rt.BaseDir, args[0], sourceOpen); code != 0 {
+				return exitCodeError{code: code}
+			}
+			return nil
+		},
+	}
+	sourceCmd.Flags().BoolVar(&sourceOpen, "open", false, "open the source file in the editor at the function")
+	pluginCmd.AddCommand(sourceCmd)
+	var lintFile string
+	var lintJSON bool
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check function synopses and parameter docs the agent relies on",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			if code := runPluginLint(rt.BaseDir, lintFile, lintJSON); code != 0 {
+				return exitCodeError{code: code}
+			}
+			return nil
+		},
+	}
+	lintCmd.Flags().StringVar(&lintFile, "file", "", "only report issues in this toolkit file")
+	lintCmd.Flags().BoolVar(&lintJSON, "json", false, "render issues as JSON")
+	pluginCmd.AddCommand(lintCmd)
+
+	var describeProvider, describeModel string
+	var describeForce, describeYes bool
+	describeCmd := &cobra.Command{
+		Use:               "describe <name>",
+		Short:             "Draft SYNOPSIS/PARAMETER/EXAMPLE help for a plugin with the agent",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePluginEntryNames(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyAskEnvDefaults(cmd.Flags().Changed, askEnvTargets{
+				provider: &describeProvider, model: &describeModel,
+			}); err != nil {
+				return err
+			}
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			opts := agent.AskOptions{Provider: describeProvider, Model: describeModel}
+			if code := runPluginDescribe(rt.BaseDir, args[0], opts, describeForce, describeYes); code != 0 {
+				return exitCodeError{code: code}
+			}
+			return nil
+		},
+	}
+	describeCmd.Flags().StringVar(&describeProvider, "provider", "openai", "LLM provider: openai|ollama|auto")
+	describeCmd.Flags().StringVar(&describeModel, "model", "", "model override")
+	describeCmd.Flags().BoolVar(&describeForce, "force", false, "replace an existing help block")
+	describeCmd.Flags().BoolVarP(&describeYes, "yes", "y", false, "insert without asking for confirmation")
+	pluginCmd.AddCommand(describeCmd)
 	pluginCmd.AddCommand(&cobra.Command{
 		Use:   "menu",
 		Short: "Open interactive plugin menu",
@@ -391,16 +699,25 @@ func newPluginCommand() *cobra.Command {
 			return runPluginArgs("menu")
 		},
 	})
-	pluginCmd.AddCommand(&cobra.Command{
-		Use:               "run <name> [args...]",
-		Short:             "Run a plugin",
+	var runSandbox bool
+	runCmd := &cobra.Command{
+		Use:   "run <name> [args...]",
+		Short: "Run a plugin",
+		Long: "Run a plugin. With --sandbox it runs in a Docker container without network, with the plugins mounted read-only and /work an empty scratch directory " +
+			"(set \"sandbox\" \"mount_workdir\" in dm.json to mount the current directory read-only at /work instead).",
 		Args:              cobra.MinimumNArgs(1),
 		ValidArgsFunction: completePluginEntryNames(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			out := append([]string{"run"}, args...)
-			return runPluginArgs(out...)
+			out := []string{"run"}
+			if runSandbox {
+				out = append(out, "--sandbox")
+			}
+			return runPluginArgs(append(out, args...)...)
 		},
-	})
+	}
+	runCmd.Flags().BoolVar(&runSandbox, "sandbox", false, "run inside a Docker container (no network, read-only plugins)")
+	runCmd.Flags().SetInterspersed(false)
+	pluginCmd.AddCommand(runCmd)
 
 	return pluginCmd
 }
@@ -431,6 +748,7 @@ func completePluginEntryNames() func(*cobra.Command, []string, string) ([]string
 }
 
 func newToolsCommand() *cobra.Command {
+	var allowProtected bool
 	toolsCmd := &cobra.Command{
 		Use:     "tools [tool]",
 		Aliases: []string{"tool"},
@@ -443,6 +761,7 @@ func newToolsCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			tools.SetAllowProtected(allowProtected)
 			var code int
 			if len(args) == 0 {
 				code = tools.RunMenu(rt.BaseDir)
@@ -456,19 +775,21 @@ func newToolsCommand() *cobra.Command {
 		},
 	}
 
-	addToolSubcommand := func(use, short, long, example, canonical string, aliases ...string) {
+	for _, td := range tools.Descriptors() {
+		canonical := td.Name
 		toolsCmd.AddCommand(&cobra.Command{
-			Use:     use,
-			Aliases: aliases,
-			Short:   short,
-			Long:    long,
-			Example: example,
+			Use:     td.Name,
+			Aliases: td.Aliases,
+			Short:   td.Short,
+			Long:    td.Help,
+			Example: td.Example,
 			Args:    cobra.NoArgs,
 			RunE: func(cmd *cobra.Command, args []string) error {
 				rt, err := loadRuntime()
 				if err != nil {
 					return err
 				}
+				tools.SetAllowProtected(allowProtected)
 				code := tools.RunByName(rt.BaseDir, canonical)
 				if code != 0 {
 					return exitCodeError{code: code}
@@ -477,48 +798,7 @@ func newToolsCommand() *cobra.Command {
 			},
 		})
 	}
-
-	addToolSubcommand(
-		"search",
-		"Search files by name/extension",
-		"Asks for base path, optional name fragment, extension and sort mode (name/date/size).",
-		"dm tools search\ndm -t s",
-		"search",
-		"s",
-	)
-	addToolSubcommand(
-		"rename",
-		"Batch rename files with preview",
-		"Asks for base path, filter and replace rules, then shows a preview before applying changes.",
-		"dm tools rename",
-		"rename",
-		"r",
-	)
-	addToolSubcommand(
-		"recent",
-		"Show recent files",
-		"Asks for base path and limit, then lists most recently modified files.",
-		"dm tools recent",
-		"recent",
-		"rec",
-	)
-	addToolSubcommand(
-		"clean",
-		"Delete empty folders",
-		"Asks for base path, previews empty folders, and asks for confirmation before deletion.",
-		"dm tools clean",
-		"clean",
-		"c",
-	)
-	addToolSubcommand(
-		"system",
-		"Show system/network snapshot",
-		"Shows host, CPU, memory, disks, interfaces, Wi-Fi networks, and ARP LAN neighbors.",
-		"dm tools system\ndm tools sys\ndm tools htop",
-		"system",
-		"sys",
-		"htop",
-	)
+	toolsCmd.PersistentFlags().BoolVar(&allowProtected, "allow-protected", false, "let clean/rename modify protected paths (roots, home, system and dm.json \"protected\" dirs)")
 
 	return toolsCmd
 }
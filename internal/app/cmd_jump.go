@@ -0,0 +1,207 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cli/internal/aliastarget"
+	"cli/internal/history"
+	"cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+// jumpPickerRows is how many matches the picker lists at once.
+const jumpPickerRows = 20
+
+// jumpTarget is a folder alias offered by dm jump.
+type jumpTarget struct {
+	Name   string
+	Path   string
+	Status string
+}
+
+// collectJumpTargets returns the folder aliases, most used first. Status is
+// "missing" or "unreachable" for targets that cannot be entered.
+func collectJumpTargets(aliases, broken map[string]string, runs map[string]history.Record, at time.Time) []jumpTarget {
+	var out []jumpTarget
+	for _, name := range sortedAliasNames(aliases) {
+		if aliasCategory(aliases[name]) != "folders" {
+			continue
+		}
+		if path := aliastarget.Path(aliases[name]); path != "" {
+			out = append(out, jumpTarget{Name: name, Path: path, Status: broken[name]})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return history.Score(runs[history.Key(history.KindAlias, out[i].Name)], at) >
+			history.Score(runs[history.Key(history.KindAlias, out[j].Name)], at)
+	})
+	return out
+}
+
+// isSubsequence reports whether the letters of query appear in s in order,
+// so "dwl" matches "downloads".
+func isSubsequence(s, query string) bool {
+	rest := []rune(query)
+	for _, r := range s {
+		if len(rest) == 0 {
+			break
+		}
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
+
+// jumpMatchRank orders matches: name prefix, name substring, fuzzy name,
+// path substring, fuzzy path. ok is false when nothing matches.
+func jumpMatchRank(t jumpTarget, query string) (int, bool) {
+	name, path := strings.ToLower(t.Name), strings.ToLower(t.Path)
+	switch {
+	case strings.HasPrefix(name, query):
+		return 0, true
+	case strings.Contains(name, query):
+		return 1, true
+	case isSubsequence(name, query):
+		return 2, true
+	case strings.Contains(path, query):
+		return 3, true
+	case isSubsequence(path, query):
+		return 4, true
+	}
+	return 0, false
+}
+
+// filterJumpTargets keeps the targets matching query, best matches first
+// and in the given order within each rank.
+func filterJumpTargets(targets []jumpTarget, query string) []jumpTarget {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return targets
+	}
+	type ranked struct {
+		target jumpTarget
+		rank   int
+	}
+	var matches []ranked
+	for _, t := range targets {
+		if rank, ok := jumpMatchRank(t, query); ok {
+			matches = append(matches, ranked{t, rank})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].rank < matches[j].rank })
+	out := make([]jumpTarget, len(matches))
+	for i, m := range matches {
+		out[i] = m.target
+	}
+	return out
+}
+
+// resolveJumpTarget returns the alias named query, otherwise its best match.
+func resolveJumpTarget(targets []jumpTarget, query string) (jumpTarget, bool) {
+	name := strings.ToLower(strings.TrimSpace(query))
+	for _, t := range targets {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	if matches := filterJumpTargets(targets, name); len(matches) > 0 && name != "" {
+		return matches[0], true
+	}
+	return jumpTarget{}, false
+}
+
+// pickJumpTarget lists the targets on w and reads a number or a filter
+// from r until one target is chosen; an empty line cancels. A filter
+// that leaves a single match picks it.
+func pickJumpTarget(r *bufio.Reader, w io.Writer, targets []jumpTarget) (jumpTarget, bool) {
+	shown := targets
+	for {
+		if len(shown) == 0 {
+			fmt.Fprintln(w, ui.Warn("No matching folders."))
+		}
+		rows := shown
+		if len(rows) > jumpPickerRows {
+			rows = rows[:jumpPickerRows]
+		}
+		for i, t := range rows {
+			line := fmt.Sprintf("%s %s %s", ui.Warn(fmt.Sprintf("%2d)", i+1)), ui.PadRight(t.Name, 16), ui.Muted(t.Path))
+			if t.Status != "" {
+				line += " " + ui.Error("["+t.Status+"]")
+			}
+			fmt.Fprintln(w, line)
+		}
+		if hidden := len(shown) - len(rows); hidden > 0 {
+			fmt.Fprintln(w, ui.Muted(fmt.Sprintf("    and %d more… (type to filter)", hidden)))
+		}
+		fmt.Fprint(w, ui.Prompt("Number or filter (Enter to cancel): "))
+		input := readLine(r)
+		if input == "" {
+			return jumpTarget{}, false
+		}
+		if n, err := strconv.Atoi(input); err == nil {
+			if n >= 1 && n <= len(rows) {
+				return rows[n-1], true
+			}
+			fmt.Fprintln(w, ui.Error("Invalid selection."))
+			continue
+		}
+		shown = filterJumpTargets(targets, input)
+		if len(shown) == 1 {
+			return shown[0], true
+		}
+	}
+}
+
+func newJumpCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "jump [name]",
+		Short: "Print the folder of a folder alias, picked interactively without a name",
+		Long: "Prints the folder a folder alias (cd/Set-Location) points at. A name may be partial or fuzzy. " +
+			"Without a name, a picker lists every folder alias with its status on stderr; type to filter and a number to choose. " +
+			"The dmj function written by `dm alias sync` and `dm profile sync` changes to the printed folder.",
+		Example: "dm jump\ndm jump dl\ncd \"$(dm jump)\"",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			aliases, err := loadAskAliases(rt.BaseDir)
+			if err != nil {
+				return err
+			}
+			broken := aliastarget.Check(aliases, aliastarget.StatTimeout, os.Stat)
+			targets := collectJumpTargets(aliases, broken, history.Load(), time.Now())
+			if len(targets) == 0 {
+				return fmt.Errorf("no folder aliases to jump to (add one with: dm alias add <name> cd <path>)")
+			}
+			var target jumpTarget
+			if len(args) == 1 {
+				var ok bool
+				if target, ok = resolveJumpTarget(targets, args[0]); !ok {
+					return fmt.Errorf("no folder alias matches %q", args[0])
+				}
+			} else {
+				var ok bool
+				if target, ok = pickJumpTarget(bufio.NewReader(os.Stdin), os.Stderr, targets); !ok {
+					return exitCodeError{code: 1}
+				}
+			}
+			if target.Status != "" {
+				return fmt.Errorf("%s -> %s is %s", target.Name, target.Path, target.Status)
+			}
+			history.Add(history.KindAlias, target.Name, 0)
+			fmt.Println(target.Path)
+			return nil
+		},
+	}
+}
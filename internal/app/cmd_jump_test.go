@@ -0,0 +1,84 @@
+package app
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"cli/internal/history"
+)
+
+func testJumpTargets() []jumpTarget {
+	aliases := map[string]string{
+		"dl":    "cd C:\\Users\\me\\Downloads",
+		"proj":  "Set-Location 'D:\\work\\projects'",
+		"music": "sl D:\\media\\music",
+		"build": "go build ./...",
+	}
+	now := time.Now()
+	runs := map[string]history.Record{
+		history.Key(history.KindAlias, "music"): {Count: 5, LastRun: now},
+	}
+	return collectJumpTargets(aliases, map[string]string{"proj": "missing"}, runs, now)
+}
+
+func TestCollectJumpTargets(t *testing.T) {
+	targets := testJumpTargets()
+	if len(targets) != 3 {
+		t.Fatalf("expected only folder aliases, got %+v", targets)
+	}
+	if targets[0].Name != "music" {
+		t.Fatalf("expected most used alias first, got %+v", targets)
+	}
+	for _, tg := range targets {
+		if tg.Name == "proj" && (tg.Path != "D:\\work\\projects" || tg.Status != "missing") {
+			t.Fatalf("unexpected proj target %+v", tg)
+		}
+	}
+}
+
+func TestFilterJumpTargets(t *testing.T) {
+	targets := testJumpTargets()
+	cases := map[string][]string{
+		"mu":    {"music"},
+		"dwn":   {"dl"},
+		"work":  {"proj"},
+		"":      {"music", "dl", "proj"},
+		"zzz":   nil,
+		"media": {"music"},
+	}
+	for query, want := range cases {
+		got := filterJumpTargets(targets, query)
+		var names []string
+		for _, tg := range got {
+			names = append(names, tg.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(want, ",") {
+			t.Fatalf("filter %q = %v, want %v", query, names, want)
+		}
+	}
+	if tg, ok := resolveJumpTarget(targets, "DL"); !ok || tg.Name != "dl" {
+		t.Fatalf("expected exact name match, got %+v", tg)
+	}
+	if _, ok := resolveJumpTarget(targets, "nothing"); ok {
+		t.Fatal("expected no match")
+	}
+}
+
+func TestPickJumpTarget(t *testing.T) {
+	targets := testJumpTargets()
+	if tg, ok := pickJumpTarget(bufio.NewReader(strings.NewReader("2\n")), io.Discard, targets); !ok || tg.Name != targets[1].Name {
+		t.Fatalf("expected second target, got %+v", tg)
+	}
+	if tg, ok := pickJumpTarget(bufio.NewReader(strings.NewReader("9\nwork\n")), io.Discard, targets); !ok || tg.Name != "proj" {
+		t.Fatalf("expected single filter match to be picked, got %+v", tg)
+	}
+	if _, ok := pickJumpTarget(bufio.NewReader(strings.NewReader("\n")), io.Discard, targets); ok {
+		t.Fatal("expected empty line to cancel")
+	}
+	if _, ok := pickJumpTarget(bufio.NewReader(strings.NewReader("")), io.Discard, targets); ok {
+		t.Fatal("expected end of input to cancel")
+	}
+}
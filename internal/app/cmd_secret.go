@@ -0,0 +1,190 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"cli/internal/plugins"
+	"cli/internal/redact"
+	"cli/internal/secrets"
+	"cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+var loadSecretStore = secrets.All
+
+// applySecrets exposes stored secrets as DM_SECRET_<NAME> to the plugins
+// their scope allows and masks their values in captured output.
+func applySecrets() {
+	plugins.SetExtraEnv(secretEnv())
+}
+
+// secretEnv returns the plugin env function. The store is decrypted on the
+// first plugin run rather than on every dm invocation (with DM_SECRETS_KEY
+// that is 600k PBKDF2 rounds), and its values are masked from then on,
+// before the plugin's output is captured.
+func secretEnv() func(name string) []string {
+	var (
+		once  sync.Once
+		store secrets.Store
+	)
+	return func(name string) []string {
+		once.Do(func() {
+			store = loadSecretStore()
+			masked := make([]string, 0, len(store.Values))
+			for _, v := range store.Values {
+				masked = append(masked, v)
+			}
+			redact.SetValues(masked)
+		})
+		return store.Env(name, plugins.MatchName)
+	}
+}
+
+// parseSecretPlugins splits the --plugins list of dm secret set; "none"
+// clears the scope.
+func parseSecretPlugins(raw string) ([]string, error) {
+	out := []string{}
+	if strings.EqualFold(strings.TrimSpace(raw), "none") {
+		return out, nil
+	}
+	for _, p := range strings.Split(raw, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("--plugins: invalid pattern %q", p)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func readSecretValue(name string) (string, error) {
+	if ui.StdinIsTerminal() {
+		return ui.ReadHidden(ui.Prompt("Value for " + name + ": "))
+	}
+	return strings.TrimRight(readLine(bufio.NewReader(os.Stdin)), "\r\n"), nil
+}
+
+func newSecretCommand() *cobra.Command {
+	secretCmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manage encrypted secrets passed to plugins",
+		Long: "Stores values encrypted in the state dir. A secret is passed only to the plugins named by --plugins on set, " +
+			"as $env:DM_SECRET_<NAME>; PowerShell functions can also call dm_secret <NAME>.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	var setPlugins string
+	setCmd := &cobra.Command{
+		Use:     "set <name> [value]",
+		Short:   "Store a secret (prompts when the value is omitted)",
+		Example: "dm secret set GITHUB_TOKEN --plugins gh,git\necho $token | dm secret set GITHUB_TOKEN\ndm secret set DB_PASS --plugins \"*\"",
+		Args:    cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := secrets.NormalizeName(args[0])
+			if err != nil {
+				return err
+			}
+			var value string
+			if len(args) == 2 {
+				value = args[1]
+			} else if value, err = readSecretValue(name); err != nil {
+				return err
+			}
+			if value == "" {
+				return fmt.Errorf("secret value is required")
+			}
+			var scope []string
+			if cmd.Flags().Changed("plugins") {
+				if scope, err = parseSecretPlugins(setPlugins); err != nil {
+					return err
+				}
+			}
+			if err := secrets.Set(name, value, scope); err != nil {
+				return err
+			}
+			fmt.Println(ui.OK("Saved secret: " + name))
+			if entries, err := secrets.List(); err == nil {
+				for _, e := range entries {
+					if e.Name == name && len(e.Plugins) == 0 {
+						fmt.Println(ui.Muted("No plugin receives it yet; add --plugins <names|patterns> (\"*\" for all)."))
+					}
+				}
+			}
+			return nil
+		},
+	}
+	setCmd.Flags().StringVar(&setPlugins, "plugins", "", "plugins that receive the secret: names, prefixes or globs, comma-separated (\"*\" for all, \"none\" to clear)")
+	secretCmd.AddCommand(setCmd)
+
+	secretCmd.AddCommand(&cobra.Command{
+		Use:   "get <name>",
+		Short: "Print a secret value",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value, ok, err := secrets.Get(args[0])
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("secret not found: %s", args[0])
+			}
+			fmt.Println(value)
+			return nil
+		},
+	})
+
+	secretCmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List secret names",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := secrets.List()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println("No secrets stored.")
+				return nil
+			}
+			for _, e := range entries {
+				scope := "no plugins"
+				if len(e.Plugins) > 0 {
+					scope = strings.Join(e.Plugins, ",")
+				}
+				fmt.Printf("%-24s %-32s %s\n", e.Name, ui.Muted("$env:"+secrets.EnvPrefix+e.Name), scope)
+			}
+			return nil
+		},
+	})
+
+	secretCmd.AddCommand(&cobra.Command{
+		Use:   "rm <name>",
+		Short: "Remove a secret",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removed, err := secrets.Remove(args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("secret not found: %s", args[0])
+			}
+			fmt.Println("Removed secret:", strings.ToUpper(args[0]))
+			return nil
+		},
+	})
+	return secretCmd
+}
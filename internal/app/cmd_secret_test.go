@@ -0,0 +1,39 @@
+package app
+
+import (
+	"testing"
+
+	"cli/internal/redact"
+	"cli/internal/secrets"
+)
+
+func TestSecretEnvLoadsStoreOnFirstPluginRun(t *testing.T) {
+	loads := 0
+	prev := loadSecretStore
+	loadSecretStore = func() secrets.Store {
+		loads++
+		return secrets.Store{
+			Values:  map[string]string{"TOKEN": "s3cret-value"},
+			Plugins: map[string][]string{"TOKEN": {"deploy"}},
+		}
+	}
+	defer func() { loadSecretStore = prev }()
+	defer redact.SetValues(nil)
+
+	env := secretEnv()
+	if loads != 0 {
+		t.Fatal("expected the store not to be loaded before a plugin runs")
+	}
+	if got := env("deploy"); len(got) != 1 || got[0] != "DM_SECRET_TOKEN=s3cret-value" {
+		t.Fatalf("unexpected env %v", got)
+	}
+	if got := env("other"); len(got) != 0 {
+		t.Fatalf("expected no secrets for an unscoped plugin, got %v", got)
+	}
+	if loads != 1 {
+		t.Fatalf("expected one load, got %d", loads)
+	}
+	if got := redact.String("out: s3cret-value"); got != "out: "+redact.Mask {
+		t.Fatalf("expected the value masked after loading, got %q", got)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"cli/internal/history"
+	"cli/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+func formatAvgDuration(ms int64) string {
+	if ms <= 0 {
+		return "-"
+	}
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Second {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+func printRunStats(stats []history.Stat, now time.Time) {
+	if len(stats) == 0 {
+		fmt.Println(ui.Muted("No runs recorded yet."))
+		return
+	}
+	slow, flaky := history.Attention(stats)
+	flagged := map[string]string{}
+	for _, s := range slow {
+		flagged[s.Name] = "slow"
+	}
+	for _, s := range flaky {
+		if flagged[s.Name] != "" {
+			flagged[s.Name] += ", flaky"
+		} else {
+			flagged[s.Name] = "flaky"
+		}
+	}
+	fmt.Printf("%-32s %5s %6s %8s  %s\n", "Name", "Runs", "Fail%", "Avg", "Last run")
+	for _, s := range stats {
+		last := formatAge(now.Sub(s.LastRun)) + " ago"
+		if s.LastCode != 0 {
+			last += fmt.Sprintf(" (exit %d)", s.LastCode)
+		}
+		line := fmt.Sprintf("%s %5d %5.0f%% %8s  %s", ui.PadRight(ui.Truncate(s.Name, 32), 32), s.Runs, s.FailureRate*100, formatAvgDuration(s.AvgMS), last)
+		if note := flagged[s.Name]; note != "" {
+			line += "  " + ui.Warn(note)
+		}
+		fmt.Println(line)
+	}
+}
+
+func newStatsCommand() *cobra.Command {
+	var (
+		kind    string
+		jsonOut bool
+	)
+	cmd := &cobra.Command{
+		Use:     "stats",
+		Short:   "Show run counts, failure rates and durations from the run history",
+		Example: "dm stats\ndm stats --kind alias\ndm stats --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			k := strings.ToLower(strings.TrimSpace(kind))
+			switch k {
+			case history.KindPlugin, history.KindTool, history.KindAlias:
+			default:
+				return fmt.Errorf("unknown kind %q (use plugin|tool|alias)", kind)
+			}
+			stats := history.Stats(history.Load(), k)
+			if jsonOut {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if stats == nil {
+					stats = []history.Stat{}
+				}
+				return enc.Encode(stats)
+			}
+			printRunStats(stats, time.Now())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kind, "kind", history.KindPlugin, "history to show: plugin|tool|alias")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "render stats as JSON")
+	return cmd
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"cli/internal/agent"
+
+	"github.com/spf13/cobra"
+)
+
+func newToolkitCommand() *cobra.Command {
+	toolkitCmd := &cobra.Command{
+		Use:   "toolkit",
+		Short: "Generate and maintain PowerShell toolkits",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	var specPath, provider, model string
+	var asJSON bool
+	generateCmd := &cobra.Command{
+		Use:     "generate --spec <file>",
+		Short:   "Generate every function listed in a JSON spec into one toolkit",
+		Example: "dm toolkit generate --spec msword.json\ndm toolkit generate --spec msword.json --provider ollama --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyAskEnvDefaults(cmd.Flags().Changed, askEnvTargets{
+				provider: &provider, model: &model,
+			}); err != nil {
+				return err
+			}
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			opts := agent.AskOptions{Provider: provider, Model: model, JSONMode: true}
+			if code := runToolkitGenerate(rt.BaseDir, specPath, opts, asJSON); code != 0 {
+				return exitCodeError{code: code}
+			}
+			return nil
+		},
+	}
+	generateCmd.Flags().StringVar(&specPath, "spec", "", "spec file listing the toolkit name, prefix and functions")
+	generateCmd.Flags().StringVar(&provider, "provider", "openai", "LLM provider: openai|ollama|auto")
+	generateCmd.Flags().StringVar(&model, "model", "", "model override")
+	generateCmd.Flags().BoolVar(&asJSON, "json", false, "render the summary report as JSON")
+	_ = generateCmd.MarkFlagRequired("spec")
+	toolkitCmd.AddCommand(generateCmd)
+
+	var dedupeJSON, dedupeReport bool
+	dedupeCmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Find functions defined in several toolkit files and remove or rename the shadowed copies",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			if code := runToolkitDedupe(rt.BaseDir, dedupeJSON, dedupeReport); code != 0 {
+				return exitCodeError{code: code}
+			}
+			return nil
+		},
+	}
+	dedupeCmd.Flags().BoolVar(&dedupeJSON, "json", false, "render duplicates as JSON")
+	dedupeCmd.Flags().BoolVar(&dedupeReport, "report", false, "only report duplicates, do not prompt")
+	toolkitCmd.AddCommand(dedupeCmd)
+
+	return toolkitCmd
+}
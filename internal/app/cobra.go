@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"cli/internal/agent"
 	"cli/internal/ui"
 
 	"github.com/spf13/cobra"
@@ -21,7 +22,10 @@ func (e exitCodeError) Error() string {
 }
 
 func Run(args []string) int {
+	startupTrace.begin(args)
+	defer startupTrace.report(os.Stderr)
 	setupSignalHandler()
+	startupTrace.mark("signal handler")
 	root := &cobra.Command{
 		Use:   "dm",
 		Short: "Personal CLI for tools, plugins, and AI helpers",
@@ -47,7 +51,10 @@ func Run(args []string) int {
 	}
 
 	var debugMode bool
+	var debugLLM bool
 	root.PersistentFlags().BoolVar(&debugMode, "debug", false, "enable debug logging")
+	root.PersistentFlags().BoolVar(&debugLLM, "debug-llm", false, "dump LLM prompts, responses, latency and planner parse results (secrets redacted) to a debug log")
+	root.PersistentFlags().Bool("trace-startup", false, "print where startup time goes to stderr")
 	root.PersistentFlags().BoolP("tools", "t", false, "shortcut for 'tools' command")
 	root.PersistentFlags().BoolP("plugins", "p", false, "shortcut for 'plugins' command")
 	root.PersistentFlags().BoolP("open", "o", false, "shortcut for 'open' command")
@@ -60,12 +67,17 @@ func Run(args []string) int {
 			level = slog.LevelDebug
 		}
 		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+		if debugLLM {
+			agent.EnableLLMDebug()
+		}
+		startupTrace.mark("flag parsing")
 	}
 
 	addCobraSubcommands(root)
 	addPluginAwareHelpCommand(root)
 	addCompletionCommands(root)
 	applySubcommandHelpTemplate(root)
+	startupTrace.mark("cobra build")
 
 	root.SetArgs(rewriteGroupShortcuts(args))
 
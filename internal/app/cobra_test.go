@@ -200,6 +200,19 @@ func TestAliasCommandIncludesSync(t *testing.T) {
 	}
 }
 
+func TestAliasListHasAllFlag(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"alias", "ls"})
+	if err != nil || cmd == nil || cmd.Name() != "ls" {
+		t.Fatalf("expected alias ls command, got %v (err %v)", cmd, err)
+	}
+	if cmd.Flags().Lookup("all") == nil {
+		t.Fatal("expected --all flag on alias ls")
+	}
+}
+
 func TestAskCommandIncludesAsPowerShellFlag(t *testing.T) {
 	root := &cobra.Command{Use: "dm"}
 	addCobraSubcommands(root)
@@ -220,3 +233,325 @@ func TestAskCommandIncludesAsPowerShellFlag(t *testing.T) {
 		t.Fatalf("expected shorthand -a, got %q", f.Shorthand)
 	}
 }
+
+func TestAskCommandIncludesRecordReplayFlags(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"ask"})
+	if err != nil {
+		t.Fatalf("expected ask command, got error: %v", err)
+	}
+	for _, name := range []string{"record", "replay"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on ask", name)
+		}
+	}
+}
+
+func TestAskCommandIncludesAutoPullFlag(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"ask"})
+	if err != nil {
+		t.Fatalf("expected ask command, got error: %v", err)
+	}
+	if cmd.Flags().Lookup("auto-pull") == nil {
+		t.Fatal("expected --auto-pull flag on ask")
+	}
+	if cmd.Flags().Lookup("allow-protected") == nil {
+		t.Fatal("expected --allow-protected flag on ask")
+	}
+	if cmd.Flags().Lookup("retry-fix") == nil {
+		t.Fatal("expected --retry-fix flag on ask")
+	}
+	if cmd.Flags().Lookup("read-only") == nil {
+		t.Fatal("expected --read-only flag on ask")
+	}
+	if cmd.Flags().Lookup("plain") == nil {
+		t.Fatal("expected --plain flag on ask")
+	}
+	for _, name := range []string{"out", "copy", "out-steps", "explain", "cache", "cache-ttl", "catalog-budget", "porcelain", "offline", "save", "resume", "plan"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on ask", name)
+		}
+	}
+}
+
+func TestAskScopingFlagsShadowRootShortcuts(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	root.PersistentFlags().BoolP("tools", "t", false, "")
+	root.PersistentFlags().BoolP("plugins", "p", false, "")
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"ask"})
+	if err != nil {
+		t.Fatalf("find ask: %v", err)
+	}
+	if err := cmd.ParseFlags([]string{"--tools", "search,recent", "--plugins", "git_*"}); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	for name, want := range map[string]string{"tools": "search,recent", "plugins": "git_*"} {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || f.Value.String() != want {
+			t.Fatalf("--%s = %v, want %q", name, f, want)
+		}
+	}
+}
+
+func TestToolsCommandIncludesAllowProtectedFlag(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"tools", "clean"})
+	if err != nil {
+		t.Fatalf("expected tools clean command, got error: %v", err)
+	}
+	if cmd.InheritedFlags().Lookup("allow-protected") == nil {
+		t.Fatal("expected --allow-protected flag on tools clean")
+	}
+}
+
+func TestOpenCommandIncludesTargets(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	for _, path := range [][]string{{"open", "config"}, {"open", "plugin"}, {"open", "profile"}} {
+		cmd, _, err := root.Find(path)
+		if err != nil || cmd == nil || cmd.Parent() == root {
+			t.Fatalf("expected %v command, got %v (err %v)", path, cmd, err)
+		}
+	}
+}
+
+func TestProfileCommandIncludesSync(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"profile", "sync"})
+	if err != nil || cmd == nil || cmd.Name() != "sync" {
+		t.Fatalf("expected profile sync command, got %v (err %v)", cmd, err)
+	}
+	if cmd.Flags().Lookup("shell") == nil {
+		t.Fatal("expected --shell flag on profile sync")
+	}
+}
+
+func TestCopyProfileCommandIncludesRestoreFlag(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"cp", "profile"})
+	if err != nil || cmd == nil || cmd.Name() != "profile" {
+		t.Fatalf("expected cp profile command, got %v (err %v)", cmd, err)
+	}
+	if cmd.Flags().Lookup("restore") == nil {
+		t.Fatal("expected --restore flag on cp profile")
+	}
+}
+
+func TestCacheClearCommandIncludesTypeFlag(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"cache", "clear"})
+	if err != nil || cmd == nil || cmd.Name() != "clear" {
+		t.Fatalf("expected cache clear command, got %v (err %v)", cmd, err)
+	}
+	if cmd.Flags().Lookup("type") == nil {
+		t.Fatal("expected --type flag on cache clear")
+	}
+}
+
+func TestAgentCatalogCommandFlags(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	root.PersistentFlags().BoolP("tools", "t", false, "")
+	root.PersistentFlags().BoolP("plugins", "p", false, "")
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"agent", "catalog"})
+	if err != nil || cmd == nil || cmd.Name() != "catalog" {
+		t.Fatalf("expected agent catalog command, got %v (err %v)", cmd, err)
+	}
+	for _, name := range []string{"scope", "tools", "plugins", "read-only", "risk-policy", "system", "prompt", "catalog-budget", "json"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on agent catalog", name)
+		}
+	}
+	if err := cmd.ParseFlags([]string{"--tools", "grep"}); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+}
+
+func TestPluginLintCommandViaAlias(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"plugin", "lint"})
+	if err != nil || cmd == nil || cmd.Name() != "lint" {
+		t.Fatalf("expected plugin lint command, got %v (err %v)", cmd, err)
+	}
+	for _, name := range []string{"file", "json"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on plugins lint", name)
+		}
+	}
+}
+
+func TestPluginDescribeCommandFlags(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"plugin", "describe"})
+	if err != nil || cmd == nil || cmd.Name() != "describe" {
+		t.Fatalf("expected plugin describe command, got %v (err %v)", cmd, err)
+	}
+	for _, name := range []string{"provider", "model", "force", "yes"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on plugins describe", name)
+		}
+	}
+}
+
+func TestToolkitGenerateCommandFlags(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"toolkit", "generate"})
+	if err != nil || cmd == nil || cmd.Name() != "generate" {
+		t.Fatalf("expected toolkit generate command, got %v (err %v)", cmd, err)
+	}
+	for _, name := range []string{"spec", "provider", "model", "json"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on toolkit generate", name)
+		}
+	}
+}
+
+func TestToolkitDedupeCommandFlags(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"toolkit", "dedupe"})
+	if err != nil || cmd == nil || cmd.Name() != "dedupe" {
+		t.Fatalf("expected toolkit dedupe command, got %v (err %v)", cmd, err)
+	}
+	for _, name := range []string{"json", "report"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on toolkit dedupe", name)
+		}
+	}
+}
+
+func TestPluginSourceCommandFlags(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"plugin", "source"})
+	if err != nil || cmd == nil || cmd.Name() != "source" {
+		t.Fatalf("expected plugin source command, got %v (err %v)", cmd, err)
+	}
+	if cmd.Flags().Lookup("open") == nil {
+		t.Fatal("expected --open flag on plugins source")
+	}
+}
+
+func TestPluginsListCommandIncludesSourcesFlag(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"plugins", "list"})
+	if err != nil || cmd == nil || cmd.Name() != "list" {
+		t.Fatalf("expected plugins list command, got %v (err %v)", cmd, err)
+	}
+	if cmd.Flags().Lookup("sources") == nil {
+		t.Fatal("expected --sources flag on plugins list")
+	}
+}
+
+func TestPluginsRunCommandPassesFlagsThrough(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"plugins", "run"})
+	if err != nil || cmd == nil || cmd.Name() != "run" {
+		t.Fatalf("expected plugins run command, got %v (err %v)", cmd, err)
+	}
+	if cmd.Flags().Lookup("sandbox") == nil {
+		t.Fatal("expected --sandbox flag on plugins run")
+	}
+	if err := cmd.ParseFlags([]string{"--sandbox", "ping", "-Count", "4"}); err != nil {
+		t.Fatalf("expected plugin args to pass through, got %v", err)
+	}
+	if got := cmd.Flags().Args(); len(got) != 3 || got[1] != "-Count" {
+		t.Fatalf("unexpected positional args: %v", got)
+	}
+}
+
+func TestSecretCommandIncludesSubcommands(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	for _, name := range []string{"set", "get", "list", "rm"} {
+		cmd, _, err := root.Find([]string{"secret", name})
+		if err != nil || cmd == nil || cmd.Name() != name {
+			t.Fatalf("expected secret %s command, got %v (err %v)", name, cmd, err)
+		}
+	}
+}
+
+func TestBenchCommandIncludesFlags(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"bench"})
+	if err != nil || cmd == nil || cmd.Name() != "bench" {
+		t.Fatalf("expected bench command, got %v (err %v)", cmd, err)
+	}
+	for _, name := range []string{"path", "name", "runs", "json"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on bench", name)
+		}
+	}
+}
+
+func TestJumpCommandRegistered(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"jump"})
+	if err != nil || cmd == nil || cmd.Name() != "jump" {
+		t.Fatalf("expected jump command, got %v (err %v)", cmd, err)
+	}
+}
+
+func TestStatsCommandIncludesFlags(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"stats"})
+	if err != nil || cmd == nil || cmd.Name() != "stats" {
+		t.Fatalf("expected stats command, got %v (err %v)", cmd, err)
+	}
+	for _, name := range []string{"kind", "json"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on stats", name)
+		}
+	}
+}
+
+func TestAgentModelsCommandFlags(t *testing.T) {
+	root := &cobra.Command{Use: "dm"}
+	addCobraSubcommands(root)
+
+	cmd, _, err := root.Find([]string{"agent", "models"})
+	if err != nil || cmd == nil || cmd.Name() != "models" {
+		t.Fatalf("expected agent models command, got %v (err %v)", cmd, err)
+	}
+	for _, name := range []string{"provider", "model", "json"} {
+		if cmd.Flags().Lookup(name) == nil {
+			t.Fatalf("expected --%s flag on agent models", name)
+		}
+	}
+}
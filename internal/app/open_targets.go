@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/internal/assets"
+	"cli/internal/platform"
+	"cli/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+type openTarget struct {
+	Path string
+	Dir  bool
+	// Seed is written when the file does not exist yet.
+	Seed string
+}
+
+func resolveOpenTarget(baseDir, kind, name string) (openTarget, error) {
+	switch kind {
+	case "config":
+		return openTarget{Path: filepath.Join(baseDir, "dm.json"), Seed: "{\n  \"tools\": []\n}\n"}, nil
+	case "agent-config":
+		return openTarget{Path: agent.ConfigPath(), Seed: assets.AgentConfigExample}, nil
+	case "aliases":
+		return openTarget{Path: askAliasFilePath(baseDir), Seed: "{}\n"}, nil
+	case "plugins":
+		return openTarget{Path: filepath.Join(baseDir, "plugins"), Dir: true}, nil
+	case "workflows":
+		if err := migrateLegacyWorkflows(baseDir); err != nil {
+			return openTarget{}, err
+		}
+		return openTarget{Path: askWorkflowDir(), Dir: true}, nil
+	case "plugin":
+		if strings.TrimSpace(name) == "" {
+			return openTarget{}, fmt.Errorf("plugin name is required")
+		}
+		info, err := plugins.GetInfo(baseDir, name)
+		if err != nil {
+			return openTarget{}, err
+		}
+		return openTarget{Path: info.Path}, nil
+	}
+	return openTarget{}, fmt.Errorf("unknown open target: %s", kind)
+}
+
+func openTargetPath(t openTarget) error {
+	if t.Dir {
+		if err := os.MkdirAll(t.Path, 0755); err != nil {
+			return err
+		}
+		platform.OpenFileBrowser(t.Path)
+		return nil
+	}
+	if !fileExists(t.Path) {
+		if err := os.MkdirAll(filepath.Dir(t.Path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(t.Path, []byte(t.Seed), 0644); err != nil {
+			return err
+		}
+	}
+	return openInEditor(t.Path)
+}
+
+func addOpenTargetCommands(openCmd *cobra.Command) {
+	simple := []struct {
+		kind  string
+		short string
+	}{
+		{"config", "Open dm.json (custom tools and settings)"},
+		{"agent-config", "Open the agent config (dm.agent.json)"},
+		{"aliases", "Open dm.aliases.json"},
+		{"plugins", "Open the plugins folder"},
+		{"workflows", "Open the saved workflows folder"},
+	}
+	for _, s := range simple {
+		kind := s.kind
+		openCmd.AddCommand(&cobra.Command{
+			Use:   kind,
+			Short: s.short,
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runOpenTarget(kind, "")
+			},
+		})
+	}
+	openCmd.AddCommand(&cobra.Command{
+		Use:               "plugin <name>",
+		Short:             "Open the source file of a plugin or function",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePluginEntryNames(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runOpenTarget("plugin", args[0])
+		},
+	})
+}
+
+func runOpenTarget(kind, name string) error {
+	rt, err := loadRuntime()
+	if err != nil {
+		return err
+	}
+	target, err := resolveOpenTarget(rt.BaseDir, kind, name)
+	if err != nil {
+		return err
+	}
+	return openTargetPath(target)
+}
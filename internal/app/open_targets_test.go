@@ -0,0 +1,32 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveOpenTarget(t *testing.T) {
+	baseDir := t.TempDir()
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+
+	got, err := resolveOpenTarget(baseDir, "config", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != filepath.Join(baseDir, "dm.json") || got.Dir {
+		t.Fatalf("unexpected config target: %+v", got)
+	}
+	got, err = resolveOpenTarget(baseDir, "workflows", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != askWorkflowDir() || !got.Dir {
+		t.Fatalf("unexpected workflows target: %+v", got)
+	}
+	if _, err := resolveOpenTarget(baseDir, "plugin", ""); err == nil {
+		t.Fatal("expected error for missing plugin name")
+	}
+	if _, err := resolveOpenTarget(baseDir, "pack", "git"); err == nil {
+		t.Fatal("expected error for unknown target")
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/internal/plugins"
+	"cli/internal/ui"
+)
+
+// describeTarget resolves a plugin to the file and function name that
+// will receive the help block; scripts use an empty function name.
+func describeTarget(baseDir, name string) (path, function string, err error) {
+	info, err := plugins.GetInfo(baseDir, name)
+	if err != nil {
+		return "", "", err
+	}
+	switch {
+	case info.Kind == "function":
+		return info.Path, info.Name, nil
+	case strings.EqualFold(filepath.Ext(info.Path), ".ps1"):
+		return info.Path, "", nil
+	}
+	return "", "", fmt.Errorf("%s is a %s plugin; only PowerShell functions and .ps1 scripts can be described", name, filepath.Ext(info.Path))
+}
+
+func runPluginDescribe(baseDir, name string, opts agent.AskOptions, force, yes bool) int {
+	path, function, err := describeTarget(baseDir, name)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	has, err := plugins.HasHelpBlock(path, function)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if has && !force {
+		fmt.Printf("Error: %s already has a help block (use --force to replace it)\n", name)
+		return 1
+	}
+	req := agent.DescribeRequest{Name: name, Script: function == ""}
+	if function == "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return 1
+		}
+		req.Source = string(data)
+	} else if req.Source, err = plugins.FunctionSource(path, function); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+
+	session, err := agent.ResolveSessionProvider(opts)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	res, err := runLLMCall("Describing "+name+"...", llmLabel(session.Options), true, func(callCtx context.Context) (agent.DescribeResult, error) {
+		opts := session.Options
+		opts.Context = callCtx
+		return agent.DescribeFunction(req, opts)
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	block := res.HelpBlock()
+	if err := plugins.ValidateHelpBlock(block); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+
+	fmt.Println()
+	fmt.Println(ui.Accent("--- " + name + " ---"))
+	fmt.Println(block)
+	fmt.Println(ui.Accent("---"))
+	fmt.Println(ui.Muted("Target: " + path))
+	if !yes {
+		fmt.Print(ui.Prompt("Insert help block? [y/N] "))
+		confirm := strings.ToLower(readLine(bufio.NewReader(os.Stdin)))
+		if confirm != "y" && confirm != "yes" {
+			fmt.Println(ui.Warn("Canceled."))
+			return 0
+		}
+	}
+	if err := plugins.InsertHelpBlock(path, function, block); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	fmt.Println(ui.OK("Updated " + path))
+	return 0
+}
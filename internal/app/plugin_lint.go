@@ -0,0 +1,79 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"cli/internal/plugins"
+	"cli/internal/ui"
+)
+
+// filterLintIssues keeps the issues of one toolkit file; an empty file
+// keeps everything.
+func filterLintIssues(issues []plugins.LintIssue, file string) []plugins.LintIssue {
+	if file == "" {
+		return issues
+	}
+	want, err := filepath.Abs(file)
+	if err != nil {
+		want = file
+	}
+	var out []plugins.LintIssue
+	for _, is := range issues {
+		if got, err := filepath.Abs(is.Path); err == nil && filepath.Clean(got) == filepath.Clean(want) {
+			out = append(out, is)
+		}
+	}
+	return out
+}
+
+func runPluginLint(baseDir, file string, asJSON bool) int {
+	issues, err := plugins.Lint(baseDir)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	issues = filterLintIssues(issues, file)
+	if asJSON {
+		if issues == nil {
+			issues = []plugins.LintIssue{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(issues)
+	} else {
+		printPluginLint(baseDir, issues)
+	}
+	if len(issues) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printPluginLint(baseDir string, issues []plugins.LintIssue) {
+	if len(issues) == 0 {
+		fmt.Println(ui.OK("No synopsis issues found."))
+		return
+	}
+	lastPath := ""
+	for _, is := range issues {
+		if is.Path != lastPath {
+			rel, err := filepath.Rel(baseDir, is.Path)
+			if err != nil {
+				rel = is.Path
+			}
+			fmt.Println(ui.Accent(rel))
+			lastPath = is.Path
+		}
+		fmt.Printf("  %s %s %s\n", is.Function, ui.Warn("["+is.Rule+"]"), is.Message)
+	}
+	fmt.Println(ui.Muted(fmt.Sprintf("%d issue(s)", len(issues))))
+	for _, is := range issues {
+		if is.Rule == "synopsis-missing" {
+			fmt.Println(ui.Muted("Tip: dm plugin describe <name> drafts the missing help with the agent."))
+			break
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"cli/internal/plugins"
+)
+
+func TestFilterLintIssues(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "plugins", "A_Toolkit.ps1")
+	b := filepath.Join(dir, "plugins", "B_Toolkit.ps1")
+	issues := []plugins.LintIssue{{Function: "a_x", Path: a}, {Function: "b_x", Path: b}}
+	if got := filterLintIssues(issues, ""); len(got) != 2 {
+		t.Fatalf("expected all issues without a file, got %+v", got)
+	}
+	got := filterLintIssues(issues, filepath.Join(dir, "plugins", ".", "B_Toolkit.ps1"))
+	if len(got) != 1 || got[0].Function != "b_x" {
+		t.Fatalf("expected only B issues, got %+v", got)
+	}
+}
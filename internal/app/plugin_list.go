@@ -0,0 +1,154 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"cli/internal/plugins"
+)
+
+type pluginListOptions struct {
+	Functions bool
+	All       bool
+	Sources   bool
+	JSON      bool
+	Filter    string
+	Sort      string
+}
+
+type pluginListItem struct {
+	Name    string   `json:"name"`
+	Kind    string   `json:"kind"`
+	Path    string   `json:"path"`
+	Shadows []string `json:"shadows,omitempty"`
+}
+
+func parsePluginListArgs(args []string) (pluginListOptions, error) {
+	opts := pluginListOptions{Sort: "name"}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		key, value, hasValue := strings.Cut(arg, "=")
+		switch key {
+		case "--functions", "-f":
+			opts.Functions = true
+		case "--all", "-a":
+			opts.All = true
+		case "--sources", "-s":
+			opts.Sources = true
+		case "--json":
+			opts.JSON = true
+		case "--filter", "--sort":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return opts, fmt.Errorf("%s requires a value", key)
+				}
+				i++
+				value = args[i]
+			}
+			if key == "--filter" {
+				opts.Filter = value
+			} else {
+				opts.Sort = strings.ToLower(strings.TrimSpace(value))
+			}
+		default:
+			return opts, fmt.Errorf("unknown list option %q", arg)
+		}
+	}
+	switch opts.Sort {
+	case "name", "kind", "path":
+	default:
+		return opts, fmt.Errorf("invalid --sort %q (use name|kind|path)", opts.Sort)
+	}
+	return opts, nil
+}
+
+// selectPluginEntries applies the kind, substring filter and sort options.
+func selectPluginEntries(items []plugins.Entry, opts pluginListOptions) []plugins.Entry {
+	filter := strings.ToLower(strings.TrimSpace(opts.Filter))
+	out := make([]plugins.Entry, 0, len(items))
+	for _, item := range items {
+		switch {
+		case opts.All:
+		case opts.Functions && item.Kind != "function":
+			continue
+		case !opts.Functions && item.Kind != "script":
+			continue
+		}
+		if filter != "" && !strings.Contains(strings.ToLower(item.Name), filter) {
+			continue
+		}
+		out = append(out, item)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		switch opts.Sort {
+		case "kind":
+			if out[i].Kind != out[j].Kind {
+				return out[i].Kind < out[j].Kind
+			}
+		case "path":
+			pi, pj := strings.ToLower(out[i].Path), strings.ToLower(out[j].Path)
+			if pi != pj {
+				return pi < pj
+			}
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func runPluginList(baseDir string, args []string) int {
+	opts, err := parsePluginListArgs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	items, err := plugins.ListEntries(baseDir, opts.Functions || opts.All)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	var shadowed map[string][]string
+	if opts.Sources || opts.JSON {
+		conflicts, err := plugins.FindConflicts(baseDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		shadowed = map[string][]string{}
+		for _, c := range conflicts {
+			shadowed[c.Name] = c.Sources[1:]
+		}
+	}
+	items = selectPluginEntries(items, opts)
+	if opts.JSON {
+		out := make([]pluginListItem, 0, len(items))
+		for _, item := range items {
+			out = append(out, pluginListItem{Name: item.Name, Kind: item.Kind, Path: item.Path, Shadows: shadowed[item.Name]})
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		return 0
+	}
+	if len(items) == 0 {
+		fmt.Println("No plugins found.")
+		return 0
+	}
+	for _, item := range items {
+		switch {
+		case opts.Sources:
+			fmt.Println(formatPluginSourceLine(baseDir, item, shadowed[item.Name]))
+		case opts.All:
+			fmt.Printf("%-9s %s\n", item.Kind, item.Name)
+		default:
+			fmt.Println(item.Name)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"cli/internal/plugins"
+)
+
+func TestParsePluginListArgs(t *testing.T) {
+	opts, err := parsePluginListArgs([]string{"--all", "--filter", "Git", "--sort=kind", "--json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.All || !opts.JSON || opts.Filter != "Git" || opts.Sort != "kind" {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+	if _, err := parsePluginListArgs([]string{"--sort", "size"}); err == nil {
+		t.Fatal("expected error for invalid sort")
+	}
+	if _, err := parsePluginListArgs([]string{"--filter"}); err == nil {
+		t.Fatal("expected error for missing filter value")
+	}
+}
+
+func TestSelectPluginEntries(t *testing.T) {
+	items := []plugins.Entry{
+		{Name: "git_status", Kind: "function", Path: "/p/functions/git.ps1"},
+		{Name: "backup", Kind: "script", Path: "/p/backup.ps1"},
+		{Name: "gitlog", Kind: "script", Path: "/p/a_gitlog.ps1"},
+	}
+	names := func(in []plugins.Entry) []string {
+		out := make([]string, len(in))
+		for i, e := range in {
+			out[i] = e.Name
+		}
+		return out
+	}
+	if got := names(selectPluginEntries(items, pluginListOptions{Sort: "name"})); !reflect.DeepEqual(got, []string{"backup", "gitlog"}) {
+		t.Fatalf("scripts only: %v", got)
+	}
+	if got := names(selectPluginEntries(items, pluginListOptions{All: true, Filter: "GIT", Sort: "kind"})); !reflect.DeepEqual(got, []string{"git_status", "gitlog"}) {
+		t.Fatalf("all filtered by kind: %v", got)
+	}
+	if got := names(selectPluginEntries(items, pluginListOptions{All: true, Sort: "path"})); !reflect.DeepEqual(got, []string{"gitlog", "backup", "git_status"}) {
+		t.Fatalf("sorted by path: %v", got)
+	}
+}
@@ -2,16 +2,22 @@ package app
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"cli/internal/history"
+	"cli/internal/platform"
 	"cli/internal/plugins"
 	"cli/internal/ui"
 )
 
+const recentMenuLimit = 5
+
 func runPluginMenu(baseDir string) int {
 	reader := bufio.NewReader(os.Stdin)
 	for {
@@ -25,24 +31,61 @@ func runPluginMenu(baseDir string) int {
 			return 0
 		}
 
+		recent := recentPluginFunctions(files)
+		if len(recent) > 0 {
+			fmt.Println()
+			fmt.Println(ui.Accent("Recent"))
+			fmt.Println(ui.Muted("------"))
+			for i, name := range recent {
+				fmt.Printf("r%d) %s\n", i+1, ui.Accent(name))
+			}
+		}
 		fmt.Println()
 		fmt.Println(ui.Accent("Plugin Files"))
 		fmt.Println(ui.Muted("------------"))
 		for i, f := range files {
 			label := pluginMenuLabel(i)
-			rel := strings.TrimPrefix(strings.ReplaceAll(f.Path, "\\", "/"), strings.ReplaceAll(filepath.Join(baseDir, "plugins"), "\\", "/")+"/")
-			fmt.Printf("%2d) [%s] %s %s\n", i+1, ui.Warn(label), ui.Accent(rel), ui.Muted(fmt.Sprintf("(%d)", len(f.Functions))))
+			count := fmt.Sprintf("(%d)", len(f.Functions))
+			path := ui.TruncateMiddle(pluginMenuRelPath(baseDir, f.Path), ui.WidthLeft(8+ui.StringWidth(label)+len(count)))
+			fmt.Printf("%2d) [%s] %s %s\n", i+1, ui.Warn(label), ui.Accent(path), ui.Muted(count))
 		}
 		fmt.Println(" 0) " + ui.Error("[x] Exit"))
-		fmt.Print(ui.Prompt("Select file > "))
-		choice := strings.TrimSpace(readLine(reader))
+		if ui.FilterAvailable() {
+			fmt.Println(ui.Muted(" /) Filter"))
+		}
+		choice := readPromptLine(reader, ui.Prompt("Select file > "))
 		if choice == "" || strings.EqualFold(choice, "x") || choice == "0" {
 			return 0
 		}
-		fileIndex, ok := parsePluginMenuChoice(choice, len(files))
-		if !ok {
-			fmt.Println(ui.Error("Invalid selection."))
-			continue
+		var fileIndex int
+		if choice == "/" && ui.FilterAvailable() {
+			labels := make([]string, 0, len(recent)+len(files))
+			for _, name := range recent {
+				labels = append(labels, "recent: "+name)
+			}
+			for _, f := range files {
+				labels = append(labels, fmt.Sprintf("%s (%d)", pluginMenuRelPath(baseDir, f.Path), len(f.Functions)))
+			}
+			idx, ok := ui.FilterSelect(reader, "Plugin Files", labels)
+			if !ok {
+				continue
+			}
+			if idx < len(recent) {
+				runRecentPluginFunction(baseDir, recent[idx], reader)
+				continue
+			}
+			fileIndex = idx - len(recent)
+		} else {
+			if idx, ok := parseRecentMenuChoice(choice, len(recent)); ok {
+				runRecentPluginFunction(baseDir, recent[idx], reader)
+				continue
+			}
+			idx, ok := parsePluginMenuChoice(choice, len(files))
+			if !ok {
+				fmt.Println(ui.Error("Invalid selection."))
+				continue
+			}
+			fileIndex = idx
 		}
 		code := runPluginFunctionsMenu(baseDir, files[fileIndex], reader)
 		if code != 0 {
@@ -60,87 +103,249 @@ func runPluginFunctionsMenu(baseDir string, file plugins.FunctionFile, reader *b
 	}
 
 	for {
+		runs := history.Load()
+		now := time.Now()
 		fmt.Println()
-		fmt.Printf("%s %s\n", ui.Accent("Functions:"), ui.Accent(strings.ReplaceAll(file.Path, "\\", "/")))
+		fmt.Printf("%s %s\n", ui.Accent("Functions:"), ui.Accent(ui.TruncateMiddle(strings.ReplaceAll(file.Path, "\\", "/"), ui.WidthLeft(11))))
 		fmt.Println(ui.Muted("----------------"))
 		for i, name := range file.Functions {
 			info, ok := infoByName[name]
 			line := fmt.Sprintf("%2d) [%s] %s", i+1, ui.Warn(pluginMenuLabel(i)), ui.Accent(name))
+			used := 7 + ui.StringWidth(pluginMenuLabel(i)) + ui.StringWidth(name)
 			if ok && len(info.Parameters) > 0 {
 				line += " " + ui.Warn("[args]")
+				used += 7
 			}
 			if ok && strings.TrimSpace(info.Synopsis) != "" {
-				line += " " + ui.Muted("- "+truncateText(info.Synopsis, 72))
+				line += " " + ui.Muted("- "+truncateText(info.Synopsis, pluginMenuSynopsisWidth(used+3)))
+			}
+			rec, ran := runs[history.Key(history.KindPlugin, name)]
+			if status := formatLastRun(rec, ran, now); status != "" {
+				line += " " + status
 			}
 			fmt.Println(line)
 		}
 		fmt.Println(" 0) " + ui.Error("[x] Exit"))
-		fmt.Println(ui.Muted(" h <n|letter>) Help"))
-		fmt.Print(ui.Prompt("Select function > "))
-
-		choice := strings.TrimSpace(readLine(reader))
+		hint := " h <n|letter>) Help   w <n|letter>) New window   1,3 or 2-4) Run several"
+		if ui.FilterAvailable() {
+			hint += "   /) Filter"
+		}
+		fmt.Println(ui.Muted(hint))
+		choice := readPromptLine(reader, ui.Prompt("Select function > "))
 		lc := strings.ToLower(choice)
 		switch lc {
 		case "", "0", "x", "exit":
 			return 0
 		}
 
-		if strings.HasPrefix(lc, "h ") {
-			target := strings.TrimSpace(choice[2:])
-			idx, ok := parsePluginMenuChoice(target, len(file.Functions))
+		var selected []int
+		if lc == "/" && ui.FilterAvailable() {
+			var ok bool
+			if selected, ok = filterPluginFunctions(baseDir, file, infoByName, reader); !ok {
+				continue
+			}
+		} else {
+			if strings.HasPrefix(lc, "h ") {
+				target := strings.TrimSpace(choice[2:])
+				idx, ok := parsePluginMenuChoice(target, len(file.Functions))
+				if !ok {
+					fmt.Println(ui.Error("Invalid help selection."))
+					continue
+				}
+				_ = runPlugin(baseDir, []string{"info", file.Functions[idx]})
+				waitForEnter(reader)
+				continue
+			}
+
+			if strings.HasPrefix(lc, "w ") {
+				idx, ok := parsePluginMenuChoice(strings.TrimSpace(choice[2:]), len(file.Functions))
+				if !ok {
+					fmt.Println(ui.Error("Invalid selection."))
+					continue
+				}
+				fn := file.Functions[idx]
+				info, hasInfo := infoByName[fn]
+				args, ok := promptPluginMenuArgs(fn, info, hasInfo, reader)
+				if !ok {
+					continue
+				}
+				if err := runPluginInNewWindow(fn, args); err != nil {
+					fmt.Fprintln(os.Stderr, "Error:", err)
+				}
+				continue
+			}
+
+			indices, ok := parsePluginMenuSelection(choice, len(file.Functions))
 			if !ok {
-				fmt.Println(ui.Error("Invalid help selection."))
+				fmt.Println(ui.Error("Invalid selection."))
 				continue
 			}
-			_ = runPlugin(baseDir, []string{"info", file.Functions[idx]})
-			waitForEnter(reader)
-			continue
+			selected = indices
 		}
-
-		funcIndex, ok := parsePluginMenuChoice(choice, len(file.Functions))
-		if !ok {
-			fmt.Println(ui.Error("Invalid selection."))
-			continue
+		results := make([]string, 0, len(selected))
+		for _, idx := range selected {
+			fn := file.Functions[idx]
+			info, hasInfo := infoByName[fn]
+			args, ok := promptPluginMenuArgs(fn, info, hasInfo, reader)
+			if !ok {
+				continue
+			}
+			code := runPlugin(baseDir, append([]string{"run", fn}, args...))
+			if code == 0 {
+				results = append(results, ui.OK(fn+": ok"))
+			} else {
+				results = append(results, ui.Error(fmt.Sprintf("%s: exit %d", fn, code)))
+			}
 		}
-		fn := file.Functions[funcIndex]
-		var (
-			paramCount int
-			argsHint   string
-		)
-		if info, ok := infoByName[fn]; ok {
-			paramCount = len(info.Parameters)
-			if len(info.Parameters) > 0 {
-				fmt.Println(ui.Accent("Parameters:"))
-				for _, p := range info.Parameters {
-					fmt.Println("-", p)
-				}
+		if len(selected) > 1 {
+			ui.PrintSection("Summary")
+			for _, r := range results {
+				fmt.Println(r)
 			}
-			if len(info.Examples) > 0 {
-				fmt.Println(ui.Accent("Example:"))
-				fmt.Println("-", info.Examples[0])
-				argsHint = argsHintFromExample(fn, info.Examples[0])
+		}
+		waitForEnter(reader)
+	}
+}
+
+// filterPluginFunctions picks functions of file with the incremental
+// filter, the "/" input of the numbered menu. It runs the info and
+// new-window actions itself; ok is false when there is nothing left to run.
+func filterPluginFunctions(baseDir string, file plugins.FunctionFile, infoByName map[string]plugins.Info, reader *bufio.Reader) ([]int, bool) {
+	labels := make([]string, len(file.Functions))
+	for i, name := range file.Functions {
+		labels[i] = name
+		if info, ok := infoByName[name]; ok && strings.TrimSpace(info.Synopsis) != "" {
+			labels[i] += " - " + truncateText(info.Synopsis, 72)
+		}
+	}
+	res, ok := ui.FilterMenu(reader, "Functions: "+strings.ReplaceAll(file.Path, "\\", "/"), labels)
+	if !ok {
+		return nil, false
+	}
+	fn := file.Functions[res.Indexes[0]]
+	switch res.Action {
+	case ui.FilterInfo:
+		_ = runPlugin(baseDir, []string{"info", fn})
+		waitForEnter(reader)
+		return nil, false
+	case ui.FilterWindow:
+		info, hasInfo := infoByName[fn]
+		if args, ok := promptPluginMenuArgs(fn, info, hasInfo, reader); ok {
+			if err := runPluginInNewWindow(fn, args); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
 			}
 		}
-		runArgs := []string{"run", fn}
-		if paramCount == 0 {
-			_ = runPlugin(baseDir, runArgs)
-			waitForEnter(reader)
-			continue
+		return nil, false
+	}
+	return res.Indexes, true
+}
+
+// promptPluginMenuArgs shows the parameters of fn and asks for its arguments
+// when it takes any. ok is false when the input cannot be parsed.
+func promptPluginMenuArgs(fn string, info plugins.Info, hasInfo bool, reader *bufio.Reader) ([]string, bool) {
+	if !hasInfo || len(info.Parameters) == 0 {
+		return nil, true
+	}
+	var argsHint string
+	fmt.Println(ui.Accent("Parameters:"), ui.Muted("("+fn+")"))
+	for _, p := range info.Parameters {
+		fmt.Println("-", p)
+	}
+	if len(info.Examples) > 0 {
+		fmt.Println(ui.Accent("Example:"))
+		fmt.Println("-", info.Examples[0])
+		argsHint = argsHintFromExample(fn, info.Examples[0])
+	}
+	if strings.TrimSpace(argsHint) != "" {
+		fmt.Println(ui.Accent("Args hint:"), argsHint)
+	}
+	parsed, err := splitMenuArgs(readPromptLine(reader, ui.Prompt("Args (optional) > ")))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return nil, false
+	}
+	return parsed, true
+}
+
+// recentPluginFunctions returns the most used functions that still exist.
+func recentPluginFunctions(files []plugins.FunctionFile) []string {
+	known := map[string]bool{}
+	for _, f := range files {
+		for _, fn := range f.Functions {
+			known[fn] = true
 		}
-		if strings.TrimSpace(argsHint) != "" {
-			fmt.Println(ui.Accent("Args hint:"), argsHint)
+	}
+	var out []string
+	for _, name := range history.Top(history.Load(), history.KindPlugin, recentMenuLimit, time.Now()) {
+		if known[name] {
+			out = append(out, name)
 		}
-		fmt.Print(ui.Prompt("Args (optional) > "))
-		rawArgs := strings.TrimSpace(readLine(reader))
-		parsedArgs, err := splitMenuArgs(rawArgs)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	return out
+}
+
+func runRecentPluginFunction(baseDir, fn string, reader *bufio.Reader) {
+	info, err := plugins.GetInfo(baseDir, fn)
+	args, ok := promptPluginMenuArgs(fn, info, err == nil, reader)
+	if !ok {
+		return
+	}
+	_ = runPlugin(baseDir, append([]string{"run", fn}, args...))
+	waitForEnter(reader)
+}
+
+// parseRecentMenuChoice parses "r1".."rN".
+func parseRecentMenuChoice(choice string, count int) (int, bool) {
+	rest, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(choice)), "r")
+	if !ok {
+		return -1, false
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 1 || n > count {
+		return -1, false
+	}
+	return n - 1, true
+}
+
+func runPluginInNewWindow(fn string, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	return platform.RunInNewTerminal(append([]string{exe, fn}, args...))
+}
+
+// parsePluginMenuSelection accepts a single choice or a list such as
+// "1,3", "a c" or "2-4" and returns the indices in the given order.
+func parsePluginMenuSelection(choice string, count int) ([]int, bool) {
+	fields := strings.FieldsFunc(choice, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(fields) == 0 {
+		return nil, false
+	}
+	var out []int
+	for _, f := range fields {
+		if lo, hi, isRange := strings.Cut(f, "-"); isRange {
+			from, okFrom := parsePluginMenuChoice(lo, count)
+			to, okTo := parsePluginMenuChoice(hi, count)
+			if !okFrom || !okTo || from > to {
+				return nil, false
+			}
+			for i := from; i <= to; i++ {
+				out = append(out, i)
+			}
 			continue
 		}
-		runArgs = append(runArgs, parsedArgs...)
-		_ = runPlugin(baseDir, runArgs)
-		waitForEnter(reader)
+		idx, ok := parsePluginMenuChoice(f, count)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, idx)
 	}
+	return out, true
+}
+
+func pluginMenuRelPath(baseDir, path string) string {
+	return strings.TrimPrefix(strings.ReplaceAll(path, "\\", "/"), strings.ReplaceAll(filepath.Join(baseDir, "plugins"), "\\", "/")+"/")
 }
 
 func parsePluginMenuChoice(choice string, count int) (int, bool) {
@@ -220,20 +425,32 @@ func readLine(r *bufio.Reader) string {
 	return strings.TrimSpace(s)
 }
 
+// readPromptLine reads free-form input with line editing and history.
+// Ctrl-C exits instead of being taken as an empty answer.
+func readPromptLine(r *bufio.Reader, prompt string) string {
+	s, err := ui.ReadLine(r, prompt)
+	if errors.Is(err, ui.ErrInterrupt) {
+		ui.ExitInterrupted()
+	}
+	return strings.TrimSpace(s)
+}
+
 func waitForEnter(r *bufio.Reader) {
 	fmt.Print(ui.Prompt("Press Enter to continue..."))
 	_, _ = r.ReadString('\n')
 }
 
-func truncateText(s string, max int) string {
-	txt := strings.TrimSpace(s)
-	if max <= 0 || len(txt) <= max {
-		return txt
-	}
-	if max <= 3 {
-		return txt[:max]
+// pluginMenuSynopsisWidth is the room for a synopsis after used cells,
+// falling back to 72 when the terminal width is unknown.
+func pluginMenuSynopsisWidth(used int) int {
+	if w := ui.WidthLeft(used); w > 0 {
+		return w
 	}
-	return txt[:max-3] + "..."
+	return 72
+}
+
+func truncateText(s string, max int) string {
+	return ui.Truncate(strings.TrimSpace(s), max)
 }
 
 func argsHintFromExample(functionName, example string) string {
@@ -250,3 +467,17 @@ func argsHintFromExample(functionName, example string) string {
 	}
 	return ""
 }
+
+// formatPluginSourceLine renders `name  path` for `dm plugins list --sources`,
+// followed by the definitions that the entry shadows.
+func formatPluginSourceLine(baseDir string, item plugins.Entry, shadows []string) string {
+	line := ui.PadRight(item.Name, 24) + " " + ui.TruncateMiddle(pluginMenuRelPath(baseDir, item.Path), ui.WidthLeft(max(25, ui.StringWidth(item.Name)+1)))
+	if len(shadows) == 0 {
+		return line
+	}
+	rel := make([]string, len(shadows))
+	for i, p := range shadows {
+		rel[i] = pluginMenuRelPath(baseDir, p)
+	}
+	return line + ui.Warn("  (shadows "+strings.Join(rel, ", ")+")")
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"cli/internal/platform"
+	"cli/internal/plugins"
+	"cli/internal/ui"
+)
+
+// pluginSourceLines returns the code behind a plugin: the function block
+// (with its help) for toolkit functions, the whole file for scripts.
+func pluginSourceLines(baseDir, name string) (path string, first int, lines []string, err error) {
+	info, err := plugins.GetInfo(baseDir, name)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if info.Kind == "function" {
+		first, lines, err = plugins.FunctionLines(info.Path, info.Name)
+		return info.Path, first, lines, err
+	}
+	data, err := os.ReadFile(info.Path)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	text := strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	return info.Path, 1, strings.Split(text, "\n"), nil
+}
+
+func formatSourceLines(first int, lines []string) string {
+	width := len(fmt.Sprint(first + len(lines) - 1))
+	var b strings.Builder
+	for i, line := range lines {
+		fmt.Fprintf(&b, "%s  %s\n", ui.Muted(fmt.Sprintf("%*d", width, first+i)), line)
+	}
+	return b.String()
+}
+
+func runPluginSource(baseDir, name string, open bool) int {
+	path, first, lines, err := pluginSourceLines(baseDir, name)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if open {
+		if err := platform.OpenInEditorAt(path, first); err != nil {
+			fmt.Println("Error:", err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Println(ui.Accent(fmt.Sprintf("%s:%d-%d", relToBaseDir(baseDir, path), first, first+len(lines)-1)))
+	fmt.Print(formatSourceLines(first, lines))
+	return 0
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli/internal/plugins"
+)
+
+func TestPluginSourceLines(t *testing.T) {
+	baseDir := t.TempDir()
+	pluginsDir := filepath.Join(baseDir, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	toolkit := "Set-StrictMode -Version Latest\n\nfunction s_hello {\n    'hello'\n}\n"
+	if err := os.WriteFile(filepath.Join(pluginsDir, "S_Toolkit.ps1"), []byte(toolkit), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginsDir, "deploy.sh"), []byte("echo a\necho b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	plugins.ResetCaches()
+	t.Cleanup(plugins.ResetCaches)
+
+	path, first, lines, err := pluginSourceLines(baseDir, "s_hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "S_Toolkit.ps1" || first != 3 || strings.Join(lines, "|") != "function s_hello {|    'hello'|}" {
+		t.Fatalf("unexpected function source %s:%d %q", path, first, lines)
+	}
+	_, first, lines, err = pluginSourceLines(baseDir, "deploy")
+	if err != nil || first != 1 || len(lines) != 2 {
+		t.Fatalf("unexpected script source %d %q (err %v)", first, lines, err)
+	}
+	t.Setenv("NO_COLOR", "1")
+	if got := formatSourceLines(9, []string{"a", "b"}); !strings.Contains(got, " 9  a\n") || !strings.Contains(got, "10  b\n") {
+		t.Fatalf("unexpected numbering:\n%s", got)
+	}
+}
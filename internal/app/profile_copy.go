@@ -0,0 +1,232 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cli/internal/platform"
+	"cli/internal/ui"
+	"cli/tools"
+
+	"github.com/spf13/cobra"
+)
+
+const profileBackupDirName = "dm-backups"
+
+var profileBackupNow = time.Now
+
+// profileBackupDir is "dir" under "backup" in the dm.json defaults, or
+// dm-backups next to the profile.
+func profileBackupDir(profilePath string) string {
+	if dir := tools.ToolDefault("backup", "dir", ""); dir != "" {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(profilePath), profileBackupDirName)
+}
+
+// backupProfile copies the current profile into a timestamped file and
+// returns its path, or "" when there is nothing to back up.
+func backupProfile(profilePath string) (string, error) {
+	if _, err := os.Stat(profilePath); os.IsNotExist(err) {
+		return "", nil
+	}
+	return platform.BackupFile(profilePath, profileBackupDir(profilePath), profileBackupNow())
+}
+
+// listProfileBackups returns backups of profilePath, newest first.
+func listProfileBackups(profilePath string) ([]string, error) {
+	ext := filepath.Ext(profilePath)
+	prefix := strings.TrimSuffix(filepath.Base(profilePath), ext) + "."
+	entries, err := os.ReadDir(profileBackupDir(profilePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) && strings.HasSuffix(e.Name(), ext) {
+			out = append(out, filepath.Join(profileBackupDir(profilePath), e.Name()))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(out)))
+	return out, nil
+}
+
+// pickProfileBackup resolves a --restore value: a 1-based index into the
+// backups (newest first), a backup file name, or a path.
+func pickProfileBackup(profilePath, choice string) (string, error) {
+	backups, err := listProfileBackups(profilePath)
+	if err != nil {
+		return "", err
+	}
+	if n, err := strconv.Atoi(choice); err == nil {
+		if n < 1 || n > len(backups) {
+			if len(backups) == 0 {
+				return "", fmt.Errorf("no backups found in %s", profileBackupDir(profilePath))
+			}
+			return "", fmt.Errorf("backup %d does not exist (1-%d, see --backups)", n, len(backups))
+		}
+		return backups[n-1], nil
+	}
+	for _, b := range backups {
+		if filepath.Base(b) == choice {
+			return b, nil
+		}
+	}
+	if info, err := os.Stat(choice); err == nil && !info.IsDir() {
+		return choice, nil
+	}
+	return "", fmt.Errorf("backup %q not found in %s", choice, profileBackupDir(profilePath))
+}
+
+func printProfileBackups(profilePath string) error {
+	backups, err := listProfileBackups(profilePath)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		fmt.Println("No backups in", profileBackupDir(profilePath))
+		return nil
+	}
+	for i, b := range backups {
+		fmt.Printf("%3d  %s\n", i+1, filepath.Base(b))
+	}
+	return nil
+}
+
+// profileAliasBlock returns the block `dm alias sync` maintains in a
+// profile, or "" when there is none.
+func profileAliasBlock(content string) string {
+	start := strings.Index(content, dmAliasProfileBegin)
+	end := strings.Index(content, dmAliasProfileEnd)
+	if start < 0 || end < start {
+		return ""
+	}
+	return content[start:end+len(dmAliasProfileEnd)] + "\n"
+}
+
+// replaceProfile overwrites target with the content of source, backing
+// target up first when backup is set (a restore does not, so restoring
+// does not push a new backup in front of the older ones). The dm alias
+// block of the current profile is carried over, so the copy does not drop
+// the aliases `dm alias sync` wrote.
+func replaceProfile(target, source string, backup bool) (string, error) {
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return "", err
+	}
+	current, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if block := profileAliasBlock(string(current)); block != "" {
+		data = []byte(upsertAskAliasesProfileBlock(string(data), block))
+	}
+	saved := ""
+	if backup {
+		if saved, err = backupProfile(target); err != nil {
+			return "", fmt.Errorf("cannot back up %s: %w", target, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return saved, err
+	}
+	return saved, os.WriteFile(target, data, 0644)
+}
+
+func confirmProfileReplace(d profileSymbolDiff, yes bool) bool {
+	printProfileSymbolDiff(d)
+	if yes {
+		return true
+	}
+	fmt.Print(ui.Prompt("Overwrite the profile? [y/N] "))
+	confirm := strings.ToLower(strings.TrimSpace(readLine(bufio.NewReader(os.Stdin))))
+	return confirm == "y" || confirm == "yes"
+}
+
+func newCopyCommand() *cobra.Command {
+	cpCmd := &cobra.Command{
+		Use:   "cp",
+		Short: "Copy managed files into place",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	var (
+		source  string
+		restore string
+		list    bool
+		yes     bool
+	)
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Copy the profile script over $PROFILE (with backup)",
+		Long: "Copies the --source script over $PROFILE, keeping the dm alias block that `dm alias sync` maintains. " +
+			"The current profile is backed up to dm-backups/ next to it (or \"backup\" \"dir\" in the dm.json defaults) and the symbol diff is shown for confirmation. " +
+			"--backups lists the backups, newest first; --restore brings back the newest one, and --restore=<n|file> the n-th one or a named file. " +
+			"A restore does not back up the profile it replaces.",
+		Example: "dm cp profile --source my_profile.ps1\ndm cp profile --backups\ndm cp profile --restore\ndm cp profile --restore=3",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := resolveUserPowerShellProfilePath()
+			if strings.TrimSpace(target) == "" {
+				return fmt.Errorf("PowerShell profile path is not available")
+			}
+			if list {
+				return printProfileBackups(target)
+			}
+			restoring := cmd.Flags().Changed("restore")
+			op := "cp profile"
+			if restoring {
+				op = "backup restore"
+			}
+			release, err := platform.AcquirePathLock(op, target, profileBackupDir(target))
+			if err != nil {
+				return err
+			}
+			defer release()
+			src := strings.TrimSpace(source)
+			if restoring {
+				if src, err = pickProfileBackup(target, strings.TrimSpace(restore)); err != nil {
+					return err
+				}
+				fmt.Println("Restore:", src)
+			}
+			d, err := diffProfileFiles(target, src)
+			if err != nil {
+				return err
+			}
+			if !confirmProfileReplace(d, yes) {
+				fmt.Println("Canceled.")
+				return nil
+			}
+			backup, err := replaceProfile(target, src, !restoring)
+			if backup != "" {
+				fmt.Println("Backup :", backup)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Println(ui.OK("Profile updated: " + target))
+			return nil
+		},
+	}
+	profileCmd.Flags().StringVar(&source, "source", "", "profile script to copy over $PROFILE")
+	profileCmd.Flags().StringVar(&restore, "restore", "", "restore a backup: its number in --backups (default 1, the newest) or file")
+	profileCmd.Flags().Lookup("restore").NoOptDefVal = "1"
+	profileCmd.Flags().BoolVar(&list, "backups", false, "list the profile backups, newest first")
+	profileCmd.Flags().BoolVarP(&yes, "yes", "y", false, "skip confirmation")
+	profileCmd.MarkFlagsMutuallyExclusive("source", "restore", "backups")
+	profileCmd.MarkFlagsOneRequired("source", "restore", "backups")
+	cpCmd.AddCommand(profileCmd)
+	return cpCmd
+}
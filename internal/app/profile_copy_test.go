@@ -0,0 +1,117 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplaceProfileBacksUpAndRestores(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "Microsoft.PowerShell_profile.ps1")
+	source := filepath.Join(dir, "source.ps1")
+	if err := os.WriteFile(target, []byte("function mine { }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(source, []byte("function theirs { }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	prev := profileBackupNow
+	profileBackupNow = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { profileBackupNow = prev }()
+
+	backup, err := replaceProfile(target, source, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, profileBackupDirName, "Microsoft.PowerShell_profile.20260102-030405.ps1")
+	if backup != want {
+		t.Fatalf("expected backup %s, got %s", want, backup)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "function theirs { }\n" {
+		t.Fatalf("expected target overwritten, got %q", data)
+	}
+
+	backups, err := listProfileBackups(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || backups[0] != want {
+		t.Fatalf("unexpected backups %v", backups)
+	}
+	if _, err := replaceProfile(target, backups[0], false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "function mine { }\n" {
+		t.Fatalf("expected restored profile, got %q", data)
+	}
+	if backups, _ := listProfileBackups(target); len(backups) != 1 {
+		t.Fatalf("expected a restore to make no backup, got %v", backups)
+	}
+}
+
+func TestProfileBackupsSameSecondAndPickOlder(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "profile.ps1")
+	prev := profileBackupNow
+	profileBackupNow = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { profileBackupNow = prev }()
+	for _, content := range []string{"original", "second", "third"} {
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := backupProfile(target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, err := listProfileBackups(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 3 {
+		t.Fatalf("expected 3 backups in the same second, got %v", backups)
+	}
+	oldest, err := pickProfileBackup(target, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(oldest); string(data) != "original" {
+		t.Fatalf("expected backup 3 to be the original, got %q", data)
+	}
+	if got, err := pickProfileBackup(target, filepath.Base(backups[0])); err != nil || got != backups[0] {
+		t.Fatalf("expected pick by name %s, got %q, %v", backups[0], got, err)
+	}
+	if _, err := pickProfileBackup(target, "4"); err == nil {
+		t.Fatal("expected an error for a missing index")
+	}
+}
+
+func TestBackupProfileMissingTarget(t *testing.T) {
+	backup, err := backupProfile(filepath.Join(t.TempDir(), "missing.ps1"))
+	if err != nil || backup != "" {
+		t.Fatalf("expected no backup for missing profile, got %q, %v", backup, err)
+	}
+}
+
+func TestReplaceProfileKeepsAliasBlock(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	dir := t.TempDir()
+	target := filepath.Join(dir, "profile.ps1")
+	source := filepath.Join(dir, "source.ps1")
+	block := dmAliasProfileBegin + "\nfunction dl { Set-Location D:\\dl }\n" + dmAliasProfileEnd + "\n"
+	if err := os.WriteFile(target, []byte("function mine { }\n\n"+block), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(source, []byte("function theirs { }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replaceProfile(target, source, true); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(target)
+	if !strings.Contains(string(data), "function theirs") || !strings.Contains(string(data), block) {
+		t.Fatalf("expected source plus alias block, got %q", data)
+	}
+}
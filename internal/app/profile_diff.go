@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"cli/internal/plugins"
+	"cli/internal/ui"
+)
+
+type profileSymbols struct {
+	Functions map[string]string
+	Aliases   map[string]string
+}
+
+type profileSymbolDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func (d profileSymbolDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// extractProfileSymbols collects function bodies (whitespace-normalized) and
+// alias targets so that two profiles can be compared symbol by symbol.
+func extractProfileSymbols(content string) profileSymbols {
+	out := profileSymbols{Functions: map[string]string{}, Aliases: map[string]string{}}
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := psFunctionName.FindStringSubmatch(line); len(m) == 2 {
+			name := strings.ToLower(m[1])
+			var body []string
+			end := plugins.FunctionEnd(lines, i)
+			for _, l := range lines[i : end+1] {
+				if l = strings.Join(strings.Fields(l), " "); l != "" {
+					body = append(body, l)
+				}
+			}
+			i = end
+			if _, seen := out.Functions[name]; !seen {
+				out.Functions[name] = strings.Join(body, "\n")
+			}
+			continue
+		}
+		if m := psSetAlias.FindStringSubmatch(line); len(m) == 3 {
+			out.Aliases[strings.ToLower(m[1])] = strings.ToLower(m[2])
+		}
+	}
+	return out
+}
+
+// diffProfileSymbols reports what replacing current with incoming would do.
+func diffProfileSymbols(current, incoming profileSymbols) profileSymbolDiff {
+	var d profileSymbolDiff
+	compare := func(kind string, cur, inc map[string]string) {
+		for name, body := range inc {
+			old, ok := cur[name]
+			switch {
+			case !ok:
+				d.Added = append(d.Added, kind+" "+name)
+			case old != body:
+				d.Changed = append(d.Changed, kind+" "+name)
+			}
+		}
+		for name := range cur {
+			if _, ok := inc[name]; !ok {
+				d.Removed = append(d.Removed, kind+" "+name)
+			}
+		}
+	}
+	compare("function", current.Functions, incoming.Functions)
+	compare("alias", current.Aliases, incoming.Aliases)
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	return d
+}
+
+func diffProfileFiles(targetPath, sourcePath string) (profileSymbolDiff, error) {
+	incoming, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return profileSymbolDiff{}, err
+	}
+	current, err := os.ReadFile(targetPath)
+	if err != nil && !os.IsNotExist(err) {
+		return profileSymbolDiff{}, err
+	}
+	return diffProfileSymbols(extractProfileSymbols(string(current)), extractProfileSymbols(string(incoming))), nil
+}
+
+func printProfileSymbolDiff(d profileSymbolDiff) {
+	if d.Empty() {
+		fmt.Println("No symbol differences.")
+		return
+	}
+	for _, s := range d.Added {
+		fmt.Println(ui.OK("+ " + s))
+	}
+	for _, s := range d.Changed {
+		fmt.Println(ui.Warn("~ " + s))
+	}
+	for _, s := range d.Removed {
+		fmt.Println(ui.Error("- " + s))
+	}
+	fmt.Println(ui.Muted(fmt.Sprintf("%d added, %d changed, %d removed", len(d.Added), len(d.Changed), len(d.Removed))))
+}
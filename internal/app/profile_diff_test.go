@@ -0,0 +1,56 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffProfileSymbols(t *testing.T) {
+	current := extractProfileSymbols(`function keep { "same" }
+function Edit-Me {
+    Write-Host "old"
+}
+function gone { }
+Set-Alias ll Get-ChildItem
+`)
+	incoming := extractProfileSymbols(`function keep {  "same"  }
+function edit-me {
+  Write-Host "new"
+}
+function fresh {
+    if ($true) { "nested" }
+}
+Set-Alias ll Get-ChildItem
+Set-Alias g git
+`)
+	d := diffProfileSymbols(current, incoming)
+	if !reflect.DeepEqual(d.Added, []string{"alias g", "function fresh"}) {
+		t.Fatalf("unexpected added: %v", d.Added)
+	}
+	if !reflect.DeepEqual(d.Changed, []string{"function edit-me"}) {
+		t.Fatalf("unexpected changed: %v", d.Changed)
+	}
+	if !reflect.DeepEqual(d.Removed, []string{"function gone"}) {
+		t.Fatalf("unexpected removed: %v", d.Removed)
+	}
+}
+
+func TestExtractProfileSymbolsMultilineBody(t *testing.T) {
+	s := extractProfileSymbols("function a {\n  if ($x) {\n    1\n  }\n}\nfunction b { 2 }\n")
+	if len(s.Functions) != 2 {
+		t.Fatalf("expected two functions, got %v", s.Functions)
+	}
+	if s.Functions["a"] != "function a {\nif ($x) {\n1\n}\n}" {
+		t.Fatalf("unexpected body for a: %q", s.Functions["a"])
+	}
+}
+
+func TestExtractProfileSymbolsBraceInString(t *testing.T) {
+	s := extractProfileSymbols("function gs {\n  Write-Host \"}\" # {\n  git status\n}\nfunction gl { git log }\n")
+	if got := s.Functions["gs"]; got != "function gs {\nWrite-Host \"}\" # {\ngit status\n}" {
+		t.Fatalf("unexpected gs body %q", got)
+	}
+	if got := s.Functions["gl"]; got != "function gl { git log }" {
+		t.Fatalf("unexpected gl body %q", got)
+	}
+}
@@ -3,11 +3,13 @@ package app
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strings"
+
+	"cli/internal/platform"
 )
 
 var psFunctionName = regexp.MustCompile(`(?i)^\s*function\s+([a-z0-9_-]+)\b`)
@@ -18,15 +20,29 @@ func resolveUserPowerShellProfilePath() string {
 	if strings.TrimSpace(home) == "" {
 		return ""
 	}
-	ps7 := filepath.Join(home, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1")
-	if fileExists(ps7) {
-		return ps7
+	candidates := powerShellProfileCandidates(home, runtime.GOOS)
+	for _, p := range candidates {
+		if fileExists(p) {
+			return p
+		}
+	}
+	return candidates[0]
+}
+
+// powerShellProfileCandidates lists the CurrentUserCurrentHost profile
+// paths for pwsh (and Windows PowerShell on Windows), preferred first.
+func powerShellProfileCandidates(home, goos string) []string {
+	if goos == "windows" {
+		return []string{
+			filepath.Join(home, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"),
+			filepath.Join(home, "Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1"),
+		}
 	}
-	winPS := filepath.Join(home, "Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1")
-	if fileExists(winPS) {
-		return winPS
+	configDir := filepath.Join(home, ".config")
+	if xdg := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); xdg != "" {
+		configDir = xdg
 	}
-	return ps7
+	return []string{filepath.Join(configDir, "powershell", "Microsoft.PowerShell_profile.ps1")}
 }
 
 func showPowerShellSymbols(path, label string) int {
@@ -107,12 +123,11 @@ func parsePowerShellSymbols(path string) ([]string, []string, error) {
 	return funcs, aliases, nil
 }
 
-func openInNotepad(path string) error {
-	cmd := exec.Command("notepad.exe", path)
-	return cmd.Start()
+func openInEditor(path string) error {
+	return platform.OpenInEditor(path)
 }
 
-func openUserPowerShellProfileInNotepad() error {
+func openUserPowerShellProfileInEditor() error {
 	dst := resolveUserPowerShellProfilePath()
 	if strings.TrimSpace(dst) == "" {
 		return fmt.Errorf("PowerShell profile path is not available")
@@ -125,5 +140,5 @@ func openUserPowerShellProfileInNotepad() error {
 			return err
 		}
 	}
-	return openInNotepad(dst)
+	return openInEditor(dst)
 }
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli/internal/platform"
+	"cli/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+var profileSyncShells = []string{"powershell", "bash", "zsh"}
+
+const (
+	dmPackSnippetBegin = "# >>> dm pack "
+	dmPackSnippetEnd   = "# <<< dm pack "
+)
+
+func posixRCPath(home, shell string) string {
+	switch shell {
+	case "bash":
+		return filepath.Join(home, ".bashrc")
+	case "zsh":
+		if zdot := strings.TrimSpace(os.Getenv("ZDOTDIR")); zdot != "" {
+			return filepath.Join(zdot, ".zshrc")
+		}
+		return filepath.Join(home, ".zshrc")
+	}
+	return ""
+}
+
+// renderPosixAliasesBlock maps each dm alias to `dm alias run`, since the
+// stored commands are PowerShell and cannot be evaluated by bash/zsh, and
+// adds dmj, which changes to the folder `dm jump` prints.
+func renderPosixAliasesBlock(aliases map[string]string, exe string) string {
+	var b strings.Builder
+	b.WriteString(dmAliasProfileBegin + "\n")
+	for _, k := range sortedAliasNames(aliases) {
+		if strings.TrimSpace(aliases[k]) == "" {
+			continue
+		}
+		b.WriteString("alias " + k + "=" + posixSingleQuote(posixSingleQuote(exe)+" alias run "+k) + "\n")
+	}
+	b.WriteString("dmj() { _dm_target=\"$(" + posixSingleQuote(exe) + " jump \"$@\")\" && [ -n \"$_dm_target\" ] && cd \"$_dm_target\"; }\n")
+	b.WriteString(dmAliasProfileEnd + "\n")
+	return b.String()
+}
+
+// renderPackSnippetBlock wraps a pack's snippet in markers naming the pack,
+// so the next sync can replace or drop it.
+func renderPackSnippetBlock(sn plugins.ShellSnippet) string {
+	return dmPackSnippetBegin + sn.Pack + " >>>\n" + ensureTrailingNewline(sn.Content) + dmPackSnippetEnd + sn.Pack + " <<<\n"
+}
+
+// replacePackSnippetBlocks drops every pack block from content and appends
+// the current ones, so packs that no longer ship a snippet lose theirs.
+func replacePackSnippetBlocks(content string, snippets []plugins.ShellSnippet) string {
+	var kept []string
+	inBlock := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !inBlock && strings.HasPrefix(trimmed, dmPackSnippetBegin):
+			inBlock = true
+		case inBlock && strings.HasPrefix(trimmed, dmPackSnippetEnd):
+			inBlock = false
+		case !inBlock:
+			kept = append(kept, line)
+		}
+	}
+	out := strings.TrimRight(strings.Join(kept, ""), "\r\n")
+	for _, sn := range snippets {
+		if out != "" {
+			out += "\n\n"
+		}
+		out += strings.TrimRight(renderPackSnippetBlock(sn), "\n")
+	}
+	return ensureTrailingNewline(out)
+}
+
+// syncPosixRC rewrites the dm alias block and the pack snippet blocks of a
+// bash or zsh rc file in one atomic write.
+func syncPosixRC(rc, aliasBlock string, snippets []plugins.ShellSnippet) error {
+	if err := os.MkdirAll(filepath.Dir(rc), 0755); err != nil {
+		return err
+	}
+	existing := ""
+	if raw, err := os.ReadFile(rc); err == nil {
+		existing = string(raw)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	updated := replacePackSnippetBlocks(upsertAskAliasesProfileBlock(existing, aliasBlock), snippets)
+	return platform.WriteFileAtomic(rc, []byte(updated), 0644)
+}
+
+func posixSingleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// syncProfiles writes the managed alias block into the profiles of the
+// requested shells, plus a block per pack that ships a .bashrc/.zshrc
+// snippet, and returns the files it updated. With "all", bash and zsh rc
+// files are only touched when they already exist.
+func syncProfiles(baseDir, home, shell, exe string) ([]string, error) {
+	aliases, err := loadAskAliases(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	shells := profileSyncShells
+	if shell != "all" {
+		shells = []string{shell}
+	}
+	targets := askAliasProfilePathsResolver()
+	for _, sh := range shells {
+		targets = append(targets, posixRCPath(home, sh))
+	}
+	release, err := platform.AcquirePathLock("profile sync", targets...)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	var written []string
+	for _, sh := range shells {
+		if sh == "powershell" {
+			if err := syncAskAliasesToProfile(aliases); err != nil {
+				return written, err
+			}
+			written = append(written, askAliasProfilePathsResolver()...)
+			continue
+		}
+		rc := posixRCPath(home, sh)
+		if rc == "" {
+			return written, fmt.Errorf("unsupported shell: %s (use powershell|bash|zsh|all)", sh)
+		}
+		if shell == "all" && !fileExists(rc) {
+			continue
+		}
+		snippets, err := plugins.ShellSnippets(baseDir, sh)
+		if err != nil {
+			return written, err
+		}
+		if err := syncPosixRC(rc, renderPosixAliasesBlock(aliases, exe), snippets); err != nil {
+			return written, err
+		}
+		written = append(written, rc)
+	}
+	return written, nil
+}
+
+func newProfileCommand() *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Sync and compare shell profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	var shell string
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Write dm aliases to PowerShell, bash and zsh profiles",
+		Long: "Rewrites the managed dm block in the PowerShell profile (Windows Documents layout or ~/.config/powershell) " +
+			"and in ~/.bashrc / ~/.zshrc when present. A pack that ships <name>.bashrc or <name>.zshrc next to its toolkit in plugins/ " +
+			"gets its own block in that rc file; blocks of packs that no longer ship one are removed.",
+		Example: "dm profile sync\ndm profile sync --shell zsh",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rt, err := loadRuntime()
+			if err != nil {
+				return err
+			}
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return err
+			}
+			exe, err := os.Executable()
+			if err != nil {
+				exe = "dm"
+			}
+			written, err := syncProfiles(rt.BaseDir, home, strings.ToLower(strings.TrimSpace(shell)), exe)
+			if err != nil {
+				return err
+			}
+			if len(written) == 0 {
+				fmt.Println("No profiles to update.")
+				return nil
+			}
+			fmt.Println("Profiles synced:")
+			for _, p := range written {
+				fmt.Printf("- %s\n", p)
+			}
+			return nil
+		},
+	}
+	syncCmd.Flags().StringVar(&shell, "shell", "all", "shell profile to update: all|powershell|bash|zsh")
+	profileCmd.AddCommand(syncCmd)
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <source>",
+		Short: "Compare $PROFILE symbols with a profile script",
+		Long: "Lists functions and aliases that copying the source script " +
+			"over $PROFILE would add (+), change (~) or remove (-).",
+		Example: "dm profile diff my_profile.ps1",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			source := strings.TrimSpace(args[0])
+			target := resolveUserPowerShellProfilePath()
+			d, err := diffProfileFiles(target, source)
+			if err != nil {
+				return err
+			}
+			fmt.Println("Source :", source)
+			fmt.Println("Target :", target)
+			fmt.Println()
+			printProfileSymbolDiff(d)
+			return nil
+		},
+	}
+	profileCmd.AddCommand(diffCmd)
+	return profileCmd
+}
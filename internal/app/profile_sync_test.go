@@ -0,0 +1,108 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncProfilesWritesExistingPosixRC(t *testing.T) {
+	baseDir := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("ZDOTDIR", "")
+	if err := os.WriteFile(askAliasFilePath(baseDir), []byte(`{"ll":"Get-ChildItem -Force"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bashrc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("export A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	psProfile := filepath.Join(home, "profile.ps1")
+	prev := askAliasProfilePathsResolver
+	askAliasProfilePathsResolver = func() []string { return []string{psProfile} }
+	defer func() { askAliasProfilePathsResolver = prev }()
+
+	written, err := syncProfiles(baseDir, home, "all", "/opt/dm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 2 || written[0] != psProfile || written[1] != bashrc {
+		t.Fatalf("expected PowerShell profile and .bashrc, got %v", written)
+	}
+	if fileExists(filepath.Join(home, ".zshrc")) {
+		t.Fatal("expected missing .zshrc to be left alone")
+	}
+	data, err := os.ReadFile(bashrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `alias ll=''\''/opt/dm'\'' alias run ll'`
+	if !strings.HasPrefix(string(data), "export A=1\n") || !strings.Contains(string(data), want) || !strings.Contains(string(data), "dmj() {") {
+		t.Fatalf("unexpected .bashrc:\n%s", data)
+	}
+
+	if _, err := syncProfiles(baseDir, home, "fish", "/opt/dm"); err == nil {
+		t.Fatal("expected unsupported shell error")
+	}
+}
+
+func TestPowerShellProfileCandidatesUnix(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "")
+	home := t.TempDir()
+	got := powerShellProfileCandidates(home, "linux")
+	want := filepath.Join(home, ".config", "powershell", "Microsoft.PowerShell_profile.ps1")
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("expected %s, got %v", want, got)
+	}
+}
+
+func TestSyncProfilesWritesPackSnippets(t *testing.T) {
+	baseDir := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	prev := askAliasProfilePathsResolver
+	askAliasProfilePathsResolver = func() []string { return nil }
+	defer func() { askAliasProfilePathsResolver = prev }()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(baseDir, "plugins", "Docker_Toolkit.bashrc"), "alias dps='docker ps'\n")
+	write(filepath.Join(baseDir, "plugins", "M365", "Auth_Toolkit.bashrc"), "export M365_TENANT=contoso")
+	write(filepath.Join(baseDir, "plugins", "Docker_Toolkit.zshrc"), "alias dz='docker ps'\n")
+	bashrc := filepath.Join(home, ".bashrc")
+	write(bashrc, "export A=1\n")
+
+	for i := 0; i < 2; i++ {
+		if _, err := syncProfiles(baseDir, home, "bash", "/opt/dm"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(bashrc)
+	got := string(data)
+	dockerBlock := "# >>> dm pack Docker_Toolkit >>>\nalias dps='docker ps'\n# <<< dm pack Docker_Toolkit <<<\n"
+	authBlock := "# >>> dm pack M365/Auth_Toolkit >>>\nexport M365_TENANT=contoso\n# <<< dm pack M365/Auth_Toolkit <<<\n"
+	if strings.Count(got, dockerBlock) != 1 || strings.Count(got, authBlock) != 1 {
+		t.Fatalf("expected one block per pack after two syncs:\n%s", got)
+	}
+	if strings.Contains(got, "dz=") || !strings.HasPrefix(got, "export A=1\n") {
+		t.Fatalf("unexpected .bashrc:\n%s", got)
+	}
+
+	if err := os.Remove(filepath.Join(baseDir, "plugins", "Docker_Toolkit.bashrc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := syncProfiles(baseDir, home, "bash", "/opt/dm"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(bashrc)
+	if strings.Contains(string(data), "Docker_Toolkit") || !strings.Contains(string(data), authBlock) {
+		t.Fatalf("expected the removed pack's block dropped:\n%s", data)
+	}
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"cli/internal/history"
+	"cli/internal/ui"
+)
+
+func exitCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
+// formatLastRun renders a short status such as "[ok 2h ago]" for menus.
+func formatLastRun(rec history.Record, ok bool, now time.Time) string {
+	if !ok || rec.LastRun.IsZero() {
+		return ""
+	}
+	age := formatAge(now.Sub(rec.LastRun))
+	if rec.LastCode == 0 {
+		return ui.Muted("[ok " + age + " ago]")
+	}
+	return ui.Error(fmt.Sprintf("[exit %d %s ago]", rec.LastCode, age))
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cli/internal/history"
+)
+
+func TestFormatLastRun(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rec := history.Record{Count: 2, LastCode: 2, LastRun: now}
+	if got := formatLastRun(rec, true, now.Add(3*time.Hour)); !strings.Contains(got, "exit 2 3h ago") {
+		t.Fatalf("unexpected status %q", got)
+	}
+	if got := formatLastRun(history.Record{}, false, now); got != "" {
+		t.Fatalf("expected no status for a function never run, got %q", got)
+	}
+}
+
+func TestExitCodeOf(t *testing.T) {
+	if exitCodeOf(nil) != 0 {
+		t.Fatal("expected 0 for nil error")
+	}
+	if exitCodeOf(errors.New("boom")) != 1 {
+		t.Fatal("expected 1 for generic error")
+	}
+}
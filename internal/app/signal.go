@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+
+	"cli/internal/platform"
+	"cli/internal/ui"
 )
 
 var (
@@ -19,15 +22,53 @@ func setupSignalHandler() {
 	signalOnce.Do(func() {
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, os.Interrupt)
+		cleanupMu.Lock()
+		cleanupFuncs = append(cleanupFuncs, platform.ReleasePathLocks)
+		cleanupMu.Unlock()
 		go func() {
-			<-sigCh
-			fmt.Fprintln(os.Stderr, "\nInterrupted. Cleaning up...")
-			runCleanup()
-			os.Exit(130)
+			for range sigCh {
+				if hook := currentInterruptHook(); hook != nil {
+					hook()
+					continue
+				}
+				exitInterrupted()
+			}
 		}()
+		ui.ExitInterrupted = exitInterrupted
 	})
 }
 
+func exitInterrupted() {
+	fmt.Fprintln(os.Stderr, "\nInterrupted. Cleaning up...")
+	runCleanup()
+	os.Exit(130)
+}
+
+var (
+	interruptHook   func()
+	interruptHookMu sync.Mutex
+)
+
+// onInterrupt routes Ctrl-C to fn instead of exiting until the returned
+// release func is called.
+func onInterrupt(fn func()) func() {
+	interruptHookMu.Lock()
+	prev := interruptHook
+	interruptHook = fn
+	interruptHookMu.Unlock()
+	return func() {
+		interruptHookMu.Lock()
+		interruptHook = prev
+		interruptHookMu.Unlock()
+	}
+}
+
+func currentInterruptHook() func() {
+	interruptHookMu.Lock()
+	defer interruptHookMu.Unlock()
+	return interruptHook
+}
+
 func runCleanup() {
 	cleanupMu.Lock()
 	fns := make([]func(), len(cleanupFuncs))
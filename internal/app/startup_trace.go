@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const traceStartupFlag = "--trace-startup"
+
+var traceNow = time.Now
+
+type startupPhase struct {
+	name string
+	took time.Duration
+}
+
+// startupTracer records how long each startup phase took. It is switched on
+// before cobra parses anything, so the flag is looked up in the raw args.
+type startupTracer struct {
+	enabled bool
+	start   time.Time
+	last    time.Time
+	phases  []startupPhase
+}
+
+var startupTrace startupTracer
+
+func (t *startupTracer) begin(args []string) {
+	*t = startupTracer{enabled: traceStartupRequested(args)}
+	if t.enabled {
+		t.start = traceNow()
+		t.last = t.start
+	}
+}
+
+// traceStartupRequested reads --trace-startup the way the bool flag parses
+// it: bare or as --trace-startup=<bool>, the last one winning, and nothing
+// after "--".
+func traceStartupRequested(args []string) bool {
+	enabled := false
+	for _, a := range args {
+		if a == "--" {
+			break
+		}
+		if a == traceStartupFlag {
+			enabled = true
+			continue
+		}
+		if v, ok := strings.CutPrefix(a, traceStartupFlag+"="); ok {
+			if b, err := strconv.ParseBool(v); err == nil {
+				enabled = b
+			}
+		}
+	}
+	return enabled
+}
+
+// mark closes the phase that ended now.
+func (t *startupTracer) mark(phase string) {
+	if !t.enabled {
+		return
+	}
+	now := traceNow()
+	t.phases = append(t.phases, startupPhase{name: phase, took: now.Sub(t.last)})
+	t.last = now
+}
+
+func (t *startupTracer) report(w io.Writer) {
+	if !t.enabled {
+		return
+	}
+	t.mark("command")
+	fmt.Fprintln(w, "startup trace:")
+	for _, p := range t.phases {
+		fmt.Fprintf(w, "  %-16s %8.1fms\n", p.name, durationMS(p.took))
+	}
+	fmt.Fprintf(w, "  %-16s %8.1fms\n", "total", durationMS(t.last.Sub(t.start)))
+}
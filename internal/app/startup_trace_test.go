@@ -0,0 +1,64 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartupTraceDisabledByDefault(t *testing.T) {
+	var tr startupTracer
+	tr.begin([]string{"tools", "search"})
+	tr.mark("cobra build")
+	var out strings.Builder
+	tr.report(&out)
+	if out.Len() != 0 || len(tr.phases) != 0 {
+		t.Fatalf("expected no trace, got %q", out.String())
+	}
+}
+
+func TestStartupTraceReportsPhases(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	old := traceNow
+	traceNow = func() time.Time {
+		clock = clock.Add(2 * time.Millisecond)
+		return clock
+	}
+	defer func() { traceNow = old }()
+
+	var tr startupTracer
+	tr.begin([]string{"--trace-startup", "tools"})
+	tr.mark("cobra build")
+	tr.mark("config load")
+	var out strings.Builder
+	tr.report(&out)
+
+	got := out.String()
+	for _, want := range []string{"cobra build", "config load", "command", "total"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in trace, got:\n%s", want, got)
+		}
+	}
+	if !strings.Contains(got, "6.0ms") {
+		t.Fatalf("expected 6ms total, got:\n%s", got)
+	}
+}
+
+func TestTraceStartupRequested(t *testing.T) {
+	cases := map[string]bool{
+		"tools":                                 false,
+		"--trace-startup tools":                 true,
+		"--trace-startup=true tools":            true,
+		"--trace-startup=1":                     true,
+		"--trace-startup=false tools":           false,
+		"--trace-startup --trace-startup=false": false,
+		"plugins run x -- --trace-startup":      false,
+		"--trace-startup=maybe":                 false,
+		"--trace-startupx":                      false,
+	}
+	for args, want := range cases {
+		if got := traceStartupRequested(strings.Fields(args)); got != want {
+			t.Fatalf("%q: got %v, want %v", args, got, want)
+		}
+	}
+}
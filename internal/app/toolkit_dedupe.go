@@ -0,0 +1,111 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli/internal/plugins"
+	"cli/internal/ui"
+)
+
+func relToBaseDir(baseDir, path string) string {
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// runToolkitDedupe reports functions shadowed by a same-named definition
+// in another file and, on a terminal, offers to remove or rename each
+// shadowed copy. It exits 1 while duplicates remain.
+func runToolkitDedupe(baseDir string, asJSON, reportOnly bool) int {
+	dups, err := plugins.FindDuplicateFunctions(baseDir)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if asJSON {
+		if dups == nil {
+			dups = []plugins.Duplicate{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(dups)
+		if len(dups) > 0 {
+			return 1
+		}
+		return 0
+	}
+	printToolkitDuplicates(baseDir, dups)
+	if len(dups) == 0 {
+		return 0
+	}
+	if reportOnly || !ui.StdinIsTerminal() {
+		return 1
+	}
+	fmt.Println()
+	if kept := resolveToolkitDuplicates(baseDir, dups, bufio.NewReader(os.Stdin)); kept > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printToolkitDuplicates(baseDir string, dups []plugins.Duplicate) {
+	if len(dups) == 0 {
+		fmt.Println(ui.OK("No duplicate functions."))
+		return
+	}
+	for _, d := range dups {
+		fmt.Println(ui.Accent(d.Name))
+		fmt.Printf("  %s %s\n", ui.OK("uses"), relToBaseDir(baseDir, d.Active))
+		for _, s := range d.Shadowed {
+			fmt.Printf("  %s %s\n", ui.Warn("shadowed"), relToBaseDir(baseDir, s))
+		}
+	}
+	fmt.Println(ui.Muted(fmt.Sprintf("%d duplicate function(s)", len(dups))))
+}
+
+// resolveToolkitDuplicates asks what to do with every shadowed copy and
+// returns how many were kept.
+func resolveToolkitDuplicates(baseDir string, dups []plugins.Duplicate, in *bufio.Reader) int {
+	removed, renamed, kept := 0, 0, 0
+	for _, d := range dups {
+		for _, path := range d.Shadowed {
+			rel := relToBaseDir(baseDir, path)
+			fmt.Print(ui.Prompt(fmt.Sprintf("%s in %s: [r]emove, re[n]ame, [s]kip? ", d.Name, rel)))
+			var err error
+			switch strings.ToLower(readLine(in)) {
+			case "r", "remove":
+				if err = plugins.RemoveFunction(path, d.Name); err == nil {
+					fmt.Println(ui.OK("Removed " + d.Name + " from " + rel))
+					removed++
+					continue
+				}
+			case "n", "rename":
+				fmt.Print(ui.Prompt("New name: "))
+				newName := strings.TrimSpace(readLine(in))
+				if newName == "" {
+					kept++
+					continue
+				}
+				if err = plugins.RenameFunction(baseDir, path, d.Name, newName); err == nil {
+					fmt.Println(ui.OK("Renamed " + d.Name + " to " + newName + " in " + rel))
+					renamed++
+					continue
+				}
+			default:
+				kept++
+				continue
+			}
+			fmt.Println(ui.Error("Error: " + err.Error()))
+			kept++
+		}
+	}
+	fmt.Println(ui.Muted(fmt.Sprintf("%d removed, %d renamed, %d kept", removed, renamed, kept)))
+	return kept
+}
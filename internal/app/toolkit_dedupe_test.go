@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli/internal/plugins"
+)
+
+func TestResolveToolkitDuplicates(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	baseDir := t.TempDir()
+	pluginsDir := filepath.Join(baseDir, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, body string) {
+		if err := os.WriteFile(filepath.Join(pluginsDir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("A_Toolkit.ps1", "function x_one { }\nfunction x_two { }\nfunction x_three { }\n")
+	write("B_Toolkit.ps1", "function x_one { }\n\nfunction x_two { }\n\nfunction x_three { }\n")
+
+	dups, err := plugins.FindDuplicateFunctions(baseDir)
+	if err != nil || len(dups) != 3 {
+		t.Fatalf("expected 3 duplicates, got %+v (err %v)", dups, err)
+	}
+	in := bufio.NewReader(strings.NewReader("r\nn\nx_two_b\ns\n"))
+	if kept := resolveToolkitDuplicates(baseDir, dups, in); kept != 1 {
+		t.Fatalf("expected 1 kept, got %d", kept)
+	}
+	data, _ := os.ReadFile(filepath.Join(pluginsDir, "B_Toolkit.ps1"))
+	text := string(data)
+	if strings.Contains(text, "x_one") || !strings.Contains(text, "function x_two_b") || !strings.Contains(text, "function x_three") {
+		t.Fatalf("unexpected shadowed file:\n%s", text)
+	}
+	dups, _ = plugins.FindDuplicateFunctions(baseDir)
+	if len(dups) != 1 || dups[0].Name != "x_three" {
+		t.Fatalf("expected only x_three left, got %+v", dups)
+	}
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli/internal/agent"
+	"cli/internal/plugins"
+	"cli/internal/ui"
+)
+
+var toolkitFunctionBuilder = agent.BuildFunction
+
+type toolkitGenerateReport struct {
+	Toolkit   string                  `json:"toolkit"`
+	Path      string                  `json:"path"`
+	Created   bool                    `json:"created"`
+	Functions []toolkitGenerateResult `json:"functions"`
+}
+
+type toolkitGenerateResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (r toolkitGenerateReport) count(status string) int {
+	n := 0
+	for _, f := range r.Functions {
+		if f.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// toolkitSpecPath is where the spec's toolkit lives:
+// plugins/[<category>/]<Name>_Toolkit.ps1.
+func toolkitSpecPath(baseDir string, spec toolkitSpec) string {
+	return filepath.Join(baseDir, "plugins", spec.Category, spec.Name+"_Toolkit.ps1")
+}
+
+// generateToolkitFromSpec builds every function in the spec, then writes the
+// ones that passed validation in one go. Functions already defined in an
+// existing toolkit file are skipped.
+func generateToolkitFromSpec(baseDir string, spec toolkitSpec, opts agent.AskOptions, show bool) (toolkitGenerateReport, error) {
+	path := toolkitSpecPath(baseDir, spec)
+	report := toolkitGenerateReport{Toolkit: spec.Name, Path: path}
+	_, statErr := os.Stat(path)
+	exists := statErr == nil
+
+	summaries := listToolkitSummaries(baseDir)
+	var codes []string
+	var names []string
+	for i, f := range spec.Functions {
+		res := toolkitGenerateResult{Name: f.Name}
+		if exists {
+			if _, err := plugins.FunctionSource(path, f.Name); err == nil {
+				res.Status = "skipped"
+				res.Error = "already defined in " + path
+				report.Functions = append(report.Functions, res)
+				continue
+			}
+		}
+		req := agent.BuilderRequest{
+			FunctionDescription: f.Description,
+			ExistingToolkits:    summaries,
+			UserRequest:         fmt.Sprintf("Batch generation of the %s toolkit (prefix %s_).", spec.Name, spec.Prefix),
+			FunctionName:        f.Name,
+			Params:              f.Params,
+		}
+		label := fmt.Sprintf("Generating %s (%d/%d)...", f.Name, i+1, len(spec.Functions))
+		built, err := runLLMCall(label, llmLabel(opts), show, func(callCtx context.Context) (agent.BuilderResult, error) {
+			callOpts := opts
+			callOpts.Context = callCtx
+			return toolkitFunctionBuilder(req, callOpts)
+		})
+		switch {
+		case errors.Is(err, errLLMCanceled):
+			return report, err
+		case err != nil:
+			res.Error = err.Error()
+		case !strings.EqualFold(strings.TrimSpace(built.FunctionName), f.Name):
+			res.Error = fmt.Sprintf("builder named the function %q", built.FunctionName)
+		default:
+			err = validatePowerShellSyntax(built.FunctionCode)
+			switch {
+			case errors.Is(err, errSyntaxNotValidated):
+				res.Status = "unvalidated"
+				res.Error = err.Error()
+			case err != nil:
+				res.Error = err.Error()
+			}
+		}
+		if res.Status == "" && res.Error != "" {
+			res.Status = "failed"
+		} else {
+			if res.Status == "" {
+				res.Status = "ok"
+			}
+			codes = append(codes, built.FunctionCode)
+			names = append(names, f.Name)
+		}
+		report.Functions = append(report.Functions, res)
+	}
+	if len(codes) == 0 {
+		return report, nil
+	}
+
+	if !exists {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return report, err
+		}
+		if _, err := createNewToolkit(filepath.Dir(path), spec.Name, spec.Prefix, codes[0]); err != nil {
+			return report, fmt.Errorf("writing toolkit: %w", err)
+		}
+		report.Created = true
+		codes, names = codes[1:], names[1:]
+	}
+	for i, code := range codes {
+		if err := appendFunctionToToolkit(path, code); err != nil {
+			return report, fmt.Errorf("writing %s: %w", names[i], err)
+		}
+		_ = updateToolkitFunctionsIndex(path, names[i])
+	}
+	plugins.ResetCaches()
+	return report, nil
+}
+
+func runToolkitGenerate(baseDir, specPath string, opts agent.AskOptions, jsonOut bool) int {
+	spec, err := loadToolkitSpec(specPath)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	session, err := agent.ResolveSessionProvider(opts)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	report, err := generateToolkitFromSpec(baseDir, spec, session.Options, !jsonOut)
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		printToolkitGenerateReport(report)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if report.count("failed") > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printToolkitGenerateReport(r toolkitGenerateReport) {
+	fmt.Println()
+	fmt.Println(ui.Accent("Toolkit " + r.Toolkit))
+	for _, f := range r.Functions {
+		switch f.Status {
+		case "ok":
+			fmt.Println("  " + ui.OK("ok") + "      " + f.Name)
+		case "unvalidated":
+			fmt.Println("  " + ui.Warn("not validated") + " " + f.Name + ui.Muted(" ("+f.Error+")"))
+		case "skipped":
+			fmt.Println("  " + ui.Muted("skipped") + " " + f.Name + ui.Muted(" ("+f.Error+")"))
+		default:
+			fmt.Println("  " + ui.Error("failed") + "  " + f.Name + ": " + f.Error)
+		}
+	}
+	written := r.count("ok") + r.count("unvalidated")
+	switch {
+	case written == 0:
+		fmt.Println(ui.Muted("Nothing written."))
+	case r.Created:
+		fmt.Println(ui.OK(fmt.Sprintf("Created %s with %d function(s).", r.Path, written)))
+	default:
+		fmt.Println(ui.OK(fmt.Sprintf("Added %d function(s) to %s.", written, r.Path)))
+	}
+	if unvalidated := r.count("unvalidated"); unvalidated > 0 {
+		fmt.Println(ui.Warn(fmt.Sprintf("%d function(s) written without a syntax check; install pwsh to validate generated code.", unvalidated)))
+	}
+	if failed := r.count("failed"); failed > 0 {
+		fmt.Println(ui.Warn(fmt.Sprintf("%d function(s) failed; fix the spec or rerun to retry them.", failed)))
+	}
+}
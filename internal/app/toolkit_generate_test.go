@@ -0,0 +1,114 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli/internal/agent"
+)
+
+func stubToolkitBuilder(t *testing.T, build func(req agent.BuilderRequest) (agent.BuilderResult, error)) {
+	t.Helper()
+	prev := toolkitFunctionBuilder
+	toolkitFunctionBuilder = func(req agent.BuilderRequest, opts agent.AskOptions) (agent.BuilderResult, error) {
+		return build(req)
+	}
+	t.Cleanup(func() { toolkitFunctionBuilder = prev })
+}
+
+func stubPwshMissing(t *testing.T) {
+	t.Helper()
+	prev := lookPwsh
+	lookPwsh = func() (string, error) { return "", errors.New("not found") }
+	t.Cleanup(func() { lookPwsh = prev })
+}
+
+func builtFunction(name string) string {
+	return fmt.Sprintf("<#\n.SYNOPSIS\nDo %s.\n#>\nfunction %s {\n    param()\n    return [pscustomobject]@{ Name = '%s' }\n}", name, name, name)
+}
+
+func TestGenerateToolkitFromSpecCreatesToolkit(t *testing.T) {
+	base := t.TempDir()
+	stubPwshMissing(t)
+	stubToolkitBuilder(t, func(req agent.BuilderRequest) (agent.BuilderResult, error) {
+		if req.FunctionName == "word_broken" {
+			return agent.BuilderResult{}, fmt.Errorf("model timeout")
+		}
+		return agent.BuilderResult{FunctionName: req.FunctionName, FunctionCode: builtFunction(req.FunctionName)}, nil
+	})
+	spec := toolkitSpec{Name: "MSWord", Prefix: "word", Category: "office", Functions: []toolkitSpecFunction{
+		{Name: "word_export_pdf", Description: "Export to PDF", Params: []string{"InputPath"}},
+		{Name: "word_broken", Description: "Fails"},
+		{Name: "word_count", Description: "Count words"},
+	}}
+	report, err := generateToolkitFromSpec(base, spec, agent.AskOptions{}, false)
+	if err != nil {
+		t.Fatalf("generateToolkitFromSpec: %v", err)
+	}
+	if !report.Created || report.count("unvalidated") != 2 || report.count("ok") != 0 || report.count("failed") != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	path := filepath.Join(base, "plugins", "office", "MSWord_Toolkit.ps1")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("toolkit not written: %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{"function word_export_pdf", "function word_count", "#   word_count", "Entry point: word_*"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("toolkit missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "word_broken") {
+		t.Fatalf("failed function should not be written:\n%s", text)
+	}
+}
+
+func TestGenerateToolkitFromSpecSkipsExistingAndChecksName(t *testing.T) {
+	base := t.TempDir()
+	stubPwshMissing(t)
+	path := filepath.Join(base, "plugins", "Net_Toolkit.ps1")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	existing := "# FUNCTIONS\n#   net_ping\n# ====\n\n" + builtFunction("net_ping") + "\n"
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	stubToolkitBuilder(t, func(req agent.BuilderRequest) (agent.BuilderResult, error) {
+		calls = append(calls, req.FunctionName)
+		name := req.FunctionName
+		if name == "net_trace" {
+			name = "net_traceroute"
+		}
+		return agent.BuilderResult{FunctionName: name, FunctionCode: builtFunction(name)}, nil
+	})
+	spec := toolkitSpec{Name: "Net", Prefix: "net", Functions: []toolkitSpecFunction{
+		{Name: "net_ping", Description: "Ping"},
+		{Name: "net_trace", Description: "Trace"},
+		{Name: "net_dns", Description: "Resolve"},
+	}}
+	report, err := generateToolkitFromSpec(base, spec, agent.AskOptions{}, false)
+	if err != nil {
+		t.Fatalf("generateToolkitFromSpec: %v", err)
+	}
+	if strings.Join(calls, ",") != "net_trace,net_dns" {
+		t.Fatalf("existing function should not be regenerated, calls: %v", calls)
+	}
+	statuses := []string{}
+	for _, f := range report.Functions {
+		statuses = append(statuses, f.Status)
+	}
+	if report.Created || strings.Join(statuses, ",") != "skipped,failed,unvalidated" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "function net_dns") || strings.Contains(string(data), "net_traceroute") {
+		t.Fatalf("unexpected toolkit content:\n%s", data)
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// toolkitSpec is a toolkit described up front in a spec file, generated in
+// one batch by dm toolkit generate.
+type toolkitSpec struct {
+	Name      string                `json:"name"`
+	Prefix    string                `json:"prefix"`
+	Category  string                `json:"category"`
+	Functions []toolkitSpecFunction `json:"functions"`
+}
+
+type toolkitSpecFunction struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Params      []string `json:"params"`
+}
+
+var (
+	toolkitSpecNameRe  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	toolkitSpecParamRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+)
+
+// loadToolkitSpec reads a JSON spec. Unknown keys are an error, so a typo
+// does not silently drop a setting.
+func loadToolkitSpec(path string) (toolkitSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return toolkitSpec{}, err
+	}
+	var spec toolkitSpec
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return toolkitSpec{}, fmt.Errorf("invalid spec %s (expected JSON): %w", path, err)
+	}
+	if dec.More() {
+		return toolkitSpec{}, fmt.Errorf("invalid spec %s: unexpected data after the JSON object", path)
+	}
+	if err := normalizeToolkitSpec(&spec); err != nil {
+		return toolkitSpec{}, fmt.Errorf("invalid spec %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// normalizeToolkitSpec validates the spec and puts every function name
+// into the <prefix>_<action> form.
+func normalizeToolkitSpec(spec *toolkitSpec) error {
+	spec.Name = strings.TrimSpace(spec.Name)
+	spec.Prefix = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(spec.Prefix), "_"))
+	spec.Category = strings.TrimSpace(spec.Category)
+	if !toolkitSpecNameRe.MatchString(spec.Name) {
+		return fmt.Errorf("name %q must be letters, digits or underscores", spec.Name)
+	}
+	if !toolkitSpecNameRe.MatchString(spec.Prefix) {
+		return fmt.Errorf("prefix %q must be letters, digits or underscores", spec.Prefix)
+	}
+	if spec.Category != "" && !toolkitSpecNameRe.MatchString(spec.Category) {
+		return fmt.Errorf("category %q must be a single folder name", spec.Category)
+	}
+	if len(spec.Functions) == 0 {
+		return fmt.Errorf("no functions listed")
+	}
+	seen := map[string]bool{}
+	for i := range spec.Functions {
+		f := &spec.Functions[i]
+		f.Name = strings.ToLower(strings.TrimSpace(f.Name))
+		f.Description = strings.TrimSpace(f.Description)
+		if !toolkitSpecNameRe.MatchString(f.Name) {
+			return fmt.Errorf("function %d: invalid name %q", i+1, f.Name)
+		}
+		if !strings.HasPrefix(f.Name, spec.Prefix+"_") {
+			f.Name = spec.Prefix + "_" + f.Name
+		}
+		if f.Description == "" {
+			return fmt.Errorf("function %s: description is required", f.Name)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("function %s is listed twice", f.Name)
+		}
+		seen[f.Name] = true
+		for j, p := range f.Params {
+			p = strings.TrimPrefix(strings.TrimSpace(p), "$")
+			if !toolkitSpecParamRe.MatchString(p) {
+				return fmt.Errorf("function %s: invalid parameter name %q", f.Name, p)
+			}
+			f.Params[j] = p
+		}
+	}
+	return nil
+}
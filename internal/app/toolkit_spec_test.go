@@ -0,0 +1,83 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadToolkitSpec(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "msword.json")
+	spec := `{
+  "name": "MSWord",
+  "prefix": "word",
+  "category": "office",
+  "functions": [
+    {"name": "export_pdf", "description": "Export a document to PDF # keeps the layout", "params": ["InputPath", "$OutputPath"]},
+    {"name": "word_count", "description": "Count the words in a document", "params": ["Path"]},
+    {"description": "Open a document in Word", "name": "open"}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadToolkitSpec(path)
+	if err != nil {
+		t.Fatalf("loadToolkitSpec: %v", err)
+	}
+	if got.Name != "MSWord" || got.Prefix != "word" || got.Category != "office" || len(got.Functions) != 3 {
+		t.Fatalf("unexpected spec: %+v", got)
+	}
+	f := got.Functions[0]
+	if f.Name != "word_export_pdf" || f.Description != "Export a document to PDF # keeps the layout" || strings.Join(f.Params, ",") != "InputPath,OutputPath" {
+		t.Fatalf("unexpected first function: %+v", f)
+	}
+	if f := got.Functions[1]; f.Name != "word_count" || strings.Join(f.Params, ",") != "Path" {
+		t.Fatalf("unexpected second function: %+v", f)
+	}
+	if f := got.Functions[2]; f.Name != "word_open" || len(f.Params) != 0 {
+		t.Fatalf("unexpected third function: %+v", f)
+	}
+}
+
+func TestLoadToolkitSpecTrimsPrefix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "spec.json")
+	spec := `{"name":"Net","prefix":"net_","functions":[{"name":"ping","description":"Ping a host","params":["Host"]}]}`
+	if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadToolkitSpec(path)
+	if err != nil {
+		t.Fatalf("loadToolkitSpec: %v", err)
+	}
+	if got.Prefix != "net" || got.Functions[0].Name != "net_ping" {
+		t.Fatalf("unexpected spec: %+v", got)
+	}
+}
+
+func TestLoadToolkitSpecErrors(t *testing.T) {
+	cases := map[string]string{
+		"yaml":           "name: A\nprefix: a\nfunctions:\n  - name: x\n    description: d\n",
+		"unknown key":    `{"name":"A","prefix":"a","owner":"me","functions":[{"name":"x","description":"d"}]}`,
+		"unknown fn key": `{"name":"A","prefix":"a","functions":[{"name":"x","description":"d","param":["P"]}]}`,
+		"trailing data":  `{"name":"A","prefix":"a","functions":[{"name":"x","description":"d"}]} {}`,
+		"no functions":   `{"name":"A","prefix":"a"}`,
+		"no description": `{"name":"A","prefix":"a","functions":[{"name":"x"}]}`,
+		"duplicate":      `{"name":"A","prefix":"a","functions":[{"name":"x","description":"d"},{"name":"a_x","description":"d"}]}`,
+		"bad param":      `{"name":"A","prefix":"a","functions":[{"name":"x","description":"d","params":["file-path"]}]}`,
+		"bad toolkit":    `{"name":"My Toolkit","prefix":"a","functions":[{"name":"x","description":"d"}]}`,
+	}
+	dir := t.TempDir()
+	for name, spec := range cases {
+		path := filepath.Join(dir, "spec.json")
+		if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadToolkitSpec(path); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Package assets holds the default files dm writes when they are missing, so
+// a bare dm binary works without the release folder next to it.
+package assets
+
+import (
+	_ "embed"
+	"strings"
+	"text/template"
+)
+
+// AgentConfigExample is dm.agent.example.json as shipped in releases.
+//
+//go:embed dm.agent.example.json
+var AgentConfigExample string
+
+//go:embed toolkit.ps1.tmpl
+var toolkitHeaderSource string
+
+var toolkitHeader = template.Must(template.New("toolkit").Parse(toolkitHeaderSource))
+
+// ToolkitHeader is what a generated toolkit file starts with.
+type ToolkitHeader struct {
+	Title    string
+	Prefix   string
+	Function string
+}
+
+// RenderToolkitHeader returns the header of a new standalone toolkit, ending
+// where the public functions go.
+func RenderToolkitHeader(h ToolkitHeader) (string, error) {
+	var b strings.Builder
+	if err := toolkitHeader.Execute(&b, h); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
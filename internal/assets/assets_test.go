@@ -0,0 +1,37 @@
+package assets
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAgentConfigExampleMatchesReleaseCopy(t *testing.T) {
+	release, err := os.ReadFile("../../dm.agent.example.json")
+	if err != nil {
+		t.Fatalf("read release example: %v", err)
+	}
+	if string(release) != AgentConfigExample {
+		t.Fatal("internal/assets/dm.agent.example.json is out of sync with the root copy")
+	}
+	var v map[string]any
+	if err := json.Unmarshal([]byte(AgentConfigExample), &v); err != nil {
+		t.Fatalf("embedded example is not valid JSON: %v", err)
+	}
+}
+
+func TestRenderToolkitHeader(t *testing.T) {
+	got, err := RenderToolkitHeader(ToolkitHeader{Title: "DISK TOOLS", Prefix: "disk", Function: "disk_usage"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# DISK TOOLS TOOLKIT", "# Entry point: disk_*", "#   disk_usage\n", "function _assert_path_exists"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in header:\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "# Public functions\n# -----------------------------------------------------------------------------\n\n") {
+		t.Fatalf("unexpected header tail:\n%s", got)
+	}
+}
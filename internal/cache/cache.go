@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"container/list"
+	"expvar"
+	"sync"
+)
+
+// Stat describes one in-process cache.
+type Stat struct {
+	Name      string `json:"name"`
+	Entries   int    `json:"entries"`
+	Limit     int    `json:"limit"`
+	Hits      int64  `json:"hits"`
+	Misses    int64  `json:"misses"`
+	Evictions int64  `json:"evictions"`
+}
+
+type registered interface {
+	Stat() Stat
+	Reset()
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []registered
+)
+
+func init() {
+	expvar.Publish("dm_caches", expvar.Func(func() any { return Stats() }))
+}
+
+// Stats returns the metrics of every cache created with New, in creation order.
+func Stats() []Stat {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	out := make([]Stat, 0, len(registry))
+	for _, c := range registry {
+		out = append(out, c.Stat())
+	}
+	return out
+}
+
+// ResetAll empties every cache and zeroes its counters.
+func ResetAll() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, c := range registry {
+		c.Reset()
+	}
+}
+
+// LRU is a concurrency-safe map holding at most limit entries; adding past
+// the limit evicts the least recently used one.
+type LRU[K comparable, V any] struct {
+	name  string
+	limit int
+
+	mu                      sync.Mutex
+	order                   *list.List
+	items                   map[K]*list.Element
+	hits, misses, evictions int64
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New creates an LRU and registers it for Stats and ResetAll. A limit <= 0
+// means unbounded.
+func New[K comparable, V any](name string, limit int) *LRU[K, V] {
+	c := &LRU[K, V]{name: name, limit: limit, order: list.New(), items: map[K]*list.Element{}}
+	registryMu.Lock()
+	registry = append(registry, c)
+	registryMu.Unlock()
+	return c
+}
+
+// Get returns the value for key and marks it as recently used.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	return c.GetIf(key, nil)
+}
+
+// GetIf is Get for entries that can go stale: when valid rejects the value
+// it is dropped and the lookup counts as a miss. valid runs without the
+// lock held, so it may do I/O.
+func (c *LRU[K, V]) GetIf(key K, valid func(V) bool) (V, bool) {
+	var zero V
+	c.mu.Lock()
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		c.mu.Unlock()
+		return zero, false
+	}
+	value := el.Value.(*lruEntry[K, V]).value
+	c.mu.Unlock()
+
+	if valid != nil && !valid(value) {
+		c.mu.Lock()
+		if cur, ok := c.items[key]; ok && cur == el {
+			c.order.Remove(el)
+			delete(c.items, key)
+		}
+		c.misses++
+		c.mu.Unlock()
+		return zero, false
+	}
+	c.mu.Lock()
+	c.hits++
+	if cur, ok := c.items[key]; ok && cur == el {
+		c.order.MoveToFront(el)
+	}
+	c.mu.Unlock()
+	return value, true
+}
+
+// Peek returns the value for key without touching order or counters.
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		return el.Value.(*lruEntry[K, V]).value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	for c.limit > 0 && c.order.Len() > c.limit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+		c.evictions++
+	}
+}
+
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Reset drops all entries and zeroes the counters.
+func (c *LRU[K, V]) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.items = map[K]*list.Element{}
+	c.hits, c.misses, c.evictions = 0, 0, 0
+}
+
+func (c *LRU[K, V]) Stat() Stat {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stat{
+		Name:      c.name,
+		Entries:   len(c.items),
+		Limit:     c.limit,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New[string, int]("test-evict", 2)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("expected a")
+	}
+	c.Set("c", 3)
+	if _, ok := c.Peek("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok := c.Peek("a"); !ok {
+		t.Fatal("expected a to survive")
+	}
+	st := c.Stat()
+	if st.Entries != 2 || st.Evictions != 1 || st.Hits != 1 || st.Misses != 0 {
+		t.Fatalf("unexpected stat %+v", st)
+	}
+}
+
+func TestGetIfDropsStaleEntries(t *testing.T) {
+	c := New[string, int]("test-stale", 0)
+	c.Set("k", 1)
+	if _, ok := c.GetIf("k", func(v int) bool { return v > 1 }); ok {
+		t.Fatal("expected stale entry to be rejected")
+	}
+	if c.Len() != 0 {
+		t.Fatal("expected stale entry to be removed")
+	}
+	if st := c.Stat(); st.Misses != 1 || st.Hits != 0 {
+		t.Fatalf("unexpected stat %+v", st)
+	}
+}
+
+func TestResetAllClearsRegisteredCaches(t *testing.T) {
+	c := New[int, int]("test-reset", 4)
+	c.Set(1, 1)
+	c.Get(1)
+	ResetAll()
+	if st := c.Stat(); st.Entries != 0 || st.Hits != 0 {
+		t.Fatalf("expected reset cache, got %+v", st)
+	}
+	found := false
+	for _, s := range Stats() {
+		found = found || s.Name == "test-reset"
+	}
+	if !found {
+		t.Fatal("expected cache in Stats")
+	}
+}
+
+func TestLRUConcurrentUse(t *testing.T) {
+	c := New[string, int]("test-concurrent", 16)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := fmt.Sprintf("k%d", (g*i)%32)
+				c.Set(key, i)
+				c.Get(key)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if c.Len() > 16 {
+		t.Fatalf("expected at most 16 entries, got %d", c.Len())
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cli/internal/platform"
+	"cli/internal/plugins"
+	"cli/internal/redact"
+	"cli/tools"
+)
+
+// File is dm.json, the settings file next to the executable.
+type File struct {
+	Tools     []tools.CustomTool        `json:"tools"`
+	PageSize  int                       `json:"page_size"`
+	Editor    string                    `json:"editor"`
+	Sandbox   Sandbox                   `json:"sandbox"`
+	Redact    []string                  `json:"redact"`
+	Protected []string                  `json:"protected"`
+	Defaults  map[string]map[string]any `json:"defaults"`
+}
+
+// Sandbox lists the plugins that always run in a container.
+type Sandbox struct {
+	Image        string   `json:"image"`
+	Plugins      []string `json:"plugins"`
+	MountWorkdir bool     `json:"mount_workdir"`
+}
+
+func Path(baseDir string) string {
+	return filepath.Join(baseDir, "dm.json")
+}
+
+// Read parses dm.json; a missing file is an empty config.
+func Read(baseDir string) (File, error) {
+	raw, err := os.ReadFile(Path(baseDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return File{}, nil
+		}
+		return File{}, err
+	}
+	var cfg File
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return File{}, fmt.Errorf("invalid %s: %w", Path(baseDir), err)
+	}
+	return cfg, nil
+}
+
+// Load reads dm.json and hands each section to the package that uses it,
+// replacing what a previous Load set. A bad section does not stop the
+// others; its problems are returned together, each naming its section.
+func Load(baseDir string) error {
+	cfg, err := Read(baseDir)
+	if err != nil {
+		apply(File{})
+		return err
+	}
+	problems := apply(cfg)
+	if len(problems) > 0 {
+		return fmt.Errorf("%s: %s", Path(baseDir), strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func apply(cfg File) []string {
+	tools.SetPageSize(cfg.PageSize)
+	platform.SetEditor(cfg.Editor)
+	plugins.SetSandbox(cfg.Sandbox.Plugins, cfg.Sandbox.Image, cfg.Sandbox.MountWorkdir)
+	tools.SetProtectedPaths(cfg.Protected)
+	var problems []string
+	if err := redact.SetPatterns(cfg.Redact); err != nil {
+		problems = append(problems, err.Error())
+	}
+	// Custom tools go first: defaults may name them.
+	if err := tools.SetCustomTools(cfg.Tools); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := tools.SetToolDefaults(cfg.Defaults); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return problems
+}
+
+// Check re-reads dm.json for `dm doctor` and reports problems with its
+// custom tools and defaults.
+func Check(baseDir string, lookPath func(string) (string, error)) ([]string, error) {
+	cfg, err := Read(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	problems := tools.CheckCustomTools(cfg.Tools, lookPath)
+	return append(problems, tools.CheckToolDefaults(cfg.Defaults)...), nil
+}
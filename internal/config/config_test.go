@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cli/internal/redact"
+	"cli/tools"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	baseDir := t.TempDir()
+	if err := os.WriteFile(Path(baseDir), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return baseDir
+}
+
+func TestLoadAppliesSectionsAndNamesProblems(t *testing.T) {
+	baseDir := writeConfig(t, `{
+		"tools": [{"name":"ping","command":"ping {{host}}","args":[{"name":"host"}]}, {"name":"broken","command":"echo {{x}}"}],
+		"redact": ["(", "internal-\\d+"],
+		"defaults": {"ping": {"host": "example.com"}, "clean": {"apply": true}}
+	}`)
+	defer func() { _ = Load(t.TempDir()) }()
+
+	err := Load(baseDir)
+	if err == nil {
+		t.Fatal("expected problems to be reported")
+	}
+	for _, want := range []string{"invalid redact patterns", "custom tools: tool broken", "defaults.clean.apply"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "skipped custom tools: defaults") {
+		t.Fatalf("defaults problems must not be reported as custom tools: %v", err)
+	}
+	if !tools.IsKnownTool("ping") {
+		t.Fatal("expected the valid custom tool to be registered")
+	}
+	if got := tools.ToolDefault("ping", "host", ""); got != "example.com" {
+		t.Fatalf("expected default for a custom tool, got %q", got)
+	}
+	if got := redact.String("build internal-42"); got != "build "+redact.Mask {
+		t.Fatalf("expected the valid redact pattern to apply, got %q", got)
+	}
+}
+
+func TestLoadMissingFileResets(t *testing.T) {
+	baseDir := writeConfig(t, `{"tools":[{"name":"ping","command":"ping"}]}`)
+	if err := Load(baseDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(t.TempDir()); err != nil {
+		t.Fatalf("a missing dm.json is not an error: %v", err)
+	}
+	if tools.IsKnownTool("ping") {
+		t.Fatal("expected custom tools to be cleared")
+	}
+}
+
+func TestCheckReportsToolsAndDefaults(t *testing.T) {
+	baseDir := writeConfig(t, `{"tools":[{"name":"search","command":"echo"}],"defaults":{"search":{"bogus":"x"}}}`)
+	problems, err := Check(baseDir, func(p string) (string, error) { return filepath.Join("/bin", p), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(problems, "\n")
+	if !strings.Contains(joined, "search: name already used") || !strings.Contains(joined, "defaults.search.bogus") {
+		t.Fatalf("unexpected problems:\n%s", joined)
+	}
+	if _, err := Check(writeConfig(t, "{"), nil); err == nil {
+		t.Fatal("expected invalid JSON to be reported")
+	}
+}
@@ -5,10 +5,16 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"cli/internal/agent"
+	"cli/internal/aliastarget"
+	"cli/internal/config"
+	"cli/internal/history"
 	"cli/internal/plugins"
 )
 
@@ -39,7 +45,12 @@ func Run(baseDir string) Report {
 	r.add(checkAgentConfig())
 	r.add(checkOllama())
 	r.add(checkOpenAI())
+	r.add(checkFallbackChain(agent.FallbackChain))
 	r.add(checkPlugins(baseDir))
+	r.add(checkPluginConflicts(baseDir))
+	r.add(checkPluginHealth(history.Load()))
+	r.add(checkCustomTools(baseDir))
+	r.add(checkAliasTargets(baseDir))
 	r.add(checkCommonToolPaths())
 	return r
 }
@@ -144,6 +155,20 @@ func checkOpenAI() Check {
 	}
 }
 
+// checkFallbackChain reports the "fallback" provider list used by
+// --provider auto, as the agent resolves it.
+func checkFallbackChain(resolve func() ([]string, error)) Check {
+	chain, err := resolve()
+	if err != nil {
+		return Check{Level: LevelError, Name: "fallback", Message: err.Error()}
+	}
+	return Check{
+		Level:   LevelOK,
+		Name:    "fallback",
+		Message: "auto tries " + strings.Join(chain, " -> "),
+	}
+}
+
 func checkPlugins(baseDir string) Check {
 	items, err := plugins.ListEntries(baseDir, true)
 	if err != nil {
@@ -167,6 +192,91 @@ func checkPlugins(baseDir string) Check {
 	}
 }
 
+func checkPluginConflicts(baseDir string) Check {
+	conflicts, err := plugins.FindConflicts(baseDir)
+	if err != nil {
+		return Check{Level: LevelError, Name: "plugin-names", Message: fmt.Sprintf("scan failed: %v", err)}
+	}
+	if len(conflicts) == 0 {
+		return Check{Level: LevelOK, Name: "plugin-names", Message: "no duplicate plugin/function names"}
+	}
+	parts := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		parts = append(parts, fmt.Sprintf("%s (uses %s, also in %s)", c.Name, relToBase(baseDir, c.Sources[0]), joinRel(baseDir, c.Sources[1:])))
+	}
+	return Check{Level: LevelWarn, Name: "plugin-names", Message: "duplicates: " + strings.Join(parts, "; ")}
+}
+
+// checkPluginHealth flags plugins whose run history shows them slow or
+// failing often.
+func checkPluginHealth(h map[string]history.Record) Check {
+	stats := history.Stats(h, history.KindPlugin)
+	slow, flaky := history.Attention(stats)
+	if len(slow) == 0 && len(flaky) == 0 {
+		return Check{Level: LevelOK, Name: "plugin-health", Message: fmt.Sprintf("%d plugins in run history, none slow or flaky", len(stats))}
+	}
+	var parts []string
+	for _, s := range flaky {
+		parts = append(parts, fmt.Sprintf("%s fails %d/%d runs", s.Name, s.Failures, s.Runs))
+	}
+	for _, s := range slow {
+		parts = append(parts, fmt.Sprintf("%s averages %s", s.Name, (time.Duration(s.AvgMS)*time.Millisecond).Round(100*time.Millisecond)))
+	}
+	return Check{Level: LevelWarn, Name: "plugin-health", Message: strings.Join(parts, "; ") + " (see dm stats)"}
+}
+
+func checkCustomTools(baseDir string) Check {
+	problems, err := config.Check(baseDir, exec.LookPath)
+	if err != nil {
+		return Check{Level: LevelError, Name: "dm.json", Message: err.Error()}
+	}
+	if len(problems) > 0 {
+		return Check{Level: LevelWarn, Name: "dm.json", Message: strings.Join(problems, "; ")}
+	}
+	return Check{Level: LevelOK, Name: "dm.json", Message: "custom tools and defaults are valid"}
+}
+
+// checkAliasTargets verifies that folder and workflow aliases still point
+// at an existing folder or plan file.
+func checkAliasTargets(baseDir string) Check {
+	path := filepath.Join(baseDir, "dm.aliases.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Check{Level: LevelOK, Name: "aliases", Message: "no aliases defined"}
+		}
+		return Check{Level: LevelError, Name: "aliases", Message: err.Error()}
+	}
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return Check{Level: LevelError, Name: "aliases", Message: fmt.Sprintf("invalid %s: %v", path, err)}
+	}
+	var broken []string
+	for name, status := range aliastarget.Check(aliases, aliastarget.StatTimeout, os.Stat) {
+		broken = append(broken, fmt.Sprintf("%s -> %s (%s)", name, aliastarget.Path(aliases[name]), status))
+	}
+	if len(broken) > 0 {
+		sort.Strings(broken)
+		return Check{Level: LevelWarn, Name: "aliases", Message: "broken alias targets: " + strings.Join(broken, ", ")}
+	}
+	return Check{Level: LevelOK, Name: "aliases", Message: fmt.Sprintf("%d aliases, all targets found", len(aliases))}
+}
+
+func relToBase(baseDir, path string) string {
+	if rel, err := filepath.Rel(baseDir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+func joinRel(baseDir string, paths []string) string {
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = relToBase(baseDir, p)
+	}
+	return strings.Join(out, ", ")
+}
+
 func checkCommonToolPaths() Check {
 	home, err := os.UserHomeDir()
 	if err != nil || strings.TrimSpace(home) == "" {
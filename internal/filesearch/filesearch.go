@@ -7,6 +7,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"cli/internal/platform"
 )
 
 type Result struct {
@@ -34,7 +36,7 @@ func Find(opts Options) ([]Result, error) {
 	}
 
 	var results []Result
-	err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+	err := filepath.WalkDir(platform.LongPath(base), func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -53,7 +55,7 @@ func Find(opts Options) ([]Result, error) {
 			return nil
 		}
 		results = append(results, Result{
-			Path:    path,
+			Path:    platform.ShortPath(path),
 			Size:    info.Size(),
 			ModTime: info.ModTime(),
 		})
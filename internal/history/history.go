@@ -0,0 +1,203 @@
+package history
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"cli/internal/platform"
+)
+
+// Record is what dm remembers about one plugin or tool between runs.
+type Record struct {
+	Count    int       `json:"count"`
+	LastRun  time.Time `json:"last_run"`
+	LastCode int       `json:"last_code"`
+	Failures int       `json:"failures,omitempty"`
+	// Timed counts the runs that TotalMS covers; older records have none.
+	Timed   int   `json:"timed,omitempty"`
+	TotalMS int64 `json:"total_ms,omitempty"`
+}
+
+const (
+	KindPlugin = "plugin"
+	KindTool   = "tool"
+	KindAlias  = "alias"
+)
+
+var now = time.Now
+
+func path() string {
+	return platform.StatePath("run-history.json")
+}
+
+func Key(kind, name string) string {
+	return kind + ":" + name
+}
+
+// Load never fails: a missing or unreadable file just means no history.
+func Load() map[string]Record {
+	out := map[string]Record{}
+	data, err := os.ReadFile(path())
+	if err != nil {
+		return out
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		slog.Debug("run history unreadable, starting over", "err", err)
+		return map[string]Record{}
+	}
+	return out
+}
+
+func Add(kind, name string, code int) {
+	AddTimed(kind, name, code, 0)
+}
+
+// AddTimed records a run that took the given time; zero means unknown.
+func AddTimed(kind, name string, code int, took time.Duration) {
+	h := Load()
+	key := Key(kind, name)
+	rec := h[key]
+	rec.Count++
+	rec.LastRun = now()
+	rec.LastCode = code
+	if code != 0 {
+		rec.Failures++
+	}
+	if took > 0 {
+		rec.Timed++
+		rec.TotalMS += took.Milliseconds()
+	}
+	h[key] = rec
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := platform.WriteFileAtomic(path(), append(data, '\n'), 0644); err != nil {
+		slog.Debug("run history not saved", "err", err)
+	}
+}
+
+// Score is the frecency of a record: the run count, halved for every week
+// since the last run.
+func Score(rec Record, at time.Time) float64 {
+	weeks := at.Sub(rec.LastRun).Hours() / (24 * 7)
+	if weeks < 0 {
+		weeks = 0
+	}
+	return float64(rec.Count) * math.Pow(0.5, weeks)
+}
+
+// Top returns up to limit names of the given kind ranked by Score.
+func Top(h map[string]Record, kind string, limit int, at time.Time) []string {
+	type scored struct {
+		name  string
+		score float64
+		last  time.Time
+	}
+	prefix := kind + ":"
+	var items []scored
+	for key, rec := range h {
+		name, ok := strings.CutPrefix(key, prefix)
+		if !ok || rec.Count == 0 {
+			continue
+		}
+		items = append(items, scored{name: name, score: Score(rec, at), last: rec.LastRun})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].score != items[j].score {
+			return items[i].score > items[j].score
+		}
+		return items[i].last.After(items[j].last)
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	out := make([]string, len(items))
+	for i, it := range items {
+		out[i] = it.name
+	}
+	return out
+}
+
+// Average is the mean duration of the timed runs.
+func (r Record) Average() time.Duration {
+	if r.Timed == 0 {
+		return 0
+	}
+	return time.Duration(r.TotalMS/int64(r.Timed)) * time.Millisecond
+}
+
+// FailureRate is the share of runs that exited non-zero.
+func (r Record) FailureRate() float64 {
+	if r.Count == 0 {
+		return 0
+	}
+	return float64(r.Failures) / float64(r.Count)
+}
+
+// Stat is a per-name summary of the run history.
+type Stat struct {
+	Name        string    `json:"name"`
+	Runs        int       `json:"runs"`
+	Failures    int       `json:"failures"`
+	FailureRate float64   `json:"failure_rate"`
+	AvgMS       int64     `json:"avg_ms"`
+	LastRun     time.Time `json:"last_run"`
+	LastCode    int       `json:"last_code"`
+}
+
+// Stats summarizes every record of the given kind, most run first.
+func Stats(h map[string]Record, kind string) []Stat {
+	prefix := kind + ":"
+	var out []Stat
+	for key, rec := range h {
+		name, ok := strings.CutPrefix(key, prefix)
+		if !ok || rec.Count == 0 {
+			continue
+		}
+		out = append(out, Stat{
+			Name:        name,
+			Runs:        rec.Count,
+			Failures:    rec.Failures,
+			FailureRate: rec.FailureRate(),
+			AvgMS:       rec.Average().Milliseconds(),
+			LastRun:     rec.LastRun,
+			LastCode:    rec.LastCode,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Runs != out[j].Runs {
+			return out[i].Runs > out[j].Runs
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Thresholds for Attention: a name needs MinRuns runs before it is judged.
+const (
+	AttentionMinRuns     = 3
+	AttentionSlow        = 10 * time.Second
+	AttentionFailureRate = 0.3
+)
+
+// Attention picks the slow and the flaky entries out of stats.
+func Attention(stats []Stat) (slow, flaky []Stat) {
+	for _, s := range stats {
+		if s.Runs < AttentionMinRuns {
+			continue
+		}
+		if time.Duration(s.AvgMS)*time.Millisecond >= AttentionSlow {
+			slow = append(slow, s)
+		}
+		if s.FailureRate >= AttentionFailureRate {
+			flaky = append(flaky, s)
+		}
+	}
+	return slow, flaky
+}
@@ -0,0 +1,88 @@
+package history
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAddUpdatesRecord(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	prev := now
+	now = func() time.Time { return at }
+	defer func() { now = prev }()
+
+	Add(KindPlugin, "backup", 0)
+	Add(KindPlugin, "backup", 2)
+
+	rec, ok := Load()[Key(KindPlugin, "backup")]
+	if !ok || rec.Count != 2 || rec.LastCode != 2 || !rec.LastRun.Equal(at) {
+		t.Fatalf("unexpected record: %+v (ok %v)", rec, ok)
+	}
+}
+
+func TestLoadIgnoresCorruptFile(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	if err := os.WriteFile(path(), []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := Load(); len(got) != 0 {
+		t.Fatalf("expected empty history, got %v", got)
+	}
+	Add(KindTool, "search", 0)
+	if got := Load(); got[Key(KindTool, "search")].Count != 1 {
+		t.Fatalf("expected history to be rewritten, got %v", got)
+	}
+}
+
+func TestTopRanksByFrecency(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := map[string]Record{
+		Key(KindTool, "search"):   {Count: 3, LastRun: at.Add(-time.Hour)},
+		Key(KindTool, "clean"):    {Count: 10, LastRun: at.Add(-28 * 24 * time.Hour)},
+		Key(KindTool, "rename"):   {Count: 1, LastRun: at.Add(-time.Minute)},
+		Key(KindPlugin, "backup"): {Count: 50, LastRun: at},
+	}
+	got := Top(h, KindTool, 2, at)
+	if !reflect.DeepEqual(got, []string{"search", "rename"}) {
+		t.Fatalf("unexpected ranking: %v", got)
+	}
+}
+
+func TestAddTimedTracksFailuresAndDuration(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	AddTimed(KindPlugin, "sync", 0, 2*time.Second)
+	AddTimed(KindPlugin, "sync", 1, 4*time.Second)
+	Add(KindPlugin, "sync", 0)
+
+	rec := Load()[Key(KindPlugin, "sync")]
+	if rec.Count != 3 || rec.Failures != 1 || rec.Timed != 2 {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+	if got := rec.Average(); got != 3*time.Second {
+		t.Fatalf("expected 3s average, got %v", got)
+	}
+}
+
+func TestAttentionNeedsEnoughRuns(t *testing.T) {
+	h := map[string]Record{
+		Key(KindPlugin, "slow"):  {Count: 4, Timed: 4, TotalMS: 60000},
+		Key(KindPlugin, "flaky"): {Count: 5, Failures: 2},
+		Key(KindPlugin, "new"):   {Count: 2, Failures: 2, Timed: 2, TotalMS: 90000},
+		Key(KindPlugin, "fine"):  {Count: 9, Failures: 1, Timed: 9, TotalMS: 900},
+		Key(KindTool, "search"):  {Count: 9, Failures: 9},
+	}
+	stats := Stats(h, KindPlugin)
+	if len(stats) != 4 || stats[0].Name != "fine" {
+		t.Fatalf("unexpected stats order: %+v", stats)
+	}
+	slow, flaky := Attention(stats)
+	if len(slow) != 1 || slow[0].Name != "slow" || slow[0].AvgMS != 15000 {
+		t.Fatalf("unexpected slow list: %+v", slow)
+	}
+	if len(flaky) != 1 || flaky[0].Name != "flaky" {
+		t.Fatalf("unexpected flaky list: %+v", flaky)
+	}
+}
@@ -0,0 +1,65 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// renameRetries covers transient sharing violations on Windows when another
+// dm process is reading the destination at the same moment.
+const renameRetries = 10
+
+// WriteFileAtomic writes data to a temp file next to path and renames it into
+// place, so concurrent readers never see a truncated file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err == nil {
+		err = renameWithRetry(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func renameWithRetry(src, dst string) error {
+	var err error
+	for i := 0; i < renameRetries; i++ {
+		if err = os.Rename(src, dst); err == nil {
+			return nil
+		}
+		time.Sleep(time.Duration(i+1) * 10 * time.Millisecond)
+	}
+	return err
+}
+
+// QuarantineFile moves an unreadable file aside as <path>.corrupt-<timestamp>
+// so the caller can start over without losing the original bytes.
+func QuarantineFile(path string) (string, error) {
+	dst := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+	if err := renameWithRetry(path, dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
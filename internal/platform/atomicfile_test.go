@@ -0,0 +1,49 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteFileAtomicReplacesContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "data.json")
+	if err := WriteFileAtomic(path, []byte("first"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte("second"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "second" {
+		t.Fatalf("unexpected content %q (err %v)", data, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("expected no leftover temp files, got %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomicConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	payloads := []string{strings.Repeat("a", 4096), strings.Repeat("b", 8192)}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(p string) {
+			defer wg.Done()
+			_ = WriteFileAtomic(path, []byte(p), 0644)
+		}(payloads[i%2])
+	}
+	wg.Wait()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != payloads[0] && string(data) != payloads[1] {
+		t.Fatalf("expected one complete payload, got %d bytes", len(data))
+	}
+}
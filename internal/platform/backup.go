@@ -0,0 +1,49 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BackupFile copies path into dir as <name>.<timestamp><ext> and returns
+// the copy's path. A backup made in the same second gets a _NN suffix
+// instead of overwriting the earlier one; names still sort oldest first.
+func BackupFile(path, dir string, now time.Time) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(filepath.Base(path), ext) + "." + now.Format("20060102-150405")
+	for n := 1; n < 100; n++ {
+		name := stem + ext
+		if n > 1 {
+			name = fmt.Sprintf("%s_%02d%s", stem, n, ext)
+		}
+		dst := filepath.Join(dir, name)
+		f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(dst)
+			return "", err
+		}
+		return dst, nil
+	}
+	return "", fmt.Errorf("too many backups of %s in %s this second", filepath.Base(path), dir)
+}
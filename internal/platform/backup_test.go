@@ -0,0 +1,37 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestBackupFileKeepsBackupsMadeInTheSameSecond(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "profile.ps1")
+	dir := filepath.Join(t.TempDir(), "backups")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var made []string
+	for _, content := range []string{"one", "two", "three"} {
+		if err := os.WriteFile(src, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		dst, err := BackupFile(src, dir, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		made = append(made, dst)
+	}
+	if filepath.Base(made[0]) != "profile.20260301-120000.ps1" || filepath.Base(made[1]) != "profile.20260301-120000_02.ps1" {
+		t.Fatalf("unexpected names %v", made)
+	}
+	if !sort.StringsAreSorted(made) {
+		t.Fatalf("backup names should sort oldest first: %v", made)
+	}
+	for i, want := range []string{"one", "two", "three"} {
+		if data, _ := os.ReadFile(made[i]); string(data) != want {
+			t.Fatalf("backup %d holds %q, want %q", i, data, want)
+		}
+	}
+}
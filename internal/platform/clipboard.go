@@ -0,0 +1,52 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// CopyToClipboard puts text on the system clipboard using the platform's
+// clipboard command.
+func CopyToClipboard(text string) error {
+	argv := resolveClipboard(runtime.GOOS, os.Getenv, exec.LookPath)
+	if len(argv) == 0 {
+		return fmt.Errorf("no clipboard command found (install wl-clipboard, xclip or xsel)")
+	}
+	// Output is not captured: xclip and wl-copy leave a child serving the
+	// selection that would keep the pipes open and block the wait.
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", argv[0], err)
+	}
+	return nil
+}
+
+func resolveClipboard(goos string, getenv func(string) string, lookPath func(string) (string, error)) []string {
+	switch goos {
+	case "windows":
+		// clip.exe reads the console code page and mangles UTF-8, so read
+		// stdin as UTF-8 from PowerShell instead.
+		return []string{"powershell", "-NoProfile", "-NonInteractive", "-Command",
+			"[Console]::InputEncoding = [Text.Encoding]::UTF8; Set-Clipboard -Value ([Console]::In.ReadToEnd())"}
+	case "darwin":
+		return []string{"pbcopy"}
+	}
+	var candidates [][]string
+	if getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append(candidates, []string{"wl-copy"})
+	}
+	candidates = append(candidates,
+		[]string{"xclip", "-selection", "clipboard"},
+		[]string{"xsel", "--clipboard", "--input"},
+	)
+	for _, c := range candidates {
+		if _, err := lookPath(c[0]); err == nil {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package platform
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolveClipboard(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+	installed := map[string]bool{"xsel": true, "wl-copy": true}
+	look := func(name string) (string, error) {
+		if installed[name] {
+			return "/usr/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+	if got := resolveClipboard("darwin", getenv, look); !reflect.DeepEqual(got, []string{"pbcopy"}) {
+		t.Fatalf("unexpected darwin clipboard %v", got)
+	}
+	if got := resolveClipboard("windows", getenv, look); len(got) == 0 || got[0] != "powershell" {
+		t.Fatalf("unexpected windows clipboard %v", got)
+	}
+	if got := resolveClipboard("linux", getenv, look); !reflect.DeepEqual(got, []string{"xsel", "--clipboard", "--input"}) {
+		t.Fatalf("expected xsel without wayland, got %v", got)
+	}
+	env["WAYLAND_DISPLAY"] = "wayland-0"
+	if got := resolveClipboard("linux", getenv, look); !reflect.DeepEqual(got, []string{"wl-copy"}) {
+		t.Fatalf("expected wl-copy on wayland, got %v", got)
+	}
+	installed = map[string]bool{}
+	if got := resolveClipboard("linux", getenv, look); got != nil {
+		t.Fatalf("expected no clipboard command, got %v", got)
+	}
+}
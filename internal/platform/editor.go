@@ -0,0 +1,110 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var configuredEditor string
+
+// terminalEditors run in the current terminal, so dm waits for them.
+var terminalEditors = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "nano": true, "emacs": true,
+	"micro": true, "hx": true, "helix": true, "kak": true, "joe": true, "ne": true,
+}
+
+// SetEditor sets the editor command from dm.json; it wins over $VISUAL and $EDITOR.
+func SetEditor(cmd string) {
+	configuredEditor = strings.TrimSpace(cmd)
+}
+
+// OpenInEditor opens path in the resolved editor. Terminal editors run
+// attached to the console; GUI editors are started in the background.
+func OpenInEditor(path string) error {
+	return OpenInEditorAt(path, 0)
+}
+
+// OpenInEditorAt is OpenInEditor with the cursor on line (1-based) for
+// editors that take a line argument; others just open the file.
+func OpenInEditorAt(path string, line int) error {
+	argv := resolveEditor(configuredEditor, os.Getenv, runtime.GOOS, exec.LookPath)
+	if len(argv) == 0 {
+		return fmt.Errorf("no editor found; set $EDITOR or \"editor\" in dm.json")
+	}
+	cmd := exec.Command(argv[0], append(argv[1:], editorFileArgs(argv[0], path, line)...)...)
+	if !terminalEditors[editorBaseName(argv[0])] {
+		return cmd.Start()
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// editorFileArgs returns the arguments that open path at line in editor.
+func editorFileArgs(editor, path string, line int) []string {
+	if line <= 0 {
+		return []string{path}
+	}
+	n := strconv.Itoa(line)
+	switch editorBaseName(editor) {
+	case "code", "code-insiders", "codium", "cursor":
+		return []string{"--goto", path + ":" + n}
+	case "subl", "sublime_text", "hx", "helix":
+		return []string{path + ":" + n}
+	case "notepad++":
+		return []string{"-n" + n, path}
+	case "vi", "vim", "nvim", "nano", "emacs", "micro", "kak", "joe", "ne":
+		return []string{"+" + n, path}
+	}
+	return []string{path}
+}
+
+func resolveEditor(configured string, getenv func(string) string, goos string, lookPath func(string) (string, error)) []string {
+	for _, raw := range []string{configured, getenv("VISUAL"), getenv("EDITOR")} {
+		if argv := splitEditorCommand(raw); len(argv) > 0 {
+			return argv
+		}
+	}
+	switch goos {
+	case "windows":
+		return []string{"notepad.exe"}
+	case "darwin":
+		return []string{"open", "-t"}
+	}
+	candidates := []string{"nano", "vi"}
+	if getenv("DISPLAY") != "" || getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append([]string{"xdg-open"}, candidates...)
+	}
+	for _, c := range candidates {
+		if _, err := lookPath(c); err == nil {
+			return []string{c}
+		}
+	}
+	return nil
+}
+
+// splitEditorCommand splits an editor setting such as `code -w` or
+// `"C:\Program Files\Notepad++\notepad++.exe" -multiInst` into argv.
+func splitEditorCommand(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	if raw[0] == '"' || raw[0] == '\'' {
+		if end := strings.IndexByte(raw[1:], raw[0]); end >= 0 {
+			return append([]string{raw[1 : end+1]}, strings.Fields(raw[end+2:])...)
+		}
+	}
+	return strings.Fields(raw)
+}
+
+func editorBaseName(path string) string {
+	base := filepath.Base(strings.ReplaceAll(path, "\\", "/"))
+	return strings.ToLower(strings.TrimSuffix(base, filepath.Ext(base)))
+}
@@ -0,0 +1,74 @@
+package platform
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolveEditorPriority(t *testing.T) {
+	env := map[string]string{"VISUAL": "code -w", "EDITOR": "vim"}
+	getenv := func(k string) string { return env[k] }
+	noLook := func(string) (string, error) { return "", errors.New("not found") }
+
+	if got := resolveEditor("hx", getenv, "linux", noLook); !reflect.DeepEqual(got, []string{"hx"}) {
+		t.Fatalf("expected configured editor, got %v", got)
+	}
+	if got := resolveEditor("", getenv, "linux", noLook); !reflect.DeepEqual(got, []string{"code", "-w"}) {
+		t.Fatalf("expected $VISUAL, got %v", got)
+	}
+	delete(env, "VISUAL")
+	if got := resolveEditor("", getenv, "linux", noLook); !reflect.DeepEqual(got, []string{"vim"}) {
+		t.Fatalf("expected $EDITOR, got %v", got)
+	}
+}
+
+func TestResolveEditorFallbacks(t *testing.T) {
+	getenv := func(string) string { return "" }
+	look := func(name string) (string, error) {
+		if name == "vi" {
+			return "/usr/bin/vi", nil
+		}
+		return "", errors.New("not found")
+	}
+	if got := resolveEditor("", getenv, "windows", look); !reflect.DeepEqual(got, []string{"notepad.exe"}) {
+		t.Fatalf("unexpected windows fallback %v", got)
+	}
+	if got := resolveEditor("", getenv, "darwin", look); !reflect.DeepEqual(got, []string{"open", "-t"}) {
+		t.Fatalf("unexpected darwin fallback %v", got)
+	}
+	if got := resolveEditor("", getenv, "linux", look); !reflect.DeepEqual(got, []string{"vi"}) {
+		t.Fatalf("unexpected linux fallback %v", got)
+	}
+}
+
+func TestSplitEditorCommandQuoted(t *testing.T) {
+	got := splitEditorCommand(`"C:\Program Files\Notepad++\notepad++.exe" -multiInst`)
+	want := []string{`C:\Program Files\Notepad++\notepad++.exe`, "-multiInst"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if editorBaseName(`C:\Tools\nvim.exe`) != "nvim" {
+		t.Fatal("expected nvim base name")
+	}
+}
+
+func TestEditorFileArgs(t *testing.T) {
+	cases := []struct {
+		editor string
+		line   int
+		want   []string
+	}{
+		{"code", 12, []string{"--goto", "a.ps1:12"}},
+		{`C:\Tools\nvim.exe`, 12, []string{"+12", "a.ps1"}},
+		{"notepad++", 3, []string{"-n3", "a.ps1"}},
+		{"hx", 7, []string{"a.ps1:7"}},
+		{"notepad.exe", 12, []string{"a.ps1"}},
+		{"code", 0, []string{"a.ps1"}},
+	}
+	for _, c := range cases {
+		if got := editorFileArgs(c.editor, "a.ps1", c.line); !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%s line %d: expected %v, got %v", c.editor, c.line, c.want, got)
+		}
+	}
+}
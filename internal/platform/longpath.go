@@ -0,0 +1,79 @@
+package platform
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	longPrefix    = `\\?\`
+	longUNCPrefix = `\\?\UNC\`
+)
+
+// LongPath returns p in the \\?\ form so Windows file APIs accept it past
+// MAX_PATH (\\?\UNC\server\share for network paths). Relative paths are made
+// absolute first. On other systems p is returned unchanged.
+func LongPath(p string) string {
+	if runtime.GOOS != "windows" || p == "" {
+		return p
+	}
+	if !strings.HasPrefix(p, `\\`) && !strings.HasPrefix(p, "//") {
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+	}
+	return longPath(p, runtime.GOOS)
+}
+
+func longPath(p, goos string) string {
+	if goos != "windows" || strings.HasPrefix(p, longPrefix) || strings.HasPrefix(p, `\\.\`) {
+		return p
+	}
+	p = strings.ReplaceAll(p, "/", `\`)
+	if isUNC(p) {
+		return longUNCPrefix + strings.TrimLeft(p, `\`)
+	}
+	if len(p) >= 3 && p[1] == ':' && p[2] == '\\' {
+		return longPrefix + p
+	}
+	return p
+}
+
+// ShortPath strips the prefix added by LongPath, for display and comparison.
+func ShortPath(p string) string {
+	switch {
+	case strings.HasPrefix(p, longUNCPrefix):
+		return `\\` + p[len(longUNCPrefix):]
+	case strings.HasPrefix(p, longPrefix):
+		return p[len(longPrefix):]
+	}
+	return p
+}
+
+// NormalizeUNC rewrites //server/share and \\?\ forms to a plain
+// \\server\share or C:\ path on Windows so filepath.Clean keeps the volume.
+func NormalizeUNC(p string) string {
+	return normalizeUNC(p, runtime.GOOS)
+}
+
+func normalizeUNC(p, goos string) string {
+	if goos != "windows" {
+		return p
+	}
+	p = ShortPath(p)
+	if isUNC(strings.ReplaceAll(p, "/", `\`)) {
+		return strings.ReplaceAll(p, "/", `\`)
+	}
+	return p
+}
+
+// isUNC reports whether p (with backslashes) looks like \\server\share.
+func isUNC(p string) bool {
+	if !strings.HasPrefix(p, `\\`) || strings.HasPrefix(p, longPrefix) || strings.HasPrefix(p, `\\.\`) {
+		return false
+	}
+	rest := p[2:]
+	i := strings.IndexByte(rest, '\\')
+	return i > 0 && i < len(rest)-1
+}
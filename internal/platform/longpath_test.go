@@ -0,0 +1,59 @@
+package platform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLongPathWindows(t *testing.T) {
+	long := `C:\data\` + strings.Repeat(`a\`, 140) + "file.txt"
+	cases := map[string]string{
+		`C:\Users\me`:              `\\?\C:\Users\me`,
+		`C:/Users/me`:              `\\?\C:\Users\me`,
+		long:                       `\\?\` + long,
+		`\\server\share\dir`:       `\\?\UNC\server\share\dir`,
+		`//server/share/dir`:       `\\?\UNC\server\share\dir`,
+		`\\?\C:\already`:           `\\?\C:\already`,
+		`\\?\UNC\server\share\dir`: `\\?\UNC\server\share\dir`,
+		`relative\dir`:             `relative\dir`,
+	}
+	for in, want := range cases {
+		if got := longPath(in, "windows"); got != want {
+			t.Fatalf("longPath(%q) = %q, want %q", in, got, want)
+		}
+		if got := ShortPath(longPath(in, "windows")); strings.HasPrefix(got, `\\?\`) {
+			t.Fatalf("ShortPath left prefix on %q", got)
+		}
+	}
+	if got := longPath("/home/me", "linux"); got != "/home/me" {
+		t.Fatalf("expected unchanged path on linux, got %q", got)
+	}
+}
+
+func TestShortPath(t *testing.T) {
+	if got := ShortPath(`\\?\UNC\server\share\x`); got != `\\server\share\x` {
+		t.Fatalf("unexpected UNC short path %q", got)
+	}
+	if got := ShortPath(`\\?\C:\x`); got != `C:\x` {
+		t.Fatalf("unexpected drive short path %q", got)
+	}
+}
+
+func TestNormalizeUNC(t *testing.T) {
+	cases := map[string]string{
+		`//server/share/dir`:       `\\server\share\dir`,
+		`\\server/share/dir`:       `\\server\share\dir`,
+		`\\?\UNC\server\share\dir`: `\\server\share\dir`,
+		`\\?\C:\dir`:               `C:\dir`,
+		`C:/dir`:                   `C:/dir`,
+		`\\server`:                 `\\server`,
+	}
+	for in, want := range cases {
+		if got := normalizeUNC(in, "windows"); got != want {
+			t.Fatalf("normalizeUNC(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := normalizeUNC("//server/share", "linux"); got != "//server/share" {
+		t.Fatalf("expected unchanged path on linux, got %q", got)
+	}
+}
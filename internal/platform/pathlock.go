@@ -0,0 +1,222 @@
+package platform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	pathLockStaleAfter = 24 * time.Hour
+	pathLockGuardWait  = 2 * time.Second
+	pathLockGuardStale = 10 * time.Second
+)
+
+// PathLock records which dm run is changing a path, so another dm run
+// (or a scheduled job) does not change the same files at the same time.
+type PathLock struct {
+	Path    string    `json:"path"`
+	Op      string    `json:"op"`
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+}
+
+// LockedError is returned when another process holds an overlapping lock.
+type LockedError struct {
+	Held PathLock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s is locked by another dm run (pid %d, %s, since %s)",
+		e.Held.Path, e.Held.PID, e.Held.Op, e.Held.Started.Format("15:04:05"))
+}
+
+// IsLocked reports whether err comes from a path held by another run.
+func IsLocked(err error) bool {
+	var le *LockedError
+	return errors.As(err, &le)
+}
+
+var (
+	heldPathLocksMu sync.Mutex
+	heldPathLocks   = map[string]bool{}
+)
+
+// ReleasePathLocks removes every lock this process still holds. dm calls
+// it on Ctrl-C, where deferred releases do not run.
+func ReleasePathLocks() {
+	heldPathLocksMu.Lock()
+	defer heldPathLocksMu.Unlock()
+	for f := range heldPathLocks {
+		_ = os.Remove(f)
+		delete(heldPathLocks, f)
+	}
+}
+
+func pathLockDir() string {
+	return StatePath("locks")
+}
+
+func lockComparablePath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	p = filepath.Clean(p)
+	if runtime.GOOS == "windows" {
+		p = strings.ToLower(p)
+	}
+	return p
+}
+
+// pathsOverlap reports whether a and b are the same path or one contains
+// the other.
+func pathsOverlap(a, b string) bool {
+	within := func(p, dir string) bool {
+		if !strings.HasSuffix(dir, string(filepath.Separator)) {
+			dir += string(filepath.Separator)
+		}
+		return strings.HasPrefix(p, dir)
+	}
+	return a == b || within(a, b) || within(b, a)
+}
+
+func (l PathLock) stale(now time.Time) bool {
+	return now.Sub(l.Started) > pathLockStaleAfter || !processAlive(l.PID)
+}
+
+// AcquirePathLock takes an advisory lock on each path for op. It fails
+// with a *LockedError when another live process holds a lock on one of
+// the paths, a folder containing it, or anything inside it; locks left by
+// processes that are gone are cleared. Locks held by this process never
+// conflict, so nested operations can lock again. The returned function
+// releases the locks.
+func AcquirePathLock(op string, paths ...string) (func(), error) {
+	dir := pathLockDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	releaseGuard, err := acquireLockGuard(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseGuard()
+
+	now := time.Now()
+	pid := os.Getpid()
+	entries, _ := os.ReadDir(dir)
+	var wanted []string
+	for _, p := range paths {
+		if strings.TrimSpace(p) != "" {
+			wanted = append(wanted, lockComparablePath(p))
+		}
+	}
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".lock") {
+			continue
+		}
+		file := filepath.Join(dir, e.Name())
+		held, ok := readPathLock(file)
+		if !ok || held.stale(now) {
+			_ = os.Remove(file)
+			continue
+		}
+		if held.PID == pid {
+			continue
+		}
+		for _, w := range wanted {
+			if pathsOverlap(w, lockComparablePath(held.Path)) {
+				return nil, &LockedError{Held: held}
+			}
+		}
+	}
+
+	var created []string
+	release := func() {
+		heldPathLocksMu.Lock()
+		defer heldPathLocksMu.Unlock()
+		for _, f := range created {
+			_ = os.Remove(f)
+			delete(heldPathLocks, f)
+		}
+	}
+	for _, w := range wanted {
+		sum := sha256.Sum256([]byte(w))
+		file := filepath.Join(dir, hex.EncodeToString(sum[:8])+".lock")
+		data, _ := json.Marshal(PathLock{Path: w, Op: op, PID: pid, Started: now})
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			if os.IsExist(err) {
+				continue // already held by this process
+			}
+			release()
+			return nil, err
+		}
+		_, err = f.Write(data)
+		f.Close()
+		if err != nil {
+			_ = os.Remove(file)
+			release()
+			return nil, err
+		}
+		created = append(created, file)
+		heldPathLocksMu.Lock()
+		heldPathLocks[file] = true
+		heldPathLocksMu.Unlock()
+	}
+	return release, nil
+}
+
+func readPathLock(file string) (PathLock, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return PathLock{}, false
+	}
+	var l PathLock
+	if err := json.Unmarshal(data, &l); err != nil || l.PID == 0 {
+		return PathLock{}, false
+	}
+	return l, true
+}
+
+// acquireLockGuard serializes lock checks between processes for the short
+// time it takes to scan and create lock files.
+func acquireLockGuard(dir string) (func(), error) {
+	return LockFile(filepath.Join(dir, "guard"))
+}
+
+// LockFile waits briefly for an exclusive lock on guard, a file created for
+// the purpose, so read-modify-write updates of shared state from several dm
+// processes do not overlap. A guard left behind by a crash is cleared after
+// a few seconds. The returned function releases the lock.
+func LockFile(guard string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(guard), 0755); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(pathLockGuardWait)
+	for {
+		f, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(guard) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(guard); statErr == nil && time.Since(info.ModTime()) > pathLockGuardStale {
+			_ = os.Remove(guard)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s", guard)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os"
+	"syscall"
+)
+
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	defer p.Release()
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
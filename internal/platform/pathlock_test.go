@@ -0,0 +1,122 @@
+package platform
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestPathLock(t *testing.T, l PathLock) {
+	t.Helper()
+	dir := pathLockDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(l)
+	if err := os.WriteFile(filepath.Join(dir, "held.lock"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPathsOverlap(t *testing.T) {
+	base := filepath.Join(string(filepath.Separator), "data")
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{base, base, true},
+		{base, filepath.Join(base, "x"), true},
+		{filepath.Join(base, "x", "y"), base, true},
+		{base, base + "2", false},
+		{filepath.Join(base, "a"), filepath.Join(base, "b"), false},
+	}
+	for _, c := range cases {
+		if got := pathsOverlap(c.a, c.b); got != c.want {
+			t.Fatalf("pathsOverlap(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestAcquirePathLockReentrantAndRelease(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	base := t.TempDir()
+
+	release, err := AcquirePathLock("rename apply", base)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	inner, err := AcquirePathLock("clean apply", filepath.Join(base, "sub"))
+	if err != nil {
+		t.Fatalf("same process should not conflict: %v", err)
+	}
+	inner()
+	release()
+
+	entries, _ := os.ReadDir(pathLockDir())
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".lock" {
+			t.Fatalf("lock file left after release: %s", e.Name())
+		}
+	}
+}
+
+func TestAcquirePathLockConflict(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	base := t.TempDir()
+	writeTestPathLock(t, PathLock{Path: base, Op: "profile sync", PID: os.Getppid(), Started: time.Now()})
+
+	_, err := AcquirePathLock("clean apply", filepath.Join(base, "sub"))
+	if !IsLocked(err) {
+		t.Fatalf("expected locked error, got %v", err)
+	}
+	release, err := AcquirePathLock("clean apply", t.TempDir())
+	if err != nil {
+		t.Fatalf("unrelated path should not conflict: %v", err)
+	}
+	release()
+}
+
+func TestAcquirePathLockClearsStale(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	base := t.TempDir()
+	writeTestPathLock(t, PathLock{Path: base, Op: "profile sync", PID: os.Getppid(), Started: time.Now().Add(-48 * time.Hour)})
+
+	release, err := AcquirePathLock("clean apply", base)
+	if err != nil {
+		t.Fatalf("stale lock should be cleared: %v", err)
+	}
+	release()
+	if _, err := os.Stat(filepath.Join(pathLockDir(), "held.lock")); !os.IsNotExist(err) {
+		t.Fatalf("stale lock file should be removed, stat err=%v", err)
+	}
+}
+
+func TestReleasePathLocks(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	if _, err := AcquirePathLock("rename apply", t.TempDir()); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	ReleasePathLocks()
+	entries, _ := os.ReadDir(pathLockDir())
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".lock" {
+			t.Fatalf("lock file left after ReleasePathLocks: %s", e.Name())
+		}
+	}
+}
+
+func TestProcessAlive(t *testing.T) {
+	if !processAlive(os.Getpid()) {
+		t.Fatal("expected this process to be alive")
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("run child: %v", err)
+	}
+	if processAlive(cmd.Process.Pid) {
+		t.Fatalf("expected exited pid %d to be reported dead", cmd.Process.Pid)
+	}
+}
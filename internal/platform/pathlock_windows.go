@@ -0,0 +1,22 @@
+package platform
+
+import "syscall"
+
+const (
+	processQueryLimitedInformation = 0x1000
+	processStillActive             = 259
+)
+
+func processAlive(pid int) bool {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// A process we may not query still exists.
+		return err == syscall.ERROR_ACCESS_DENIED
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return true
+	}
+	return code == processStillActive
+}
@@ -48,6 +48,41 @@ func OpenTerminal(path string) {
 	_ = exec.Command("x-terminal-emulator", "--working-directory", path).Start()
 }
 
+// RunInNewTerminal starts argv in a separate terminal window that stays open
+// after the command exits.
+func RunInNewTerminal(argv []string) error {
+	if len(argv) == 0 {
+		return fmt.Errorf("no command to run")
+	}
+	switch runtime.GOOS {
+	case "windows":
+		args := append([]string{"/C", "start", "", "cmd", "/K"}, argv...)
+		return exec.Command("cmd", args...).Start()
+	case "darwin":
+		script := fmt.Sprintf(`tell application "Terminal" to do script "%s"`, EscapeQuotes(shellJoin(argv)))
+		return exec.Command("osascript", "-e", script).Start()
+	}
+	for _, term := range []string{"x-terminal-emulator", "gnome-terminal", "konsole", "xterm"} {
+		if _, err := exec.LookPath(term); err != nil {
+			continue
+		}
+		flag := "-e"
+		if term == "gnome-terminal" {
+			flag = "--"
+		}
+		return exec.Command(term, flag, "sh", "-c", shellJoin(argv)+"; exec $SHELL").Start()
+	}
+	return fmt.Errorf("no terminal emulator found")
+}
+
+func shellJoin(argv []string) string {
+	out := make([]string, len(argv))
+	for i, a := range argv {
+		out[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(out, " ")
+}
+
 func EscapeQuotes(s string) string {
 	return strings.ReplaceAll(s, `"`, `\"`)
 }
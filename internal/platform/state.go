@@ -0,0 +1,68 @@
+package platform
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// StateDir returns the per-user directory for dm state (caches, history,
+// saved workflows). DM_STATE_DIR overrides the platform default.
+func StateDir() string {
+	home, _ := os.UserHomeDir()
+	return resolveStateDir(os.Getenv, runtime.GOOS, home)
+}
+
+// StatePath joins parts under StateDir.
+func StatePath(parts ...string) string {
+	return filepath.Join(append([]string{StateDir()}, parts...)...)
+}
+
+func resolveStateDir(getenv func(string) string, goos, home string) string {
+	if dir := strings.TrimSpace(getenv("DM_STATE_DIR")); dir != "" {
+		return dir
+	}
+	switch goos {
+	case "windows":
+		if dir := strings.TrimSpace(getenv("LOCALAPPDATA")); dir != "" {
+			return filepath.Join(dir, "dm")
+		}
+		return filepath.Join(home, "AppData", "Local", "dm")
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "dm")
+	}
+	if dir := strings.TrimSpace(getenv("XDG_STATE_HOME")); dir != "" {
+		return filepath.Join(dir, "dm")
+	}
+	return filepath.Join(home, ".local", "state", "dm")
+}
+
+// MoveFile renames src to dst, copying when they are on different volumes.
+func MoveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		in.Close()
+		return err
+	}
+	_, err = io.Copy(out, in)
+	in.Close()
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
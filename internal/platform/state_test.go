@@ -0,0 +1,27 @@
+package platform
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveStateDir(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+
+	if got := resolveStateDir(getenv, "linux", "/home/u"); got != filepath.Join("/home/u", ".local", "state", "dm") {
+		t.Fatalf("unexpected linux default %s", got)
+	}
+	env["XDG_STATE_HOME"] = "/xdg"
+	if got := resolveStateDir(getenv, "linux", "/home/u"); got != filepath.Join("/xdg", "dm") {
+		t.Fatalf("unexpected XDG state dir %s", got)
+	}
+	env["LOCALAPPDATA"] = `C:\Users\u\AppData\Local`
+	if got := resolveStateDir(getenv, "windows", `C:\Users\u`); got != filepath.Join(`C:\Users\u\AppData\Local`, "dm") {
+		t.Fatalf("unexpected windows state dir %s", got)
+	}
+	env["DM_STATE_DIR"] = "/custom"
+	if got := resolveStateDir(getenv, "darwin", "/Users/u"); got != "/custom" {
+		t.Fatalf("expected DM_STATE_DIR override, got %s", got)
+	}
+}
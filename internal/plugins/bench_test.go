@@ -28,10 +28,7 @@ func benchmarkPluginDataset(b *testing.B, files, funcsPerFile int) string {
 }
 
 func clearPluginBenchCache() {
-	cacheMu.Lock()
-	entryListCache = map[string]entryListCacheValue{}
-	entryInfoCache = map[string]entryInfoCacheValue{}
-	cacheMu.Unlock()
+	ResetCaches()
 }
 
 func BenchmarkListEntriesWithFunctionsCold(b *testing.B) {
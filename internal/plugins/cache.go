@@ -2,15 +2,26 @@ package plugins
 
 import (
 	"os"
-	"sync"
+
+	"cli/internal/cache"
+)
+
+const (
+	entryListCacheLimit = 32
+	entryInfoCacheLimit = 512
 )
 
 var (
-	cacheMu        sync.RWMutex
-	entryListCache = map[string]entryListCacheValue{}
-	entryInfoCache = map[string]entryInfoCacheValue{}
+	entryListCache = cache.New[string, entryListCacheValue]("plugin-entries", entryListCacheLimit)
+	entryInfoCache = cache.New[string, entryInfoCacheValue]("plugin-info", entryInfoCacheLimit)
 )
 
+// ResetCaches drops cached plugin listings and info.
+func ResetCaches() {
+	entryListCache.Reset()
+	entryInfoCache.Reset()
+}
+
 type entryListCacheValue struct {
 	DirPath    string
 	Items      []Entry
@@ -33,15 +44,12 @@ func listEntriesCacheKey(dir string, includeFunctions bool) string {
 }
 
 func getCachedEntryList(key string) ([]Entry, bool) {
-	cacheMu.RLock()
-	value, ok := entryListCache[key]
-	cacheMu.RUnlock()
+	value, ok := entryListCache.GetIf(key, func(v entryListCacheValue) bool {
+		return fingerprintsValid(v.DirPath, v.DirStamp, v.FileStamps)
+	})
 	if !ok {
 		return nil, false
 	}
-	if !fingerprintsValid(value.DirPath, value.DirStamp, value.FileStamps) {
-		return nil, false
-	}
 	out := make([]Entry, len(value.Items))
 	copy(out, value.Items)
 	return out, true
@@ -51,14 +59,12 @@ func setCachedEntryList(key string, dirPath string, items []Entry, dirStamp int6
 	out := make([]Entry, len(items))
 	copy(out, items)
 	fsCopy := cloneFileStamps(fileStamps)
-	cacheMu.Lock()
-	entryListCache[key] = entryListCacheValue{
+	entryListCache.Set(key, entryListCacheValue{
 		DirPath:    dirPath,
 		Items:      out,
 		DirStamp:   dirStamp,
 		FileStamps: fsCopy,
-	}
-	cacheMu.Unlock()
+	})
 }
 
 func infoCacheKey(dir, name string) string {
@@ -66,28 +72,23 @@ func infoCacheKey(dir, name string) string {
 }
 
 func getCachedInfo(key string) (Info, bool) {
-	cacheMu.RLock()
-	value, ok := entryInfoCache[key]
-	cacheMu.RUnlock()
+	value, ok := entryInfoCache.GetIf(key, func(v entryInfoCacheValue) bool {
+		return fingerprintsValid(v.DirPath, v.DirStamp, v.FileStamps)
+	})
 	if !ok {
 		return Info{}, false
 	}
-	if !fingerprintsValid(value.DirPath, value.DirStamp, value.FileStamps) {
-		return Info{}, false
-	}
 	return cloneInfo(value.Info), true
 }
 
 func setCachedInfo(key string, dirPath string, info Info, dirStamp int64, fileStamps map[string]int64) {
 	fsCopy := cloneFileStamps(fileStamps)
-	cacheMu.Lock()
-	entryInfoCache[key] = entryInfoCacheValue{
+	entryInfoCache.Set(key, entryInfoCacheValue{
 		DirPath:    dirPath,
 		Info:       cloneInfo(info),
 		DirStamp:   dirStamp,
 		FileStamps: fsCopy,
-	}
-	cacheMu.Unlock()
+	})
 }
 
 func cloneInfo(info Info) Info {
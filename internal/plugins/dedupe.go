@@ -0,0 +1,139 @@
+package plugins
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var psFunctionName = regexp.MustCompile(`(?i)^[a-z0-9_-]+$`)
+
+// Duplicate is a public PowerShell function defined in more than one file.
+// Active is the definition dm loads; the Shadowed copies are never used.
+type Duplicate struct {
+	Name     string   `json:"name"`
+	Active   string   `json:"active"`
+	Shadowed []string `json:"shadowed"`
+}
+
+// FindDuplicateFunctions lists functions defined in several files under
+// baseDir/plugins, in the order dm loads those files.
+func FindDuplicateFunctions(baseDir string) ([]Duplicate, error) {
+	files, err := listPowerShellFunctionFiles(filepath.Join(baseDir, "plugins"))
+	if err != nil {
+		return nil, err
+	}
+	var order []string
+	sources := map[string][]string{}
+	for _, p := range files {
+		names, err := readPowerShellFunctionNames(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range names {
+			if !isPublicFunctionName(n) || containsPath(sources[n], p) {
+				continue
+			}
+			if len(sources[n]) == 0 {
+				order = append(order, n)
+			}
+			sources[n] = append(sources[n], p)
+		}
+	}
+	var out []Duplicate
+	for _, n := range order {
+		if paths := sources[n]; len(paths) > 1 {
+			out = append(out, Duplicate{Name: n, Active: paths[0], Shadowed: paths[1:]})
+		}
+	}
+	return out, nil
+}
+
+// functionSpan returns the lines of name in lines including its help block,
+// or -1 when the function is not defined there.
+func functionSpan(lines []string, name string) (from, to int) {
+	fnIdx, start, _ := helpSpan(lines, name)
+	if fnIdx == -1 {
+		return -1, -1
+	}
+	from = fnIdx
+	if start != -1 {
+		from = start
+	}
+	return from, FunctionEnd(lines, fnIdx)
+}
+
+// indexLine finds the "#   name" entry of the FUNCTIONS list in a toolkit
+// header, looking only above the first line of code at before.
+func indexLine(lines []string, name string, before int) int {
+	for i := 0; i < before && i < len(lines); i++ {
+		t := strings.TrimSpace(lines[i])
+		if rest, ok := strings.CutPrefix(t, "#"); ok && strings.EqualFold(strings.TrimSpace(rest), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// RemoveFunction deletes a function and its help block from path, along
+// with its entry in the toolkit's FUNCTIONS index.
+func RemoveFunction(path, name string) error {
+	lines, crlf, err := readScriptLines(path)
+	if err != nil {
+		return err
+	}
+	from, to := functionSpan(lines, name)
+	if from == -1 {
+		return fmt.Errorf("function %s not found in %s", name, path)
+	}
+	if from > 0 && strings.TrimSpace(lines[from-1]) == "" && (to+1 >= len(lines) || strings.TrimSpace(lines[to+1]) == "") {
+		from--
+	}
+	out := append(append([]string{}, lines[:from]...), lines[to+1:]...)
+	if idx := indexLine(out, name, from); idx != -1 {
+		out = append(out[:idx], out[idx+1:]...)
+	}
+	return writeScriptLines(path, out, crlf)
+}
+
+// RenameFunction renames a function in path, including references in its
+// own help block and body and its FUNCTIONS index entry. newName must not
+// be used by any plugin under baseDir, or the rename would only create a
+// new duplicate.
+func RenameFunction(baseDir, path, name, newName string) error {
+	if !psFunctionName.MatchString(newName) {
+		return fmt.Errorf("invalid function name %q", newName)
+	}
+	lines, crlf, err := readScriptLines(path)
+	if err != nil {
+		return err
+	}
+	if fnIdx, _, _ := helpSpan(lines, newName); fnIdx != -1 {
+		return fmt.Errorf("function %s already exists in %s", newName, path)
+	}
+	entries, err := ListEntries(baseDir, true)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.Name, newName) {
+			return fmt.Errorf("%s already exists in %s", newName, e.Path)
+		}
+	}
+	from, to := functionSpan(lines, name)
+	if from == -1 {
+		return fmt.Errorf("function %s not found in %s", name, path)
+	}
+	word := regexp.MustCompile(`(?i)(^|[^A-Za-z0-9_-])` + regexp.QuoteMeta(name) + `($|[^A-Za-z0-9_-])`)
+	rename := func(s string) string {
+		return word.ReplaceAllString(s, "${1}"+newName+"${2}")
+	}
+	for i := from; i <= to; i++ {
+		lines[i] = rename(lines[i])
+	}
+	if idx := indexLine(lines, name, from); idx != -1 {
+		lines[idx] = rename(lines[idx])
+	}
+	return writeScriptLines(path, lines, crlf)
+}
@@ -0,0 +1,114 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const dedupeToolkit = "# FUNCTIONS\r\n#   net_ping\r\n#   net_dns\r\n# ====\r\n\r\n" +
+	"function _assert_path_exists { }\r\n\r\n" +
+	"<#\r\n.SYNOPSIS\r\nPing a host.\r\n.EXAMPLE\r\nnet_ping -Host x\r\n#>\r\nfunction net_ping {\r\n    param($Host)\r\n    if ($Host) { net_ping_inner }\r\n}\r\n\r\n" +
+	"function net_dns { }\r\n"
+
+func writeDedupeFixture(t *testing.T) (string, string, string) {
+	t.Helper()
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	baseDir := t.TempDir()
+	pluginsDir := filepath.Join(baseDir, "plugins")
+	if err := os.MkdirAll(filepath.Join(pluginsDir, "old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	active := filepath.Join(pluginsDir, "Net_Toolkit.ps1")
+	shadowed := filepath.Join(pluginsDir, "old", "Old_Net_Toolkit.ps1")
+	if err := os.WriteFile(active, []byte("function _assert_path_exists { }\nfunction net_ping { }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(shadowed, []byte(dedupeToolkit), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return baseDir, active, shadowed
+}
+
+func TestFindDuplicateFunctions(t *testing.T) {
+	baseDir, active, shadowed := writeDedupeFixture(t)
+	dups, err := FindDuplicateFunctions(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dups) != 1 {
+		t.Fatalf("expected only net_ping (private helpers are ignored), got %+v", dups)
+	}
+	d := dups[0]
+	if d.Name != "net_ping" || d.Active != active || len(d.Shadowed) != 1 || d.Shadowed[0] != shadowed {
+		t.Fatalf("unexpected duplicate: %+v", d)
+	}
+}
+
+func TestRemoveFunction(t *testing.T) {
+	_, _, shadowed := writeDedupeFixture(t)
+	if err := RemoveFunction(shadowed, "net_ping"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(shadowed)
+	text := string(data)
+	want := "# FUNCTIONS\r\n#   net_dns\r\n# ====\r\n\r\nfunction _assert_path_exists { }\r\n\r\nfunction net_dns { }\r\n"
+	if text != want {
+		t.Fatalf("unexpected file after remove:\n%q", text)
+	}
+	if err := RemoveFunction(shadowed, "net_ping"); err == nil {
+		t.Fatal("expected an error for a missing function")
+	}
+}
+
+func TestRenameFunction(t *testing.T) {
+	baseDir, active, shadowed := writeDedupeFixture(t)
+	if err := RenameFunction(baseDir, shadowed, "net_ping", "net_dns"); err == nil {
+		t.Fatal("expected an error when the new name exists")
+	}
+	if err := os.WriteFile(active, []byte("function net_ping { }\nfunction net_ping_old { }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ResetCaches()
+	if err := RenameFunction(baseDir, shadowed, "net_ping", "net_ping_old"); err == nil || !strings.Contains(err.Error(), active) {
+		t.Fatalf("expected a name defined in another file to be rejected, got %v", err)
+	}
+	if err := RenameFunction(baseDir, shadowed, "net_ping", "net_ping_legacy"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(shadowed)
+	text := string(data)
+	for _, want := range []string{"#   net_ping_legacy\r\n", "function net_ping_legacy {\r\n", "net_ping_legacy -Host x", "net_ping_inner"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q after rename:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "function net_ping {") {
+		t.Fatalf("old definition still present:\n%s", text)
+	}
+}
+
+func TestRemoveFunctionWithBracesInStringsKeepsBackup(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "G_Git.ps1")
+	src := "function gs {\n  Write-Host \"}\"\n  # }\n  git status\n}\n\nfunction gl { git log }\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveFunction(path, "gs"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "\nfunction gl { git log }\n" {
+		t.Fatalf("unexpected file after remove:\n%q", data)
+	}
+	backups, _ := os.ReadDir(ScriptBackupDir())
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %d", len(backups))
+	}
+	saved, _ := os.ReadFile(filepath.Join(ScriptBackupDir(), backups[0].Name()))
+	if string(saved) != src {
+		t.Fatalf("backup does not hold the original:\n%q", saved)
+	}
+}
@@ -0,0 +1,184 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"cli/internal/platform"
+)
+
+// helpSpan locates the function definition line of name in lines and the
+// comment-based help block right above it (start/end are -1 when there is
+// none). An empty name refers to a script's leading help block.
+func helpSpan(lines []string, name string) (fnIdx, start, end int) {
+	fnIdx, start, end = -1, -1, -1
+	if name == "" {
+		fnIdx = 0
+		for fnIdx < len(lines) && strings.TrimSpace(lines[fnIdx]) == "" {
+			fnIdx++
+		}
+		if fnIdx < len(lines) && strings.TrimSpace(lines[fnIdx]) == "<#" {
+			start = fnIdx
+			for i := start + 1; i < len(lines); i++ {
+				if strings.TrimSpace(lines[i]) == "#>" {
+					end = i
+					break
+				}
+			}
+			if end == -1 {
+				start = -1
+			}
+		}
+		return fnIdx, start, end
+	}
+	for i, line := range lines {
+		m := psFunctionLine.FindStringSubmatch(line)
+		if len(m) == 2 && strings.EqualFold(strings.TrimSpace(m[1]), name) {
+			fnIdx = i
+			break
+		}
+	}
+	if fnIdx == -1 {
+		return -1, -1, -1
+	}
+	e := fnIdx - 1
+	for e >= 0 && strings.TrimSpace(lines[e]) == "" {
+		e--
+	}
+	if e < 0 || strings.TrimSpace(lines[e]) != "#>" {
+		return fnIdx, -1, -1
+	}
+	s := e - 1
+	for s >= 0 && strings.TrimSpace(lines[s]) != "<#" {
+		s--
+	}
+	if s < 0 {
+		return fnIdx, -1, -1
+	}
+	return fnIdx, s, e
+}
+
+// FunctionSource returns the code of a PowerShell function in path, from
+// its definition line to the matching closing brace.
+func FunctionSource(path, name string) (string, error) {
+	lines, _, err := readScriptLines(path)
+	if err != nil {
+		return "", err
+	}
+	fnIdx, _, _ := helpSpan(lines, name)
+	if fnIdx == -1 {
+		return "", fmt.Errorf("function %s not found in %s", name, path)
+	}
+	return strings.Join(lines[fnIdx:FunctionEnd(lines, fnIdx)+1], "\n"), nil
+}
+
+// FunctionLines returns a function with its help block from path and the
+// 1-based line number it starts at.
+func FunctionLines(path, name string) (int, []string, error) {
+	lines, _, err := readScriptLines(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	from, to := functionSpan(lines, name)
+	if from == -1 {
+		return 0, nil, fmt.Errorf("function %s not found in %s", name, path)
+	}
+	return from + 1, lines[from : to+1], nil
+}
+
+// readScriptLines splits a script into lines without their line endings and
+// reports whether it used CRLF, so writeScriptLines can keep them.
+func readScriptLines(path string) ([]string, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	text := string(data)
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), strings.Contains(text, "\r\n"), nil
+}
+
+// ScriptBackupDir is where a script is copied before dm edits it in place.
+func ScriptBackupDir() string {
+	return platform.StatePath("plugin-backups")
+}
+
+// writeScriptLines saves lines over path after copying the old file to
+// ScriptBackupDir; the write is atomic.
+func writeScriptLines(path string, lines []string, crlf bool) error {
+	updated := strings.Join(lines, "\n")
+	if crlf {
+		updated = strings.ReplaceAll(updated, "\n", "\r\n")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if _, err := platform.BackupFile(path, ScriptBackupDir(), time.Now()); err != nil {
+		return fmt.Errorf("cannot back up %s: %w", path, err)
+	}
+	if err := platform.WriteFileAtomic(path, []byte(updated), info.Mode().Perm()); err != nil {
+		return err
+	}
+	ResetCaches()
+	return nil
+}
+
+// HasHelpBlock reports whether the function (or, with an empty name, the
+// script) in path already has a comment-based help block.
+func HasHelpBlock(path, name string) (bool, error) {
+	lines, _, err := readScriptLines(path)
+	if err != nil {
+		return false, err
+	}
+	_, start, _ := helpSpan(lines, name)
+	return start != -1, nil
+}
+
+// ValidateHelpBlock checks that block is a single comment-based help
+// block: "<#" and "#>" on their own first and last lines and neither
+// marker anywhere in between, where it would end the comment early and
+// leave the rest of the text to run as code.
+func ValidateHelpBlock(block string) error {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(block, "\r\n", "\n"), "\n"), "\n")
+	if len(lines) < 2 || strings.TrimSpace(lines[0]) != "<#" || strings.TrimSpace(lines[len(lines)-1]) != "#>" {
+		return fmt.Errorf("help block must start with <# and end with #>")
+	}
+	for _, line := range lines[1 : len(lines)-1] {
+		if strings.Contains(line, "#>") || strings.Contains(line, "<#") {
+			return fmt.Errorf("help block text contains a comment marker: %q", strings.TrimSpace(line))
+		}
+	}
+	return nil
+}
+
+// InsertHelpBlock writes block above the function name in path (or at the
+// top of a script when name is empty), replacing an existing help block.
+// The file's line endings are kept; a block that fails ValidateHelpBlock
+// is rejected.
+func InsertHelpBlock(path, name, block string) error {
+	if err := ValidateHelpBlock(block); err != nil {
+		return err
+	}
+	lines, crlf, err := readScriptLines(path)
+	if err != nil {
+		return err
+	}
+	fnIdx, start, end := helpSpan(lines, name)
+	if fnIdx == -1 {
+		return fmt.Errorf("function %s not found in %s", name, path)
+	}
+	blockLines := strings.Split(strings.TrimRight(strings.ReplaceAll(block, "\r\n", "\n"), "\n"), "\n")
+	var out []string
+	if start != -1 {
+		out = append(out, lines[:start]...)
+		out = append(out, blockLines...)
+		out = append(out, lines[end+1:]...)
+	} else {
+		out = append(out, lines[:fnIdx]...)
+		out = append(out, blockLines...)
+		out = append(out, lines[fnIdx:]...)
+	}
+	return writeScriptLines(path, out, crlf)
+}
@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInsertHelpBlockAboveFunction(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "Git_Toolkit.ps1")
+	content := "# header\r\n\r\nfunction g_status {\r\n    param([string]$Path)\r\n    git status\r\n}\r\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src, err := FunctionSource(path, "g_status")
+	if err != nil || !strings.HasPrefix(src, "function g_status") || !strings.HasSuffix(src, "}") {
+		t.Fatalf("unexpected source %q, %v", src, err)
+	}
+	if has, _ := HasHelpBlock(path, "g_status"); has {
+		t.Fatal("expected no help block yet")
+	}
+
+	block := "<#\n.SYNOPSIS\nShow git status\n#>"
+	if err := InsertHelpBlock(path, "g_status", block); err != nil {
+		t.Fatal(err)
+	}
+	if err := InsertHelpBlock(path, "g_status", "<#\n.SYNOPSIS\nShow repository status\n#>"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	got := string(data)
+	if strings.Count(got, ".SYNOPSIS") != 1 || !strings.Contains(got, "Show repository status\r\n#>\r\nfunction g_status") {
+		t.Fatalf("unexpected file:\n%q", got)
+	}
+	help, err := parsePowerShellFunctionHelp(path, "g_status")
+	if err != nil || help.Synopsis != "Show repository status" {
+		t.Fatalf("unexpected help %+v, %v", help, err)
+	}
+}
+
+func TestInsertHelpBlockAtScriptTop(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "cleanup.ps1")
+	if err := os.WriteFile(path, []byte("param([int]$Days)\nGet-ChildItem\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := InsertHelpBlock(path, "", "<#\n.SYNOPSIS\nDelete old files\n#>"); err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := HasHelpBlock(path, ""); !has {
+		t.Fatal("expected the script to have a help block")
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "<#\n.SYNOPSIS\nDelete old files\n#>\nparam(") {
+		t.Fatalf("unexpected file:\n%s", data)
+	}
+}
+
+func TestInsertHelpBlockRejectsCommentMarkers(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "G_Git.ps1")
+	src := "function g_status {\n    git status\n}\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range []string{
+		"<#\n.SYNOPSIS\nShow status #>\nRemove-Item -Recurse C:\\ <#\n#>",
+		"<#\n.SYNOPSIS\nnested <# comment\n#>",
+		".SYNOPSIS\nShow status",
+	} {
+		if err := InsertHelpBlock(path, "g_status", block); err == nil {
+			t.Fatalf("expected %q to be rejected", block)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != src {
+		t.Fatalf("file changed:\n%s", data)
+	}
+}
+
+func TestFunctionLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "T_Toolkit.ps1")
+	src := "# header\r\n\r\n<#\r\n.SYNOPSIS\r\nFirst.\r\n#>\r\nfunction t_one {\r\n    'one'\r\n}\r\n\r\nfunction t_two { 'two' }\r\n"
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first, lines, err := FunctionLines(path, "t_one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != 3 || len(lines) != 7 || lines[0] != "<#" || lines[6] != "}" {
+		t.Fatalf("unexpected block at %d: %q", first, lines)
+	}
+	if first, lines, err = FunctionLines(path, "t_two"); err != nil || first != 11 || len(lines) != 1 {
+		t.Fatalf("unexpected one-line block at %d: %q (err %v)", first, lines, err)
+	}
+	if _, _, err := FunctionLines(path, "t_three"); err == nil {
+		t.Fatal("expected an error for a missing function")
+	}
+}
@@ -0,0 +1,135 @@
+package plugins
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LintSynopsisMax is the longest synopsis the linter accepts; longer ones
+// crowd the agent catalog.
+const LintSynopsisMax = 120
+
+// lintSimilarity is the word overlap at which two synopses are reported as
+// duplicate-sounding.
+const lintSimilarity = 0.8
+
+// LintIssue is a documentation problem in a toolkit function that makes it
+// harder for the planner to pick the right one.
+type LintIssue struct {
+	Function string `json:"function"`
+	Path     string `json:"path"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+var lintStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "of": true, "to": true,
+	"in": true, "on": true, "for": true, "with": true, "from": true, "by": true, "is": true,
+}
+
+// Lint checks the help of every public PowerShell function under
+// baseDir/plugins: missing or overlong synopses, parameters without a
+// .PARAMETER entry and synopses that read almost the same as another one.
+func Lint(baseDir string) ([]LintIssue, error) {
+	files, err := ListFunctionFiles(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	type linted struct {
+		name, path, synopsis string
+		words                map[string]bool
+	}
+	var all []linted
+	var issues []LintIssue
+	for _, f := range files {
+		for _, name := range f.Functions {
+			if !isPublicFunctionName(name) {
+				continue
+			}
+			help, err := parsePowerShellFunctionHelp(f.Path, name)
+			if err != nil {
+				return nil, err
+			}
+			params := parsePowerShellParamBlock(f.Path, name)
+			issues = append(issues, lintFunction(name, f.Path, help, params)...)
+			if words := synopsisWords(help.Synopsis); len(words) > 0 {
+				all = append(all, linted{name: name, path: f.Path, synopsis: help.Synopsis, words: words})
+			}
+		}
+	}
+	for i := range all {
+		for j := i + 1; j < len(all); j++ {
+			if wordOverlap(all[i].words, all[j].words) < lintSimilarity {
+				continue
+			}
+			issues = append(issues,
+				LintIssue{Function: all[i].name, Path: all[i].path, Rule: "synopsis-duplicate",
+					Message: fmt.Sprintf("synopsis sounds like %s (%q)", all[j].name, all[j].synopsis)},
+				LintIssue{Function: all[j].name, Path: all[j].path, Rule: "synopsis-duplicate",
+					Message: fmt.Sprintf("synopsis sounds like %s (%q)", all[i].name, all[i].synopsis)},
+			)
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Function < issues[j].Function
+	})
+	return issues, nil
+}
+
+func lintFunction(name, path string, help functionHelp, params []ParamDetail) []LintIssue {
+	var issues []LintIssue
+	add := func(rule, msg string) {
+		issues = append(issues, LintIssue{Function: name, Path: path, Rule: rule, Message: msg})
+	}
+	synopsis := strings.TrimSpace(help.Synopsis)
+	switch {
+	case synopsis == "" || strings.EqualFold(synopsis, name):
+		add("synopsis-missing", "no .SYNOPSIS")
+	case len(synopsis) > LintSynopsisMax:
+		add("synopsis-long", fmt.Sprintf("synopsis is %d chars (max %d)", len(synopsis), LintSynopsisMax))
+	}
+	documented := map[string]bool{}
+	for _, p := range help.Parameters {
+		pname, text, _ := strings.Cut(p, ":")
+		if strings.TrimSpace(text) != "" {
+			documented[strings.ToLower(strings.TrimSpace(pname))] = true
+		}
+	}
+	for _, p := range params {
+		if !documented[strings.ToLower(p.Name)] {
+			add("param-undocumented", fmt.Sprintf("parameter %s has no .PARAMETER description", p.Name))
+		}
+	}
+	return issues
+}
+
+func synopsisWords(synopsis string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(synopsis), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !lintStopWords[w] {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// wordOverlap is the Jaccard index of two word sets.
+func wordOverlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
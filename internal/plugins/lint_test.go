@@ -0,0 +1,51 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	clearPluginCacheForTest()
+	baseDir := t.TempDir()
+	pluginsDir := filepath.Join(baseDir, "plugins")
+	if err := os.MkdirAll(pluginsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	long := strings.Repeat("word ", 30)
+	content := strings.Join([]string{
+		"<#\n.SYNOPSIS\nShow the status of the git repository\n.PARAMETER Path\nRepository folder\n#>\nfunction g_status {\n  param([string]$Path)\n}\n",
+		"<#\n.SYNOPSIS\nShow status of git repository\n#>\nfunction g_state {\n  param([string]$Path, [switch]$Short)\n}\n",
+		"function g_nodoc {\n}\n",
+		"<#\n.SYNOPSIS\n" + long + "\n#>\nfunction g_long {\n}\n",
+		"function _g_helper {\n}\n",
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(pluginsDir, "Git_Toolkit.ps1"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := Lint(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, is := range issues {
+		got[is.Function+":"+is.Rule] = true
+	}
+	for _, want := range []string{
+		"g_nodoc:synopsis-missing",
+		"g_long:synopsis-long",
+		"g_state:param-undocumented",
+		"g_status:synopsis-duplicate",
+		"g_state:synopsis-duplicate",
+	} {
+		if !got[want] {
+			t.Fatalf("missing %s in %+v", want, issues)
+		}
+	}
+	if got["g_status:param-undocumented"] || got["_g_helper:synopsis-missing"] {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+}
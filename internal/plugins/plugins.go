@@ -46,6 +46,7 @@ type Info struct {
 type RunError struct {
 	Err    error
 	Output string
+	Kind   ErrorKind
 }
 
 func (e *RunError) Error() string {
@@ -153,6 +154,68 @@ func ListFunctionFiles(baseDir string) ([]FunctionFile, error) {
 	return out, nil
 }
 
+// Conflict is a plugin or function name defined by more than one file.
+// Sources[0] is the definition dm actually runs.
+type Conflict struct {
+	Name    string
+	Sources []string
+}
+
+func FindConflicts(baseDir string) ([]Conflict, error) {
+	dir := filepath.Join(baseDir, "plugins")
+	sources := map[string][]string{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !isSupportedPlugin(e.Name()) {
+			continue
+		}
+		name := pluginName(e.Name())
+		sources[name] = append(sources[name], filepath.Join(dir, e.Name()))
+	}
+	for name, paths := range sources {
+		sort.SliceStable(paths, func(i, j int) bool { return pluginScore(paths[i]) < pluginScore(paths[j]) })
+		sources[name] = paths
+	}
+	files, err := listPowerShellFunctionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range files {
+		names, err := readPowerShellFunctionNames(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range names {
+			if !containsPath(sources[n], p) {
+				sources[n] = append(sources[n], p)
+			}
+		}
+	}
+	var out []Conflict
+	for name, paths := range sources {
+		if len(paths) > 1 {
+			out = append(out, Conflict{Name: name, Sources: paths})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func containsPath(paths []string, p string) bool {
+	for _, v := range paths {
+		if strings.EqualFold(v, p) {
+			return true
+		}
+	}
+	return false
+}
+
 func GetInfo(baseDir, name string) (Info, error) {
 	dir := filepath.Join(baseDir, "plugins")
 	cacheKey := infoCacheKey(dir, name)
@@ -251,7 +314,18 @@ func RunWithOutputAgent(baseDir, name string, args []string) RunResult {
 	return runPluginInternal(baseDir, name, args, false)
 }
 
+// RunScriptFile runs a standalone script file the way a plugin of the same
+// type is run, with the terminal attached.
+func RunScriptFile(path string) RunResult {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	out, err := execPluginCapture(name, path, nil, true)
+	return RunResult{Output: out, Err: err}
+}
+
 func runPluginInternal(baseDir, name string, args []string, interactive bool) RunResult {
+	if IsSandboxed(name) {
+		return runSandboxedInternal(baseDir, name, args, interactive)
+	}
 	dir := filepath.Join(baseDir, "plugins")
 	candidate, err := findPlugin(dir, name)
 	if err != nil {
@@ -274,7 +348,7 @@ func runPluginInternal(baseDir, name string, args []string, interactive bool) Ru
 		out, runErr := runPowerShellFunctionCapture(sources, name, args, interactive)
 		return RunResult{Output: out, Err: runErr}
 	}
-	out, runErr := execPluginCapture(candidate, args, interactive)
+	out, runErr := execPluginCapture(name, candidate, args, interactive)
 	return RunResult{Output: out, Err: runErr}
 }
 
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -15,6 +16,38 @@ import (
 
 const pluginExecTimeout = 5 * time.Minute
 
+var extraEnvFor func(name string) []string
+
+// SetExtraEnv makes plugin runs get the KEY=value entries env returns for
+// the plugin name. Sandboxed runs do not receive them.
+func SetExtraEnv(env func(name string) []string) {
+	extraEnvFor = env
+}
+
+func pluginEnv(name string) []string {
+	if extraEnvFor == nil {
+		return nil
+	}
+	extra := extraEnvFor(name)
+	if len(extra) == 0 {
+		return nil
+	}
+	return append(os.Environ(), extra...)
+}
+
+// MatchName reports whether pattern selects the plugin name. A pattern
+// without glob characters is a prefix, so "git" and "git_*" both select
+// every git_ function.
+func MatchName(pattern, name string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.ContainsAny(pattern, "*?[") {
+		return name == pattern || strings.HasPrefix(name, pattern+"_")
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
 type psNamedArg struct {
 	Name     string
 	Value    string
@@ -92,6 +125,7 @@ func buildPowerShellFunctionScript(profilePaths []string, functionName string, a
 		"$dmProfilePaths=@(" + strings.Join(quotedPaths, ",") + ")",
 		"$dmNamedArgs=@{}",
 		"$dmPositionalArgs=@()",
+		"function dm_secret([string]$Name){ [Environment]::GetEnvironmentVariable('DM_SECRET_'+$Name.ToUpper()) }",
 	}
 	for _, a := range namedArgs {
 		valueExpr := "$true"
@@ -136,6 +170,7 @@ func runPowerShellFunctionCapture(profilePaths []string, functionName string, ar
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, ps, "-NoProfile", "-NonInteractive", "-File", tmpPath)
+	cmd.Env = pluginEnv(functionName)
 	var output bytes.Buffer
 	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
 	cmd.Stderr = io.MultiWriter(os.Stderr, &output)
@@ -143,18 +178,12 @@ func runPowerShellFunctionCapture(profilePaths []string, functionName string, ar
 		cmd.Stdin = os.Stdin
 	}
 	if err := cmd.Run(); err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return output.String(), &RunError{
-				Err:    errors.New("plugin execution timed out after " + pluginExecTimeout.String()),
-				Output: output.String(),
-			}
-		}
-		return output.String(), &RunError{Err: err, Output: output.String()}
+		return output.String(), newRunError(err, output.String(), ctx.Err() == context.DeadlineExceeded)
 	}
 	return output.String(), nil
 }
 
-func execPluginCapture(path string, args []string, interactive bool) (string, error) {
+func execPluginCapture(name, path string, args []string, interactive bool) (string, error) {
 	ext := strings.ToLower(filepath.Ext(path))
 
 	ctx, cancel := context.WithTimeout(context.Background(), pluginExecTimeout)
@@ -202,6 +231,7 @@ func execPluginCapture(path string, args []string, interactive bool) (string, er
 	if len(args) > 0 {
 		cmd.Args = append(cmd.Args, args...)
 	}
+	cmd.Env = pluginEnv(name)
 
 	var output bytes.Buffer
 	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
@@ -210,13 +240,7 @@ func execPluginCapture(path string, args []string, interactive bool) (string, er
 		cmd.Stdin = os.Stdin
 	}
 	if err := cmd.Run(); err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			return output.String(), &RunError{
-				Err:    errors.New("plugin execution timed out after " + pluginExecTimeout.String()),
-				Output: output.String(),
-			}
-		}
-		return output.String(), &RunError{Err: err, Output: output.String()}
+		return output.String(), newRunError(err, output.String(), ctx.Err() == context.DeadlineExceeded)
 	}
 	return output.String(), nil
 }
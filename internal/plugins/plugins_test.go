@@ -11,10 +11,7 @@ import (
 )
 
 func clearPluginCacheForTest() {
-	cacheMu.Lock()
-	entryListCache = map[string]entryListCacheValue{}
-	entryInfoCache = map[string]entryInfoCacheValue{}
-	cacheMu.Unlock()
+	ResetCaches()
 }
 
 func TestRunNotFound(t *testing.T) {
@@ -358,6 +355,40 @@ func TestListFunctionFiles(t *testing.T) {
 	}
 }
 
+func TestFindConflicts(t *testing.T) {
+	baseDir := t.TempDir()
+	pluginsDir := filepath.Join(baseDir, "plugins")
+	if err := os.MkdirAll(filepath.Join(pluginsDir, "functions"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"backup.ps1":            "Write-Host backup\n",
+		"backup.cmd":            "echo backup\n",
+		"functions/git.ps1":     "function gs { }\nfunction gl { }\n",
+		"functions/z_extra.ps1": "function gs { git status -sb }\n",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(pluginsDir, filepath.FromSlash(name)), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conflicts, err := FindConflicts(baseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", conflicts)
+	}
+	if conflicts[0].Name != "backup" || len(conflicts[0].Sources) != 2 {
+		t.Fatalf("unexpected script conflict: %+v", conflicts[0])
+	}
+	gs := conflicts[1]
+	if gs.Name != "gs" || len(gs.Sources) != 2 || filepath.Base(gs.Sources[0]) != "git.ps1" {
+		t.Fatalf("unexpected function conflict: %+v", gs)
+	}
+}
+
 func TestSplitPowerShellSplatArgs(t *testing.T) {
 	args := []string{"-ComputerName", "192.168.50.235", "-Count", "4", "-Force", "extra"}
 	named, positional := splitPowerShellSplatArgs(args)
@@ -407,4 +438,7 @@ func TestBuildPowerShellFunctionScript_UsesNamedAndPositionalSplat(t *testing.T)
 	if !strings.Contains(script, "& 'sys_ping' @dmNamedArgs @dmPositionalArgs") {
 		t.Fatalf("missing final named/positional splat invocation:\n%s", script)
 	}
+	if !strings.Contains(script, "function dm_secret(") {
+		t.Fatalf("missing dm_secret helper:\n%s", script)
+	}
 }
@@ -0,0 +1,115 @@
+package plugins
+
+import "strings"
+
+type psScanState int
+
+const (
+	psCode psScanState = iota
+	psSingleQuoted
+	psDoubleQuoted
+	psHereSingle
+	psHereDouble
+	psBlockComment
+)
+
+// psScanner finds the braces of PowerShell code line by line, skipping
+// quoted strings, here-strings and comments. Strings and block comments
+// may span lines, so the state carries over between calls.
+type psScanner struct {
+	state psScanState
+}
+
+// braces returns the '{' and '}' of line that are code, in order.
+func (s *psScanner) braces(line string) []byte {
+	switch s.state {
+	case psHereSingle, psHereDouble:
+		closing := "'@"
+		if s.state == psHereDouble {
+			closing = `"@`
+		}
+		if !strings.HasPrefix(line, closing) {
+			return nil
+		}
+		s.state = psCode
+		line = line[len(closing):]
+	}
+	var out []byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		next := byte(0)
+		if i+1 < len(line) {
+			next = line[i+1]
+		}
+		switch s.state {
+		case psBlockComment:
+			if c == '#' && next == '>' {
+				s.state = psCode
+				i++
+			}
+		case psSingleQuoted:
+			if c == '\'' {
+				if next == '\'' {
+					i++
+				} else {
+					s.state = psCode
+				}
+			}
+		case psDoubleQuoted:
+			switch {
+			case c == '`':
+				i++
+			case c == '"' && next == '"':
+				i++
+			case c == '"':
+				s.state = psCode
+			}
+		default:
+			switch {
+			case c == '`':
+				i++
+			case c == '<' && next == '#':
+				s.state = psBlockComment
+				i++
+			case c == '#' && (i == 0 || strings.IndexByte(" \t;{}()|&=,", line[i-1]) >= 0):
+				return out
+			case c == '@' && (next == '\'' || next == '"') && strings.TrimSpace(line[i+2:]) == "":
+				s.state = psHereSingle
+				if next == '"' {
+					s.state = psHereDouble
+				}
+				return out
+			case c == '\'':
+				s.state = psSingleQuoted
+			case c == '"':
+				s.state = psDoubleQuoted
+			case c == '{' || c == '}':
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// FunctionEnd returns the index of the line closing the PowerShell function
+// that starts at fnIdx, or the last line when the braces never balance.
+// Braces in strings, here-strings and comments do not count.
+func FunctionEnd(lines []string, fnIdx int) int {
+	var s psScanner
+	depth := 0
+	opened := false
+	for i := fnIdx; i < len(lines); i++ {
+		for _, b := range s.braces(lines[i]) {
+			if b == '{' {
+				depth++
+				opened = true
+				continue
+			}
+			depth--
+			if opened && depth <= 0 {
+				return i
+			}
+		}
+	}
+	return len(lines) - 1
+}
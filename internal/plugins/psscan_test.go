@@ -0,0 +1,32 @@
+package plugins
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFunctionEndSkipsStringsAndComments(t *testing.T) {
+	cases := map[string]string{
+		"double quoted":  "function gs {\n  Write-Host \"}\"\n  git status\n}\nfunction next { }",
+		"single quoted":  "function gs {\n  $s = '{ it''s }'\n  git status\n}\nfunction next { }",
+		"escaped quote":  "function gs {\n  Write-Host \"`\"}\"\n  git status\n}\nfunction next { }",
+		"line comment":   "function gs {\n  # close with }\n  git status\n}\nfunction next { }",
+		"block comment":  "function gs {\n  <# } \n  { #>\n  git status\n}\nfunction next { }",
+		"here-string":    "function gs {\n  $t = @\"\n}\n\"@\n  $u = @'\n{\n'@\n  git status\n}\nfunction next { }",
+		"nested blocks":  "function gs {\n  if ($x) { 'a' } else {\n    'b'\n  }\n  git status\n}\nfunction next { }",
+		"multi-line str": "function gs {\n  $s = \"first }\nsecond }\"\n  git status\n}\nfunction next { }",
+	}
+	for name, src := range cases {
+		lines := strings.Split(src, "\n")
+		end := FunctionEnd(lines, 0)
+		if lines[end] != "}" || lines[end-1] != "  git status" {
+			t.Fatalf("%s: function ends at line %d %q", name, end, lines[end])
+		}
+	}
+	if got := FunctionEnd([]string{"function t_two { 'two' }", "x"}, 0); got != 0 {
+		t.Fatalf("one-line function should end on its own line, got %d", got)
+	}
+	if got := FunctionEnd([]string{"function open {", "  'x'"}, 0); got != 1 {
+		t.Fatalf("unbalanced function should run to the last line, got %d", got)
+	}
+}
@@ -0,0 +1,55 @@
+package plugins
+
+import (
+	"errors"
+	"regexp"
+)
+
+// ErrorKind says why a plugin run failed, as far as its output tells.
+type ErrorKind string
+
+const (
+	ErrorNotFound ErrorKind = "not-found"
+	ErrorSyntax   ErrorKind = "syntax"
+	ErrorThrow    ErrorKind = "runtime-throw"
+	ErrorExit     ErrorKind = "exit-code"
+	ErrorTimeout  ErrorKind = "timeout"
+)
+
+var (
+	syntaxErrorOutput = regexp.MustCompile(`(?i)ParserError|Unexpected token|Missing closing '[^']+'|missing the terminator|Missing expression after|syntax error`)
+	notFoundOutput    = regexp.MustCompile(`(?i)is not recognized as (?:the |a )?name of a cmdlet|CommandNotFoundException|ItemNotFoundException|Cannot find path|required path '[^']+' does not exist|was not loaded from plugin sources|No such file or directory|command not found|(?m): not found$`)
+	throwOutput       = regexp.MustCompile(`(?m)^(?:\w*Exception: |\s*\+ FullyQualifiedErrorId|Line \|)|RuntimeException`)
+)
+
+func newRunError(err error, output string, timedOut bool) *RunError {
+	if timedOut {
+		return &RunError{Err: errors.New("plugin execution timed out after " + pluginExecTimeout.String()), Output: output, Kind: ErrorTimeout}
+	}
+	return &RunError{Err: err, Output: output, Kind: classifyRunOutput(output)}
+}
+
+// classifyRunOutput checks the most specific causes first: a parse error
+// also prints an exception header, and a missing command is reported as a
+// thrown error by PowerShell.
+func classifyRunOutput(output string) ErrorKind {
+	switch {
+	case syntaxErrorOutput.MatchString(output):
+		return ErrorSyntax
+	case notFoundOutput.MatchString(output):
+		return ErrorNotFound
+	case throwOutput.MatchString(output):
+		return ErrorThrow
+	}
+	return ErrorExit
+}
+
+// ErrorKindOf returns the kind of a failed run, or "" when err is not a
+// RunError.
+func ErrorKindOf(err error) ErrorKind {
+	var re *RunError
+	if errors.As(err, &re) {
+		return re.Kind
+	}
+	return ""
+}
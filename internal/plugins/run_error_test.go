@@ -0,0 +1,38 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyRunOutput(t *testing.T) {
+	cases := map[string]ErrorKind{
+		"ParserError: C:\\p\\x.ps1:3\nLine |\n   3 |  if ($a {\n     | Missing closing ')' in expression.":           ErrorSyntax,
+		"foo: The term 'foo' is not recognized as a name of a cmdlet, function, script file, or executable program.": ErrorNotFound,
+		"Exception: C:\\p\\x.ps1:3\nLine |\n   3 |  throw \"Required path 'D:\\data' does not exist.\"":              ErrorNotFound,
+		"Exception: C:\\p\\x.ps1:5\nLine |\n   5 |  throw \"boom\"\n     | boom":                                     ErrorThrow,
+		"boom\nAt line:1 char:1\n    + CategoryInfo : OperationStopped: (boom:String) [], RuntimeException":          ErrorThrow,
+		"done with warnings":      ErrorExit,
+		"":                        ErrorExit,
+		"sh: 1: rsync: not found": ErrorNotFound,
+	}
+	for out, want := range cases {
+		if got := classifyRunOutput(out); got != want {
+			t.Fatalf("classifyRunOutput(%q) = %q, want %q", out, got, want)
+		}
+	}
+}
+
+func TestNewRunErrorTimeoutWinsOverOutput(t *testing.T) {
+	err := newRunError(errors.New("signal: killed"), "ParserError: bad", true)
+	if err.Kind != ErrorTimeout {
+		t.Fatalf("expected timeout, got %q", err.Kind)
+	}
+	if got := ErrorKindOf(fmt.Errorf("wrapped: %w", err)); got != ErrorTimeout {
+		t.Fatalf("expected kind through wrapping, got %q", got)
+	}
+	if got := ErrorKindOf(errors.New("plain")); got != "" {
+		t.Fatalf("expected no kind for plain errors, got %q", got)
+	}
+}
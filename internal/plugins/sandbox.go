@@ -0,0 +1,209 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sandboxImageRepo  = "mcr.microsoft.com/powershell"
+	sandboxPluginsDir = "/dm/plugins"
+	sandboxRunDir     = "/dm/run"
+	sandboxWorkDir    = "/work"
+	// The container runs as nobody, with a process and memory cap.
+	sandboxUser      = "65534:65534"
+	sandboxPidsLimit = "256"
+	sandboxMemory    = "1g"
+	sandboxTmpfs     = "rw,nosuid,nodev,size=64m,mode=1777"
+)
+
+var (
+	sandboxImage        string
+	sandboxPlugins      = map[string]bool{}
+	sandboxMountWorkdir bool
+)
+
+// SetSandbox applies the "sandbox" section of dm.json: the listed plugins
+// always run in a container built from image, which must be pinned by
+// digest. mountWorkdir mounts the current directory read-only at /work;
+// otherwise /work is an empty scratch directory.
+func SetSandbox(names []string, image string, mountWorkdir bool) {
+	sandboxPlugins = map[string]bool{}
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			sandboxPlugins[strings.ToLower(n)] = true
+		}
+	}
+	sandboxImage = strings.TrimSpace(image)
+	sandboxMountWorkdir = mountWorkdir
+}
+
+// pinnedSandboxImage returns the configured image, refusing one that is not
+// pinned by digest: a tag can be moved to different content at any time.
+func pinnedSandboxImage() (string, error) {
+	if sandboxImage == "" {
+		return "", fmt.Errorf("sandbox needs a digest-pinned image: set sandbox.image in dm.json to %s@sha256:<digest> (after 'docker pull %s:latest', 'docker image inspect --format \"{{index .RepoDigests 0}}\" %s:latest' prints it)", sandboxImageRepo, sandboxImageRepo, sandboxImageRepo)
+	}
+	if !strings.Contains(sandboxImage, "@sha256:") {
+		return "", fmt.Errorf("sandbox image %q is not pinned by digest (use name@sha256:<digest>)", sandboxImage)
+	}
+	return sandboxImage, nil
+}
+
+func IsSandboxed(name string) bool {
+	return sandboxPlugins[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// sandboxSpec describes one containerized plugin run.
+type sandboxSpec struct {
+	Image      string
+	PluginsDir string
+	WorkDir    string
+	RunDir     string
+	Command    []string
+}
+
+// dockerArgs builds `docker run` arguments: no network, no capabilities or
+// privilege escalation, a read-only root filesystem, a non-root user and
+// pids/memory limits. Plugins (and the working directory, when WorkDir is
+// set) are mounted read-only; /tmp and an unmounted /work are scratch space.
+func (s sandboxSpec) dockerArgs(interactive bool) []string {
+	args := []string{
+		"run", "--rm", "--network", "none",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--read-only",
+		"--user", sandboxUser,
+		"--pids-limit", sandboxPidsLimit,
+		"--memory", sandboxMemory,
+		"--tmpfs", "/tmp:" + sandboxTmpfs,
+		"-e", "HOME=/tmp",
+	}
+	if interactive {
+		args = append(args, "-i")
+	}
+	args = append(args, "-v", s.PluginsDir+":"+sandboxPluginsDir+":ro")
+	if s.WorkDir != "" {
+		args = append(args, "-v", s.WorkDir+":"+sandboxWorkDir+":ro")
+	} else {
+		args = append(args, "--tmpfs", sandboxWorkDir+":"+sandboxTmpfs)
+	}
+	if s.RunDir != "" {
+		args = append(args, "-v", s.RunDir+":"+sandboxRunDir+":ro")
+	}
+	args = append(args, "-w", sandboxWorkDir, s.Image)
+	return append(args, s.Command...)
+}
+
+// sandboxPath maps a file under the plugins directory to its container path.
+func sandboxPath(pluginsDir, path string) (string, error) {
+	rel, err := filepath.Rel(pluginsDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside the plugins directory", path)
+	}
+	return sandboxPluginsDir + "/" + filepath.ToSlash(rel), nil
+}
+
+func sandboxScriptCommand(containerPath string) ([]string, error) {
+	switch ext := strings.ToLower(filepath.Ext(containerPath)); ext {
+	case ".ps1":
+		return []string{"pwsh", "-NoProfile", "-NonInteractive", "-File", containerPath}, nil
+	case ".sh":
+		return []string{"sh", containerPath}, nil
+	case ".cmd", ".bat", ".exe":
+		return nil, fmt.Errorf("%s plugins cannot run in the Linux sandbox", ext)
+	}
+	return []string{containerPath}, nil
+}
+
+// RunSandboxed runs a plugin script or function inside a Docker container.
+func RunSandboxed(baseDir, name string, args []string) error {
+	r := runSandboxedInternal(baseDir, name, args, true)
+	return r.Err
+}
+
+func runSandboxedInternal(baseDir, name string, args []string, interactive bool) RunResult {
+	docker := firstAvailableBinary("docker", "podman")
+	if docker == "" {
+		return RunResult{Err: errors.New("sandbox needs docker or podman on PATH")}
+	}
+	image, err := pinnedSandboxImage()
+	if err != nil {
+		return RunResult{Err: err}
+	}
+	dir := filepath.Join(baseDir, "plugins")
+	spec := sandboxSpec{Image: image, PluginsDir: dir}
+	if sandboxMountWorkdir {
+		if spec.WorkDir, err = os.Getwd(); err != nil {
+			return RunResult{Err: err}
+		}
+	}
+
+	candidate, err := findPlugin(dir, name)
+	if err != nil {
+		return RunResult{Err: err}
+	}
+	if candidate != "" {
+		p, err := sandboxPath(dir, candidate)
+		if err != nil {
+			return RunResult{Err: err}
+		}
+		command, err := sandboxScriptCommand(p)
+		if err != nil {
+			return RunResult{Err: err}
+		}
+		spec.Command = append(command, args...)
+	} else {
+		_, loadFiles, found, err := findPowerShellFunction(dir, name)
+		if err != nil {
+			return RunResult{Err: err}
+		}
+		if !found {
+			return RunResult{Err: fmt.Errorf("%w: %s", ErrNotFound, name)}
+		}
+		sources := make([]string, 0, len(loadFiles))
+		for _, f := range loadFiles {
+			p, err := sandboxPath(dir, f)
+			if err != nil {
+				return RunResult{Err: err}
+			}
+			sources = append(sources, p)
+		}
+		runDir, err := os.MkdirTemp("", "dm-sandbox-*")
+		if err != nil {
+			return RunResult{Err: err}
+		}
+		defer func() { _ = os.RemoveAll(runDir) }()
+		// The container user is not the owner of the temp dir.
+		if err := os.Chmod(runDir, 0755); err != nil {
+			return RunResult{Err: err}
+		}
+		script := buildPowerShellFunctionScript(sources, name, args)
+		if err := os.WriteFile(filepath.Join(runDir, "run.ps1"), []byte(script), 0644); err != nil {
+			return RunResult{Err: err}
+		}
+		spec.RunDir = runDir
+		spec.Command = []string{"pwsh", "-NoProfile", "-NonInteractive", "-File", sandboxRunDir + "/run.ps1"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginExecTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, docker, spec.dockerArgs(interactive)...)
+	var output bytes.Buffer
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &output)
+	if interactive {
+		cmd.Stdin = os.Stdin
+	}
+	if err := cmd.Run(); err != nil {
+		return RunResult{Output: output.String(), Err: newRunError(err, output.String(), ctx.Err() == context.DeadlineExceeded)}
+	}
+	return RunResult{Output: output.String()}
+}
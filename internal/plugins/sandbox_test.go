@@ -0,0 +1,84 @@
+package plugins
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSandboxDockerArgs(t *testing.T) {
+	spec := sandboxSpec{
+		Image:      "img",
+		PluginsDir: "/opt/dm/plugins",
+		WorkDir:    "/home/u/src",
+		RunDir:     "/tmp/run",
+		Command:    []string{"pwsh", "-File", "/dm/run/run.ps1"},
+	}
+	got := spec.dockerArgs(true)
+	want := []string{
+		"run", "--rm", "--network", "none",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--read-only",
+		"--user", "65534:65534",
+		"--pids-limit", "256",
+		"--memory", "1g",
+		"--tmpfs", "/tmp:rw,nosuid,nodev,size=64m,mode=1777",
+		"-e", "HOME=/tmp",
+		"-i",
+		"-v", "/opt/dm/plugins:/dm/plugins:ro",
+		"-v", "/home/u/src:/work:ro",
+		"-v", "/tmp/run:/dm/run:ro",
+		"-w", "/work", "img",
+		"pwsh", "-File", "/dm/run/run.ps1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected docker args:\n%v\nwant\n%v", got, want)
+	}
+
+	spec.WorkDir = ""
+	got = spec.dockerArgs(false)
+	if slices.Contains(got, "-i") || !slices.Contains(got, "/work:rw,nosuid,nodev,size=64m,mode=1777") || slices.Contains(got, "/home/u/src:/work:ro") {
+		t.Fatalf("expected a scratch /work without the working directory, got %v", got)
+	}
+}
+
+func TestSandboxPathAndCommand(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plugins")
+	p, err := sandboxPath(dir, filepath.Join(dir, "functions", "git.ps1"))
+	if err != nil || p != "/dm/plugins/functions/git.ps1" {
+		t.Fatalf("unexpected container path %q (err %v)", p, err)
+	}
+	if _, err := sandboxPath(dir, filepath.Join(filepath.Dir(dir), "other.ps1")); err == nil {
+		t.Fatal("expected error for a file outside the plugins directory")
+	}
+	cmd, err := sandboxScriptCommand("/dm/plugins/backup.sh")
+	if err != nil || !reflect.DeepEqual(cmd, []string{"sh", "/dm/plugins/backup.sh"}) {
+		t.Fatalf("unexpected command %v (err %v)", cmd, err)
+	}
+	if _, err := sandboxScriptCommand("/dm/plugins/tool.cmd"); err == nil {
+		t.Fatal("expected .cmd plugins to be rejected")
+	}
+}
+
+func TestSetSandbox(t *testing.T) {
+	defer SetSandbox(nil, "", false)
+	SetSandbox([]string{" Downloaded_Tool "}, "", false)
+	if !IsSandboxed("downloaded_tool") || IsSandboxed("other") {
+		t.Fatal("unexpected sandbox selection")
+	}
+	if _, err := pinnedSandboxImage(); err == nil {
+		t.Fatal("expected an error without a configured image")
+	}
+	SetSandbox(nil, "mcr.microsoft.com/powershell:latest", false)
+	if _, err := pinnedSandboxImage(); err == nil {
+		t.Fatal("expected a tag-only image to be refused")
+	}
+	pinned := "mcr.microsoft.com/powershell@sha256:" + strings.Repeat("ab", 32)
+	SetSandbox(nil, pinned, true)
+	if got, err := pinnedSandboxImage(); err != nil || got != pinned || !sandboxMountWorkdir {
+		t.Fatalf("expected the pinned image, got %q (err %v)", got, err)
+	}
+}
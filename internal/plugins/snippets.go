@@ -0,0 +1,54 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ShellSnippet is a bash or zsh snippet a pack ships next to its toolkit,
+// such as plugins/Docker_Toolkit.bashrc for Docker_Toolkit.ps1.
+type ShellSnippet struct {
+	Pack    string // path below plugins without the extension, e.g. M365/SharePoint_Toolkit
+	Path    string
+	Content string
+}
+
+// ShellSnippets returns the <pack>.<shell>rc files under plugins, sorted
+// by pack.
+func ShellSnippets(baseDir, shell string) ([]ShellSnippet, error) {
+	dir := filepath.Join(baseDir, "plugins")
+	ext := "." + shell + "rc"
+	var out []ShellSnippet
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(d.Name()), ext) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		out = append(out, ShellSnippet{
+			Pack:    filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))),
+			Path:    path,
+			Content: strings.ReplaceAll(string(data), "\r\n", "\n"),
+		})
+		return nil
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Pack) < strings.ToLower(out[j].Pack) })
+	return out, nil
+}
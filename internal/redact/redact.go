@@ -0,0 +1,122 @@
+package redact
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const Mask = "[REDACTED]"
+
+// builtinPatterns mask the whole match except a group named keep (e.g.
+// keep "Bearer ").
+var builtinPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{30,}\b`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`),
+	regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`(?i)(?P<keep>bearer\s+)[A-Za-z0-9._\-]{8,}`),
+	regexp.MustCompile(`(?i)(?P<keep>"?(?:api[_-]?key|token|secret|password|passwd|pwd)"?\s*[:=]\s*"?)[^"\s,}]+`),
+}
+
+// envSecrets are environment variables whose values are masked verbatim.
+var envSecrets = []string{"OPENAI_API_KEY"}
+
+var (
+	mu     sync.RWMutex
+	extra  []*regexp.Regexp
+	values []string
+)
+
+// SetValues masks these literal values (e.g. stored secrets), replacing the
+// previous set. Values shorter than 4 characters are ignored.
+func SetValues(vs []string) {
+	var keep []string
+	for _, v := range vs {
+		if len(strings.TrimSpace(v)) >= 4 {
+			keep = append(keep, v)
+		}
+	}
+	mu.Lock()
+	values = keep
+	mu.Unlock()
+}
+
+// SetPatterns installs user regexes from dm.json ("redact"), replacing the
+// previous set. Invalid patterns are skipped and reported.
+func SetPatterns(patterns []string) error {
+	var compiled []*regexp.Regexp
+	var bad []string
+	for _, p := range patterns {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			bad = append(bad, fmt.Sprintf("%q: %v", p, err))
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	mu.Lock()
+	extra = compiled
+	mu.Unlock()
+	if len(bad) > 0 {
+		return fmt.Errorf("invalid redact patterns: %s", strings.Join(bad, "; "))
+	}
+	return nil
+}
+
+// String masks API keys, tokens, passwords and user patterns in s.
+func String(s string) string {
+	for _, name := range envSecrets {
+		if v := strings.TrimSpace(os.Getenv(name)); len(v) >= 8 {
+			s = strings.ReplaceAll(s, v, Mask)
+		}
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, Mask)
+	}
+	for _, re := range builtinPatterns {
+		s = apply(re, s)
+	}
+	for _, re := range extra {
+		s = apply(re, s)
+	}
+	return s
+}
+
+// apply masks every match of re. Only a group named keep stays in clear
+// text; other groups are masked like the rest of the match, so a pattern
+// such as (token=\S+) cannot leak what it captures.
+func apply(re *regexp.Regexp, s string) string {
+	keep := re.SubexpIndex("keep")
+	if keep < 0 {
+		return re.ReplaceAllString(s, Mask)
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(s[last:m[0]])
+		ks, ke := m[2*keep], m[2*keep+1]
+		if ks < 0 {
+			b.WriteString(Mask)
+		} else {
+			if ks > m[0] {
+				b.WriteString(Mask)
+			}
+			b.WriteString(s[ks:ke])
+			if ke < m[1] {
+				b.WriteString(Mask)
+			}
+		}
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"cli/internal/platform"
 )
 
 type PlanItem struct {
@@ -58,12 +60,13 @@ func BuildPlan(opts Options) ([]PlanItem, error) {
 	}
 
 	var plan []PlanItem
+	root := platform.LongPath(base)
 	walk := func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
 		if d.IsDir() {
-			if !opts.Recursive && path != base {
+			if !opts.Recursive && path != root {
 				return filepath.SkipDir
 			}
 			return nil
@@ -82,7 +85,7 @@ func BuildPlan(opts Options) ([]PlanItem, error) {
 				return nil
 			}
 			newPath := filepath.Join(filepath.Dir(path), newName)
-			plan = append(plan, PlanItem{OldPath: path, NewPath: newPath})
+			plan = append(plan, PlanItem{OldPath: platform.ShortPath(path), NewPath: platform.ShortPath(newPath)})
 			return nil
 		}
 
@@ -98,7 +101,7 @@ func BuildPlan(opts Options) ([]PlanItem, error) {
 				return nil
 			}
 			newPath := filepath.Join(filepath.Dir(path), newName)
-			plan = append(plan, PlanItem{OldPath: path, NewPath: newPath})
+			plan = append(plan, PlanItem{OldPath: platform.ShortPath(path), NewPath: platform.ShortPath(newPath)})
 			return nil
 		}
 		newName := replaceInsensitive(name, from, to)
@@ -106,11 +109,11 @@ func BuildPlan(opts Options) ([]PlanItem, error) {
 			return nil
 		}
 		newPath := filepath.Join(filepath.Dir(path), newName)
-		plan = append(plan, PlanItem{OldPath: path, NewPath: newPath})
+		plan = append(plan, PlanItem{OldPath: platform.ShortPath(path), NewPath: platform.ShortPath(newPath)})
 		return nil
 	}
 
-	if err := filepath.WalkDir(base, walk); err != nil {
+	if err := filepath.WalkDir(root, walk); err != nil {
 		return nil, err
 	}
 
@@ -124,12 +127,12 @@ func ApplyPlan(plan []PlanItem) error {
 			return fmt.Errorf("duplicate target path: %s", item.NewPath)
 		}
 		seen[item.NewPath] = struct{}{}
-		if _, err := os.Stat(item.NewPath); err == nil {
+		if _, err := os.Stat(platform.LongPath(item.NewPath)); err == nil {
 			return fmt.Errorf("target already exists: %s", item.NewPath)
 		}
 	}
 	for _, item := range plan {
-		if err := os.Rename(item.OldPath, item.NewPath); err != nil {
+		if err := os.Rename(platform.LongPath(item.OldPath), platform.LongPath(item.NewPath)); err != nil {
 			return err
 		}
 	}
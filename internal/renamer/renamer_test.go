@@ -258,3 +258,26 @@ func TestReplaceInsensitive_EmptyFrom(t *testing.T) {
 		t.Fatalf("expected unchanged, got %q", got)
 	}
 }
+
+func TestBuildPlan_LongPath(t *testing.T) {
+	dir := t.TempDir()
+	deep := dir
+	for len(deep) < 300 {
+		deep = filepath.Join(deep, "a_fairly_long_directory_name")
+	}
+	createFile(t, filepath.Join(deep, "report_v1.txt"))
+
+	plan, err := BuildPlan(Options{BasePath: dir, From: "report", To: "summary", Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 || plan[0].OldPath != filepath.Join(deep, "report_v1.txt") {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	if err := ApplyPlan(plan); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(deep, "summary_v1.txt")); err != nil {
+		t.Fatalf("expected renamed file: %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package secrets
+
+// osKeyProtection is empty where dm has no OS key protection: secrets.key
+// is only guarded by its user-only permissions.
+const osKeyProtection = ""
+
+func protectKey(key []byte) ([]byte, error) {
+	return key, nil
+}
+
+func unprotectKey(data []byte) ([]byte, error) {
+	return data, nil
+}
@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// osKeyProtection names how secrets.key is protected on this OS: DPAPI
+// ties it to the Windows user account.
+const osKeyProtection = "dpapi"
+
+const cryptProtectUIForbidden = 0x1
+
+var (
+	crypt32                = syscall.NewLazyDLL("crypt32.dll")
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procCryptProtectData   = crypt32.NewProc("CryptProtectData")
+	procCryptUnprotectData = crypt32.NewProc("CryptUnprotectData")
+	procLocalFree          = kernel32.NewProc("LocalFree")
+)
+
+type dataBlob struct {
+	size uint32
+	data *byte
+}
+
+func newDataBlob(b []byte) *dataBlob {
+	if len(b) == 0 {
+		return &dataBlob{}
+	}
+	return &dataBlob{size: uint32(len(b)), data: &b[0]}
+}
+
+func (b *dataBlob) bytes() []byte {
+	out := make([]byte, b.size)
+	copy(out, unsafe.Slice(b.data, b.size))
+	return out
+}
+
+func callDPAPI(proc *syscall.LazyProc, in []byte) ([]byte, error) {
+	var out dataBlob
+	r, _, err := proc.Call(uintptr(unsafe.Pointer(newDataBlob(in))), 0, 0, 0, 0, cryptProtectUIForbidden, uintptr(unsafe.Pointer(&out)))
+	if r == 0 {
+		return nil, fmt.Errorf("%s: %w", proc.Name, err)
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out.data)))
+	return out.bytes(), nil
+}
+
+func protectKey(key []byte) ([]byte, error) {
+	return callDPAPI(procCryptProtectData, key)
+}
+
+func unprotectKey(data []byte) ([]byte, error) {
+	return callDPAPI(procCryptUnprotectData, data)
+}
@@ -0,0 +1,306 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cli/internal/platform"
+)
+
+// EnvPrefix is prepended to secret names when they are passed to plugins.
+const EnvPrefix = "DM_SECRET_"
+
+// storeMagic starts secrets.enc, followed by the salt used to derive the
+// key from DM_SECRETS_KEY.
+var storeMagic = []byte("DMS2")
+
+const saltSize = 16
+
+// passphraseIterations is the PBKDF2-SHA256 work factor for DM_SECRETS_KEY.
+var passphraseIterations = 600000
+
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Store is the decrypted content of the secret store. Plugins maps a
+// secret name to the plugin names or patterns allowed to receive it.
+type Store struct {
+	Values  map[string]string   `json:"values"`
+	Plugins map[string][]string `json:"plugins,omitempty"`
+}
+
+// Entry describes a stored secret without its value.
+type Entry struct {
+	Name    string
+	Plugins []string
+}
+
+func storePath() string {
+	return platform.StatePath("secrets.enc")
+}
+
+func keyPath() string {
+	return platform.StatePath("secrets.key")
+}
+
+// NormalizeName upper-cases name so it can be used as an environment variable.
+func NormalizeName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid secret name %q (use letters, digits and _)", name)
+	}
+	return strings.ToUpper(name), nil
+}
+
+// loadFileKey returns the random key kept in secrets.key, creating it on
+// first use. Where the OS can protect it (DPAPI on Windows) the file holds
+// the protected key, so copying the state dir elsewhere does not reveal it.
+func loadFileKey(create bool) ([]byte, error) {
+	data, err := os.ReadFile(keyPath())
+	if err == nil {
+		return decodeFileKey(strings.TrimSpace(string(data)))
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	encoded, err := encodeFileKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := platform.WriteFileAtomic(keyPath(), []byte(encoded+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func encodeFileKey(key []byte) (string, error) {
+	if osKeyProtection == "" {
+		return "raw:" + hex.EncodeToString(key), nil
+	}
+	protected, err := protectKey(key)
+	if err != nil {
+		return "", err
+	}
+	return osKeyProtection + ":" + hex.EncodeToString(protected), nil
+}
+
+func decodeFileKey(s string) ([]byte, error) {
+	scheme, encoded, found := strings.Cut(s, ":")
+	if !found {
+		scheme, encoded = "raw", s
+	}
+	data, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets key in %s", keyPath())
+	}
+	switch scheme {
+	case "raw":
+	case osKeyProtection:
+		if data, err = unprotectKey(data); err != nil {
+			return nil, fmt.Errorf("cannot unprotect secrets key %s: %w", keyPath(), err)
+		}
+	default:
+		return nil, fmt.Errorf("secrets key %s is protected with %s, which is not available here", keyPath(), scheme)
+	}
+	if len(data) != 32 {
+		return nil, fmt.Errorf("invalid secrets key in %s", keyPath())
+	}
+	return data, nil
+}
+
+// deriveKey returns the AES-256 key for a store with salt. DM_SECRETS_KEY
+// wins and is stretched with PBKDF2; otherwise the key file is used.
+func deriveKey(salt []byte, create bool) ([]byte, error) {
+	if pass := os.Getenv("DM_SECRETS_KEY"); pass != "" {
+		return pbkdf2.Key(sha256.New, pass, salt, passphraseIterations, 32)
+	}
+	return loadFileKey(create)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func load() (Store, error) {
+	out := Store{Values: map[string]string{}, Plugins: map[string][]string{}}
+	data, err := os.ReadFile(storePath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return Store{}, err
+		}
+		return out, nil
+	}
+	if !bytes.HasPrefix(data, storeMagic) || len(data) < len(storeMagic)+saltSize {
+		return Store{}, fmt.Errorf("%s is not a dm secrets file", storePath())
+	}
+	salt := data[len(storeMagic) : len(storeMagic)+saltSize]
+	data = data[len(storeMagic)+saltSize:]
+	key, err := deriveKey(salt, false)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Store{}, fmt.Errorf("secrets key %s is missing", keyPath())
+		}
+		return Store{}, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return Store{}, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return Store{}, errors.New("secrets file is truncated")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return Store{}, errors.New("cannot decrypt secrets (wrong key?)")
+	}
+	if err := json.Unmarshal(plain, &out); err != nil {
+		return Store{}, err
+	}
+	if out.Values == nil {
+		out.Values = map[string]string{}
+	}
+	if out.Plugins == nil {
+		out.Plugins = map[string][]string{}
+	}
+	return out, nil
+}
+
+func save(s Store) error {
+	plain, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	key, err := deriveKey(salt, true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data := append(append(append([]byte(nil), storeMagic...), salt...), gcm.Seal(nonce, nonce, plain, nil)...)
+	return platform.WriteFileAtomic(storePath(), data, 0600)
+}
+
+// Set stores name. plugins lists the plugin names or patterns that receive
+// it; nil keeps the current list, and a secret without one is passed to no
+// plugin.
+func Set(name, value string, plugins []string) error {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return err
+	}
+	s, err := load()
+	if err != nil {
+		return err
+	}
+	s.Values[name] = value
+	if plugins != nil {
+		if len(plugins) == 0 {
+			delete(s.Plugins, name)
+		} else {
+			s.Plugins[name] = plugins
+		}
+	}
+	return save(s)
+}
+
+func Get(name string) (string, bool, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return "", false, err
+	}
+	s, err := load()
+	if err != nil {
+		return "", false, err
+	}
+	v, ok := s.Values[name]
+	return v, ok, nil
+}
+
+// Remove deletes name and reports whether it existed.
+func Remove(name string) (bool, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return false, err
+	}
+	s, err := load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := s.Values[name]; !ok {
+		return false, nil
+	}
+	delete(s.Values, name)
+	delete(s.Plugins, name)
+	return true, save(s)
+}
+
+// List returns the stored secrets with their plugin scope, sorted by name.
+func List() ([]Entry, error) {
+	s, err := load()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Entry, 0, len(s.Values))
+	for k := range s.Values {
+		out = append(out, Entry{Name: k, Plugins: s.Plugins[k]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// All returns every stored secret. An unreadable store is logged and treated
+// as empty so plugin runs are not blocked by it.
+func All() Store {
+	s, err := load()
+	if err != nil {
+		slog.Debug("secrets not loaded", "err", err)
+		return Store{Values: map[string]string{}, Plugins: map[string][]string{}}
+	}
+	return s
+}
+
+// Env renders the secrets whose scope allows plugin as DM_SECRET_<NAME>=value
+// entries, sorted by name. allowed reports whether a pattern covers plugin.
+func (s Store) Env(plugin string, allowed func(pattern, plugin string) bool) []string {
+	var out []string
+	for k, v := range s.Values {
+		for _, p := range s.Plugins[k] {
+			if allowed(p, plugin) {
+				out = append(out, EnvPrefix+k+"="+v)
+				break
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
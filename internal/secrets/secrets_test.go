@@ -0,0 +1,122 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetGetRoundTrip(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	t.Setenv("DM_SECRETS_KEY", "")
+
+	if err := Set("github_token", "ghp-value", []string{"gh"}); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := Set("DB_PASS", "p@ss", nil); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	v, ok, err := Get("GITHUB_TOKEN")
+	if err != nil || !ok || v != "ghp-value" {
+		t.Fatalf("unexpected get result %q %v %v", v, ok, err)
+	}
+	entries, err := List()
+	want := []Entry{{Name: "DB_PASS"}, {Name: "GITHUB_TOKEN", Plugins: []string{"gh"}}}
+	if err != nil || !reflect.DeepEqual(entries, want) {
+		t.Fatalf("unexpected entries %v (err %v)", entries, err)
+	}
+	if err := Set("github_token", "ghp-new", nil); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := All().Plugins["GITHUB_TOKEN"]; !reflect.DeepEqual(got, []string{"gh"}) {
+		t.Fatalf("expected update to keep the plugin scope, got %v", got)
+	}
+	data, err := os.ReadFile(storePath())
+	if err != nil {
+		t.Fatalf("read store: %v", err)
+	}
+	if strings.Contains(string(data), "ghp-value") {
+		t.Fatal("secret stored in plain text")
+	}
+	removed, err := Remove("db_pass")
+	if err != nil || !removed {
+		t.Fatalf("remove: %v %v", removed, err)
+	}
+	if _, ok, _ := Get("DB_PASS"); ok {
+		t.Fatal("expected DB_PASS to be removed")
+	}
+}
+
+func TestWrongKeyFails(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	t.Setenv("DM_SECRETS_KEY", "first")
+	passphraseIterations = 1000
+	defer func() { passphraseIterations = 600000 }()
+	if err := Set("A", "1234", nil); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	t.Setenv("DM_SECRETS_KEY", "second")
+	if _, _, err := Get("A"); err == nil {
+		t.Fatal("expected decrypt error with wrong key")
+	}
+	if got := All(); len(got.Values) != 0 {
+		t.Fatalf("expected empty store on error, got %v", got)
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	if _, err := NormalizeName("bad-name"); err == nil {
+		t.Fatal("expected error for dash")
+	}
+	if got, err := NormalizeName(" api_key "); err != nil || got != "API_KEY" {
+		t.Fatalf("unexpected %q %v", got, err)
+	}
+}
+
+func TestEnvOnlyScopedSecrets(t *testing.T) {
+	s := Store{
+		Values:  map[string]string{"B": "2", "A": "1", "C": "3"},
+		Plugins: map[string][]string{"A": {"*"}, "B": {"gh"}},
+	}
+	allowed := func(pattern, plugin string) bool { return pattern == "*" || pattern == plugin }
+	if got, want := s.Env("gh", allowed), []string{"DM_SECRET_A=1", "DM_SECRET_B=2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if got, want := s.Env("other", allowed), []string{"DM_SECRET_A=1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func TestKeyFileIsEncodedWithScheme(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	t.Setenv("DM_SECRETS_KEY", "")
+	if err := Set("A", "1234", nil); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	data, err := os.ReadFile(keyPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheme := osKeyProtection
+	if scheme == "" {
+		scheme = "raw"
+	}
+	if !strings.HasPrefix(string(data), scheme+":") {
+		t.Fatalf("expected %s key encoding, got %q", scheme, data)
+	}
+}
+
+func TestLoadRejectsStoreWithoutHeader(t *testing.T) {
+	t.Setenv("DM_STATE_DIR", t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(storePath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(storePath(), []byte("not a store"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Get("ANY"); err == nil || !strings.Contains(err.Error(), "not a dm secrets file") {
+		t.Fatalf("expected a header error, got %v", err)
+	}
+}
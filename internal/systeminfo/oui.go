@@ -0,0 +1,95 @@
+package systeminfo
+
+import (
+	"bufio"
+	_ "embed"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed oui.txt
+var ouiData string
+
+var (
+	ouiOnce  sync.Once
+	ouiTable map[string]string
+)
+
+// VendorRandomized is reported for locally administered MACs, which phones
+// and laptops use for Wi-Fi privacy; they carry no vendor prefix.
+const VendorRandomized = "randomized"
+
+func loadOUI() {
+	ouiTable = map[string]string{}
+	sc := bufio.NewScanner(strings.NewReader(ouiData))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prefix, vendor, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		ouiTable[strings.ToUpper(prefix)] = strings.TrimSpace(vendor)
+	}
+}
+
+// LookupVendor returns the hardware vendor for a MAC address in any of the
+// usual notations, or "" when the prefix is unknown.
+func LookupVendor(mac string) string {
+	hex := strings.ToUpper(strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.TrimSpace(mac)))
+	if len(hex) < 6 {
+		return ""
+	}
+	first, err := strconv.ParseUint(hex[:2], 16, 8)
+	if err != nil {
+		return ""
+	}
+	if hex == "FFFFFFFFFFFF" || first&0x01 != 0 {
+		return ""
+	}
+	if first&0x02 != 0 {
+		return VendorRandomized
+	}
+	ouiOnce.Do(loadOUI)
+	return ouiTable[hex[:6]]
+}
+
+func fillNeighborVendors(list []LANNeighbor) {
+	for i := range list {
+		list[i].Vendor = LookupVendor(list[i].MAC)
+	}
+}
+
+// ResolveNeighborNames fills Hostname via reverse DNS. Lookups run in
+// parallel and whatever has not answered within timeout is left empty.
+func ResolveNeighborNames(list []LANNeighbor, timeout time.Duration) {
+	type answer struct {
+		idx  int
+		name string
+	}
+	answers := make(chan answer, len(list))
+	for i, n := range list {
+		go func(i int, ip string) {
+			hosts, err := net.LookupAddr(ip)
+			if err != nil || len(hosts) == 0 {
+				answers <- answer{idx: i}
+				return
+			}
+			answers <- answer{idx: i, name: strings.TrimSuffix(hosts[0], ".")}
+		}(i, n.IP)
+	}
+	deadline := time.After(timeout)
+	for range list {
+		select {
+		case a := <-answers:
+			list[a.idx].Hostname = a.name
+		case <-deadline:
+			return
+		}
+	}
+}
@@ -15,7 +15,58 @@ import (
 	"time"
 )
 
+// Snapshot sections; Wi-Fi and ARP are the slow ones.
+const (
+	SectionCPU   = "cpu"
+	SectionMem   = "mem"
+	SectionDisks = "disks"
+	SectionNet   = "net"
+	SectionWiFi  = "wifi"
+	SectionARP   = "arp"
+)
+
+var AllSections = []string{SectionCPU, SectionMem, SectionDisks, SectionNet, SectionWiFi, SectionARP}
+
+var sectionAliases = map[string]string{
+	"memory": SectionMem, "disk": SectionDisks, "interfaces": SectionNet,
+	"network": SectionNet, "wi-fi": SectionWiFi, "lan": SectionARP, "neighbors": SectionARP,
+}
+
+// ParseSections reads a comma or space separated section list. Empty input
+// or "all" selects every section.
+func ParseSections(raw string) ([]string, error) {
+	fields := strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool { return r == ',' || r == ' ' || r == ';' })
+	if len(fields) == 0 {
+		return AllSections, nil
+	}
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if f == "all" {
+			return AllSections, nil
+		}
+		if alias, ok := sectionAliases[f]; ok {
+			f = alias
+		}
+		known := false
+		for _, s := range AllSections {
+			known = known || s == f
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown section %q (use %s)", f, strings.Join(AllSections, ","))
+		}
+		seen[f] = true
+	}
+	out := make([]string, 0, len(seen))
+	for _, s := range AllSections {
+		if seen[s] {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
 type Snapshot struct {
+	Sections      []string
 	GeneratedAt   time.Time
 	System        System
 	Memory        Memory
@@ -57,16 +108,37 @@ type WiFiNetwork struct {
 	SSID           string
 	Signal         string
 	Authentication string
+	BSSID          string
+	Vendor         string
 }
 
 type LANNeighbor struct {
-	IP   string
-	MAC  string
-	Type string
+	IP       string
+	MAC      string
+	Type     string
+	Vendor   string
+	Hostname string
+}
+
+// Has reports whether section was collected.
+func (s Snapshot) Has(section string) bool {
+	for _, v := range s.Sections {
+		if v == section {
+			return true
+		}
+	}
+	return false
 }
 
 func Collect() Snapshot {
+	return CollectSections(AllSections)
+}
+
+// CollectSections gathers only the given sections; host, OS and CPU count
+// are always included.
+func CollectSections(sections []string) Snapshot {
 	s := Snapshot{
+		Sections:    sections,
 		GeneratedAt: time.Now(),
 		System: System{
 			OS:       runtime.GOOS,
@@ -81,18 +153,22 @@ func Collect() Snapshot {
 		s.Warnings = append(s.Warnings, fmt.Sprintf("hostname: %v", err))
 	}
 
-	ifaces, err := collectInterfaces()
-	if err != nil {
-		s.Warnings = append(s.Warnings, fmt.Sprintf("interfaces: %v", err))
-	} else {
-		s.Interfaces = ifaces
+	if s.Has(SectionNet) {
+		ifaces, err := collectInterfaces()
+		if err != nil {
+			s.Warnings = append(s.Warnings, fmt.Sprintf("interfaces: %v", err))
+		} else {
+			s.Interfaces = ifaces
+		}
 	}
 
-	neighbors, err := collectLANNeighbors()
-	if err != nil {
-		s.Warnings = append(s.Warnings, fmt.Sprintf("arp: %v", err))
-	} else {
-		s.LANNeighbors = neighbors
+	if s.Has(SectionARP) {
+		neighbors, err := collectLANNeighbors()
+		if err != nil {
+			s.Warnings = append(s.Warnings, fmt.Sprintf("arp: %v", err))
+		} else {
+			s.LANNeighbors = neighbors
+		}
 	}
 
 	if runtime.GOOS == "windows" {
@@ -140,26 +216,35 @@ func collectLANNeighbors() ([]LANNeighbor, error) {
 	if err != nil {
 		return nil, err
 	}
-	return parseARPTable(raw), nil
+	list := parseARPTable(raw)
+	fillNeighborVendors(list)
+	return list, nil
 }
 
 func collectWindowsSystem(s *Snapshot) error {
-	mem, boot, err := windowsMemoryAndBoot()
-	if err != nil {
-		return fmt.Errorf("windows memory/boot: %w", err)
-	}
-	s.Memory = mem
-	if !boot.IsZero() {
-		s.System.BootTime = boot
+	if s.Has(SectionCPU) || s.Has(SectionMem) {
+		mem, boot, err := windowsMemoryAndBoot()
+		if err != nil {
+			return fmt.Errorf("windows memory/boot: %w", err)
+		}
+		s.Memory = mem
+		if !boot.IsZero() {
+			s.System.BootTime = boot
+		}
 	}
 
-	disks, err := windowsDisks()
-	if err != nil {
-		s.Warnings = append(s.Warnings, fmt.Sprintf("windows disks: %v", err))
-	} else {
-		s.Disks = disks
+	if s.Has(SectionDisks) {
+		disks, err := windowsDisks()
+		if err != nil {
+			s.Warnings = append(s.Warnings, fmt.Sprintf("windows disks: %v", err))
+		} else {
+			s.Disks = disks
+		}
 	}
 
+	if !s.Has(SectionWiFi) {
+		return nil
+	}
 	connected, err := windowsConnectedWiFi()
 	if err != nil {
 		s.Warnings = append(s.Warnings, fmt.Sprintf("windows wifi interface: %v", err))
@@ -350,6 +435,13 @@ func parseWiFiNetworks(out string) []WiFiNetwork {
 			curr.SSID = valueAfterColon(t)
 			continue
 		}
+		if strings.HasPrefix(l, "bssid") {
+			if curr.BSSID == "" {
+				curr.BSSID = strings.ToLower(valueAfterColon(t))
+				curr.Vendor = LookupVendor(curr.BSSID)
+			}
+			continue
+		}
 		if strings.HasPrefix(l, "authentication") || strings.HasPrefix(l, "autenticazione") {
 			curr.Authentication = valueAfterColon(t)
 			continue
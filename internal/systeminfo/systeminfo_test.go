@@ -1,6 +1,9 @@
 package systeminfo
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestParseWiFiNetworks(t *testing.T) {
 	in := `
@@ -14,6 +17,7 @@ SSID 1 : HomeNet
 
 SSID 2 : Guest
     Authentication          : Open
+    BSSID 1                 : B8-27-EB-01-02-03
     Signal                  : 42%
 `
 	got := parseWiFiNetworks(in)
@@ -26,6 +30,9 @@ SSID 2 : Guest
 	if got[1].Signal != "79%" || got[1].Authentication != "WPA2-Personal" {
 		t.Fatalf("unexpected network fields: %+v", got[1])
 	}
+	if got[0].BSSID != "b8-27-eb-01-02-03" || got[0].Vendor != "Raspberry Pi" {
+		t.Fatalf("unexpected BSSID fields: %+v", got[0])
+	}
 }
 
 func TestParseConnectedSSID(t *testing.T) {
@@ -56,3 +63,50 @@ Interface: 192.168.1.100 --- 0xb
 		t.Fatalf("unexpected first row: %+v", got[0])
 	}
 }
+
+func TestParseSections(t *testing.T) {
+	got, err := ParseSections("arp, Memory cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "cpu,mem,arp" {
+		t.Fatalf("unexpected sections %v", got)
+	}
+	for _, raw := range []string{"", "all", "cpu,all"} {
+		got, err := ParseSections(raw)
+		if err != nil || len(got) != len(AllSections) {
+			t.Fatalf("ParseSections(%q) = %v, %v; want all", raw, got, err)
+		}
+	}
+	if _, err := ParseSections("gpu"); err == nil {
+		t.Fatal("expected unknown section error")
+	}
+}
+
+func TestCollectSectionsSkipsUnrequested(t *testing.T) {
+	s := CollectSections([]string{SectionCPU})
+	if !s.Has(SectionCPU) || s.Has(SectionARP) {
+		t.Fatalf("unexpected sections %v", s.Sections)
+	}
+	if len(s.Interfaces) != 0 || len(s.LANNeighbors) != 0 {
+		t.Fatal("expected net and arp to be skipped")
+	}
+}
+
+func TestLookupVendor(t *testing.T) {
+	cases := map[string]string{
+		"00:50:56:aa:bb:cc": "VMware",
+		"00-15-5D-01-02-03": "Microsoft Hyper-V",
+		"b827.eb01.0203":    "Raspberry Pi",
+		"da:a1:19:00:00:01": VendorRandomized,
+		"01:00:5e:00:00:fb": "",
+		"ff:ff:ff:ff:ff:ff": "",
+		"12:34":             "",
+		"00:00:01:00:00:00": "",
+	}
+	for mac, want := range cases {
+		if got := LookupVendor(mac); got != want {
+			t.Fatalf("LookupVendor(%q) = %q, want %q", mac, got, want)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// FilterThreshold is the list size above which menus switch to the
+// incremental filter instead of a numbered list.
+const FilterThreshold = 20
+
+const filterMaxRows = 15
+
+type filterKey int
+
+const (
+	keyRune filterKey = iota
+	keyEnter
+	keyBackspace
+	keyUp
+	keyDown
+	keyCancel
+	keyToggle
+	keyWindow
+	keyInfo
+	keyIgnore
+)
+
+// FilterAction is what the user asked to do with the selection.
+type FilterAction int
+
+const (
+	FilterRun FilterAction = iota
+	FilterWindow
+	FilterInfo
+)
+
+// FilterResult is returned by FilterMenu. Indexes holds the marked items in
+// list order, or just the highlighted one when nothing was marked.
+type FilterResult struct {
+	Indexes []int
+	Action  FilterAction
+}
+
+// FilterAvailable reports whether the interactive filter can run, which
+// needs a terminal on both stdin and stdout.
+func FilterAvailable() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// FilterSelect shows items with type-to-filter and arrow-key selection.
+// It returns the index of the chosen item, or false when canceled.
+func FilterSelect(r *bufio.Reader, title string, items []string) (int, bool) {
+	res, ok := filterRaw(r, title, items, false)
+	if !ok {
+		return -1, false
+	}
+	return res.Indexes[0], true
+}
+
+// FilterMenu is FilterSelect for action menus: Tab marks several items,
+// Ctrl-W asks to run the highlighted item in a new window and Ctrl-E asks
+// for its details.
+func FilterMenu(r *bufio.Reader, title string, items []string) (FilterResult, bool) {
+	return filterRaw(r, title, items, true)
+}
+
+func filterRaw(r *bufio.Reader, title string, items []string, actions bool) (FilterResult, bool) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return FilterResult{}, false
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+	return filterLoop(r, title, items, actions)
+}
+
+func filterLoop(r *bufio.Reader, title string, items []string, actions bool) (FilterResult, bool) {
+	query := ""
+	cursor := 0
+	drawn := 0
+	marked := map[int]bool{}
+	hint := ""
+	if actions {
+		hint = "tab mark | ^W new window | ^E info"
+	}
+	for {
+		matches := filterMatches(items, query)
+		if cursor >= len(matches) {
+			cursor = len(matches) - 1
+		}
+		if cursor < 0 {
+			cursor = 0
+		}
+		drawn = drawFilter(title, query, items, matches, cursor, drawn, marked, hint)
+
+		k, ch := readFilterKey(r)
+		if !actions && (k == keyToggle || k == keyWindow || k == keyInfo) {
+			continue
+		}
+		switch k {
+		case keyCancel:
+			clearFilter(drawn)
+			return FilterResult{}, false
+		case keyEnter:
+			if len(marked) > 0 {
+				clearFilter(drawn)
+				out := make([]int, 0, len(marked))
+				for i := range items {
+					if marked[i] {
+						out = append(out, i)
+					}
+				}
+				return FilterResult{Indexes: out}, true
+			}
+			if len(matches) == 0 {
+				continue
+			}
+			clearFilter(drawn)
+			return FilterResult{Indexes: []int{matches[cursor]}}, true
+		case keyWindow, keyInfo:
+			if len(matches) == 0 {
+				continue
+			}
+			clearFilter(drawn)
+			action := FilterWindow
+			if k == keyInfo {
+				action = FilterInfo
+			}
+			return FilterResult{Indexes: []int{matches[cursor]}, Action: action}, true
+		case keyToggle:
+			if len(matches) == 0 {
+				continue
+			}
+			idx := matches[cursor]
+			if marked[idx] {
+				delete(marked, idx)
+			} else {
+				marked[idx] = true
+			}
+			if cursor < len(matches)-1 {
+				cursor++
+			}
+		case keyUp:
+			if cursor > 0 {
+				cursor--
+			}
+		case keyDown:
+			if cursor < len(matches)-1 {
+				cursor++
+			}
+		case keyBackspace:
+			if rs := []rune(query); len(rs) > 0 {
+				query = string(rs[:len(rs)-1])
+				cursor = 0
+			}
+		case keyRune:
+			query += string(ch)
+			cursor = 0
+		}
+	}
+}
+
+// filterMatches returns the indexes of items matching query, substring
+// matches first, then fuzzy (in-order character) matches.
+func filterMatches(items []string, query string) []int {
+	q := strings.ToLower(strings.TrimSpace(query))
+	out := make([]int, 0, len(items))
+	if q == "" {
+		for i := range items {
+			out = append(out, i)
+		}
+		return out
+	}
+	rank := map[int]int{}
+	for i, item := range items {
+		lc := strings.ToLower(item)
+		switch {
+		case strings.HasPrefix(lc, q):
+			rank[i] = 0
+		case strings.Contains(lc, q):
+			rank[i] = 1
+		case fuzzyContains(lc, q):
+			rank[i] = 2
+		default:
+			continue
+		}
+		out = append(out, i)
+	}
+	sort.SliceStable(out, func(a, b int) bool {
+		return rank[out[a]] < rank[out[b]]
+	})
+	return out
+}
+
+func fuzzyContains(s, q string) bool {
+	qs := []rune(q)
+	j := 0
+	for _, c := range s {
+		if j < len(qs) && c == qs[j] {
+			j++
+		}
+	}
+	return j == len(qs)
+}
+
+func readFilterKey(r *bufio.Reader) (filterKey, rune) {
+	ch, _, err := r.ReadRune()
+	if err != nil {
+		return keyCancel, 0
+	}
+	switch ch {
+	case '\r', '\n':
+		return keyEnter, 0
+	case 127, 8:
+		return keyBackspace, 0
+	case 3, 4:
+		return keyCancel, 0
+	case 9: // Tab
+		return keyToggle, 0
+	case 23: // Ctrl-W
+		return keyWindow, 0
+	case 5: // Ctrl-E
+		return keyInfo, 0
+	case 16: // Ctrl-P
+		return keyUp, 0
+	case 14: // Ctrl-N
+		return keyDown, 0
+	case 27:
+		if r.Buffered() == 0 {
+			return keyCancel, 0
+		}
+		next, _, _ := r.ReadRune()
+		if next != '[' && next != 'O' {
+			return keyIgnore, 0
+		}
+		code, _, _ := r.ReadRune()
+		switch code {
+		case 'A':
+			return keyUp, 0
+		case 'B':
+			return keyDown, 0
+		}
+		return keyIgnore, 0
+	}
+	if unicode.IsPrint(ch) {
+		return keyRune, ch
+	}
+	return keyIgnore, 0
+}
+
+func drawFilter(title, query string, items []string, matches []int, cursor, previous int, marked map[int]bool, hint string) int {
+	clearFilter(previous)
+	out, lines := renderFilter(title, query, items, matches, cursor, marked, hint, TerminalWidth()-1)
+	fmt.Print(out)
+	return lines
+}
+
+// renderFilter builds the filter screen and the number of rows above the
+// query line. Every line is cut to width cells (no cut when width <= 0):
+// a wrapped row would make the count clearFilter moves up by wrong.
+func renderFilter(title, query string, items []string, matches []int, cursor int, marked map[int]bool, hint string, width int) (string, int) {
+	var b strings.Builder
+	parts := []styledPart{
+		{title, Accent},
+		{" " + fmt.Sprintf("(%d/%d)", len(matches), len(items)), Muted},
+	}
+	if len(marked) > 0 {
+		parts = append(parts, styledPart{" " + fmt.Sprintf("%d marked", len(marked)), Warn})
+	}
+	if hint != "" {
+		parts = append(parts, styledPart{"  " + hint, Muted})
+	}
+	b.WriteString(fitParts(parts, width) + "\r\n")
+	start := 0
+	if cursor >= filterMaxRows {
+		start = cursor - filterMaxRows + 1
+	}
+	lines := 1
+	for i := start; i < len(matches) && i < start+filterMaxRows; i++ {
+		parts := []styledPart{{"  ", nil}}
+		if i == cursor {
+			parts[0].style = Warn
+			parts[0].text = "> "
+		}
+		if marked[matches[i]] {
+			parts = append(parts, styledPart{"* ", Warn})
+		}
+		label := styledPart{items[matches[i]], nil}
+		if i == cursor {
+			label.style = Accent
+		}
+		b.WriteString(fitParts(append(parts, label), width) + "\r\n")
+		lines++
+	}
+	b.WriteString(fitParts([]styledPart{{"filter > ", Prompt}, {query, nil}}, width))
+	return b.String(), lines
+}
+
+// styledPart is a piece of a filter line with the colour applied after
+// cutting, so escape codes never count toward the width.
+type styledPart struct {
+	text  string
+	style func(string) string
+}
+
+func fitParts(parts []styledPart, width int) string {
+	if width > 0 {
+		plain := ""
+		for _, p := range parts {
+			plain += p.text
+		}
+		if StringWidth(plain) > width {
+			left := width - 3
+			if width <= 3 {
+				left = width
+			}
+			var cut []styledPart
+			for _, p := range parts {
+				if left <= 0 {
+					break
+				}
+				if w := StringWidth(p.text); w > left {
+					p.text = cutWidth(p.text, left)
+				}
+				left -= StringWidth(p.text)
+				cut = append(cut, p)
+			}
+			if width > 3 {
+				cut = append(cut, styledPart{"...", nil})
+			}
+			parts = cut
+		}
+	}
+	var b strings.Builder
+	for _, p := range parts {
+		if p.style != nil {
+			b.WriteString(p.style(p.text))
+		} else {
+			b.WriteString(p.text)
+		}
+	}
+	return b.String()
+}
+
+func clearFilter(lines int) {
+	if lines <= 0 {
+		return
+	}
+	fmt.Printf("\r\033[%dA\033[J", lines)
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterMatchesRanksPrefixThenSubstringThenFuzzy(t *testing.T) {
+	items := []string{"docker_logs", "sys_uptime", "dc_up", "start_docker"}
+	got := filterMatches(items, "d")
+	if len(got) != 3 {
+		t.Fatalf("expected 3 matches, got %v", got)
+	}
+	got = filterMatches(items, "dock")
+	want := []int{0, 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	got = filterMatches(items, "sup")
+	want = []int{1}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected fuzzy match %v, got %v", want, got)
+	}
+	if got := filterMatches(items, ""); len(got) != len(items) {
+		t.Fatalf("expected empty query to match all, got %v", got)
+	}
+}
+
+func TestReadFilterKey(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("a\x1b[A\x1b[B\x7f\r\x03\t\x17\x05"))
+	want := []filterKey{keyRune, keyUp, keyDown, keyBackspace, keyEnter, keyCancel, keyToggle, keyWindow, keyInfo}
+	for i, w := range want {
+		if got, _ := readFilterKey(r); got != w {
+			t.Fatalf("key %d: expected %v, got %v", i, w, got)
+		}
+	}
+}
+
+func TestFilterLoopMarksSeveralItems(t *testing.T) {
+	items := []string{"alpha", "beta", "gamma"}
+	// mark alpha, skip beta, mark gamma, then run
+	r := bufio.NewReader(strings.NewReader("\t\x1b[B\t\r"))
+	res, ok := filterLoop(r, "t", items, true)
+	if !ok || res.Action != FilterRun || !reflect.DeepEqual(res.Indexes, []int{0, 2}) {
+		t.Fatalf("unexpected result %+v (ok %v)", res, ok)
+	}
+}
+
+func TestFilterLoopActions(t *testing.T) {
+	items := []string{"alpha", "beta"}
+	res, ok := filterLoop(bufio.NewReader(strings.NewReader("bet\x17")), "t", items, true)
+	if !ok || res.Action != FilterWindow || !reflect.DeepEqual(res.Indexes, []int{1}) {
+		t.Fatalf("unexpected window result %+v (ok %v)", res, ok)
+	}
+	// without actions, Tab and Ctrl-W are ignored
+	res, ok = filterLoop(bufio.NewReader(strings.NewReader("\t\x17\r")), "t", items, false)
+	if !ok || res.Action != FilterRun || !reflect.DeepEqual(res.Indexes, []int{0}) {
+		t.Fatalf("unexpected plain result %+v (ok %v)", res, ok)
+	}
+}
+
+func TestRenderFilterCutsLinesToWidth(t *testing.T) {
+	withEnv("NO_COLOR", "1", func() {
+		items := []string{"docker_logs - show the logs of a running container", "dc_up - docker compose up -d in the current folder"}
+		out, rows := renderFilter("Plugins in a very long pack name", strings.Repeat("q", 40), items, []int{0, 1}, 0, map[int]bool{1: true}, "tab mark, enter run", 20)
+		lines := strings.Split(out, "\r\n")
+		if rows != len(lines)-1 {
+			t.Fatalf("expected %d rows above the query line, got %d", len(lines)-1, rows)
+		}
+		for _, l := range lines {
+			if StringWidth(l) > 20 {
+				t.Fatalf("line wider than 20 cells: %q", l)
+			}
+		}
+		if lines[1] != "> docker_logs - s..." || lines[2] != "  * dc_up - docke..." {
+			t.Fatalf("unexpected rows %q", lines[1:3])
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+)
+
+type codeLang struct {
+	comment   string
+	keywords  map[string]bool
+	foldCase  bool
+	variables bool
+	dashWords bool
+}
+
+func keywordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	langPowerShell = &codeLang{
+		comment:   "#",
+		keywords:  keywordSet("if elseif else foreach for while do until switch function param return break continue try catch finally throw begin process end in trap exit filter"),
+		foldCase:  true,
+		variables: true,
+		dashWords: true,
+	}
+	langShell = &codeLang{
+		comment:   "#",
+		keywords:  keywordSet("if then else elif fi for while until do done case esac function return in local export echo exit set"),
+		variables: true,
+	}
+	langGo = &codeLang{
+		comment:  "//",
+		keywords: keywordSet("package import func return if else for range switch case default type struct interface map chan go defer var const nil true false break continue select"),
+	}
+	langPython = &codeLang{
+		comment:  "#",
+		keywords: keywordSet("def return if elif else for while in import from as class try except finally with lambda None True False and or not pass raise yield break continue"),
+	}
+	langJS = &codeLang{
+		comment:  "//",
+		keywords: keywordSet("function return if else for while const let var class new import export from async await try catch finally throw null undefined true false switch case default break continue"),
+	}
+	langData = &codeLang{
+		comment:  "#",
+		keywords: keywordSet("true false null"),
+	}
+)
+
+var codeLangs = map[string]*codeLang{
+	"powershell": langPowerShell, "pwsh": langPowerShell, "ps1": langPowerShell, "ps": langPowerShell,
+	"bash": langShell, "sh": langShell, "shell": langShell, "zsh": langShell, "console": langShell,
+	"go": langGo, "golang": langGo,
+	"python": langPython, "py": langPython,
+	"javascript": langJS, "js": langJS, "typescript": langJS, "ts": langJS,
+	"json": langData, "yaml": langData, "yml": langData,
+}
+
+// highlightCode colors one line of a fenced code block for the given
+// language tag. Unknown languages, and terminals without color, get the
+// plain muted style used for code blocks.
+func highlightCode(lang, line string) string {
+	l := codeLangs[strings.ToLower(strings.TrimSpace(lang))]
+	if l == nil || !supportsColor() {
+		return Muted(line)
+	}
+	rs := []rune(line)
+	var b strings.Builder
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case l.comment != "" && strings.HasPrefix(string(rs[i:]), l.comment):
+			b.WriteString(Muted(string(rs[i:])))
+			return b.String()
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(rs) && rs[j] != c {
+				if rs[j] == '\\' && c == '"' {
+					j++
+				}
+				j++
+			}
+			if j < len(rs) {
+				j++
+			}
+			if j > len(rs) {
+				j = len(rs)
+			}
+			b.WriteString(colorize("32", string(rs[i:j])))
+			i = j
+		case c == '$' && l.variables && i+1 < len(rs) && (isCodeWordRune(rs[i+1]) || rs[i+1] == '{'):
+			j := i + 1
+			for j < len(rs) && (isCodeWordRune(rs[j]) || rs[j] == ':' || rs[j] == '{' || rs[j] == '}') {
+				j++
+			}
+			b.WriteString(colorize("36", string(rs[i:j])))
+			i = j
+		case unicode.IsDigit(c) && (i == 0 || !isCodeWordRune(rs[i-1])):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.' || rs[j] == 'x') {
+				j++
+			}
+			b.WriteString(colorize("33", string(rs[i:j])))
+			i = j
+		case c == '-' && l.dashWords && i+1 < len(rs) && unicode.IsLetter(rs[i+1]) && (i == 0 || rs[i-1] == ' '):
+			j := i + 1
+			for j < len(rs) && isCodeWordRune(rs[j]) {
+				j++
+			}
+			b.WriteString(colorize("33", string(rs[i:j])))
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(rs) && (isCodeWordRune(rs[j]) || l.dashWords && rs[j] == '-' && j+1 < len(rs) && unicode.IsLetter(rs[j+1])) {
+				j++
+			}
+			word := string(rs[i:j])
+			key := word
+			if l.foldCase {
+				key = strings.ToLower(word)
+			}
+			switch {
+			case l.keywords[key]:
+				b.WriteString(colorize("35", word))
+			case l.dashWords && strings.Contains(word, "-"):
+				b.WriteString(colorize("36", word))
+			default:
+				b.WriteString(word)
+			}
+			i = j
+		default:
+			b.WriteRune(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isCodeWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
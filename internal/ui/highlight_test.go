@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlightCodePowerShell(t *testing.T) {
+	withEnv("NO_COLOR", "", func() {
+		withEnv("TERM", "xterm", func() {
+			got := highlightCode("powershell", `foreach ($f in Get-ChildItem -Path "C:\temp") { 42 } # done`)
+			for _, want := range []string{
+				"\x1b[35mforeach\x1b[0m",
+				"\x1b[36m$f\x1b[0m",
+				"\x1b[36mGet-ChildItem\x1b[0m",
+				"\x1b[33m-Path\x1b[0m",
+				"\x1b[32m\"C:\\temp\"\x1b[0m",
+				"\x1b[33m42\x1b[0m",
+				"\x1b[90m# done\x1b[0m",
+			} {
+				if !strings.Contains(got, want) {
+					t.Fatalf("expected %q in %q", want, got)
+				}
+			}
+		})
+	})
+}
+
+func TestHighlightCodeCommentInsideString(t *testing.T) {
+	withEnv("NO_COLOR", "", func() {
+		withEnv("TERM", "xterm", func() {
+			got := highlightCode("go", `url := "http://example.com" // link`)
+			if !strings.Contains(got, "\x1b[32m\"http://example.com\"\x1b[0m") {
+				t.Fatalf("string with // should stay a string: %q", got)
+			}
+			if !strings.Contains(got, "\x1b[90m// link\x1b[0m") {
+				t.Fatalf("expected trailing comment: %q", got)
+			}
+		})
+	})
+}
+
+func TestHighlightCodeNoColorOrUnknownLanguage(t *testing.T) {
+	withEnv("NO_COLOR", "1", func() {
+		if got := highlightCode("go", `return "x"`); got != `return "x"` {
+			t.Fatalf("expected plain text with NO_COLOR, got %q", got)
+		}
+		if got := highlightCode("cobol", "MOVE A TO B"); got != "MOVE A TO B" {
+			t.Fatalf("expected plain text for unknown language, got %q", got)
+		}
+	})
+}
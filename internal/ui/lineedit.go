@@ -0,0 +1,583 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cli/internal/platform"
+	"cli/internal/redact"
+
+	"golang.org/x/term"
+)
+
+// ErrInterrupt is returned by ReadLine when the user presses Ctrl-C.
+var ErrInterrupt = errors.New("interrupted")
+
+// ExitInterrupted ends the process after Ctrl-C at a prompt that has no
+// way to cancel; dm replaces it to run its cleanup first.
+var ExitInterrupted = func() {
+	fmt.Println()
+	os.Exit(130)
+}
+
+const lineHistoryMax = 500
+
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// LineEditor reads lines with cursor movement, word deletion, history
+// (Up/Down, Ctrl-R search), Alt+Enter for a newline and bracketed paste.
+// It falls back to a plain line read when stdin or stdout is not a terminal.
+type LineEditor struct {
+	history     []string
+	historyFile string
+	// fileEntries counts the lines in historyFile, so remember knows when
+	// the file has grown past lineHistoryMax.
+	fileEntries int
+}
+
+// NewLineEditor returns an editor whose history is loaded from and appended
+// to historyFile; an empty path keeps history in memory only.
+func NewLineEditor(historyFile string) *LineEditor {
+	e := &LineEditor{historyFile: historyFile}
+	e.loadHistory()
+	return e
+}
+
+var defaultEditor = &LineEditor{}
+
+// ReadLine reads one line with the shared in-memory editor used by menus
+// and tool prompts.
+func ReadLine(r *bufio.Reader, prompt string) (string, error) {
+	return defaultEditor.ReadLine(r, prompt)
+}
+
+func (e *LineEditor) ReadLine(r *bufio.Reader, prompt string) (string, error) {
+	if !FilterAvailable() {
+		fmt.Print(prompt)
+		line, err := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err
+	}
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		fmt.Print(prompt)
+		line, err := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err
+	}
+	width := TerminalWidth()
+	if width <= 0 {
+		width = defaultTerminalWidth
+	}
+	fmt.Print("\033[?2004h")
+	line, err := e.edit(r, os.Stdout, prompt, width)
+	fmt.Print("\033[?2004l")
+	_ = term.Restore(fd, state)
+	if err == nil {
+		e.remember(line)
+	}
+	return line, err
+}
+
+type lineKey int
+
+const (
+	lkRune lineKey = iota
+	lkEnter
+	lkNewline
+	lkBackspace
+	lkDelete
+	lkLeft
+	lkRight
+	lkWordLeft
+	lkWordRight
+	lkHome
+	lkEnd
+	lkUp
+	lkDown
+	lkKillWord
+	lkKillStart
+	lkKillEnd
+	lkSearch
+	lkClear
+	lkInterrupt
+	lkEOF
+	lkPaste
+	lkCancel
+	lkIgnore
+)
+
+// readLineKey decodes one keystroke, including the escape sequences sent
+// for arrows, Home/End, Delete, Alt+key and bracketed paste.
+func readLineKey(r *bufio.Reader) (lineKey, rune) {
+	ch, _, err := r.ReadRune()
+	if err != nil {
+		return lkEOF, 0
+	}
+	switch ch {
+	case '\r', '\n':
+		return lkEnter, 0
+	case 127, 8:
+		return lkBackspace, 0
+	case 1:
+		return lkHome, 0
+	case 2:
+		return lkLeft, 0
+	case 3:
+		return lkInterrupt, 0
+	case 4:
+		return lkDelete, 4
+	case 5:
+		return lkEnd, 0
+	case 6:
+		return lkRight, 0
+	case 7:
+		return lkCancel, 0
+	case 11:
+		return lkKillEnd, 0
+	case 12:
+		return lkClear, 0
+	case 14:
+		return lkDown, 0
+	case 16:
+		return lkUp, 0
+	case 18:
+		return lkSearch, 0
+	case 21:
+		return lkKillStart, 0
+	case 23:
+		return lkKillWord, 0
+	case 27:
+		return readEscapeKey(r)
+	}
+	if ch == '\t' {
+		return lkRune, ' '
+	}
+	if unicode.IsPrint(ch) {
+		return lkRune, ch
+	}
+	return lkIgnore, 0
+}
+
+func readEscapeKey(r *bufio.Reader) (lineKey, rune) {
+	if r.Buffered() == 0 {
+		return lkCancel, 0
+	}
+	next, _, _ := r.ReadRune()
+	switch next {
+	case '\r', '\n':
+		return lkNewline, 0
+	case 'b', 'B':
+		return lkWordLeft, 0
+	case 'f', 'F':
+		return lkWordRight, 0
+	case 'O':
+		code, _, _ := r.ReadRune()
+		return csiKey(code, ""), 0
+	case '[':
+	default:
+		return lkIgnore, 0
+	}
+	var params strings.Builder
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			return lkIgnore, 0
+		}
+		if c >= 0x40 && c <= 0x7e {
+			return csiKey(c, params.String()), 0
+		}
+		params.WriteRune(c)
+	}
+}
+
+func csiKey(final rune, params string) lineKey {
+	switch final {
+	case 'A':
+		return lkUp
+	case 'B':
+		return lkDown
+	case 'C':
+		if strings.HasSuffix(params, ";5") || strings.HasSuffix(params, ";3") {
+			return lkWordRight
+		}
+		return lkRight
+	case 'D':
+		if strings.HasSuffix(params, ";5") || strings.HasSuffix(params, ";3") {
+			return lkWordLeft
+		}
+		return lkLeft
+	case 'H':
+		return lkHome
+	case 'F':
+		return lkEnd
+	case '~':
+		switch params {
+		case "1", "7":
+			return lkHome
+		case "4", "8":
+			return lkEnd
+		case "3":
+			return lkDelete
+		case "200":
+			return lkPaste
+		}
+	}
+	return lkIgnore
+}
+
+// readPaste collects bracketed-paste content up to the ESC[201~ marker.
+func readPaste(r *bufio.Reader) string {
+	const end = "\x1b[201~"
+	var b strings.Builder
+	for {
+		c, _, err := r.ReadRune()
+		if err != nil {
+			break
+		}
+		b.WriteRune(c)
+		if strings.HasSuffix(b.String(), end) {
+			break
+		}
+	}
+	text := strings.TrimSuffix(b.String(), end)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+type lineBuffer struct {
+	buf []rune
+	pos int
+}
+
+func (l *lineBuffer) insert(s string) {
+	rs := []rune(s)
+	l.buf = append(l.buf[:l.pos], append(rs, l.buf[l.pos:]...)...)
+	l.pos += len(rs)
+}
+
+func (l *lineBuffer) set(s string) {
+	l.buf = []rune(s)
+	l.pos = len(l.buf)
+}
+
+func (l *lineBuffer) backspace() {
+	if l.pos == 0 {
+		return
+	}
+	l.buf = append(l.buf[:l.pos-1], l.buf[l.pos:]...)
+	l.pos--
+}
+
+func (l *lineBuffer) deleteForward() {
+	if l.pos >= len(l.buf) {
+		return
+	}
+	l.buf = append(l.buf[:l.pos], l.buf[l.pos+1:]...)
+}
+
+func (l *lineBuffer) wordStart() int {
+	i := l.pos
+	for i > 0 && unicode.IsSpace(l.buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(l.buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+func (l *lineBuffer) wordEnd() int {
+	i := l.pos
+	for i < len(l.buf) && unicode.IsSpace(l.buf[i]) {
+		i++
+	}
+	for i < len(l.buf) && !unicode.IsSpace(l.buf[i]) {
+		i++
+	}
+	return i
+}
+
+func (l *lineBuffer) killWord() {
+	start := l.wordStart()
+	l.buf = append(l.buf[:start], l.buf[l.pos:]...)
+	l.pos = start
+}
+
+func (l *lineBuffer) killStart() {
+	l.buf = append([]rune{}, l.buf[l.pos:]...)
+	l.pos = 0
+}
+
+func (l *lineBuffer) killEnd() {
+	l.buf = l.buf[:l.pos]
+}
+
+func (l *lineBuffer) String() string {
+	return string(l.buf)
+}
+
+// lineView tracks what was drawn so the next refresh can erase it, even
+// when the line wrapped over several terminal rows.
+type lineView struct {
+	out       io.Writer
+	width     int
+	cursorRow int
+}
+
+func displayLine(rs []rune) string {
+	return strings.ReplaceAll(string(rs), "\n", "↵")
+}
+
+func visibleWidth(s string) int {
+	return StringWidth(ansiSequence.ReplaceAllString(s, ""))
+}
+
+func (v *lineView) draw(prompt string, l *lineBuffer) {
+	if v.cursorRow > 0 {
+		fmt.Fprintf(v.out, "\033[%dA", v.cursorRow)
+	}
+	fmt.Fprint(v.out, "\r\033[J", prompt, displayLine(l.buf))
+	promptW := visibleWidth(prompt)
+	total := promptW + StringWidth(displayLine(l.buf))
+	cursor := promptW + StringWidth(displayLine(l.buf[:l.pos]))
+	endRow := total / v.width
+	if total > 0 && total%v.width == 0 {
+		fmt.Fprint(v.out, "\r\n")
+	}
+	row, col := cursor/v.width, cursor%v.width
+	if up := endRow - row; up > 0 {
+		fmt.Fprintf(v.out, "\033[%dA", up)
+	}
+	fmt.Fprint(v.out, "\r")
+	if col > 0 {
+		fmt.Fprintf(v.out, "\033[%dC", col)
+	}
+	v.cursorRow = row
+}
+
+// finish moves below the drawn line before returning to the caller.
+func (v *lineView) finish(prompt string, l *lineBuffer) {
+	l.pos = len(l.buf)
+	v.draw(prompt, l)
+	fmt.Fprint(v.out, "\r\n")
+}
+
+func (e *LineEditor) edit(r *bufio.Reader, out io.Writer, prompt string, width int) (string, error) {
+	l := &lineBuffer{}
+	v := &lineView{out: out, width: width}
+	histIdx := len(e.history)
+	pending := ""
+	v.draw(prompt, l)
+	for {
+		k, ch := readLineKey(r)
+		switch k {
+		case lkRune:
+			l.insert(string(ch))
+		case lkNewline:
+			l.insert("\n")
+		case lkPaste:
+			l.insert(readPaste(r))
+		case lkEnter:
+			v.finish(prompt, l)
+			return l.String(), nil
+		case lkInterrupt:
+			v.finish(prompt, l)
+			return "", ErrInterrupt
+		case lkEOF:
+			v.finish(prompt, l)
+			return l.String(), io.EOF
+		case lkDelete:
+			if ch == 4 && len(l.buf) == 0 {
+				v.finish(prompt, l)
+				return "", io.EOF
+			}
+			l.deleteForward()
+		case lkBackspace:
+			l.backspace()
+		case lkLeft:
+			if l.pos > 0 {
+				l.pos--
+			}
+		case lkRight:
+			if l.pos < len(l.buf) {
+				l.pos++
+			}
+		case lkWordLeft:
+			l.pos = l.wordStart()
+		case lkWordRight:
+			l.pos = l.wordEnd()
+		case lkHome:
+			l.pos = 0
+		case lkEnd:
+			l.pos = len(l.buf)
+		case lkKillWord:
+			l.killWord()
+		case lkKillStart:
+			l.killStart()
+		case lkKillEnd:
+			l.killEnd()
+		case lkUp:
+			if histIdx > 0 {
+				if histIdx == len(e.history) {
+					pending = l.String()
+				}
+				histIdx--
+				l.set(e.history[histIdx])
+			}
+		case lkDown:
+			if histIdx < len(e.history) {
+				histIdx++
+				if histIdx == len(e.history) {
+					l.set(pending)
+				} else {
+					l.set(e.history[histIdx])
+				}
+			}
+		case lkClear:
+			fmt.Fprint(out, "\033[H\033[2J")
+			v.cursorRow = 0
+		case lkSearch:
+			if done := e.search(r, v, prompt, l); done {
+				v.finish(prompt, l)
+				return l.String(), nil
+			}
+			histIdx = len(e.history)
+		}
+		v.draw(prompt, l)
+	}
+}
+
+// search runs Ctrl-R reverse history search. It reports true when Enter
+// accepted a match; any other non-search key leaves the match in l for
+// further editing.
+func (e *LineEditor) search(r *bufio.Reader, v *lineView, prompt string, l *lineBuffer) bool {
+	original := l.String()
+	query := ""
+	from := len(e.history) - 1
+	match := -1
+	find := func(start int) int {
+		for i := start; i >= 0; i-- {
+			if query != "" && strings.Contains(strings.ToLower(e.history[i]), strings.ToLower(query)) {
+				return i
+			}
+		}
+		return -1
+	}
+	for {
+		label := fmt.Sprintf("(reverse-i-search)`%s': ", query)
+		view := &lineBuffer{}
+		if match >= 0 {
+			view.set(e.history[match])
+		}
+		v.draw(label, view)
+		k, ch := readLineKey(r)
+		switch k {
+		case lkRune:
+			query += string(ch)
+			match = find(from)
+		case lkBackspace:
+			if rs := []rune(query); len(rs) > 0 {
+				query = string(rs[:len(rs)-1])
+			}
+			from = len(e.history) - 1
+			match = find(from)
+		case lkSearch:
+			if match > 0 {
+				if next := find(match - 1); next >= 0 {
+					match = next
+				}
+			}
+		case lkEnter:
+			if match >= 0 {
+				l.set(e.history[match])
+			}
+			v.draw(prompt, l)
+			return true
+		case lkCancel, lkInterrupt:
+			l.set(original)
+			return false
+		default:
+			if match >= 0 {
+				l.set(e.history[match])
+			}
+			return false
+		}
+		if match >= 0 {
+			from = match
+		}
+	}
+}
+
+func (e *LineEditor) remember(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > lineHistoryMax {
+		e.history = e.history[len(e.history)-lineHistoryMax:]
+	}
+	if e.historyFile == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(e.historyFile), 0755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(e.historyFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(f, strconv.Quote(redact.String(line)))
+	f.Close()
+	if e.fileEntries++; e.fileEntries > lineHistoryMax {
+		e.trimHistoryFile()
+	}
+}
+
+// trimHistoryFile rewrites the history file with its last lineHistoryMax
+// lines. It reads the file again rather than writing e.history, so lines
+// another session appended meanwhile are kept.
+func (e *LineEditor) trimHistoryFile() {
+	data, err := os.ReadFile(e.historyFile)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > lineHistoryMax {
+		lines = lines[len(lines)-lineHistoryMax:]
+	}
+	if platform.WriteFileAtomic(e.historyFile, []byte(strings.Join(lines, "\n")+"\n"), 0o600) == nil {
+		e.fileEntries = len(lines)
+	}
+}
+
+func (e *LineEditor) loadHistory() {
+	if e.historyFile == "" {
+		return
+	}
+	data, err := os.ReadFile(e.historyFile)
+	if err != nil {
+		return
+	}
+	for _, raw := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(raw) != "" {
+			e.fileEntries++
+		}
+		if line, err := strconv.Unquote(strings.TrimSpace(raw)); err == nil && line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > lineHistoryMax {
+		e.history = e.history[len(e.history)-lineHistoryMax:]
+	}
+}
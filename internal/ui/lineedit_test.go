@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runEdit(t *testing.T, e *LineEditor, keys string) (string, error) {
+	t.Helper()
+	r := bufio.NewReader(strings.NewReader(keys))
+	return e.edit(r, io.Discard, "> ", 20)
+}
+
+func TestLineEditorCursorAndWordEditing(t *testing.T) {
+	e := &LineEditor{}
+	cases := []struct{ keys, want string }{
+		{"hello\x1b[D\x1b[DXY\r", "helXYlo"},
+		{"abc\x01Z\x05!\r", "Zabc!"},
+		{"git status --short\x17\x17log\r", "git log"},
+		{"one two\x1bbX\r", "one Xtwo"},
+		{"abcdef\x1b[D\x1b[D\x0b\r", "abcd"},
+		{"abcdef\x1b[D\x1b[D\x15\r", "ef"},
+		{"abc\x1b[H\x1b[3~\r", "bc"},
+		{"a\tb\r", "a b"},
+	}
+	for _, c := range cases {
+		got, err := runEdit(t, e, c.keys)
+		if err != nil || got != c.want {
+			t.Fatalf("keys %q: got %q, %v; want %q", c.keys, got, err, c.want)
+		}
+	}
+}
+
+func TestLineEditorNewlineAndPaste(t *testing.T) {
+	e := &LineEditor{}
+	got, err := runEdit(t, e, "first\x1b\rsecond\r")
+	if err != nil || got != "first\nsecond" {
+		t.Fatalf("alt+enter: got %q, %v", got, err)
+	}
+	got, err = runEdit(t, e, "log: \x1b[200~line 1\r\nline 2\x1b[201~\r")
+	if err != nil || got != "log: line 1\nline 2" {
+		t.Fatalf("paste: got %q, %v", got, err)
+	}
+}
+
+func TestLineEditorHistoryAndSearch(t *testing.T) {
+	e := &LineEditor{history: []string{"git status", "docker ps", "git log"}}
+	if got, _ := runEdit(t, e, "\x1b[A\x1b[A\r"); got != "docker ps" {
+		t.Fatalf("up twice: got %q", got)
+	}
+	if got, _ := runEdit(t, e, "draft\x1b[A\x1b[B\r"); got != "draft" {
+		t.Fatalf("down should restore the draft, got %q", got)
+	}
+	if got, _ := runEdit(t, e, "\x12git\r"); got != "git log" {
+		t.Fatalf("search: got %q", got)
+	}
+	if got, _ := runEdit(t, e, "\x12git\x12\r"); got != "git status" {
+		t.Fatalf("repeated search: got %q", got)
+	}
+	if got, _ := runEdit(t, e, "\x12dock\x05 -a\r"); got != "docker ps -a" {
+		t.Fatalf("search then edit: got %q", got)
+	}
+}
+
+func TestLineEditorInterruptAndEOF(t *testing.T) {
+	e := &LineEditor{}
+	if _, err := runEdit(t, e, "abc\x03"); err != ErrInterrupt {
+		t.Fatalf("expected ErrInterrupt, got %v", err)
+	}
+	if _, err := runEdit(t, e, "\x04"); err != io.EOF {
+		t.Fatalf("expected EOF on Ctrl-D, got %v", err)
+	}
+	if got, err := runEdit(t, e, "ab\x01\x04\r"); err != nil || got != "b" {
+		t.Fatalf("Ctrl-D should delete under cursor, got %q, %v", got, err)
+	}
+}
+
+func TestLineEditorPersistsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "history.txt")
+	e := NewLineEditor(path)
+	e.remember("first")
+	e.remember("multi\nline")
+	e.remember("multi\nline")
+	e.remember("  ")
+
+	again := NewLineEditor(path)
+	if strings.Join(again.history, "|") != "first|multi\nline" {
+		t.Fatalf("unexpected reloaded history %q", again.history)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("history file missing: %v", err)
+	}
+}
+
+func TestLineEditorRedactsPersistedHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.txt")
+	e := NewLineEditor(path)
+	e.remember("login with password=hunter2")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Fatalf("expected secret redacted in history file, got %q", data)
+	}
+}
+
+func TestLineViewWrapsLongLines(t *testing.T) {
+	var b strings.Builder
+	v := &lineView{out: &b, width: 10}
+	l := &lineBuffer{}
+	l.set("abcdefghijkl")
+	v.draw("> ", l)
+	if v.cursorRow != 1 {
+		t.Fatalf("expected cursor on the second row, got %d", v.cursorRow)
+	}
+	b.Reset()
+	l.pos = 0
+	v.draw("> ", l)
+	if !strings.HasPrefix(b.String(), "\x1b[1A\r") {
+		t.Fatalf("redraw should move up to the first row, got %q", b.String())
+	}
+}
+
+func TestLineEditorTrimsHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.txt")
+	e := NewLineEditor(path)
+	for i := 0; i < lineHistoryMax+50; i++ {
+		e.remember(fmt.Sprintf("cmd %d", i))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) != lineHistoryMax {
+		t.Fatalf("expected %d lines on disk, got %d", lineHistoryMax, len(lines))
+	}
+	if lines[len(lines)-1] != `"cmd 549"` || lines[0] != `"cmd 50"` {
+		t.Fatalf("expected the newest entries kept, got %s .. %s", lines[0], lines[len(lines)-1])
+	}
+	again := NewLineEditor(path)
+	again.remember("one more")
+	if again.fileEntries != lineHistoryMax {
+		t.Fatalf("expected a reopened editor to trim too, got %d entries", again.fileEntries)
+	}
+}
@@ -16,19 +16,22 @@ var (
 
 // RenderMarkdown converts common markdown elements to terminal-friendly output.
 // Syntax markers (**, ##, `, ```) are always stripped.
-// ANSI styling is applied only when the terminal supports color.
+// ANSI styling, including syntax highlighting of fenced code blocks with a
+// known language tag, is applied only when the terminal supports color.
 func RenderMarkdown(text string) string {
 	lines := strings.Split(text, "\n")
 	var out []string
 	inCodeBlock := false
+	lang := ""
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
 		if strings.HasPrefix(trimmed, "```") {
 			inCodeBlock = !inCodeBlock
+			lang = ""
 			if inCodeBlock {
-				lang := strings.TrimPrefix(trimmed, "```")
+				lang = strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
 				if lang != "" {
 					out = append(out, Muted("  "+lang))
 				}
@@ -37,7 +40,7 @@ func RenderMarkdown(text string) string {
 		}
 
 		if inCodeBlock {
-			out = append(out, Muted("  "+line))
+			out = append(out, "  "+highlightCode(lang, line))
 			continue
 		}
 
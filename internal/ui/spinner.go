@@ -15,12 +15,19 @@ type Spinner struct {
 	done    chan struct{}
 	exited  chan struct{}
 	message string
+	detail  string
 }
 
 func NewSpinner(message string) *Spinner {
 	return &Spinner{message: message}
 }
 
+// SetDetail adds a muted note, such as the provider/model, after the
+// elapsed time. Call it before Start.
+func (s *Spinner) SetDetail(detail string) {
+	s.detail = detail
+}
+
 func (s *Spinner) Start() {
 	if !term.IsTerminal(int(os.Stderr.Fd())) && !term.IsTerminal(int(os.Stdout.Fd())) {
 		return
@@ -38,6 +45,7 @@ func (s *Spinner) Start() {
 	go func() {
 		defer close(s.exited)
 		frames := []string{"|", "/", "-", "\\"}
+		started := time.Now()
 		i := 0
 		for {
 			select {
@@ -45,8 +53,11 @@ func (s *Spinner) Start() {
 				fmt.Fprint(os.Stderr, "\r\033[K")
 				return
 			default:
-				label := Muted(fmt.Sprintf("\r  %s %s", frames[i%len(frames)], s.message))
-				fmt.Fprint(os.Stderr, label)
+				label := fmt.Sprintf("\r  %s %s %s", frames[i%len(frames)], s.message, formatElapsed(time.Since(started)))
+				if s.detail != "" {
+					label += "  " + s.detail
+				}
+				fmt.Fprint(os.Stderr, Muted(label))
 				i++
 				time.Sleep(120 * time.Millisecond)
 			}
@@ -66,3 +77,11 @@ func (s *Spinner) Stop() {
 	s.mu.Unlock()
 	<-exited
 }
+
+func formatElapsed(d time.Duration) string {
+	secs := int(d / time.Second)
+	if secs < 60 {
+		return fmt.Sprintf("%ds", secs)
+	}
+	return fmt.Sprintf("%dm%02ds", secs/60, secs%60)
+}
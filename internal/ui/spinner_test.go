@@ -0,0 +1,20 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatElapsed(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                       "0s",
+		1500 * time.Millisecond: "1s",
+		59 * time.Second:        "59s",
+		75 * time.Second:        "1m15s",
+	}
+	for d, want := range cases {
+		if got := formatElapsed(d); got != want {
+			t.Fatalf("formatElapsed(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
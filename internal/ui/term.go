@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// defaultTerminalWidth is used when stdout is a terminal whose size cannot
+// be read.
+const defaultTerminalWidth = 80
+
+// minWidthLeft keeps WidthLeft from squeezing a column to nothing on very
+// narrow terminals.
+const minWidthLeft = 16
+
+func StdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// StdoutIsTerminal reports whether stdout is a terminal.
+func StdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// ClearPrinted moves the cursor back over text just printed to stdout,
+// ending on its last line, and erases it so it can be printed again. It
+// does nothing and returns false when stdout is not a terminal or text
+// has scrolled out of view.
+func ClearPrinted(text string) bool {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return false
+	}
+	w, h, err := term.GetSize(fd)
+	if err != nil || w <= 0 {
+		return false
+	}
+	rows := 0
+	for _, line := range strings.Split(text, "\n") {
+		rows += max(1, (StringWidth(line)+w-1)/w)
+	}
+	if rows >= h {
+		return false
+	}
+	fmt.Print("\r")
+	if rows > 1 {
+		fmt.Printf("\033[%dA", rows-1)
+	}
+	fmt.Print("\033[J")
+	return true
+}
+
+// ReadHidden reads a line from the terminal without echoing it.
+func ReadHidden(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return string(b), err
+}
+
+// TerminalWidth returns the number of columns of the terminal on stdout.
+// COLUMNS overrides it; when stdout is not a terminal and COLUMNS is unset
+// it returns 0, meaning output should not be cut to fit.
+func TerminalWidth() int {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS"))); err == nil && n > 0 {
+		return n
+	}
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0
+	}
+	if w, _, err := term.GetSize(fd); err == nil && w > 0 {
+		return w
+	}
+	return defaultTerminalWidth
+}
+
+// WidthLeft returns the cells left on a terminal line after used cells, or
+// 0 when the width is unknown (Truncate and Wrap then leave text as is).
+func WidthLeft(used int) int {
+	w := TerminalWidth()
+	if w <= 0 {
+		return 0
+	}
+	return max(w-used, minWidthLeft)
+}
@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wideRanges are the East Asian wide/fullwidth blocks and emoji that take
+// two terminal cells.
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE30, 0xFE4F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F000, 0x1FAFF},
+	{0x20000, 0x3FFFD},
+}
+
+// RuneWidth returns how many terminal cells r occupies: 0 for combining
+// marks and control characters, 2 for wide characters, otherwise 1.
+func RuneWidth(r rune) int {
+	if r == 0 || unicode.IsControl(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, w := range wideRanges {
+		if r < w.lo {
+			break
+		}
+		if r <= w.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// StringWidth is the display width of s in terminal cells.
+func StringWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += RuneWidth(r)
+	}
+	return n
+}
+
+// Truncate shortens s to at most width cells, ending with "..." when cut.
+func Truncate(s string, width int) string {
+	if width <= 0 || StringWidth(s) <= width {
+		return s
+	}
+	if width <= 3 {
+		return cutWidth(s, width)
+	}
+	return cutWidth(s, width-3) + "..."
+}
+
+func cutWidth(s string, width int) string {
+	n := 0
+	for i, r := range s {
+		w := RuneWidth(r)
+		if n+w > width {
+			return s[:i]
+		}
+		n += w
+	}
+	return s
+}
+
+// TruncateMiddle shortens s to at most width cells by replacing its middle
+// with "...", so both the start and the file name of a long path stay
+// visible.
+func TruncateMiddle(s string, width int) string {
+	if width <= 0 || StringWidth(s) <= width {
+		return s
+	}
+	if width <= 3 {
+		return cutWidth(s, width)
+	}
+	keep := width - 3
+	tail := keep - keep/2
+	if sep := strings.LastIndexAny(s, `/\`); sep >= 0 && StringWidth(s[sep:]) <= keep-4 {
+		tail = StringWidth(s[sep:])
+	}
+	return cutWidth(s, keep-tail) + "..." + lastWidth(s, tail)
+}
+
+func lastWidth(s string, width int) string {
+	n := 0
+	for i := len(s); i > 0; {
+		r, size := utf8.DecodeLastRuneInString(s[:i])
+		if n+RuneWidth(r) > width {
+			return s[i:]
+		}
+		n += RuneWidth(r)
+		i -= size
+	}
+	return s
+}
+
+// Wrap breaks s into lines of at most width cells at spaces, starting
+// continuation lines with indent. Words longer than a line are kept whole
+// rather than split mid-word.
+func Wrap(s string, width int, indent string) string {
+	if width <= 0 || StringWidth(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	lineW := 0
+	for i, word := range strings.Fields(s) {
+		ww := StringWidth(word)
+		switch {
+		case i == 0:
+			if lead := len(s) - len(strings.TrimLeft(s, " ")); lead > 0 {
+				b.WriteString(s[:lead])
+				lineW = lead
+			}
+		case lineW+1+ww > width:
+			b.WriteString("\n" + indent)
+			lineW = StringWidth(indent)
+		default:
+			b.WriteByte(' ')
+			lineW++
+		}
+		b.WriteString(word)
+		lineW += ww
+	}
+	return b.String()
+}
+
+// TruncateBytes cuts s to at most n bytes without splitting a character.
+func TruncateBytes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// PadRight pads s with spaces to width cells, like %-*s for display width.
+func PadRight(s string, width int) string {
+	if w := StringWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
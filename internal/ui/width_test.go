@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestStringWidth(t *testing.T) {
+	cases := map[string]int{
+		"abc":        3,
+		"日本語":        6,
+		"café":       4,
+		"cafe\u0301": 4,
+		"🚀 go":       5,
+		"":           0,
+	}
+	for in, want := range cases {
+		if got := StringWidth(in); got != want {
+			t.Fatalf("StringWidth(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("hello world", 8); got != "hello..." {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := Truncate("日本語のテキスト", 9); got != "日本語..." {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := Truncate("short", 10); got != "short" {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := Truncate("日本語", 3); got != "日" {
+		t.Fatalf("unexpected %q", got)
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	got := TruncateBytes("añb€c", 5)
+	if !utf8.ValidString(got) || got != "añb" {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := TruncateBytes("abc", 10); got != "abc" {
+		t.Fatalf("unexpected %q", got)
+	}
+}
+
+func TestPadRight(t *testing.T) {
+	if got := PadRight("日本", 6); got != "日本  " {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := PadRight("toolong", 3); got != "toolong" {
+		t.Fatalf("unexpected %q", got)
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	path := `C:\Users\me\Projects\very\deep\folder\report.docx`
+	got := TruncateMiddle(path, 30)
+	if StringWidth(got) > 30 || got[:3] != `C:\` || !strings.HasSuffix(got, `\report.docx`) {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := TruncateMiddle("abcdefghij", 7); got != "ab...ij" {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := TruncateMiddle("short", 0); got != "short" {
+		t.Fatalf("width 0 should not cut, got %q", got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	got := Wrap("- g_log(Count=10): Show recent commits on the current branch", 24, "    ")
+	want := "- g_log(Count=10): Show\n    recent commits on\n    the current branch"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got := Wrap("see /a/very/long/path/that/does/not/fit", 10, ""); got != "see\n/a/very/long/path/that/does/not/fit" {
+		t.Fatalf("long words should stay whole, got %q", got)
+	}
+}
+
+func TestTerminalWidthFromColumns(t *testing.T) {
+	t.Setenv("COLUMNS", "100")
+	if got := TerminalWidth(); got != 100 {
+		t.Fatalf("TerminalWidth() = %d, want 100", got)
+	}
+	if got := WidthLeft(90); got != minWidthLeft {
+		t.Fatalf("WidthLeft(90) = %d, want %d", got, minWidthLeft)
+	}
+}
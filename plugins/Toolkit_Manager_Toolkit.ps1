@@ -536,7 +536,9 @@ function tk_scaffold {
 .SYNOPSIS
 Validate PowerShell syntax of a toolkit file.
 .DESCRIPTION
-Parses the toolkit file as a scriptblock to check for syntax errors.
+Parses the toolkit file as a scriptblock to check for syntax errors,
+then runs `dm plugins lint` on it (synopsis and parameter docs) when dm
+is on PATH.
 .PARAMETER Name
 Toolkit label, filename, or prefix.
 .EXAMPLE
@@ -555,6 +557,17 @@ function tk_validate {
         [scriptblock]::Create($content) | Out-Null
         $fns = _tk_extract_functions -FilePath $file.FullName
 
+        Write-Output "OK: $($file.Name) - syntax valid, $($fns.Count) functions."
+
+        if (Get-Command -Name dm -ErrorAction SilentlyContinue) {
+            $lint = @(& dm plugins lint --file $file.FullName 2>&1)
+            if ($LASTEXITCODE -ne 0) {
+                Write-Output "Warnings (catalog documentation):"
+            }
+            $lint | ForEach-Object { Write-Output $_ }
+            return
+        }
+
         $issues = @()
         foreach ($fn in $fns) {
             $help = Get-Help $fn -ErrorAction SilentlyContinue
@@ -562,8 +575,6 @@ function tk_validate {
                 $issues += "  - $fn : missing .SYNOPSIS"
             }
         }
-
-        Write-Output "OK: $($file.Name) - syntax valid, $($fns.Count) functions."
         if ($issues.Count -gt 0) {
             Write-Output "Warnings (missing documentation):"
             $issues | ForEach-Object { Write-Output $_ }
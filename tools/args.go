@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	ArgString = "string"
+	ArgInt    = "int"
+	ArgBool   = "bool"
+	ArgPath   = "path"
+)
+
+// ArgSpec describes one tool_args key accepted by a tool.
+type ArgSpec struct {
+	Name        string
+	Type        string
+	Required    bool
+	Enum        []string
+	Default     string
+	Description string
+}
+
+func formatArgSpecs(args []ArgSpec) string {
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		notes := []string{argType(a)}
+		if a.Required {
+			notes = append(notes, "required")
+		}
+		if len(a.Enum) > 0 {
+			notes[0] = strings.Join(a.Enum, "|")
+		}
+		if a.Default != "" {
+			notes = append(notes, "default "+a.Default)
+		}
+		if a.Description != "" {
+			notes = append(notes, a.Description)
+		}
+		parts = append(parts, a.Name+" ("+strings.Join(notes, ", ")+")")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func argType(a ArgSpec) string {
+	if a.Type == "" {
+		return ArgString
+	}
+	return a.Type
+}
+
+func validArgType(t string) bool {
+	switch t {
+	case "", ArgString, ArgInt, ArgBool, ArgPath:
+		return true
+	}
+	return false
+}
+
+// ValidateToolArgs checks agent-provided tool_args against the tool's schema.
+// Keys that are not declared are ignored.
+func ValidateToolArgs(name string, args map[string]string) error {
+	t := lookupTool(name)
+	if t == nil {
+		return fmt.Errorf("unknown tool: %s", name)
+	}
+	d := t.Describe()
+	var problems []string
+	for _, spec := range d.Args {
+		raw, has := args[spec.Name]
+		v := strings.TrimSpace(raw)
+		if !has || v == "" {
+			if spec.Required {
+				problems = append(problems, spec.Name+" is required")
+			}
+			continue
+		}
+		if err := validateArgValue(spec, v); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid tool_args for %s: %s", d.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func validateArgValue(spec ArgSpec, v string) error {
+	switch argType(spec) {
+	case ArgInt:
+		if _, err := strconv.Atoi(v); err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", spec.Name, v)
+		}
+	case ArgBool:
+		switch strings.ToLower(v) {
+		case "1", "0", "true", "false", "yes", "no", "y", "n":
+		default:
+			return fmt.Errorf("%s must be true or false, got %q", spec.Name, v)
+		}
+	}
+	if len(spec.Enum) > 0 {
+		for _, e := range spec.Enum {
+			if strings.EqualFold(v, e) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s, got %q", spec.Name, strings.Join(spec.Enum, "|"), v)
+	}
+	return nil
+}
@@ -81,17 +81,14 @@ func BenchmarkSearchPagingCacheHit(b *testing.B) {
 	if err != nil {
 		b.Fatal(err)
 	}
-	pagingCacheMu.Lock()
-	searchPageCache[key] = searchPageCacheEntry{
+	searchPages.lru.Set(key, pageCacheEntry[filesearch.Result]{
 		Results: results,
 		Stored:  time.Now(),
-		LastUse: time.Now(),
-	}
-	pagingCacheMu.Unlock()
+	})
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		got, err := getOrLoadSearchPageResults(key, func() ([]filesearch.Result, error) {
+		got, err := searchPages.getOrLoad(key, func() ([]filesearch.Result, error) {
 			return filesearch.Find(filesearch.Options{
 				BasePath: base,
 				NamePart: "note",
@@ -154,17 +151,14 @@ func BenchmarkRecentPagingCacheHit(b *testing.B) {
 	if err != nil {
 		b.Fatal(err)
 	}
-	pagingCacheMu.Lock()
-	recentPageCache[key] = recentPageCacheEntry{
+	recentPages.lru.Set(key, pageCacheEntry[recentItem]{
 		Results: items,
 		Stored:  time.Now(),
-		LastUse: time.Now(),
-	}
-	pagingCacheMu.Unlock()
+	})
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		got, err := getOrLoadRecentPageResults(key, func() ([]recentItem, error) {
+		got, err := recentPages.getOrLoad(key, func() ([]recentItem, error) {
 			return collectRecentSorted(base)
 		})
 		if err != nil {
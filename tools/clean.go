@@ -8,11 +8,49 @@ import (
 	"sort"
 	"strings"
 
+	"cli/internal/platform"
 	"cli/internal/ui"
 )
 
+func init() { registerTool(cleanTool{}) }
+
+type cleanTool struct{}
+
+func (cleanTool) Describe() ToolDescriptor {
+	return ToolDescriptor{
+		Key:      "c",
+		Name:     "clean",
+		Synopsis: "Delete empty folders",
+		Aliases:  []string{"c"},
+		Args: []ArgSpec{
+			{Name: "base", Type: ArgPath},
+			{Name: "exclude", Type: ArgString, Description: "comma-separated globs to keep e.g. .git,node_modules"},
+			{Name: "apply", Type: ArgBool, Description: "true for delete, otherwise preview"},
+		},
+		RiskLevel: "low",
+		RiskNote:  "preview only",
+		Short:     "Delete empty folders",
+		Help:      "Asks for base path and exclude globs, previews empty folders, and asks for confirmation before deletion.",
+		Example:   "dm tools clean",
+		Order:     40,
+	}
+}
+
+func (cleanTool) RunInteractive(_ string, r *bufio.Reader) int { return RunCleanEmpty(r) }
+
+func (cleanTool) RunAuto(baseDir string, params ToolParams) AutoRunResult {
+	return AutoRunResult{Code: RunCleanEmptyAuto(baseDir, params)}
+}
+
+func (cleanTool) Risk(params ToolParams) (string, string) {
+	if params.Bool("apply") {
+		return "high", "delete empty directories"
+	}
+	return "low", "preview only"
+}
+
 func RunCleanEmpty(r *bufio.Reader) int {
-	base := prompt(r, "Base path", currentWorkingDir("."))
+	base := prompt(r, "Base path", ToolDefault("clean", "base", currentWorkingDir(".")))
 	base = normalizeInputPath(base, currentWorkingDir("."))
 	if strings.TrimSpace(base) == "" {
 		fmt.Println("Error: base path is required.")
@@ -23,14 +61,22 @@ func RunCleanEmpty(r *bufio.Reader) int {
 		fmt.Println(ui.Muted("Hint: use '.' for current dir or '..' for parent dir."))
 		return 1
 	}
+	exclude, err := parseExcludeGlobs(prompt(r, "Exclude globs (comma separated, optional)", ToolDefault("clean", "exclude", "")))
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
 
-	dirs, code := showEmptyDirs(base)
+	dirs, code := showEmptyDirs(base, exclude)
 	if code != 0 {
 		return code
 	}
 	if len(dirs) == 0 {
 		return 0
 	}
+	if !guardProtected(base) {
+		return 1
+	}
 
 	confirm := prompt(r, "Delete these folders? [y/N]", "N")
 	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
@@ -38,6 +84,11 @@ func RunCleanEmpty(r *bufio.Reader) int {
 		return 0
 	}
 
+	release, ok := lockPath("clean apply", base)
+	if !ok {
+		return 1
+	}
+	defer release()
 	return removeEmptyDirs(dirs)
 }
 
@@ -47,23 +98,36 @@ func RunCleanEmptyAuto(baseDir string, params map[string]string) int {
 		base = currentWorkingDir(baseDir)
 	}
 	base = normalizeAgentPath(base, baseDir)
-	dirs, code := showEmptyDirs(base)
+	apply := ToolParams(params).Bool("apply")
+	exclude, err := parseExcludeGlobs(params["exclude"])
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	if apply && (!guardProtected(base) || !confirmAgentPath(base)) {
+		return 1
+	}
+	dirs, code := showEmptyDirs(base, exclude)
 	if code != 0 {
 		return code
 	}
 	if len(dirs) == 0 {
 		return 0
 	}
-	apply := strings.ToLower(strings.TrimSpace(params["apply"]))
-	if apply != "1" && apply != "true" && apply != "yes" && apply != "y" {
+	if !apply {
 		fmt.Println(ui.Muted("Preview only. Set tool_args.apply=true to delete."))
 		return 0
 	}
+	release, ok := lockPath("clean apply", base)
+	if !ok {
+		return 1
+	}
+	defer release()
 	return removeEmptyDirs(dirs)
 }
 
-func showEmptyDirs(base string) ([]string, int) {
-	dirs, err := findEmptyDirs(base)
+func showEmptyDirs(base string, exclude []string) ([]string, int) {
+	dirs, err := findEmptyDirs(base, exclude)
 	if err != nil {
 		fmt.Println("Error:", err)
 		return nil, 1
@@ -81,30 +145,36 @@ func showEmptyDirs(base string) ([]string, int) {
 
 func removeEmptyDirs(dirs []string) int {
 	for _, d := range dirs {
-		_ = os.Remove(d)
+		_ = os.Remove(platform.LongPath(d))
 	}
 	fmt.Println("Done.")
 	return 0
 }
 
-func findEmptyDirs(base string) ([]string, error) {
+// findEmptyDirs lists empty folders under base, deepest first. Folders
+// matching an exclude glob are kept and not walked into.
+func findEmptyDirs(base string, exclude []string) ([]string, error) {
 	var dirs []string
-	err := filepath.Walk(base, func(path string, info os.FileInfo, err error) error {
+	root := platform.LongPath(base)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 		if !info.IsDir() {
 			return nil
 		}
-		if path == base {
+		if path == root {
 			return nil
 		}
+		if excludedPath(root, path, exclude) {
+			return filepath.SkipDir
+		}
 		entries, err := os.ReadDir(path)
 		if err != nil {
 			return nil
 		}
 		if len(entries) == 0 {
-			dirs = append(dirs, path)
+			dirs = append(dirs, platform.ShortPath(path))
 		}
 		return nil
 	})
@@ -0,0 +1,287 @@
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"cli/internal/ui"
+)
+
+type CustomToolArg struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Required    bool     `json:"required"`
+	Default     string   `json:"default"`
+	Type        string   `json:"type"`
+	Enum        []string `json:"enum"`
+}
+
+type CustomTool struct {
+	Name     string          `json:"name"`
+	Synopsis string          `json:"synopsis"`
+	Aliases  []string        `json:"aliases"`
+	Args     []CustomToolArg `json:"args"`
+	Command  string          `json:"command"`
+	Risk     string          `json:"risk"`
+}
+
+var customPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+
+type customTool struct {
+	def   CustomTool
+	order int
+}
+
+func (t customTool) Describe() ToolDescriptor {
+	return ToolDescriptor{
+		Name:      strings.ToLower(strings.TrimSpace(t.def.Name)),
+		Synopsis:  t.def.Synopsis,
+		Aliases:   t.def.Aliases,
+		Args:      customArgSpecs(t.def),
+		RiskLevel: customRiskLevel(t.def.Risk),
+		RiskNote:  "custom command: " + t.def.Command,
+		Short:     t.def.Synopsis,
+		Order:     t.order,
+	}
+}
+
+func (t customTool) RunInteractive(_ string, r *bufio.Reader) int {
+	return runCustomToolInteractive(t.def, r)
+}
+
+func (t customTool) RunAuto(_ string, params ToolParams) AutoRunResult {
+	return AutoRunResult{Code: runCustomTool(t.def, params)}
+}
+
+// SetCustomTools registers the dm.json "tools" after the built-in tools,
+// replacing any previous set. Invalid tools are skipped; a name or alias
+// that another tool already answers to is reported, and such an alias is
+// dropped, since lookups would never reach it.
+func SetCustomTools(defs []CustomTool) error {
+	unregisterTools(func(t Tool) bool {
+		_, isCustom := t.(customTool)
+		return !isCustom
+	})
+	var problems []string
+	for i := range defs {
+		ct := defs[i]
+		if err := validateCustomTool(ct); err != nil {
+			problems = append(problems, err.Error()+" (skipped)")
+			continue
+		}
+		var aliases []string
+		for _, alias := range ct.Aliases {
+			if owner := lookupTool(alias); owner != nil {
+				problems = append(problems, fmt.Sprintf("tool %s: alias %s already used by %s (ignored)", strings.ToLower(strings.TrimSpace(ct.Name)), alias, owner.Describe().Name))
+				continue
+			}
+			aliases = append(aliases, alias)
+		}
+		ct.Aliases = aliases
+		registerTool(customTool{def: ct, order: 1000 + i})
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("custom tools: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func validateCustomTool(ct CustomTool) error {
+	if err := validateCustomToolDef(ct); err != nil {
+		return err
+	}
+	if name := strings.ToLower(strings.TrimSpace(ct.Name)); normalizeToolName(name) != "" {
+		return fmt.Errorf("tool %s: name already used", name)
+	}
+	return nil
+}
+
+func validateCustomToolDef(ct CustomTool) error {
+	name := strings.ToLower(strings.TrimSpace(ct.Name))
+	if name == "" {
+		return fmt.Errorf("tool without name")
+	}
+	if strings.TrimSpace(ct.Command) == "" {
+		return fmt.Errorf("tool %s: command is required", name)
+	}
+	declared := map[string]bool{}
+	for _, a := range ct.Args {
+		declared[strings.TrimSpace(a.Name)] = true
+		if !validArgType(strings.ToLower(strings.TrimSpace(a.Type))) {
+			return fmt.Errorf("tool %s: arg %s has unknown type %q", name, a.Name, a.Type)
+		}
+	}
+	for _, m := range customPlaceholder.FindAllStringSubmatch(ct.Command, -1) {
+		if !declared[m[1]] {
+			return fmt.Errorf("tool %s: placeholder {{%s}} has no matching arg", name, m[1])
+		}
+	}
+	return nil
+}
+
+var powerShellCmdlet = regexp.MustCompile(`^[A-Za-z]+-[A-Za-z]+$`)
+
+// CheckCustomTools reports invalid or duplicate tools, names and aliases
+// that clash with built-in tools and commands whose program cannot be
+// found, for `dm doctor`.
+func CheckCustomTools(defs []CustomTool, lookPath func(string) (string, error)) []string {
+	var problems []string
+	seen := map[string]bool{}
+	for _, ct := range defs {
+		if err := validateCustomToolDef(ct); err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(ct.Name))
+		if seen[name] {
+			problems = append(problems, fmt.Sprintf("tool %s: defined more than once", name))
+		}
+		seen[name] = true
+		if isBuiltinTool(lookupTool(name)) {
+			problems = append(problems, fmt.Sprintf("tool %s: name already used by a built-in tool", name))
+		}
+		for _, alias := range ct.Aliases {
+			if t := lookupTool(alias); isBuiltinTool(t) {
+				problems = append(problems, fmt.Sprintf("tool %s: alias %s already used by built-in tool %s", name, alias, t.Describe().Name))
+			}
+		}
+		if prog := customCommandProgram(ct.Command); prog != "" {
+			if _, err := lookPath(prog); err != nil {
+				problems = append(problems, fmt.Sprintf("tool %s: program %q not found", name, prog))
+			}
+		}
+	}
+	return problems
+}
+
+func isBuiltinTool(t Tool) bool {
+	if t == nil {
+		return false
+	}
+	_, isCustom := t.(customTool)
+	return !isCustom
+}
+
+// customCommandProgram returns the executable a custom command starts, or ""
+// when it cannot be checked (placeholders, PowerShell cmdlets).
+func customCommandProgram(command string) string {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return ""
+	}
+	var prog string
+	if command[0] == '"' || command[0] == '\'' {
+		end := strings.IndexByte(command[1:], command[0])
+		if end < 0 {
+			return ""
+		}
+		prog = command[1 : end+1]
+	} else {
+		prog = strings.Fields(command)[0]
+	}
+	if customPlaceholder.MatchString(prog) || powerShellCmdlet.MatchString(prog) {
+		return ""
+	}
+	return prog
+}
+
+func customRiskLevel(raw string) string {
+	switch r := strings.ToLower(strings.TrimSpace(raw)); r {
+	case "low", "medium", "high":
+		return r
+	default:
+		return "medium"
+	}
+}
+
+func customArgSpecs(ct CustomTool) []ArgSpec {
+	specs := make([]ArgSpec, 0, len(ct.Args))
+	for _, a := range ct.Args {
+		specs = append(specs, ArgSpec{
+			Name:        a.Name,
+			Type:        strings.ToLower(strings.TrimSpace(a.Type)),
+			Required:    a.Required && a.Default == "",
+			Enum:        a.Enum,
+			Default:     a.Default,
+			Description: a.Description,
+		})
+	}
+	return specs
+}
+
+func renderCustomCommand(ct CustomTool, params map[string]string, quote func(string) string) (string, error) {
+	values := map[string]string{}
+	var missing []string
+	for _, a := range ct.Args {
+		v := strings.TrimSpace(params[a.Name])
+		if v == "" {
+			v = a.Default
+		}
+		if v == "" && a.Required {
+			missing = append(missing, a.Name)
+		}
+		values[a.Name] = v
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("missing required args: %s", strings.Join(missing, ", "))
+	}
+	return customPlaceholder.ReplaceAllStringFunc(ct.Command, func(m string) string {
+		key := customPlaceholder.FindStringSubmatch(m)[1]
+		if values[key] == "" {
+			return ""
+		}
+		return quote(values[key])
+	}), nil
+}
+
+func customShellQuote(v string) string {
+	if runtime.GOOS == "windows" {
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
+
+func runCustomTool(ct CustomTool, params map[string]string) int {
+	command, err := renderCustomCommand(ct, params, customShellQuote)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stdout
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+		fmt.Println("Error:", err)
+		return 1
+	}
+	return 0
+}
+
+func runCustomToolInteractive(ct CustomTool, r *bufio.Reader) int {
+	params := map[string]string{}
+	for _, a := range ct.Args {
+		label := a.Name
+		if a.Description != "" {
+			label += " (" + a.Description + ")"
+		}
+		params[a.Name] = prompt(r, label, a.Default)
+	}
+	fmt.Println(ui.Muted("Running custom tool " + ct.Name + "..."))
+	return runCustomTool(ct, params)
+}
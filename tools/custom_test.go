@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func customToolDefs(t *testing.T, raw string) []CustomTool {
+	t.Helper()
+	var defs []CustomTool
+	if err := json.Unmarshal([]byte(raw), &defs); err != nil {
+		t.Fatal(err)
+	}
+	return defs
+}
+
+func TestSetCustomToolsRegistersAndReplaces(t *testing.T) {
+	defs := customToolDefs(t, `[
+		{"name":"Ping","synopsis":"Ping a host","aliases":["pg","s"],"command":"ping {{host}}","args":[{"name":"host","required":true}],"risk":"low"},
+		{"name":"search","command":"echo dup"},
+		{"name":"broken","command":"echo {{missing}}"},
+		{"name":"typed","command":"echo {{n}}","args":[{"name":"n","type":"float"}]}
+	]`)
+	defer func() { _ = SetCustomTools(nil) }()
+
+	err := SetCustomTools(defs)
+	if err == nil || !strings.Contains(err.Error(), "search") || !strings.Contains(err.Error(), "missing") || !strings.Contains(err.Error(), "float") {
+		t.Fatalf("expected errors for duplicate and broken tools, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "alias s already used by search") {
+		t.Fatalf("expected the alias collision to be reported, got %v", err)
+	}
+	if !IsKnownTool("ping") || normalizeToolName("pg") != "ping" {
+		t.Fatal("expected custom tool to be registered with its free alias")
+	}
+	if normalizeToolName("s") != "search" {
+		t.Fatal("a colliding alias must keep pointing at the built-in tool")
+	}
+	if !strings.Contains(BuildAgentCatalog(), "- ping: Ping a host | tool_args: host (string, required)") {
+		t.Fatalf("expected custom tool in catalog, got:\n%s", BuildAgentCatalog())
+	}
+	if risk, _ := ToolRisk("ping", nil); risk != "low" {
+		t.Fatalf("expected low risk, got %q", risk)
+	}
+
+	_ = SetCustomTools(defs)
+	count := 0
+	for _, td := range Descriptors() {
+		if td.Name == "ping" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected reload to replace custom tools, got %d entries", count)
+	}
+	if IsKnownTool("") {
+		t.Fatal("empty name must not match a custom tool")
+	}
+}
+
+func TestRenderCustomCommand(t *testing.T) {
+	ct := CustomTool{
+		Name:    "greet",
+		Command: "echo {{ greeting }} {{name}}",
+		Args: []CustomToolArg{
+			{Name: "greeting", Default: "hi"},
+			{Name: "name", Required: true},
+		},
+	}
+	quote := func(v string) string { return "[" + v + "]" }
+	got, err := renderCustomCommand(ct, map[string]string{"name": "bob"}, quote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "echo [hi] [bob]" {
+		t.Fatalf("unexpected command: %q", got)
+	}
+	if _, err := renderCustomCommand(ct, nil, quote); err == nil {
+		t.Fatal("expected missing required arg error")
+	}
+}
+
+func TestCheckCustomToolsReportsProblems(t *testing.T) {
+	defs := customToolDefs(t, `[
+		{"name":"ping","command":"ping {{host}}","args":[{"name":"host"}]},
+		{"name":"ping","command":"ping -c 1 {{host}}","args":[{"name":"host"}]},
+		{"name":"search","command":"echo dup"},
+		{"name":"ghost","command":"\"no-such-program\" --x"},
+		{"name":"ls","aliases":["s"],"command":"Get-ChildItem"}
+	]`)
+	lookPath := func(p string) (string, error) {
+		if p == "no-such-program" {
+			return "", os.ErrNotExist
+		}
+		return "/bin/" + p, nil
+	}
+	problems := CheckCustomTools(defs, lookPath)
+	joined := strings.Join(problems, "\n")
+	for _, want := range []string{"ping: defined more than once", "search: name already used by a built-in tool", `ghost: program "no-such-program" not found`, "ls: alias s already used by built-in tool search"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected %q in problems:\n%s", want, joined)
+		}
+	}
+	if len(problems) != 4 {
+		t.Fatalf("expected 4 problems, got %v", problems)
+	}
+}
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// toolDefaults holds the dm.json "defaults" section: argument values per
+// tool, used when neither the user nor the agent gives one.
+var toolDefaults = map[string]map[string]string{}
+
+// Arguments that only make sense per call: a default apply would turn a
+// preview into a destructive run behind the risk check.
+var nonDefaultableArgs = map[string]bool{"apply": true, "cursor": true, "offset": true}
+
+// SetToolDefaults replaces the per-tool defaults. Numbers and booleans are
+// kept as text, lists are joined with commas and a leading ~ is expanded,
+// which is how tools take their arguments.
+func SetToolDefaults(raw map[string]map[string]any) error {
+	out := map[string]map[string]string{}
+	var problems []string
+	for tool, args := range raw {
+		name := strings.ToLower(strings.TrimSpace(tool))
+		if canonical := normalizeToolName(name); canonical != "" {
+			name = canonical
+		}
+		for arg, v := range args {
+			arg = strings.ToLower(strings.TrimSpace(arg))
+			if nonDefaultableArgs[arg] {
+				problems = append(problems, fmt.Sprintf("defaults.%s.%s: cannot have a default", name, arg))
+				continue
+			}
+			s, ok := configDefaultString(v)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("defaults.%s.%s: unsupported value", name, arg))
+				continue
+			}
+			if out[name] == nil {
+				out[name] = map[string]string{}
+			}
+			out[name][arg] = s
+		}
+	}
+	toolDefaults = out
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func configDefaultString(v any) (string, bool) {
+	switch x := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return expandConfigHome(strings.TrimSpace(x)), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(x), true
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			s, ok := item.(string)
+			if !ok {
+				return "", false
+			}
+			if s = strings.TrimSpace(s); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ","), true
+	}
+	return "", false
+}
+
+func expandConfigHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") && !strings.HasPrefix(p, `~\`) {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || strings.TrimSpace(home) == "" {
+		return p
+	}
+	return filepath.Join(home, p[1:])
+}
+
+// ToolDefault returns the dm.json default of arg for tool, or fallback.
+func ToolDefault(tool, arg, fallback string) string {
+	if v := toolDefaults[tool][arg]; v != "" {
+		return v
+	}
+	return fallback
+}
+
+// withToolDefaults returns params with the configured defaults filled in
+// for the declared args that were left empty.
+func withToolDefaults(d ToolDescriptor, params map[string]string) map[string]string {
+	defaults := toolDefaults[d.Name]
+	if len(defaults) == 0 {
+		return params
+	}
+	out := make(map[string]string, len(params)+len(defaults))
+	for k, v := range params {
+		out[k] = v
+	}
+	for _, spec := range d.Args {
+		if v := defaults[spec.Name]; v != "" && strings.TrimSpace(out[spec.Name]) == "" {
+			out[spec.Name] = v
+		}
+	}
+	return out
+}
+
+// describe returns the descriptor of t with configured defaults shown on
+// its args, so the agent catalog tells the model what an omitted arg means.
+func describe(t Tool) ToolDescriptor {
+	d := t.Describe()
+	defaults := toolDefaults[d.Name]
+	if len(defaults) == 0 {
+		return d
+	}
+	args := make([]ArgSpec, len(d.Args))
+	copy(args, d.Args)
+	for i := range args {
+		if v := defaults[args[i].Name]; v != "" {
+			args[i].Default = v
+		}
+	}
+	d.Args = args
+	return d
+}
+
+// CheckToolDefaults reports defaults for args a tool does not declare or
+// with values the arg does not accept.
+func CheckToolDefaults(raw map[string]map[string]any) []string {
+	var problems []string
+	for tool, args := range raw {
+		name := strings.ToLower(strings.TrimSpace(tool))
+		t := lookupTool(name)
+		if t == nil {
+			continue
+		}
+		d := t.Describe()
+		specs := map[string]ArgSpec{}
+		for _, spec := range d.Args {
+			specs[spec.Name] = spec
+		}
+		for arg, v := range args {
+			arg = strings.ToLower(strings.TrimSpace(arg))
+			spec, ok := specs[arg]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("defaults.%s.%s: %s has no such arg", name, arg, d.Name))
+				continue
+			}
+			s, ok := configDefaultString(v)
+			if !ok || nonDefaultableArgs[arg] || s == "" {
+				continue
+			}
+			if err := validateArgValue(spec, s); err != nil {
+				problems = append(problems, fmt.Sprintf("defaults.%s: %v", name, err))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
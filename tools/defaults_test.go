@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetToolDefaultsFromConfig(t *testing.T) {
+	var raw map[string]map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"search":{"base":"/data","limit":25,"sort":"date"},
+		"rec":{"limit":30,"group":true},
+		"clean":{"exclude":[".git","node_modules"],"apply":true},
+		"backup":{"dir":"/backups"},
+		"grep":{"pattern":{"x":1}}
+	}`), &raw); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetToolDefaults(nil) }()
+
+	err := SetToolDefaults(raw)
+	if err == nil || !strings.Contains(err.Error(), "defaults.clean.apply") || !strings.Contains(err.Error(), "defaults.grep.pattern") {
+		t.Fatalf("expected errors for apply and unsupported value, got %v", err)
+	}
+	cases := map[[2]string]string{
+		{"search", "base"}:    "/data",
+		{"search", "limit"}:   "25",
+		{"recent", "limit"}:   "30",
+		{"recent", "group"}:   "true",
+		{"clean", "exclude"}:  ".git,node_modules",
+		{"backup", "dir"}:     "/backups",
+		{"clean", "apply"}:    "fallback",
+		{"search", "missing"}: "fallback",
+	}
+	for k, want := range cases {
+		if got := ToolDefault(k[0], k[1], "fallback"); got != want {
+			t.Fatalf("ToolDefault(%s, %s) = %q, want %q", k[0], k[1], got, want)
+		}
+	}
+	if !strings.Contains(BuildAgentCatalog(), "sort (name|date|size, default date)") {
+		t.Fatalf("expected configured default in catalog, got:\n%s", BuildAgentCatalog())
+	}
+}
+
+func TestWithToolDefaultsFillsOnlyEmptyDeclaredArgs(t *testing.T) {
+	if err := SetToolDefaults(map[string]map[string]any{"search": {"sort": "size", "limit": float64(5), "bogus": "x"}}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = SetToolDefaults(nil) }()
+
+	params := map[string]string{"sort": "name", "limit": " "}
+	got := withToolDefaults(lookupTool("search").Describe(), params)
+	if got["sort"] != "name" || got["limit"] != "5" {
+		t.Fatalf("unexpected params %v", got)
+	}
+	if _, ok := got["bogus"]; ok {
+		t.Fatal("undeclared arg must not be filled")
+	}
+	if params["limit"] != " " {
+		t.Fatal("input params must not be modified")
+	}
+}
+
+func TestConfigDefaultExpandsHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	if got, _ := configDefaultString("~/Downloads"); got != filepath.Join(home, "Downloads") {
+		t.Fatalf("expected expanded home, got %q", got)
+	}
+}
+
+func TestCheckToolDefaults(t *testing.T) {
+	problems := CheckToolDefaults(map[string]map[string]any{
+		"search": {"sort": "newest", "limit": "ten", "base": "/data"},
+		"recent": {"colour": "red"},
+		"backup": {"dir": "/backups"},
+	})
+	joined := strings.Join(problems, "; ")
+	if len(problems) != 3 || !strings.Contains(joined, "sort must be one of") || !strings.Contains(joined, "limit must be an integer") || !strings.Contains(joined, "recent has no such arg") {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+}
+
+func TestFindEmptyDirsExclude(t *testing.T) {
+	base := t.TempDir()
+	for _, d := range []string{"a/empty", ".git/refs", "node_modules/pkg/empty"} {
+		if err := os.MkdirAll(filepath.Join(base, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dirs, err := findEmptyDirs(base, []string{".git", "node_modules"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 1 || dirs[0] != filepath.Join(base, "a", "empty") {
+		t.Fatalf("unexpected empty dirs %v", dirs)
+	}
+}
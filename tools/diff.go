@@ -10,6 +10,37 @@ import (
 	"cli/internal/ui"
 )
 
+func init() { registerTool(diffTool{}) }
+
+type diffTool struct{}
+
+func (diffTool) Describe() ToolDescriptor {
+	return ToolDescriptor{
+		Key:      "d",
+		Name:     "diff",
+		Synopsis: "Show git changes or compare two files",
+		Aliases:  []string{"changes"},
+		Args: []ArgSpec{
+			{Name: "mode", Type: ArgString, Enum: []string{"git", "files"}, Default: "git"},
+			{Name: "limit", Type: ArgInt, Default: "80", Description: "max diff lines"},
+			{Name: "file_a", Type: ArgPath, Description: "for files mode"},
+			{Name: "file_b", Type: ArgPath, Description: "for files mode"},
+		},
+		RiskLevel: "low",
+		RiskNote:  "read/inspect operation",
+		Short:     "Show git changes or compare two files",
+		Help:      "Shows the git working tree diff, or compares two files line by line.",
+		Example:   "dm tools diff\ndm tools changes",
+		Order:     80,
+	}
+}
+
+func (diffTool) RunInteractive(_ string, r *bufio.Reader) int { return RunDiff(r) }
+
+func (diffTool) RunAuto(baseDir string, params ToolParams) AutoRunResult {
+	return RunDiffAutoDetailed(baseDir, params)
+}
+
 const (
 	diffMaxDiffLines = 200
 	diffDefaultLines = 80
@@ -9,14 +9,50 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"cli/internal/platform"
 	"cli/internal/ui"
 
 	pdflib "github.com/ledongthuc/pdf"
 )
 
+func init() { registerTool(grepTool{}) }
+
+type grepTool struct{}
+
+func (grepTool) Describe() ToolDescriptor {
+	return ToolDescriptor{
+		Key:      "g",
+		Name:     "grep",
+		Synopsis: "Search INSIDE files for text (supports PDF). Use when looking for a string in file contents, not filenames.",
+		Aliases:  []string{"find", "rg"},
+		Args: []ArgSpec{
+			{Name: "pattern", Type: ArgString, Required: true, Description: "text to find inside files"},
+			{Name: "base", Type: ArgPath, Description: "directory, default cwd"},
+			{Name: "ext", Type: ArgString, Description: "filter extension e.g. go/ps1/pdf"},
+			{Name: "limit", Type: ArgInt, Default: "20", Description: "page size, max 50"},
+			{Name: "case_sensitive", Type: ArgBool, Default: "false"},
+			{Name: "offset", Type: ArgInt},
+			{Name: "cursor", Type: ArgString, Description: "continuation token from a previous page"},
+		},
+		RiskLevel: "low",
+		RiskNote:  "read/inspect operation",
+		Short:     "Search text inside files",
+		Help:      "Asks for a pattern, base path and optional extension, then lists matching lines (PDF text included).",
+		Example:   "dm tools grep\ndm tools rg",
+		Order:     70,
+	}
+}
+
+func (grepTool) RunInteractive(_ string, r *bufio.Reader) int { return RunGrep(r) }
+
+func (grepTool) RunAuto(baseDir string, params ToolParams) AutoRunResult {
+	return RunGrepAutoDetailed(baseDir, params)
+}
+
 const (
 	grepDefaultLimit = 20
 	grepMaxLimit     = 50
+	grepMaxMatches   = 1000        // collected per query; pages are cut from these
 	grepMaxFileBytes = 1024 * 1024 // 1 MB
 	grepMaxLineLen   = 500
 )
@@ -50,7 +86,7 @@ func RunGrep(r *bufio.Reader) int {
 	caseSensitive := strings.ToLower(prompt(r, "Case sensitive (y/N)", "n"))
 
 	matches := grepFiles(base, pattern, ext, caseSensitive == "y" || caseSensitive == "yes", grepDefaultLimit)
-	printGrepResults(matches, pattern)
+	printGrepPage(matches, pattern, 0, len(matches))
 	return 0
 }
 
@@ -73,19 +109,23 @@ func RunGrepAutoDetailed(baseDir string, params map[string]string) AutoRunResult
 		caseSensitive = true
 	}
 
-	limit := grepDefaultLimit
-	if v := strings.TrimSpace(params["limit"]); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
-			limit = n
-		}
+	queryKey := strings.Join([]string{strings.ToLower(base), strings.ToLower(ext), strconv.FormatBool(caseSensitive), pattern}, "|")
+	req, err := parsePageRequest(params, queryKey)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return AutoRunResult{Code: 1}
 	}
-	if limit > grepMaxLimit {
-		limit = grepMaxLimit
+	if strings.TrimSpace(params["limit"]) == "" && strings.TrimSpace(params["cursor"]) == "" {
+		req.Limit = grepDefaultLimit
 	}
-
-	matches := grepFiles(base, pattern, ext, caseSensitive, limit)
-	printGrepResults(matches, pattern)
-	return AutoRunResult{Code: 0}
+	if req.Limit > grepMaxLimit {
+		req.Limit = grepMaxLimit
+	}
+	matches, _ := grepPages.getOrLoad(queryKey, func() ([]grepMatch, error) {
+		return grepFiles(base, pattern, ext, caseSensitive, grepMaxMatches), nil
+	})
+	shown := printGrepPage(matches, pattern, req.Offset, req.Limit)
+	return continuePage(params, queryKey, req, shown, len(matches), "matches")
 }
 
 func grepFiles(base, pattern, ext string, caseSensitive bool, limit int) []grepMatch {
@@ -104,7 +144,8 @@ func grepFiles(base, pattern, ext string, caseSensitive bool, limit int) []grepM
 
 	var matches []grepMatch
 
-	_ = filepath.Walk(base, func(path string, info os.FileInfo, err error) error {
+	root := platform.LongPath(base)
+	_ = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -127,9 +168,9 @@ func grepFiles(base, pattern, ext string, caseSensitive bool, limit int) []grepM
 			}
 		}
 
-		relPath, _ := filepath.Rel(base, path)
+		relPath, _ := filepath.Rel(root, path)
 		if relPath == "" {
-			relPath = path
+			relPath = platform.ShortPath(path)
 		}
 
 		var lines []string
@@ -157,7 +198,7 @@ func grepFiles(base, pattern, ext string, caseSensitive bool, limit int) []grepM
 			if strings.Contains(compareLine, searchPattern) {
 				trimmed := strings.TrimRight(line, "\r\n")
 				if len(trimmed) > grepMaxLineLen {
-					trimmed = trimmed[:grepMaxLineLen] + "..."
+					trimmed = ui.TruncateBytes(trimmed, grepMaxLineLen) + "..."
 				}
 				matches = append(matches, grepMatch{
 					File:    relPath,
@@ -175,22 +216,39 @@ func grepFiles(base, pattern, ext string, caseSensitive bool, limit int) []grepM
 	return matches
 }
 
-func printGrepResults(matches []grepMatch, pattern string) {
+// printGrepPage prints matches[offset:offset+limit] grouped by file and
+// returns how many it printed.
+func printGrepPage(matches []grepMatch, pattern string, offset, limit int) int {
 	if len(matches) == 0 {
 		fmt.Printf("No matches found for '%s'.\n", pattern)
-		return
+		return 0
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(matches) {
+		fmt.Println("No more matches.")
+		return 0
+	}
+	page := matches[offset:]
+	if len(page) > limit {
+		page = page[:limit]
 	}
 
 	fileGroups := make(map[string][]grepMatch)
 	fileOrder := make([]string, 0)
-	for _, m := range matches {
+	for _, m := range page {
 		if _, exists := fileGroups[m.File]; !exists {
 			fileOrder = append(fileOrder, m.File)
 		}
 		fileGroups[m.File] = append(fileGroups[m.File], m)
 	}
 
-	fmt.Printf("Found %d matches in %d files\n\n", len(matches), len(fileOrder))
+	if len(page) == len(matches) {
+		fmt.Printf("Found %d matches in %d files\n\n", len(matches), len(fileOrder))
+	} else {
+		fmt.Printf("Showing %d-%d of %d matches\n\n", offset+1, offset+len(page), len(matches))
+	}
 	for _, file := range fileOrder {
 		fmt.Println(ui.Accent(file))
 		for _, m := range fileGroups[file] {
@@ -199,9 +257,10 @@ func printGrepResults(matches []grepMatch, pattern string) {
 		fmt.Println()
 	}
 
-	if len(matches) >= grepMaxLimit {
-		fmt.Println(ui.Muted("(results truncated, refine your search)"))
+	if len(matches) >= grepMaxMatches && offset+len(page) == len(matches) {
+		fmt.Println(ui.Muted(fmt.Sprintf("(stopped at %d matches, refine your search)", grepMaxMatches)))
 	}
+	return len(page)
 }
 
 func extractPDFText(path string) (string, error) {
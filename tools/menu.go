@@ -3,23 +3,35 @@ package tools
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"cli/internal/history"
+	"cli/internal/platform"
 	"cli/internal/ui"
 )
 
+// recentLimit is how many recently used entries menus show on top.
+const recentLimit = 5
+
 type ToolDescriptor struct {
 	Key       string
 	Name      string
 	Synopsis  string
 	Aliases   []string
-	AgentArgs string
+	Args      []ArgSpec
 	RiskLevel string
 	RiskNote  string
+	Short     string
+	Help      string
+	Example   string
+	Order     int
 }
 
 type AutoRunResult struct {
@@ -28,32 +40,59 @@ type AutoRunResult struct {
 	CanContinue    bool
 	ContinuePrompt string
 	ContinueParams map[string]string
+	Cursor         string
+	Rows           []ResultRow
 }
 
-var ToolRegistry = []ToolDescriptor{
-	{Key: "s", Name: "search", Synopsis: "Find files by filename (not content). Use when looking for files whose NAME contains a word.", Aliases: []string{"s"}, AgentArgs: "base, ext, name (substring match on filename), sort, limit, offset", RiskLevel: "low", RiskNote: "read/inspect operation"},
-	{Key: "r", Name: "rename", Synopsis: "Batch rename files with preview", Aliases: []string{"r"}, AgentArgs: "base, from, to, name, case_sensitive", RiskLevel: "medium", RiskNote: "batch rename files"},
-	{Key: "e", Name: "recent", Synopsis: "Show recent files", Aliases: []string{"rec"}, AgentArgs: "base, limit, offset", RiskLevel: "low", RiskNote: "read/inspect operation"},
-	{Key: "c", Name: "clean", Synopsis: "Delete empty folders", Aliases: []string{"c"}, AgentArgs: "base, apply (true for delete, otherwise preview)", RiskLevel: "low", RiskNote: "preview only"},
-	{Key: "y", Name: "system", Synopsis: "Show system/network snapshot", Aliases: []string{"sys", "htop"}, AgentArgs: "", RiskLevel: "low", RiskNote: "read/inspect operation"},
-	{Key: "f", Name: "read", Synopsis: "Read file contents or list directory", Aliases: []string{"cat", "view"}, AgentArgs: "path (required), offset (start line, default 1), limit (max lines, default 100)", RiskLevel: "low", RiskNote: "read/inspect operation"},
-	{Key: "g", Name: "grep", Synopsis: "Search INSIDE files for text (supports PDF). Use when looking for a string in file contents, not filenames.", Aliases: []string{"find", "rg"}, AgentArgs: "pattern (required, text to find inside files), base (directory, default cwd), ext (filter extension e.g. go/ps1/pdf), limit (max results, default 20), case_sensitive (default false)", RiskLevel: "low", RiskNote: "read/inspect operation"},
-	{Key: "d", Name: "diff", Synopsis: "Show git changes or compare two files", Aliases: []string{"changes"}, AgentArgs: "mode (git|files, default git), limit (max diff lines, default 80), file_a (for files mode), file_b (for files mode)", RiskLevel: "low", RiskNote: "read/inspect operation"},
+// ResultRow is one file listed by a tool page (search, recent), kept so
+// JSON consumers get the same data the agent saw.
+type ResultRow struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
 }
 
 func RunMenu(baseDir string) int {
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
+		items := Descriptors()
+		recent := recentTools(items)
+		if len(items) > ui.FilterThreshold && ui.FilterAvailable() {
+			labels := make([]string, 0, len(recent)+len(items))
+			for _, name := range recent {
+				labels = append(labels, "recent: "+name)
+			}
+			for _, item := range items {
+				labels = append(labels, item.Name+" - "+item.Synopsis)
+			}
+			idx, ok := ui.FilterSelect(reader, "Tools", labels)
+			if !ok {
+				return 0
+			}
+			name := ""
+			if idx < len(recent) {
+				name = recent[idx]
+			} else {
+				name = items[idx-len(recent)].Name
+			}
+			_ = RunByNameWithReader(baseDir, name, reader)
+			waitForEnter(reader)
+			continue
+		}
+		if len(recent) > 0 {
+			ui.PrintSection("Recent")
+			for i, name := range recent {
+				fmt.Printf("r%d) %s\n", i+1, ui.Accent(name))
+			}
+		}
 		ui.PrintSection("Tools")
-		for i, item := range ToolRegistry {
+		for i, item := range items {
 			fmt.Printf("%2d) [%s] %s %s\n", i+1, ui.Warn(item.Key), ui.Accent(item.Name), ui.Muted("- "+item.Synopsis))
 		}
 		fmt.Println(" 0) " + ui.Error("[x] Exit"))
 		fmt.Println(ui.Muted(" h <n|letter>) Help"))
-		fmt.Print(ui.Prompt("Select tool > "))
-
-		choice := strings.TrimSpace(readLine(reader))
+		choice := readPromptLine(reader, ui.Prompt("Select tool > "))
 		lc := strings.ToLower(choice)
 		switch choice {
 		case "0", "x", "X", "exit", "Exit", "":
@@ -61,23 +100,28 @@ func RunMenu(baseDir string) int {
 		default:
 			if strings.HasPrefix(lc, "h ") {
 				target := strings.TrimSpace(choice[2:])
-				idx, ok := parseToolMenuChoice(target, len(ToolRegistry))
+				idx, ok := parseToolMenuChoice(target, len(items))
 				if !ok {
 					fmt.Println(ui.Error("Invalid help selection."))
 					continue
 				}
-				item := ToolRegistry[idx]
+				item := items[idx]
 				fmt.Println(ui.Accent("Tool:"), item.Name)
 				fmt.Println(ui.Accent("Summary:"), item.Synopsis)
 				waitForEnter(reader)
 				continue
 			}
-			idx, ok := parseToolMenuChoice(choice, len(ToolRegistry))
+			if idx, ok := parseRecentChoice(lc, len(recent)); ok {
+				_ = RunByNameWithReader(baseDir, recent[idx], reader)
+				waitForEnter(reader)
+				continue
+			}
+			idx, ok := parseToolMenuChoice(choice, len(items))
 			if !ok {
 				fmt.Println(ui.Error("Invalid selection."))
 				continue
 			}
-			_ = RunByNameWithReader(baseDir, ToolRegistry[idx].Name, reader)
+			_ = RunByNameWithReader(baseDir, items[idx].Name, reader)
 			waitForEnter(reader)
 		}
 	}
@@ -114,65 +158,61 @@ func RunByNameWithParamsCapture(baseDir, name string, params map[string]string)
 }
 
 func RunByNameWithParamsDetailed(baseDir, name string, params map[string]string) AutoRunResult {
-	switch normalizeToolName(name) {
-	case "search":
-		return RunSearchAutoDetailed(baseDir, params)
-	case "rename":
-		return RunRenameAutoDetailed(baseDir, params)
-	case "recent":
-		return RunRecentAutoDetailed(baseDir, params)
-	case "clean":
-		return AutoRunResult{Code: RunCleanEmptyAuto(baseDir, params)}
-	case "system":
-		return AutoRunResult{Code: RunSystemAuto()}
-	case "read":
-		return RunReadAutoDetailed(baseDir, params)
-	case "grep":
-		return RunGrepAutoDetailed(baseDir, params)
-	case "diff":
-		return RunDiffAutoDetailed(baseDir, params)
-	default:
+	t := lookupTool(name)
+	if t == nil {
 		return AutoRunResult{Code: RunByName(baseDir, name)}
 	}
+	return t.RunAuto(baseDir, withToolDefaults(t.Describe(), params))
 }
 
 func RunByNameWithReader(baseDir, name string, reader *bufio.Reader) int {
-	switch normalizeToolName(name) {
-	case "search":
-		return RunSearch(reader)
-	case "rename":
-		return RunRename(baseDir, reader)
-	case "recent":
-		return RunRecent(reader)
-	case "clean":
-		return RunCleanEmpty(reader)
-	case "system":
-		return RunSystem(reader)
-	case "read":
-		return RunRead(reader)
-	case "grep":
-		return RunGrep(reader)
-	case "diff":
-		return RunDiff(reader)
-	default:
+	t := lookupTool(name)
+	if t == nil {
+		names := make([]string, 0, len(registry))
+		for _, d := range Descriptors() {
+			names = append(names, d.Name)
+		}
 		fmt.Println(ui.Error("Invalid tool:"), name)
-		fmt.Println(ui.Muted("Use: search|rename|recent|clean|system|read|grep|diff"))
+		fmt.Println(ui.Muted("Use: " + strings.Join(names, "|")))
 		return 1
 	}
+	code := t.RunInteractive(baseDir, reader)
+	history.Add(history.KindTool, t.Describe().Name, code)
+	return code
 }
 
-func normalizeToolName(name string) string {
-	lc := strings.ToLower(strings.TrimSpace(name))
-	for i, t := range ToolRegistry {
-		if lc == t.Name || lc == t.Key || lc == fmt.Sprintf("%d", i+1) {
-			return t.Name
-		}
-		for _, alias := range t.Aliases {
-			if lc == strings.ToLower(alias) {
-				return t.Name
-			}
+// recentTools returns the most used tools that are still registered.
+func recentTools(items []ToolDescriptor) []string {
+	known := map[string]bool{}
+	for _, item := range items {
+		known[item.Name] = true
+	}
+	var out []string
+	for _, name := range history.Top(history.Load(), history.KindTool, recentLimit, time.Now()) {
+		if known[name] {
+			out = append(out, name)
 		}
 	}
+	return out
+}
+
+// parseRecentChoice parses "r1".."rN" from a menu prompt.
+func parseRecentChoice(choice string, count int) (int, bool) {
+	rest, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(choice)), "r")
+	if !ok {
+		return -1, false
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 1 || n > count {
+		return -1, false
+	}
+	return n - 1, true
+}
+
+func normalizeToolName(name string) string {
+	if t := lookupTool(name); t != nil {
+		return t.Describe().Name
+	}
 	return ""
 }
 
@@ -197,16 +237,17 @@ func parseToolMenuChoice(choice string, count int) (int, bool) {
 		}
 		return -1, false
 	}
+	items := Descriptors()
 	// letter
 	if len(v) == 1 {
-		for i, item := range ToolRegistry {
+		for i, item := range items {
 			if item.Key == v {
 				return i, true
 			}
 		}
 	}
 	// direct name
-	for i, item := range ToolRegistry {
+	for i, item := range items {
 		if item.Name == v {
 			return i, true
 		}
@@ -215,13 +256,13 @@ func parseToolMenuChoice(choice string, count int) (int, bool) {
 }
 
 func prompt(r *bufio.Reader, label, def string) string {
+	var p string
 	if def != "" {
-		fmt.Printf("%s ", ui.Prompt(fmt.Sprintf("%s [%s]:", label, def)))
+		p = ui.Prompt(fmt.Sprintf("%s [%s]:", label, def)) + " "
 	} else {
-		fmt.Printf("%s ", ui.Prompt(label+":"))
+		p = ui.Prompt(label+":") + " "
 	}
-	text, _ := r.ReadString('\n')
-	text = strings.TrimSpace(text)
+	text := readPromptLine(r, p)
 	if text == "" {
 		return def
 	}
@@ -233,6 +274,16 @@ func readLine(r *bufio.Reader) string {
 	return strings.TrimSpace(s)
 }
 
+// readPromptLine reads free-form input with line editing and history.
+// Ctrl-C exits instead of being taken as an empty answer.
+func readPromptLine(r *bufio.Reader, prompt string) string {
+	s, err := ui.ReadLine(r, prompt)
+	if errors.Is(err, ui.ErrInterrupt) {
+		ui.ExitInterrupted()
+	}
+	return strings.TrimSpace(s)
+}
+
 func waitForEnter(r *bufio.Reader) {
 	fmt.Print(ui.Prompt("Press Enter to continue..."))
 	_, _ = r.ReadString('\n')
@@ -255,19 +306,35 @@ func normalizeInputPath(raw, fallback string) string {
 	if strings.TrimSpace(p) == "" {
 		p = "."
 	}
-	return filepath.Clean(p)
+	return filepath.Clean(platform.NormalizeUNC(p))
 }
 
 func IsKnownTool(name string) bool {
 	return normalizeToolName(name) != ""
 }
 
+// CanonicalToolName resolves a tool name or alias to its registered name,
+// or returns "" when no tool matches.
+func CanonicalToolName(name string) string {
+	return normalizeToolName(name)
+}
+
 func BuildAgentCatalog() string {
-	lines := make([]string, 0, len(ToolRegistry))
-	for _, t := range ToolRegistry {
+	return BuildAgentCatalogFiltered(nil)
+}
+
+// BuildAgentCatalogFiltered lists only the tools for which keep returns true;
+// a nil keep lists every tool.
+func BuildAgentCatalogFiltered(keep func(name string) bool) string {
+	items := Descriptors()
+	lines := make([]string, 0, len(items))
+	for _, t := range items {
+		if keep != nil && !keep(t.Name) {
+			continue
+		}
 		line := "- " + t.Name + ": " + t.Synopsis
-		if t.AgentArgs != "" {
-			line += " | tool_args: " + t.AgentArgs
+		if len(t.Args) > 0 {
+			line += " | tool_args: " + formatArgSpecs(t.Args)
 		} else {
 			line += " (no args needed)"
 		}
@@ -277,20 +344,15 @@ func BuildAgentCatalog() string {
 }
 
 func ToolRisk(name string, args map[string]string) (string, string) {
-	canonical := normalizeToolName(name)
-	for _, t := range ToolRegistry {
-		if t.Name != canonical {
-			continue
-		}
-		if t.Name == "clean" {
-			apply := strings.ToLower(strings.TrimSpace(args["apply"]))
-			if apply == "1" || apply == "true" || apply == "yes" || apply == "y" {
-				return "high", "delete empty directories"
-			}
-		}
-		return t.RiskLevel, t.RiskNote
+	t := lookupTool(name)
+	if t == nil {
+		return "low", "read/inspect operation"
+	}
+	if ra, ok := t.(riskAssessor); ok {
+		return ra.Risk(args)
 	}
-	return "low", "read/inspect operation"
+	d := t.Describe()
+	return d.RiskLevel, d.RiskNote
 }
 
 func validateExistingDir(path, label string) error {
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cli/internal/cache"
+	"cli/internal/filesearch"
+)
+
+const (
+	pagingCacheTTL        = 15 * time.Second
+	pagingCacheMaxEntries = 8
+	defaultPageSize       = 10
+	pageCursorVersion     = "p1"
+)
+
+type pageCacheEntry[T any] struct {
+	Results []T
+	Stored  time.Time
+}
+
+// pageCache keeps the full result list of recent queries so that follow-up
+// pages do not rescan the disk.
+type pageCache[T any] struct {
+	lru *cache.LRU[string, pageCacheEntry[T]]
+}
+
+func newPageCache[T any](name string) *pageCache[T] {
+	return &pageCache[T]{lru: cache.New[string, pageCacheEntry[T]](name, pagingCacheMaxEntries)}
+}
+
+// ResetPagingCaches drops cached search/recent/grep results.
+func ResetPagingCaches() {
+	searchPages.lru.Reset()
+	recentPages.lru.Reset()
+	grepPages.lru.Reset()
+}
+
+var (
+	searchPages = newPageCache[filesearch.Result]("search-pages")
+	recentPages = newPageCache[recentItem]("recent-pages")
+	grepPages   = newPageCache[grepMatch]("grep-pages")
+	nowFunc     = time.Now
+	pageSize    = defaultPageSize
+)
+
+// SetPageSize changes the default page size of list-producing tools.
+// Values <= 0 restore the built-in default.
+func SetPageSize(n int) {
+	if n <= 0 {
+		n = defaultPageSize
+	}
+	pageSize = n
+}
+
+func (c *pageCache[T]) getOrLoad(key string, loader func() ([]T, error)) ([]T, error) {
+	now := nowFunc()
+	entry, ok := c.lru.GetIf(key, func(e pageCacheEntry[T]) bool {
+		return now.Sub(e.Stored) <= pagingCacheTTL
+	})
+	if ok {
+		out := make([]T, len(entry.Results))
+		copy(out, entry.Results)
+		return out, nil
+	}
+
+	results, err := loader()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]T, len(results))
+	copy(out, results)
+
+	c.lru.Set(key, pageCacheEntry[T]{Results: out, Stored: now})
+	return out, nil
+}
+
+// pageRows converts the shown part of items into result rows.
+func pageRows[T any](items []T, offset, shown int, row func(T) ResultRow) []ResultRow {
+	if shown <= 0 || offset < 0 || offset >= len(items) {
+		return nil
+	}
+	end := min(offset+shown, len(items))
+	out := make([]ResultRow, 0, end-offset)
+	for _, it := range items[offset:end] {
+		out = append(out, row(it))
+	}
+	return out
+}
+
+type pageRequest struct {
+	Offset int
+	Limit  int
+}
+
+// parsePageRequest reads offset/limit from tool params. A cursor, when
+// present, takes precedence and must belong to the same query.
+func parsePageRequest(params map[string]string, queryKey string) (pageRequest, error) {
+	req := pageRequest{Limit: pageSize}
+	if n, err := strconv.Atoi(strings.TrimSpace(params["limit"])); err == nil && n > 0 {
+		req.Limit = n
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(params["offset"])); err == nil && n >= 0 {
+		req.Offset = n
+	}
+	if cursor := strings.TrimSpace(params["cursor"]); cursor != "" {
+		offset, limit, err := decodePageCursor(cursor, queryKey)
+		if err != nil {
+			return pageRequest{}, err
+		}
+		req.Offset, req.Limit = offset, limit
+	}
+	return req, nil
+}
+
+// continuePage builds the result of a page, offering the next page when
+// more items remain.
+func continuePage(params map[string]string, queryKey string, req pageRequest, shown, total int, noun string) AutoRunResult {
+	nextOffset := req.Offset + shown
+	if nextOffset >= total {
+		return AutoRunResult{Code: 0}
+	}
+	next := copyStringMap(params)
+	delete(next, "cursor")
+	next["offset"] = strconv.Itoa(nextOffset)
+	next["limit"] = strconv.Itoa(req.Limit)
+	return AutoRunResult{
+		Code:           0,
+		CanContinue:    true,
+		ContinuePrompt: fmt.Sprintf("Show next %d %s? [Y/n]: ", req.Limit, noun),
+		ContinueParams: next,
+		Cursor:         encodePageCursor(queryKey, nextOffset, req.Limit),
+	}
+}
+
+func pageQueryHash(queryKey string) string {
+	sum := sha256.Sum256([]byte(queryKey))
+	return hex.EncodeToString(sum[:6])
+}
+
+func encodePageCursor(queryKey string, offset, limit int) string {
+	raw := strings.Join([]string{pageCursorVersion, pageQueryHash(queryKey), strconv.Itoa(offset), strconv.Itoa(limit)}, ":")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePageCursor(token, queryKey string) (int, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 4 || parts[0] != pageCursorVersion {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	if parts[1] != pageQueryHash(queryKey) {
+		return 0, 0, fmt.Errorf("cursor belongs to a different query")
+	}
+	offset, err1 := strconv.Atoi(parts[2])
+	limit, err2 := strconv.Atoi(parts[3])
+	if err1 != nil || err2 != nil || offset < 0 || limit <= 0 {
+		return 0, 0, fmt.Errorf("invalid cursor")
+	}
+	return offset, limit, nil
+}
@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cli/internal/filesearch"
+)
+
+func withPagingTestClock(t *testing.T, at time.Time) func() {
+	t.Helper()
+	prev := nowFunc
+	now := at
+	nowFunc = func() time.Time { return now }
+	return func() {
+		nowFunc = prev
+	}
+}
+
+func TestSearchPagingCacheHitSkipsLoader(t *testing.T) {
+	ResetPagingCaches()
+	restore := withPagingTestClock(t, time.Unix(1000, 0))
+	defer restore()
+
+	loaderCalls := 0
+	_, err := searchPages.getOrLoad("k1", func() ([]filesearch.Result, error) {
+		loaderCalls++
+		return []filesearch.Result{{Path: "a"}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = searchPages.getOrLoad("k1", func() ([]filesearch.Result, error) {
+		loaderCalls++
+		return []filesearch.Result{{Path: "b"}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaderCalls != 1 {
+		t.Fatalf("expected loader to run once, got %d", loaderCalls)
+	}
+}
+
+func TestSearchPagingCacheTTLExpiryReloads(t *testing.T) {
+	ResetPagingCaches()
+	base := time.Unix(2000, 0)
+	restore := withPagingTestClock(t, base)
+	defer restore()
+
+	loaderCalls := 0
+	_, err := searchPages.getOrLoad("ttl", func() ([]filesearch.Result, error) {
+		loaderCalls++
+		return []filesearch.Result{{Path: "a"}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nowFunc = func() time.Time { return base.Add(pagingCacheTTL + time.Second) }
+	_, err = searchPages.getOrLoad("ttl", func() ([]filesearch.Result, error) {
+		loaderCalls++
+		return []filesearch.Result{{Path: "b"}}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaderCalls != 2 {
+		t.Fatalf("expected loader to run twice after TTL expiry, got %d", loaderCalls)
+	}
+}
+
+func TestRecentPagingCacheEvictsOldest(t *testing.T) {
+	ResetPagingCaches()
+	base := time.Unix(3000, 0)
+	restore := withPagingTestClock(t, base)
+	defer restore()
+
+	for i := 0; i < pagingCacheMaxEntries+1; i++ {
+		idx := i
+		now := base.Add(time.Duration(i) * time.Second)
+		nowFunc = func() time.Time { return now }
+		key := "k" + string(rune('a'+i))
+		_, err := recentPages.getOrLoad(key, func() ([]recentItem, error) {
+			return []recentItem{{Path: string(rune('a' + idx))}}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, hasOldest := recentPages.lru.Peek("ka")
+	if hasOldest {
+		t.Fatal("expected oldest recent cache entry to be evicted")
+	}
+}
+
+func TestSearchPagingCacheLoaderError(t *testing.T) {
+	ResetPagingCaches()
+	restore := withPagingTestClock(t, time.Unix(4000, 0))
+	defer restore()
+
+	wantErr := errors.New("boom")
+	_, err := searchPages.getOrLoad("err", func() ([]filesearch.Result, error) {
+		return nil, wantErr
+	})
+	if err == nil {
+		t.Fatal("expected loader error")
+	}
+}
+
+func TestPageCursorRoundTrip(t *testing.T) {
+	token := encodePageCursor("q", 20, 10)
+	if token != encodePageCursor("q", 20, 10) {
+		t.Fatal("expected stable cursor for the same query and offset")
+	}
+	req, err := parsePageRequest(map[string]string{"cursor": token, "offset": "0"}, "q")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Offset != 20 || req.Limit != 10 {
+		t.Fatalf("expected offset 20 limit 10, got %+v", req)
+	}
+	if _, err := parsePageRequest(map[string]string{"cursor": token}, "other"); err == nil {
+		t.Fatal("expected cursor from another query to be rejected")
+	}
+	if _, err := parsePageRequest(map[string]string{"cursor": "not-a-cursor"}, "q"); err == nil {
+		t.Fatal("expected invalid cursor error")
+	}
+}
+
+func TestContinuePage(t *testing.T) {
+	SetPageSize(5)
+	defer SetPageSize(0)
+
+	req, err := parsePageRequest(map[string]string{"base": "."}, "q")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Limit != 5 {
+		t.Fatalf("expected configured page size, got %d", req.Limit)
+	}
+	res := continuePage(map[string]string{"base": ".", "cursor": "old"}, "q", req, 5, 12, "items")
+	if !res.CanContinue || res.ContinueParams["offset"] != "5" || res.ContinueParams["limit"] != "5" {
+		t.Fatalf("unexpected continuation: %+v", res)
+	}
+	if _, has := res.ContinueParams["cursor"]; has {
+		t.Fatal("expected stale cursor to be dropped from continuation params")
+	}
+	if res.Cursor != encodePageCursor("q", 5, 5) {
+		t.Fatalf("unexpected cursor %q", res.Cursor)
+	}
+	if last := continuePage(nil, "q", pageRequest{Offset: 10, Limit: 5}, 2, 12, "items"); last.CanContinue {
+		t.Fatal("expected no continuation on the last page")
+	}
+}
+
+func TestPageRows(t *testing.T) {
+	items := []recentItem{{Path: "a", Size: 1}, {Path: "b", Size: 2}, {Path: "c", Size: 3}}
+	rows := pageRows(items, 1, 5, func(it recentItem) ResultRow {
+		return ResultRow{Path: it.Path, Size: it.Size}
+	})
+	if len(rows) != 2 || rows[0].Path != "b" || rows[1].Size != 3 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if rows := pageRows(items, 3, 1, func(it recentItem) ResultRow { return ResultRow{} }); rows != nil {
+		t.Fatalf("expected no rows past the end, got %+v", rows)
+	}
+}
+
+func TestRecentAutoReturnsRows(t *testing.T) {
+	ResetPagingCaches()
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt", "c.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res := RunRecentAutoDetailed(dir, map[string]string{"base": dir, "ext": "txt", "limit": "1"})
+	if res.Code != 0 || len(res.Rows) != 1 || !res.CanContinue {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if filepath.Ext(res.Rows[0].Path) != ".txt" || res.Rows[0].Size != 5 || res.Rows[0].ModTime.IsZero() {
+		t.Fatalf("unexpected row %+v", res.Rows[0])
+	}
+}
+
+func TestGrepAutoPagesWithCursor(t *testing.T) {
+	ResetPagingCaches()
+	dir := t.TempDir()
+	var b strings.Builder
+	for i := 0; i < 25; i++ {
+		fmt.Fprintf(&b, "needle %d\n", i)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{"base": dir, "pattern": "needle", "limit": "10"}
+	res := RunGrepAutoDetailed(dir, params)
+	if res.Code != 0 || !res.CanContinue || res.Cursor == "" || res.ContinueParams["offset"] != "10" {
+		t.Fatalf("unexpected first page %+v", res)
+	}
+	cursor := res.Cursor
+	res = RunGrepAutoDetailed(dir, map[string]string{"base": dir, "pattern": "needle", "cursor": cursor})
+	if res.Code != 0 || res.ContinueParams["offset"] != "20" {
+		t.Fatalf("unexpected second page %+v", res)
+	}
+	res = RunGrepAutoDetailed(dir, res.ContinueParams)
+	if res.Code != 0 || res.CanContinue || res.Cursor != "" {
+		t.Fatalf("expected the last page, got %+v", res)
+	}
+	if res := RunGrepAutoDetailed(dir, map[string]string{"base": dir, "pattern": "other", "cursor": cursor}); res.Code != 1 {
+		t.Fatalf("expected a foreign cursor to fail, got %+v", res)
+	}
+}
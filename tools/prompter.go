@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"cli/internal/ui"
+)
+
+// Prompter takes over the questions a tool asks while the agent runs it,
+// for front ends that own stdin (dm ask --porcelain).
+type Prompter interface {
+	// Ask answers a missing argument; ok is false when it cannot, and
+	// the tool fails instead of waiting on the terminal.
+	Ask(label, def string) (answer string, ok bool)
+	// Confirm answers a yes/no question that defaults to no.
+	Confirm(question string) bool
+}
+
+var autoPrompter Prompter
+
+// SetPrompter routes the prompts of agent-run tools through p; nil goes
+// back to the terminal.
+func SetPrompter(p Prompter) {
+	autoPrompter = p
+}
+
+// askAuto asks for an argument the agent left out, failing with an error
+// when the prompter cannot answer.
+func askAuto(r *bufio.Reader, label, def string) (string, bool) {
+	if autoPrompter == nil {
+		return prompt(r, label, def), true
+	}
+	answer, ok := autoPrompter.Ask(label, def)
+	if !ok {
+		fmt.Println(ui.Error("Error:"), fmt.Sprintf("missing argument: %s", label))
+	}
+	return answer, ok
+}
+
+// confirmAuto asks a yes/no question during an agent run; only y/yes
+// confirms.
+func confirmAuto(r *bufio.Reader, question string) bool {
+	if autoPrompter != nil {
+		return autoPrompter.Confirm(question)
+	}
+	return strings.ToLower(strings.TrimSpace(prompt(r, question+" [y/N]", "N"))) == "y"
+}
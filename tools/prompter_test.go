@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+type stubPrompter struct {
+	asked     []string
+	confirmed []string
+}
+
+func (p *stubPrompter) Ask(label, def string) (string, bool) {
+	p.asked = append(p.asked, label)
+	return "", false
+}
+
+func (p *stubPrompter) Confirm(question string) bool {
+	p.confirmed = append(p.confirmed, question)
+	return true
+}
+
+func TestAutoPromptsUsePrompter(t *testing.T) {
+	p := &stubPrompter{}
+	SetPrompter(p)
+	defer SetPrompter(nil)
+
+	r := bufio.NewReader(strings.NewReader("typed\n"))
+	if _, ok := askAuto(r, "Replace from", ""); ok {
+		t.Fatal("expected the prompter to refuse")
+	}
+	if !confirmAuto(r, "Apply these renames?") {
+		t.Fatal("expected the prompter to confirm")
+	}
+	if len(p.asked) != 1 || len(p.confirmed) != 1 {
+		t.Fatalf("unexpected prompter calls %+v", p)
+	}
+	if line, _ := r.ReadString('\n'); line != "typed\n" {
+		t.Fatalf("expected the reader untouched, got %q", line)
+	}
+}
+
+func TestRenameAutoMissingArgFailsWithPrompter(t *testing.T) {
+	SetPrompter(&stubPrompter{})
+	defer SetPrompter(nil)
+	if res := RunRenameAutoDetailed(t.TempDir(), map[string]string{"base": t.TempDir()}); res.Code != 1 {
+		t.Fatalf("expected missing from to fail, got %d", res.Code)
+	}
+}
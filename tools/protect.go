@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"cli/internal/platform"
+	"cli/internal/ui"
+)
+
+var (
+	configProtected []string
+	allowProtected  bool
+	pathConfirmer   func(path string) bool
+)
+
+// SetProtectedPaths sets the extra deny-list entries from dm.json "protected".
+func SetProtectedPaths(paths []string) {
+	configProtected = nil
+	for _, p := range paths {
+		if p = strings.TrimSpace(p); p != "" {
+			configProtected = append(configProtected, p)
+		}
+	}
+}
+
+// SetPathConfirmer makes broad-path confirmations go through confirm
+// instead of the terminal, e.g. for machine-readable front ends.
+func SetPathConfirmer(confirm func(path string) bool) {
+	pathConfirmer = confirm
+}
+
+// SetAllowProtected lifts the protected-path guard (--allow-protected).
+func SetAllowProtected(allow bool) {
+	allowProtected = allow
+}
+
+func systemDirs() []string {
+	if runtime.GOOS == "windows" {
+		dirs := []string{os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)"), os.Getenv("ProgramData")}
+		win := os.Getenv("SystemRoot")
+		if win == "" {
+			win = os.Getenv("windir")
+		}
+		if win == "" {
+			win = `C:\Windows`
+		}
+		return append(dirs, win)
+	}
+	dirs := []string{"/bin", "/boot", "/dev", "/etc", "/lib", "/proc", "/sbin", "/sys", "/usr"}
+	if runtime.GOOS == "darwin" {
+		dirs = append(dirs, "/System", "/Library", "/Applications")
+	}
+	return dirs
+}
+
+func comparablePath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	p = filepath.Clean(p)
+	if runtime.GOOS == "windows" {
+		p = strings.ToLower(p)
+	}
+	return p
+}
+
+func pathWithin(p, dir string) bool {
+	if p == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(p, dir)
+}
+
+// protectedReason reports why path must not be changed destructively, or ""
+// when it is fine. Roots and the home directory are refused only as a
+// whole; system and configured directories include everything below them.
+func protectedReason(path string) string {
+	p := comparablePath(path)
+	if filepath.Dir(p) == p {
+		return "filesystem root"
+	}
+	if home, err := os.UserHomeDir(); err == nil && strings.TrimSpace(home) != "" && p == comparablePath(home) {
+		return "home directory"
+	}
+	for _, dir := range systemDirs() {
+		if strings.TrimSpace(dir) != "" && pathWithin(p, comparablePath(dir)) {
+			return "system directory " + dir
+		}
+	}
+	for _, dir := range configProtected {
+		if pathWithin(p, comparablePath(dir)) {
+			return "listed under \"protected\" in dm.json"
+		}
+	}
+	return ""
+}
+
+// guardProtected prints an error and returns false when path is protected
+// and --allow-protected was not given.
+func guardProtected(path string) bool {
+	reason := protectedReason(path)
+	if reason == "" || allowProtected {
+		return true
+	}
+	fmt.Println(ui.Error("Error:"), fmt.Sprintf("%s is protected (%s).", path, reason))
+	fmt.Println(ui.Muted("Hint: rerun with --allow-protected if you really mean it."))
+	return false
+}
+
+// lockPath takes the advisory lock on path for a destructive op, printing
+// an error and returning false when another dm run holds it.
+func lockPath(op, path string) (func(), bool) {
+	release, err := platform.AcquirePathLock(op, path)
+	if err != nil {
+		fmt.Println(ui.Error("Error:"), err)
+		if platform.IsLocked(err) {
+			fmt.Println(ui.Muted("Hint: wait for the other run to finish and try again."))
+		}
+		return nil, false
+	}
+	return release, true
+}
+
+// isBroadPath reports whether p covers too much to hand to a write-capable
+// tool without a second look: a root, a directory directly below a root, or
+// the home directory and its parents.
+func isBroadPath(p string) bool {
+	cp := comparablePath(p)
+	parent := filepath.Dir(cp)
+	if parent == cp || filepath.Dir(parent) == parent {
+		return true
+	}
+	if home, err := os.UserHomeDir(); err == nil && strings.TrimSpace(home) != "" {
+		return pathWithin(comparablePath(home), cp)
+	}
+	return false
+}
+
+// confirmAgentPath asks the user before a write-capable tool runs on a broad
+// path picked by the agent. This is independent of the risk policy and of
+// --allow-protected; without a terminal the run is refused.
+func confirmAgentPath(path string) bool {
+	if pathConfirmer != nil {
+		return !isBroadPath(path) || pathConfirmer(path)
+	}
+	return confirmBroadPath(path, bufio.NewReader(os.Stdin), ui.StdinIsTerminal())
+}
+
+func confirmBroadPath(path string, r *bufio.Reader, interactive bool) bool {
+	if !isBroadPath(path) {
+		return true
+	}
+	fmt.Println(ui.Warn(fmt.Sprintf("The agent chose a very broad path: %s", path)))
+	if !interactive {
+		fmt.Println(ui.Error("Error:"), "refusing to modify a broad path without confirmation; pass a narrower base.")
+		return false
+	}
+	if strings.ToLower(strings.TrimSpace(prompt(r, "Type 'yes' to continue", ""))) != "yes" {
+		fmt.Println(ui.Warn("Canceled."))
+		return false
+	}
+	return true
+}
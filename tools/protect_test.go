@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProtectedReasonRootsAndHome(t *testing.T) {
+	root := filepath.VolumeName(t.TempDir()) + string(filepath.Separator)
+	if protectedReason(root) == "" {
+		t.Fatalf("expected %s to be protected", root)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		t.Skip("no home directory")
+	}
+	if protectedReason(home) == "" {
+		t.Fatalf("expected home %s to be protected", home)
+	}
+	if got := protectedReason(filepath.Join(home, "Downloads")); got != "" {
+		t.Fatalf("expected home subdir to be allowed, got %q", got)
+	}
+}
+
+func TestProtectedReasonConfigPaths(t *testing.T) {
+	dir := t.TempDir()
+	keep := filepath.Join(dir, "keep")
+	SetProtectedPaths([]string{" ", keep})
+	defer SetProtectedPaths(nil)
+
+	if protectedReason(filepath.Join(keep, "sub")) == "" {
+		t.Fatal("expected path below a configured entry to be protected")
+	}
+	if got := protectedReason(keep + "-other"); got != "" {
+		t.Fatalf("expected sibling with shared prefix to be allowed, got %q", got)
+	}
+	if got := protectedReason(dir); got != "" {
+		t.Fatalf("expected parent of configured entry to be allowed, got %q", got)
+	}
+}
+
+func TestGuardProtectedOverride(t *testing.T) {
+	dir := t.TempDir()
+	SetProtectedPaths([]string{dir})
+	defer SetProtectedPaths(nil)
+
+	if guardProtected(dir) {
+		t.Fatal("expected guard to refuse protected path")
+	}
+	SetAllowProtected(true)
+	defer SetAllowProtected(false)
+	if !guardProtected(dir) {
+		t.Fatal("expected --allow-protected to lift the guard")
+	}
+}
+
+func TestRunCleanEmptyAutoRefusesProtectedBase(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	if err := os.Mkdir(empty, 0755); err != nil {
+		t.Fatal(err)
+	}
+	SetProtectedPaths([]string{dir})
+	defer SetProtectedPaths(nil)
+
+	if code := RunCleanEmptyAuto(dir, map[string]string{"base": dir, "apply": "true"}); code != 1 {
+		t.Fatalf("expected refusal exit code 1, got %d", code)
+	}
+	if _, err := os.Stat(empty); err != nil {
+		t.Fatalf("expected empty dir to survive, got %v", err)
+	}
+}
+
+func TestIsBroadPath(t *testing.T) {
+	root := filepath.VolumeName(t.TempDir()) + string(filepath.Separator)
+	deep := filepath.Join(t.TempDir(), "a", "b")
+	cases := map[string]bool{
+		root:                       true,
+		filepath.Join(root, "srv"): true,
+		deep:                       false,
+	}
+	if home, err := os.UserHomeDir(); err == nil && filepath.Dir(filepath.Dir(home)) != filepath.Dir(home) {
+		cases[home] = true
+		cases[filepath.Dir(home)] = true
+		cases[filepath.Join(home, "Downloads", "old")] = false
+	}
+	for p, want := range cases {
+		if got := isBroadPath(p); got != want {
+			t.Fatalf("isBroadPath(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestConfirmBroadPath(t *testing.T) {
+	root := filepath.VolumeName(t.TempDir()) + string(filepath.Separator)
+	reader := func(s string) *bufio.Reader { return bufio.NewReader(strings.NewReader(s)) }
+
+	if !confirmBroadPath(filepath.Join(t.TempDir(), "a", "b"), reader(""), false) {
+		t.Fatal("expected narrow path to pass without confirmation")
+	}
+	if confirmBroadPath(root, reader("yes\n"), false) {
+		t.Fatal("expected broad path to be refused without a terminal")
+	}
+	if confirmBroadPath(root, reader("y\n"), true) {
+		t.Fatal("expected anything but 'yes' to cancel")
+	}
+	if !confirmBroadPath(root, reader("YES\n"), true) {
+		t.Fatal("expected 'yes' to confirm")
+	}
+}
+
+func TestConfirmAgentPathUsesPathConfirmer(t *testing.T) {
+	root := filepath.VolumeName(t.TempDir()) + string(filepath.Separator)
+	var asked []string
+	SetPathConfirmer(func(path string) bool {
+		asked = append(asked, path)
+		return true
+	})
+	defer SetPathConfirmer(nil)
+
+	if !confirmAgentPath(filepath.Join(t.TempDir(), "a", "b")) || len(asked) != 0 {
+		t.Fatalf("expected narrow path to pass without asking, asked %v", asked)
+	}
+	if !confirmAgentPath(root) || len(asked) != 1 || asked[0] != root {
+		t.Fatalf("expected broad path to go through the confirmer, asked %v", asked)
+	}
+}
@@ -12,6 +12,36 @@ import (
 	"cli/internal/ui"
 )
 
+func init() { registerTool(readTool{}) }
+
+type readTool struct{}
+
+func (readTool) Describe() ToolDescriptor {
+	return ToolDescriptor{
+		Key:      "f",
+		Name:     "read",
+		Synopsis: "Read file contents or list directory",
+		Aliases:  []string{"cat", "view"},
+		Args: []ArgSpec{
+			{Name: "path", Type: ArgPath, Required: true},
+			{Name: "offset", Type: ArgInt, Default: "1", Description: "start line"},
+			{Name: "limit", Type: ArgInt, Default: "100", Description: "max lines"},
+		},
+		RiskLevel: "low",
+		RiskNote:  "read/inspect operation",
+		Short:     "Read file contents or list directory",
+		Help:      "Asks for a path, then prints file lines (or lists the directory).",
+		Example:   "dm tools read\ndm tools cat",
+		Order:     60,
+	}
+}
+
+func (readTool) RunInteractive(_ string, r *bufio.Reader) int { return RunRead(r) }
+
+func (readTool) RunAuto(baseDir string, params ToolParams) AutoRunResult {
+	return RunReadAutoDetailed(baseDir, params)
+}
+
 const (
 	readDefaultLimit = 100
 	readMaxLimit     = 500
@@ -98,7 +128,7 @@ func printFileContents(path string, startLine, limit int) int {
 			if fi != nil && !e.IsDir() {
 				size = formatReadSize(fi.Size())
 			}
-			fmt.Printf("  %s  %-40s %s\n", kind, e.Name(), size)
+			fmt.Printf("  %s  %s %s\n", kind, ui.PadRight(ui.TruncateMiddle(e.Name(), 40), 40), size)
 			shown++
 		}
 		return 0
@@ -11,9 +11,45 @@ import (
 	"time"
 
 	"cli/internal/filesearch"
+	"cli/internal/platform"
 	"cli/internal/ui"
 )
 
+func init() { registerTool(recentTool{}) }
+
+type recentTool struct{}
+
+func (recentTool) Describe() ToolDescriptor {
+	return ToolDescriptor{
+		Key:      "e",
+		Name:     "recent",
+		Synopsis: "Show recent files",
+		Aliases:  []string{"rec"},
+		Args: []ArgSpec{
+			{Name: "base", Type: ArgPath},
+			{Name: "ext", Type: ArgString, Description: "only this extension e.g. pdf"},
+			{Name: "exclude", Type: ArgString, Description: "comma-separated globs e.g. node_modules,*.tmp"},
+			{Name: "since", Type: ArgString, Enum: []string{"all", "today", "week"}, Default: "all"},
+			{Name: "group", Type: ArgBool, Default: "false", Description: "group files by directory"},
+			{Name: "limit", Type: ArgInt, Description: "page size"},
+			{Name: "offset", Type: ArgInt},
+			{Name: "cursor", Type: ArgString, Description: "continuation token from a previous page"},
+		},
+		RiskLevel: "low",
+		RiskNote:  "read/inspect operation",
+		Short:     "Show recent files",
+		Help:      "Asks for base path, extension, exclude globs, period (all/today/week), grouping and limit, then lists most recently modified files.",
+		Example:   "dm tools recent",
+		Order:     30,
+	}
+}
+
+func (recentTool) RunInteractive(_ string, r *bufio.Reader) int { return RunRecent(r) }
+
+func (recentTool) RunAuto(baseDir string, params ToolParams) AutoRunResult {
+	return RunRecentAutoDetailed(baseDir, params)
+}
+
 type recentItem struct {
 	Path    string
 	ModTime time.Time
@@ -21,7 +57,7 @@ type recentItem struct {
 }
 
 func RunRecent(r *bufio.Reader) int {
-	base := prompt(r, "Base path", currentWorkingDir("."))
+	base := prompt(r, "Base path", ToolDefault("recent", "base", currentWorkingDir(".")))
 	base = normalizeInputPath(base, currentWorkingDir("."))
 	if strings.TrimSpace(base) == "" {
 		fmt.Println("Error: base path is required.")
@@ -32,14 +68,25 @@ func RunRecent(r *bufio.Reader) int {
 		fmt.Println(ui.Muted("Hint: use '.' for current dir or '..' for parent dir."))
 		return 1
 	}
-	limitStr := prompt(r, "Limit", "20")
+	filter, err := parseRecentFilter(
+		prompt(r, "Extension (optional)", ToolDefault("recent", "ext", "")),
+		prompt(r, "Exclude globs (comma separated, optional)", ToolDefault("recent", "exclude", "")),
+		prompt(r, "Period (all|today|week)", ToolDefault("recent", "since", "all")),
+		prompt(r, "Group by directory? (y/N)", ToolDefault("recent", "group", "n")),
+		time.Now(),
+	)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	limitStr := prompt(r, "Limit", ToolDefault("recent", "limit", "20"))
 	limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
 	if err != nil || limit <= 0 {
 		fmt.Println("Error: invalid limit.")
 		return 1
 	}
 
-	_, _, code := runRecentQuery(base, 0, limit)
+	_, _, code := runRecentQuery(base, filter, 0, limit)
 	return code
 }
 
@@ -49,58 +96,47 @@ func RunRecentAutoDetailed(baseDir string, params map[string]string) AutoRunResu
 		base = currentWorkingDir(baseDir)
 	}
 	base = normalizeAgentPath(base, baseDir)
-	limit := 10
-	if rawLimit := strings.TrimSpace(params["limit"]); rawLimit != "" {
-		if n, err := strconv.Atoi(rawLimit); err == nil && n > 0 {
-			limit = n
-		}
-	}
-	offset := 0
-	if rawOffset := strings.TrimSpace(params["offset"]); rawOffset != "" {
-		if n, err := strconv.Atoi(rawOffset); err == nil && n >= 0 {
-			offset = n
-		}
+	filter, err := parseRecentFilter(params["ext"], params["exclude"], params["since"], params["group"], nowFunc())
+	if err != nil {
+		fmt.Println("Error:", err)
+		return AutoRunResult{Code: 1}
 	}
 	cacheKey := strings.ToLower(strings.TrimSpace(base))
-	items, err := getOrLoadRecentPageResults(cacheKey, func() ([]recentItem, error) {
+	queryKey := cacheKey + "|" + filter.key()
+	req, err := parsePageRequest(params, queryKey)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return AutoRunResult{Code: 1}
+	}
+	items, err := recentPages.getOrLoad(cacheKey, func() ([]recentItem, error) {
 		return collectRecentSorted(base)
 	})
 	if err != nil {
 		fmt.Println("Error:", err)
 		return AutoRunResult{Code: 1}
 	}
-	shown, total, code := runRecentPage(items, offset, limit)
+	items = filter.apply(base, items)
+	shown, total, code := runRecentPage(items, req.Offset, req.Limit, filter.Group)
 	if code != 0 {
 		return AutoRunResult{Code: code}
 	}
-	nextOffset := offset + shown
-	if nextOffset < total {
-		next := copyStringMap(params)
-		next["offset"] = strconv.Itoa(nextOffset)
-		next["limit"] = strconv.Itoa(limit)
-		return AutoRunResult{
-			Code:           0,
-			CanContinue:    true,
-			ContinuePrompt: fmt.Sprintf("Show next %d recent files? [Y/n]: ", limit),
-			ContinueParams: next,
-		}
-	}
-	return AutoRunResult{Code: 0}
+	res := continuePage(params, queryKey, req, shown, total, "recent files")
+	res.Rows = pageRows(items, req.Offset, shown, func(it recentItem) ResultRow {
+		return ResultRow{Path: it.Path, Size: it.Size, ModTime: it.ModTime}
+	})
+	return res
 }
 
-func runRecentQuery(base string, offset, limit int) (int, int, int) {
-	items, err := collectRecent(base)
+func runRecentQuery(base string, filter recentFilter, offset, limit int) (int, int, int) {
+	items, err := collectRecentSorted(base)
 	if err != nil {
 		fmt.Println("Error:", err)
 		return 0, 0, 1
 	}
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].ModTime.After(items[j].ModTime)
-	})
-	return runRecentPage(items, offset, limit)
+	return runRecentPage(filter.apply(base, items), offset, limit, filter.Group)
 }
 
-func runRecentPage(items []recentItem, offset, limit int) (int, int, int) {
+func runRecentPage(items []recentItem, offset, limit int, group bool) (int, int, int) {
 	if len(items) == 0 {
 		fmt.Println("No files found.")
 		return 0, 0, 0
@@ -120,7 +156,16 @@ func runRecentPage(items []recentItem, offset, limit int) (int, int, int) {
 	end := offset + len(show)
 	fmt.Printf("Showing %d-%d of %d files\n", start, end, len(items))
 
+	prevDir := ""
 	for _, it := range show {
+		if group {
+			if dir := filepath.Dir(it.Path); dir != prevDir {
+				fmt.Println(ui.Accent(dir))
+				prevDir = dir
+			}
+			fmt.Printf("  %s | %s | %s\n", it.ModTime.Format("2006-01-02 15:04"), filesearch.FormatSize(it.Size), filepath.Base(it.Path))
+			continue
+		}
 		fmt.Printf("%s | %s | %s\n", it.ModTime.Format("2006-01-02 15:04"), filesearch.FormatSize(it.Size), it.Path)
 	}
 	if len(items) > end {
@@ -129,6 +174,13 @@ func runRecentPage(items []recentItem, offset, limit int) (int, int, int) {
 	return len(show), len(items), 0
 }
 
+// CountRecent walks base the way the recent tool does and returns the number
+// of files seen; dm bench uses it.
+func CountRecent(base string) (int, error) {
+	items, err := collectRecentSorted(base)
+	return len(items), err
+}
+
 func collectRecentSorted(base string) ([]recentItem, error) {
 	items, err := collectRecent(base)
 	if err != nil {
@@ -142,7 +194,7 @@ func collectRecentSorted(base string) ([]recentItem, error) {
 
 func collectRecent(base string) ([]recentItem, error) {
 	var items []recentItem
-	err := filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+	err := filepath.WalkDir(platform.LongPath(base), func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -154,7 +206,7 @@ func collectRecent(base string) ([]recentItem, error) {
 			return nil
 		}
 		items = append(items, recentItem{
-			Path:    path,
+			Path:    platform.ShortPath(path),
 			ModTime: info.ModTime(),
 			Size:    info.Size(),
 		})
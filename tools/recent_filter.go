@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// recentFilter narrows the recent listing; the zero value keeps everything.
+type recentFilter struct {
+	Ext     string
+	Exclude []string
+	Period  string
+	Since   time.Time
+	Group   bool
+}
+
+func parseRecentFilter(ext, exclude, period, group string, now time.Time) (recentFilter, error) {
+	var f recentFilter
+	if ext = strings.ToLower(strings.TrimSpace(ext)); ext != "" {
+		f.Ext = "." + strings.TrimPrefix(ext, ".")
+	}
+	globs, err := parseExcludeGlobs(exclude)
+	if err != nil {
+		return recentFilter{}, err
+	}
+	f.Exclude = globs
+	switch p := strings.ToLower(strings.TrimSpace(period)); p {
+	case "", "all":
+	case "today":
+		f.Period = p
+		f.Since = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	case "week":
+		f.Period = p
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		daysSinceMonday := (int(now.Weekday()) + 6) % 7
+		f.Since = midnight.AddDate(0, 0, -daysSinceMonday)
+	default:
+		return recentFilter{}, fmt.Errorf("invalid period %q (use all|today|week)", period)
+	}
+	switch strings.ToLower(strings.TrimSpace(group)) {
+	case "y", "yes", "true", "1":
+		f.Group = true
+	}
+	return f, nil
+}
+
+// key identifies the filter in page cursors so they cannot be reused across
+// different filters.
+func (f recentFilter) key() string {
+	return strings.Join([]string{f.Ext, strings.Join(f.Exclude, ","), f.Period, fmt.Sprint(f.Group)}, "|")
+}
+
+// apply filters items (sorted newest first) and, when grouping, orders them
+// by directory, most recently touched directory first.
+func (f recentFilter) apply(base string, items []recentItem) []recentItem {
+	out := make([]recentItem, 0, len(items))
+	for _, it := range items {
+		if f.Ext != "" && strings.ToLower(filepath.Ext(it.Path)) != f.Ext {
+			continue
+		}
+		if !f.Since.IsZero() && it.ModTime.Before(f.Since) {
+			continue
+		}
+		if f.excluded(base, it.Path) {
+			continue
+		}
+		out = append(out, it)
+	}
+	if f.Group {
+		rank := map[string]int{}
+		for _, it := range out {
+			dir := filepath.Dir(it.Path)
+			if _, ok := rank[dir]; !ok {
+				rank[dir] = len(rank)
+			}
+		}
+		sort.SliceStable(out, func(i, j int) bool {
+			return rank[filepath.Dir(out[i].Path)] < rank[filepath.Dir(out[j].Path)]
+		})
+	}
+	return out
+}
+
+func (f recentFilter) excluded(base, path string) bool {
+	return excludedPath(base, path, f.Exclude)
+}
+
+// parseExcludeGlobs splits a comma-separated list of exclude globs.
+func parseExcludeGlobs(exclude string) ([]string, error) {
+	var globs []string
+	for _, g := range strings.Split(exclude, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			if _, err := filepath.Match(g, ""); err != nil {
+				return nil, fmt.Errorf("invalid exclude glob %q", g)
+			}
+			globs = append(globs, g)
+		}
+	}
+	return globs, nil
+}
+
+// excludedPath matches globs without a slash against every path segment
+// (so "node_modules" skips the whole tree) and globs with one against the
+// path relative to base.
+func excludedPath(base, path string, globs []string) bool {
+	if len(globs) == 0 {
+		return false
+	}
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		rel = path
+	}
+	rel = filepath.ToSlash(rel)
+	segments := strings.Split(rel, "/")
+	for _, g := range globs {
+		g = filepath.ToSlash(g)
+		if strings.Contains(g, "/") {
+			if ok, _ := filepath.Match(g, rel); ok {
+				return true
+			}
+			continue
+		}
+		for _, s := range segments {
+			if ok, _ := filepath.Match(g, s); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
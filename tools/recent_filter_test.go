@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRecentFilterPeriods(t *testing.T) {
+	now := time.Date(2026, 3, 12, 15, 30, 0, 0, time.UTC) // Thursday
+	f, err := parseRecentFilter("PDF", " node_modules , *.tmp ", "week", "yes", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Ext != ".pdf" || !f.Group || !reflect.DeepEqual(f.Exclude, []string{"node_modules", "*.tmp"}) {
+		t.Fatalf("unexpected filter %+v", f)
+	}
+	if want := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC); !f.Since.Equal(want) {
+		t.Fatalf("week starts %v, want %v", f.Since, want)
+	}
+	f, _ = parseRecentFilter("", "", "today", "", now)
+	if want := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC); !f.Since.Equal(want) {
+		t.Fatalf("today starts %v, want %v", f.Since, want)
+	}
+	if _, err := parseRecentFilter("", "", "month", "", now); err == nil {
+		t.Fatal("expected invalid period error")
+	}
+	if _, err := parseRecentFilter("", "[", "", "", now); err == nil {
+		t.Fatal("expected invalid glob error")
+	}
+}
+
+func TestRecentFilterApply(t *testing.T) {
+	base := filepath.Join("root")
+	now := time.Date(2026, 3, 12, 12, 0, 0, 0, time.UTC)
+	items := []recentItem{
+		{Path: filepath.Join(base, "a", "new.pdf"), ModTime: now},
+		{Path: filepath.Join(base, "b", "mid.pdf"), ModTime: now.Add(-time.Hour)},
+		{Path: filepath.Join(base, "node_modules", "x", "dep.pdf"), ModTime: now.Add(-2 * time.Hour)},
+		{Path: filepath.Join(base, "a", "old.pdf"), ModTime: now.Add(-3 * time.Hour)},
+		{Path: filepath.Join(base, "a", "notes.txt"), ModTime: now.Add(-4 * time.Hour)},
+		{Path: filepath.Join(base, "b", "ancient.pdf"), ModTime: now.AddDate(0, 0, -10)},
+	}
+	f, err := parseRecentFilter("pdf", "node_modules", "week", "true", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := f.apply(base, items)
+	var names []string
+	for _, it := range got {
+		names = append(names, filepath.Base(it.Path))
+	}
+	want := []string{"new.pdf", "old.pdf", "mid.pdf"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+	if f.key() == (recentFilter{}).key() {
+		t.Fatal("expected filter key to differ from the empty filter")
+	}
+}
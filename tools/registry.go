@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"bufio"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ToolParams map[string]string
+
+func (p ToolParams) String(key, def string) string {
+	if v := strings.TrimSpace(p[key]); v != "" {
+		return v
+	}
+	return def
+}
+
+func (p ToolParams) Int(key string, def int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(p[key]))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func (p ToolParams) Bool(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(p[key])) {
+	case "1", "true", "yes", "y":
+		return true
+	}
+	return false
+}
+
+// Tool is a self-describing tool: its descriptor drives the menu, the agent
+// catalog, risk assessment and name resolution.
+type Tool interface {
+	Describe() ToolDescriptor
+	RunInteractive(baseDir string, r *bufio.Reader) int
+	RunAuto(baseDir string, params ToolParams) AutoRunResult
+}
+
+type riskAssessor interface {
+	Risk(params ToolParams) (string, string)
+}
+
+var registry []Tool
+
+func registerTool(t Tool) {
+	registry = append(registry, t)
+	sort.SliceStable(registry, func(i, j int) bool {
+		return registry[i].Describe().Order < registry[j].Describe().Order
+	})
+}
+
+func unregisterTools(keep func(Tool) bool) {
+	kept := registry[:0:0]
+	for _, t := range registry {
+		if keep(t) {
+			kept = append(kept, t)
+		}
+	}
+	registry = kept
+}
+
+func Descriptors() []ToolDescriptor {
+	out := make([]ToolDescriptor, 0, len(registry))
+	for _, t := range registry {
+		out = append(out, describe(t))
+	}
+	return out
+}
+
+func lookupTool(name string) Tool {
+	lc := strings.ToLower(strings.TrimSpace(name))
+	if lc == "" {
+		return nil
+	}
+	for i, t := range registry {
+		d := t.Describe()
+		if lc == d.Name || (d.Key != "" && lc == d.Key) || lc == strconv.Itoa(i+1) {
+			return t
+		}
+		for _, alias := range d.Aliases {
+			if lc == strings.ToLower(alias) {
+				return t
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryDescriptorsResolve(t *testing.T) {
+	for _, d := range Descriptors() {
+		if got := lookupTool(d.Name); got == nil || got.Describe().Name != d.Name {
+			t.Fatalf("expected %q to resolve to itself", d.Name)
+		}
+		for _, alias := range d.Aliases {
+			if got := lookupTool(alias); got == nil || got.Describe().Name != d.Name {
+				t.Fatalf("expected alias %q to resolve to %q", alias, d.Name)
+			}
+		}
+	}
+}
+
+func TestRegistryCatalogListsEveryTool(t *testing.T) {
+	catalog := BuildAgentCatalog()
+	for _, d := range Descriptors() {
+		if !strings.Contains(catalog, d.Name) {
+			t.Fatalf("expected catalog to mention %q", d.Name)
+		}
+		if level, _ := ToolRisk(d.Name, nil); level != d.RiskLevel {
+			t.Fatalf("expected risk %q for %q, got %q", d.RiskLevel, d.Name, level)
+		}
+	}
+}
+
+func TestCleanApplyIsHighRisk(t *testing.T) {
+	level, _ := ToolRisk("clean", map[string]string{"apply": "true"})
+	if level != "high" {
+		t.Fatalf("expected high risk for clean apply, got %q", level)
+	}
+}
+
+func TestValidateToolArgs(t *testing.T) {
+	if err := ValidateToolArgs("read", map[string]string{"path": "a.txt", "limit": "5"}); err != nil {
+		t.Fatalf("expected valid args, got %v", err)
+	}
+	if err := ValidateToolArgs("read", map[string]string{"limit": "5"}); err == nil || !strings.Contains(err.Error(), "path is required") {
+		t.Fatalf("expected missing path error, got %v", err)
+	}
+	if err := ValidateToolArgs("search", map[string]string{"limit": "ten"}); err == nil || !strings.Contains(err.Error(), "limit must be an integer") {
+		t.Fatalf("expected integer error, got %v", err)
+	}
+	if err := ValidateToolArgs("diff", map[string]string{"mode": "svn"}); err == nil || !strings.Contains(err.Error(), "git|files") {
+		t.Fatalf("expected enum error, got %v", err)
+	}
+	if err := ValidateToolArgs("clean", map[string]string{"apply": "maybe"}); err == nil {
+		t.Fatal("expected bool error")
+	}
+	if err := ValidateToolArgs("search", map[string]string{"unknown": "x"}); err != nil {
+		t.Fatalf("expected undeclared keys to be ignored, got %v", err)
+	}
+}
+
+func TestFormatArgSpecs(t *testing.T) {
+	got := formatArgSpecs([]ArgSpec{
+		{Name: "path", Type: ArgPath, Required: true},
+		{Name: "mode", Enum: []string{"git", "files"}, Default: "git"},
+	})
+	want := "path (path, required), mode (git|files, default git)"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -4,12 +4,45 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"cli/internal/renamer"
 	"cli/internal/ui"
 )
 
+func init() { registerTool(renameTool{}) }
+
+type renameTool struct{}
+
+func (renameTool) Describe() ToolDescriptor {
+	return ToolDescriptor{
+		Key:      "r",
+		Name:     "rename",
+		Synopsis: "Batch rename files with preview",
+		Aliases:  []string{"r"},
+		Args: []ArgSpec{
+			{Name: "base", Type: ArgPath},
+			{Name: "from", Type: ArgString},
+			{Name: "to", Type: ArgString},
+			{Name: "name", Type: ArgString},
+			{Name: "case_sensitive", Type: ArgBool},
+		},
+		RiskLevel: "medium",
+		RiskNote:  "batch rename files",
+		Short:     "Batch rename files with preview",
+		Help:      "Asks for base path, filter and replace rules, then shows a preview before applying changes.",
+		Example:   "dm tools rename",
+		Order:     20,
+	}
+}
+
+func (renameTool) RunInteractive(baseDir string, r *bufio.Reader) int { return RunRename(baseDir, r) }
+
+func (renameTool) RunAuto(baseDir string, params ToolParams) AutoRunResult {
+	return RunRenameAutoDetailed(baseDir, params)
+}
+
 func RunRename(baseDir string, r *bufio.Reader) int {
 	cleanBase := normalizeInputPath(prompt(r, "Base path", currentWorkingDir(baseDir)), currentWorkingDir(baseDir))
 	if err := validateExistingDir(cleanBase, "base path"); err != nil {
@@ -17,6 +50,9 @@ func RunRename(baseDir string, r *bufio.Reader) int {
 		fmt.Println(ui.Muted("Hint: use '.' for current dir or '..' for parent dir."))
 		return 1
 	}
+	if !guardProtected(cleanBase) {
+		return 1
+	}
 	opts := renamer.Options{
 		BasePath:      cleanBase,
 		NamePart:      prompt(r, "Name contains (optional)", ""),
@@ -42,10 +78,7 @@ func RunRename(baseDir string, r *bufio.Reader) int {
 		return 0
 	}
 
-	fmt.Println("\nPreview:")
-	for _, item := range plan {
-		fmt.Printf("%s -> %s\n", item.OldPath, item.NewPath)
-	}
+	printRenamePreview(plan)
 
 	confirm := prompt(r, "Proceed? [y/N]", "N")
 	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
@@ -53,6 +86,11 @@ func RunRename(baseDir string, r *bufio.Reader) int {
 		return 0
 	}
 
+	release, ok := lockPath("rename apply", cleanBase)
+	if !ok {
+		return 1
+	}
+	defer release()
 	if err := renamer.ApplyPlan(plan); err != nil {
 		fmt.Println("Error:", err)
 		return 1
@@ -65,9 +103,13 @@ func RunRenameAutoDetailed(baseDir string, params map[string]string) AutoRunResu
 	reader := bufio.NewReader(os.Stdin)
 	cwd := currentWorkingDir(baseDir)
 
-	base, ok := params["base"]
-	if !ok || strings.TrimSpace(base) == "" {
-		base = prompt(reader, "Base path", cwd)
+	base, fromAgent := params["base"]
+	if !fromAgent || strings.TrimSpace(base) == "" {
+		var ok bool
+		if base, ok = askAuto(reader, "Base path", cwd); !ok {
+			return AutoRunResult{Code: 1}
+		}
+		fromAgent = false
 	}
 	base = normalizeInputPath(base, cwd)
 	if err := validateExistingDir(base, "base path"); err != nil {
@@ -75,10 +117,18 @@ func RunRenameAutoDetailed(baseDir string, params map[string]string) AutoRunResu
 		fmt.Println(ui.Muted("Hint: use '.' for current dir or '..' for parent dir."))
 		return AutoRunResult{Code: 1}
 	}
+	if !guardProtected(base) {
+		return AutoRunResult{Code: 1}
+	}
+	if fromAgent && !confirmAgentPath(base) {
+		return AutoRunResult{Code: 1}
+	}
 
 	from, ok := params["from"]
 	if !ok || strings.TrimSpace(from) == "" {
-		from = prompt(reader, "Replace from", "")
+		if from, ok = askAuto(reader, "Replace from", ""); !ok {
+			return AutoRunResult{Code: 1}
+		}
 	}
 	from = strings.TrimSpace(from)
 	if from == "" {
@@ -88,12 +138,16 @@ func RunRenameAutoDetailed(baseDir string, params map[string]string) AutoRunResu
 
 	namePart := strings.TrimSpace(params["name"])
 	if _, has := params["name"]; !has {
-		namePart = prompt(reader, "Name contains (optional)", "")
+		if namePart, ok = askAuto(reader, "Name contains (optional)", ""); !ok {
+			return AutoRunResult{Code: 1}
+		}
 	}
 
 	to, hasTo := params["to"]
 	if !hasTo {
-		to = prompt(reader, "Replace to (empty = delete)", "")
+		if to, ok = askAuto(reader, "Replace to (empty = delete)", ""); !ok {
+			return AutoRunResult{Code: 1}
+		}
 	}
 
 	caseSensitive := false
@@ -101,7 +155,7 @@ func RunRenameAutoDetailed(baseDir string, params map[string]string) AutoRunResu
 		v := strings.ToLower(strings.TrimSpace(rawCase))
 		caseSensitive = v == "1" || v == "true" || v == "yes" || v == "y"
 	} else {
-		caseSensitive = strings.ToLower(strings.TrimSpace(prompt(reader, "Case sensitive for replace? (y/N)", "N"))) == "y"
+		caseSensitive = confirmAuto(reader, "Case sensitive for replace?")
 	}
 
 	opts := renamer.Options{
@@ -124,17 +178,18 @@ func RunRenameAutoDetailed(baseDir string, params map[string]string) AutoRunResu
 		return AutoRunResult{Code: 0}
 	}
 
-	fmt.Println("\nPreview:")
-	for _, item := range plan {
-		fmt.Printf("%s -> %s\n", item.OldPath, item.NewPath)
-	}
+	printRenamePreview(plan)
 
-	confirm := prompt(reader, "Apply these renames? [y/N]", "N")
-	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+	if !confirmAuto(reader, "Apply these renames?") {
 		fmt.Println(ui.Warn("Canceled."))
 		return AutoRunResult{Code: 0}
 	}
 
+	release, ok := lockPath("rename apply", base)
+	if !ok {
+		return AutoRunResult{Code: 1}
+	}
+	defer release()
 	if err := renamer.ApplyPlan(plan); err != nil {
 		fmt.Println("Error:", err)
 		return AutoRunResult{Code: 1}
@@ -142,3 +197,25 @@ func RunRenameAutoDetailed(baseDir string, params map[string]string) AutoRunResu
 	fmt.Println("Done.")
 	return AutoRunResult{Code: 0}
 }
+
+// printRenamePreview lists the planned renames. When a file stays in its
+// folder only the new name is shown, and long paths are shortened in the
+// middle so each entry fits on one terminal line.
+func printRenamePreview(plan []renamer.PlanItem) {
+	fmt.Println("\nPreview:")
+	for _, item := range plan {
+		fmt.Println(formatRenamePreview(item.OldPath, item.NewPath, ui.TerminalWidth()))
+	}
+}
+
+func formatRenamePreview(oldPath, newPath string, width int) string {
+	newShown := newPath
+	if filepath.Dir(oldPath) == filepath.Dir(newPath) {
+		newShown = filepath.Base(newPath)
+	}
+	if width <= 0 {
+		return oldPath + " -> " + newShown
+	}
+	newShown = ui.TruncateMiddle(newShown, max(width/2-2, 16))
+	return ui.TruncateMiddle(oldPath, max(width-ui.StringWidth(newShown)-4, 16)) + " -> " + newShown
+}
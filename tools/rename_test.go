@@ -0,0 +1,20 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatRenamePreview(t *testing.T) {
+	if got := formatRenamePreview("docs/a.txt", "docs/b.txt", 0); got != "docs/a.txt -> b.txt" {
+		t.Fatalf("unexpected %q", got)
+	}
+	if got := formatRenamePreview("docs/a.txt", "old/a.txt", 0); got != "docs/a.txt -> old/a.txt" {
+		t.Fatalf("unexpected %q", got)
+	}
+	old := "projects/client/" + strings.Repeat("nested/", 10) + "IMG_0001.jpg"
+	got := formatRenamePreview(old, "projects/client/"+strings.Repeat("nested/", 10)+"holiday-001.jpg", 60)
+	if len(got) > 60 || !strings.Contains(got, "IMG_0001.jpg -> holiday-001.jpg") {
+		t.Fatalf("unexpected %q (%d)", got, len(got))
+	}
+}
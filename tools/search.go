@@ -13,8 +13,42 @@ import (
 	"cli/internal/ui"
 )
 
+func init() { registerTool(searchTool{}) }
+
+type searchTool struct{}
+
+func (searchTool) Describe() ToolDescriptor {
+	return ToolDescriptor{
+		Key:      "s",
+		Name:     "search",
+		Synopsis: "Find files by filename (not content). Use when looking for files whose NAME contains a word.",
+		Aliases:  []string{"s"},
+		Args: []ArgSpec{
+			{Name: "base", Type: ArgPath},
+			{Name: "ext", Type: ArgString},
+			{Name: "name", Type: ArgString, Description: "substring match on filename"},
+			{Name: "sort", Type: ArgString, Enum: []string{"name", "date", "size"}, Default: "name"},
+			{Name: "limit", Type: ArgInt, Description: "page size"},
+			{Name: "offset", Type: ArgInt},
+			{Name: "cursor", Type: ArgString, Description: "continuation token from a previous page"},
+		},
+		RiskLevel: "low",
+		RiskNote:  "read/inspect operation",
+		Short:     "Search files by name/extension",
+		Help:      "Asks for base path, optional name fragment, extension and sort mode (name/date/size).",
+		Example:   "dm tools search\ndm -t s",
+		Order:     10,
+	}
+}
+
+func (searchTool) RunInteractive(_ string, r *bufio.Reader) int { return RunSearch(r) }
+
+func (searchTool) RunAuto(baseDir string, params ToolParams) AutoRunResult {
+	return RunSearchAutoDetailed(baseDir, params)
+}
+
 func RunSearch(r *bufio.Reader) int {
-	base := prompt(r, "Base path", currentWorkingDir("."))
+	base := prompt(r, "Base path", ToolDefault("search", "base", currentWorkingDir(".")))
 	base = normalizeInputPath(base, currentWorkingDir("."))
 	if strings.TrimSpace(base) == "" {
 		fmt.Println("Error: base path is required.")
@@ -25,9 +59,9 @@ func RunSearch(r *bufio.Reader) int {
 		fmt.Println(ui.Muted("Hint: use '.' for current dir or '..' for parent dir."))
 		return 1
 	}
-	name := prompt(r, "Name contains", "")
-	ext := prompt(r, "Extension (optional)", "")
-	sortBy := prompt(r, "Sort (name|date|size)", "name")
+	name := prompt(r, "Name contains", ToolDefault("search", "name", ""))
+	ext := prompt(r, "Extension (optional)", ToolDefault("search", "ext", ""))
+	sortBy := prompt(r, "Sort (name|date|size)", ToolDefault("search", "sort", "name"))
 
 	results, err := filesearch.Find(filesearch.Options{
 		BasePath: base,
@@ -43,6 +77,10 @@ func RunSearch(r *bufio.Reader) int {
 		fmt.Println("No files found.")
 		return 0
 	}
+	if limit := (ToolParams{"limit": ToolDefault("search", "limit", "")}).Int("limit", 0); limit > 0 && len(results) > limit {
+		fmt.Println(ui.Muted(fmt.Sprintf("Showing the first %d of %d files (limit from dm.json).", limit, len(results))))
+		results = results[:limit]
+	}
 	for i, item := range results {
 		idx := ui.Warn(fmt.Sprintf("%2d)", i+1))
 		fmt.Printf("%s %s | %s | %s\n", idx, item.ModTime.Format("2006-01-02 15:04"), filesearch.FormatSize(item.Size), item.Path)
@@ -73,20 +111,13 @@ func RunSearchAutoDetailed(baseDir string, params map[string]string) AutoRunResu
 	if sortBy == "" {
 		sortBy = "name"
 	}
-	limit := 10
-	if rawLimit := strings.TrimSpace(params["limit"]); rawLimit != "" {
-		if n, err := strconv.Atoi(rawLimit); err == nil && n > 0 {
-			limit = n
-		}
-	}
-	offset := 0
-	if rawOffset := strings.TrimSpace(params["offset"]); rawOffset != "" {
-		if n, err := strconv.Atoi(rawOffset); err == nil && n >= 0 {
-			offset = n
-		}
-	}
 	cacheKey := strings.ToLower(strings.Join([]string{base, name, ext, sortBy}, "|"))
-	results, err := getOrLoadSearchPageResults(cacheKey, func() ([]filesearch.Result, error) {
+	req, err := parsePageRequest(params, cacheKey)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return AutoRunResult{Code: 1}
+	}
+	results, err := searchPages.getOrLoad(cacheKey, func() ([]filesearch.Result, error) {
 		return filesearch.Find(filesearch.Options{
 			BasePath: base,
 			NamePart: name,
@@ -98,23 +129,15 @@ func RunSearchAutoDetailed(baseDir string, params map[string]string) AutoRunResu
 		fmt.Println("Error:", err)
 		return AutoRunResult{Code: 1}
 	}
-	shown, total, code := runSearchQueryFromResults(results, offset, limit, true)
+	shown, total, code := runSearchQueryFromResults(results, req.Offset, req.Limit, autoPrompter == nil)
 	if code != 0 {
 		return AutoRunResult{Code: code}
 	}
-	nextOffset := offset + shown
-	if nextOffset < total {
-		next := copyStringMap(params)
-		next["offset"] = strconv.Itoa(nextOffset)
-		next["limit"] = strconv.Itoa(limit)
-		return AutoRunResult{
-			Code:           0,
-			CanContinue:    true,
-			ContinuePrompt: fmt.Sprintf("Show next %d search results? [Y/n]: ", limit),
-			ContinueParams: next,
-		}
-	}
-	return AutoRunResult{Code: 0}
+	res := continuePage(params, cacheKey, req, shown, total, "search results")
+	res.Rows = pageRows(results, req.Offset, shown, func(r filesearch.Result) ResultRow {
+		return ResultRow{Path: r.Path, Size: r.Size, ModTime: r.ModTime}
+	})
+	return res
 }
 
 func runSearchQueryFromResults(results []filesearch.Result, offset, limit int, promptOpen bool) (int, int, int) {
@@ -3,6 +3,7 @@ package tools
 import (
 	"bufio"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -10,28 +11,99 @@ import (
 	"cli/internal/ui"
 )
 
-func RunSystemAuto() int {
-	return RunSystem(nil)
+func init() { registerTool(systemTool{}) }
+
+type systemTool struct{}
+
+func (systemTool) Describe() ToolDescriptor {
+	return ToolDescriptor{
+		Key:      "y",
+		Name:     "system",
+		Synopsis: "Show system/network snapshot",
+		Aliases:  []string{"sys", "htop"},
+		Args: []ArgSpec{
+			{Name: "sections", Type: ArgString, Default: "all", Description: "comma list of cpu,mem,disks,net,wifi,arp (wifi/arp are slow)"},
+			{Name: "resolve", Type: ArgBool, Default: "false", Description: "reverse-DNS the ARP neighbors"},
+		},
+		RiskLevel: "low",
+		RiskNote:  "read/inspect operation",
+		Short:     "Show system/network snapshot",
+		Help:      "Asks which sections to show (cpu, mem, disks, net, wifi, arp or all), then shows host, CPU, memory, disks, interfaces, Wi-Fi networks, and ARP LAN neighbors with their hardware vendor. resolve=true also looks up neighbor host names.",
+		Example:   "dm tools system\ndm tools sys\ndm tools htop",
+		Order:     50,
+	}
+}
+
+func (systemTool) RunInteractive(_ string, r *bufio.Reader) int { return RunSystem(r) }
+
+func (systemTool) RunAuto(_ string, params ToolParams) AutoRunResult {
+	return AutoRunResult{Code: RunSystemAuto(params["sections"], params.Bool("resolve"))}
+}
+
+const neighborResolveTimeout = 3 * time.Second
+
+func RunSystemAuto(sections string, resolve bool) int {
+	list, err := systeminfo.ParseSections(sections)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	s := systeminfo.CollectSections(list)
+	if resolve {
+		systeminfo.ResolveNeighborNames(s.LANNeighbors, neighborResolveTimeout)
+	}
+	printSystemSnapshot(s, resolve)
+	return 0
 }
 
-func RunSystem(_ *bufio.Reader) int {
-	s := systeminfo.Collect()
+func RunSystem(r *bufio.Reader) int {
+	sections := prompt(r, "Sections (all or "+strings.Join(systeminfo.AllSections, ",")+")", "all")
+	resolve := false
+	if list, err := systeminfo.ParseSections(sections); err == nil && slices.Contains(list, systeminfo.SectionARP) {
+		answer := prompt(r, "Resolve neighbor host names? [y/N]", "N")
+		resolve = strings.ToLower(strings.TrimSpace(answer)) == "y"
+	}
+	return RunSystemAuto(sections, resolve)
+}
 
+func printSystemSnapshot(s systeminfo.Snapshot, resolved bool) {
 	ui.PrintSection("System Snapshot")
 	ui.PrintKV("Generated", s.GeneratedAt.Format(time.RFC3339))
 	ui.PrintKV("Host", valueOrDash(s.System.Hostname))
 	ui.PrintKV("OS", fmt.Sprintf("%s/%s", s.System.OS, s.System.Arch))
 	ui.PrintKV("CPU", fmt.Sprintf("%d", s.System.CPUCount))
-	if !s.System.BootTime.IsZero() {
+	if s.Has(systeminfo.SectionCPU) && !s.System.BootTime.IsZero() {
 		uptime := time.Since(s.System.BootTime).Round(time.Minute)
 		ui.PrintKV("Boot time", s.System.BootTime.Format(time.RFC3339))
 		ui.PrintKV("Uptime", uptime.String())
 	}
-	if s.Memory.TotalBytes > 0 {
+	if s.Has(systeminfo.SectionMem) && s.Memory.TotalBytes > 0 {
 		used := s.Memory.TotalBytes - s.Memory.FreeBytes
 		ui.PrintKV("Memory", fmt.Sprintf("%s used / %s total", formatBytes(used), formatBytes(s.Memory.TotalBytes)))
 	}
 
+	if s.Has(systeminfo.SectionDisks) {
+		printSystemDisks(s)
+	}
+	if s.Has(systeminfo.SectionNet) {
+		printSystemInterfaces(s)
+	}
+	if s.Has(systeminfo.SectionWiFi) {
+		printSystemWiFi(s)
+	}
+	if s.Has(systeminfo.SectionARP) {
+		printSystemNeighbors(s, resolved)
+	}
+
+	if len(s.Warnings) > 0 {
+		ui.PrintSection("Warnings")
+		for _, w := range s.Warnings {
+			fmt.Printf("- %s\n", ui.Warn(w))
+		}
+	}
+}
+
+func printSystemDisks(s systeminfo.Snapshot) {
 	ui.PrintSection("Disks")
 	if len(s.Disks) == 0 {
 		fmt.Println(ui.Muted("- none"))
@@ -46,7 +118,9 @@ func RunSystem(_ *bufio.Reader) int {
 			fmt.Printf("%-5s %-13s %-13s %5.1f%%\n", d.Name, formatBytes(used), formatBytes(d.SizeBytes), usedPct)
 		}
 	}
+}
 
+func printSystemInterfaces(s systeminfo.Snapshot) {
 	ui.PrintSection("Interfaces")
 	if len(s.Interfaces) == 0 {
 		fmt.Println(ui.Muted("- none"))
@@ -61,21 +135,25 @@ func RunSystem(_ *bufio.Reader) int {
 			if len(inf.Addresses) > 0 {
 				addrs = strings.Join(inf.Addresses, ", ")
 			}
-			fmt.Printf("%-30s %-6s %-17s %s\n", inf.Name, state, valueOrDash(inf.Hardware), addrs)
+			fmt.Printf("%s %-6s %-17s %s\n", ui.PadRight(inf.Name, 30), state, valueOrDash(inf.Hardware), addrs)
 		}
 	}
+}
 
+func printSystemWiFi(s systeminfo.Snapshot) {
 	ui.PrintSection("Wi-Fi")
 	ui.PrintKV("Connected", valueOrDash(s.ConnectedWiFi))
 	if len(s.WiFiNetworks) == 0 {
 		fmt.Println(ui.Muted("- no networks detected"))
 	} else {
-		fmt.Printf("%-32s %-8s %s\n", "SSID", "Signal", "Auth")
+		fmt.Printf("%-32s %-8s %-16s %s\n", "SSID", "Signal", "Auth", "Vendor")
 		for _, net := range s.WiFiNetworks {
-			fmt.Printf("%-32s %-8s %s\n", valueOrDash(net.SSID), valueOrDash(net.Signal), valueOrDash(net.Authentication))
+			fmt.Printf("%s %-8s %s %s\n", ui.PadRight(valueOrDash(net.SSID), 32), valueOrDash(net.Signal), ui.PadRight(valueOrDash(net.Authentication), 16), valueOrDash(net.Vendor))
 		}
 	}
+}
 
+func printSystemNeighbors(s systeminfo.Snapshot, resolved bool) {
 	ui.PrintSection("LAN Neighbors (ARP)")
 	if len(s.LANNeighbors) == 0 {
 		fmt.Println(ui.Muted("- none"))
@@ -84,23 +162,23 @@ func RunSystem(_ *bufio.Reader) int {
 		if limit > 25 {
 			limit = 25
 		}
-		fmt.Printf("%-16s %-17s %s\n", "IP", "MAC", "Type")
+		header := fmt.Sprintf("%-16s %-17s %-20s %-8s", "IP", "MAC", "Vendor", "Type")
+		if resolved {
+			header += " Name"
+		}
+		fmt.Println(strings.TrimRight(header, " "))
 		for i := 0; i < limit; i++ {
 			n := s.LANNeighbors[i]
-			fmt.Printf("%-16s %-17s %s\n", n.IP, n.MAC, n.Type)
+			line := fmt.Sprintf("%-16s %-17s %s %-8s", n.IP, n.MAC, ui.PadRight(ui.Truncate(valueOrDash(n.Vendor), 20), 20), n.Type)
+			if resolved {
+				line += " " + valueOrDash(n.Hostname)
+			}
+			fmt.Println(strings.TrimRight(line, " "))
 		}
 		if len(s.LANNeighbors) > limit {
 			fmt.Println(ui.Muted(fmt.Sprintf("... and %d more", len(s.LANNeighbors)-limit)))
 		}
 	}
-
-	if len(s.Warnings) > 0 {
-		ui.PrintSection("Warnings")
-		for _, w := range s.Warnings {
-			fmt.Printf("- %s\n", ui.Warn(w))
-		}
-	}
-	return 0
 }
 
 func formatBytes(n uint64) string {